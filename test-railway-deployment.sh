@@ -18,7 +18,7 @@ fi
 
 # Test 2: Container startup
 echo "🔄 Testing container startup..."
-CONTAINER_ID=$(docker run -d -p 8080:8080 go-interview-practice-test)
+CONTAINER_ID=$(docker run -d -p 8080:8080 -e AUTH_PROVIDER=local go-interview-practice-test)
 
 # Wait for container to start
 echo "⏳ Waiting for container to start..."
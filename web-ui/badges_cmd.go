@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"web-ui/internal/config"
+	"web-ui/internal/services"
+)
+
+const badgesUsage = `usage: web-ui badges export [-out DIR]
+
+Writes every contributor's profile badges to DIR (default: badges/ in the
+repository) in the layout the server uses, so the directory can be published
+as-is; CI publishes it to the gh-pages branch and keeps the older copy on main
+up to date:
+
+  DIR/<user>.svg          full-size profile card
+  DIR/<user>/compact.svg  compact horizontal badge
+  DIR/<user>.json         shields.io endpoint badge
+  DIR/<user>/achievements.json  the contributor's achievements
+
+Contributors with private profiles are left out.
+
+DIR/<user>_compact.svg is written too, for links made before the badges were
+served.`
+
+// runBadgesCommand implements `web-ui badges ...`
+func runBadgesCommand(cfg *config.Config, args []string) error {
+	if len(args) == 0 || args[0] != "export" {
+		return fmt.Errorf("%s", badgesUsage)
+	}
+
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	out := flags.String("out", filepath.Join(cfg.Content.Root, "badges"), "directory to write the badges to")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	svc, err := services.NewServices(cfg)
+	if err != nil {
+		return err
+	}
+
+	var contributors []services.BadgeStats
+	for _, stats := range svc.Badges.AllStats() {
+		if svc.Profiles.IsPublic(stats.Username) {
+			contributors = append(contributors, stats)
+		}
+	}
+	for _, stats := range contributors {
+		files := map[string][]byte{
+			stats.Username + ".svg":                            stats.CardSVG(),
+			filepath.Join(stats.Username, "compact.svg"):       stats.CompactSVG(),
+			stats.Username + "_compact.svg":                    stats.CompactSVG(),
+			stats.Username + ".json":                           stats.ShieldsJSON(),
+			filepath.Join(stats.Username, "achievements.json"): stats.AchievementsJSON(),
+		}
+		for name, body := range files {
+			path := filepath.Join(*out, name)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return err
+			}
+			if err := os.WriteFile(path, body, 0644); err != nil {
+				return err
+			}
+		}
+	}
+
+	log.Printf("Exported badges for %d contributor(s) to %s", len(contributors), *out)
+	return nil
+}
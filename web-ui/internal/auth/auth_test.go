@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"web-ui/internal/config"
+)
+
+func TestSessionsRejectForgedAndExpiredCookies(t *testing.T) {
+	cfg := config.Default()
+	cfg.Server.DataDir = t.TempDir()
+	sessions, err := NewSessions(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// carry copies the cookies a response set onto a new request
+	carry := func(rec *httptest.ResponseRecorder) *http.Request {
+		r := httptest.NewRequest("GET", "/", nil)
+		for _, c := range rec.Result().Cookies() {
+			r.AddCookie(c)
+		}
+		return r
+	}
+
+	rec := httptest.NewRecorder()
+	sessions.Start(rec, httptest.NewRequest("GET", "/", nil), User{Login: "octocat"})
+	cookie := rec.Result().Cookies()[0]
+	if !cookie.HttpOnly || cookie.SameSite != http.SameSiteLaxMode {
+		t.Errorf("session cookie is not HttpOnly and SameSite=Lax: %+v", cookie)
+	}
+	if user, ok := sessions.User(carry(rec)); !ok || user.Login != "octocat" {
+		t.Fatalf("signed session read back as %+v, %v", user, ok)
+	}
+
+	// Changing the payload breaks the signature
+	forged := httptest.NewRequest("GET", "/", nil)
+	payload, signature, _ := strings.Cut(cookie.Value, ".")
+	forged.AddCookie(&http.Cookie{Name: sessionCookie, Value: strings.ToUpper(payload[:1]) + payload[1:] + "." + signature})
+	forged.AddCookie(&http.Cookie{Name: "username", Value: "octocat"})
+	if user, ok := sessions.User(forged); ok {
+		t.Errorf("forged session accepted as %+v", user)
+	}
+
+	// A key restored from the data directory still verifies; an expired session does not
+	again, _ := NewSessions(cfg)
+	if _, ok := again.User(carry(rec)); !ok {
+		t.Error("session did not survive a restart")
+	}
+	again.ttl = -time.Second
+	rec = httptest.NewRecorder()
+	again.Start(rec, httptest.NewRequest("GET", "/", nil), User{Login: "octocat"})
+	if _, ok := again.User(carry(rec)); ok {
+		t.Error("expired session accepted")
+	}
+
+	// The OAuth state only checks out in the browser that started the sign-in
+	rec = httptest.NewRecorder()
+	state := sessions.NewState(rec, httptest.NewRequest("GET", "/", nil), "/challenge/3")
+	if _, ok := sessions.CheckState(httptest.NewRecorder(), carry(rec), "other"); ok {
+		t.Error("mismatched state accepted")
+	}
+	if next, ok := sessions.CheckState(httptest.NewRecorder(), carry(rec), state); !ok || next != "/challenge/3" {
+		t.Errorf("state check = %q, %v", next, ok)
+	}
+	if _, ok := sessions.CheckState(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil), state); ok {
+		t.Error("state accepted without its cookie")
+	}
+}
+
+func TestGitHubProviderAgainstStub(t *testing.T) {
+	stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login/oauth/access_token":
+			if r.FormValue("client_secret") != "secret" || r.FormValue("code") != "good" {
+				json.NewEncoder(w).Encode(map[string]string{"error": "bad_verification_code"})
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]string{"access_token": "token"})
+		case "/user":
+			if r.Header.Get("Authorization") != "Bearer token" {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]string{"login": "octocat", "name": "The Octocat"})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer stub.Close()
+
+	cfg := config.Default()
+	cfg.Auth.Provider = "github"
+	cfg.Auth.GitHubClientID, cfg.Auth.GitHubClientSecret = "id", "secret"
+	cfg.Auth.GitHubURL, cfg.Auth.GitHubAPIURL = stub.URL, stub.URL
+	provider := NewProvider(cfg)
+
+	if url := provider.AuthCodeURL("s", "http://localhost/auth/callback"); !strings.HasPrefix(url, stub.URL+"/login/oauth/authorize?") || !strings.Contains(url, "state=s") {
+		t.Errorf("authorize URL = %s", url)
+	}
+	user, err := provider.Exchange(context.Background(), "good", "http://localhost/auth/callback")
+	if err != nil || user.Login != "octocat" || user.Name != "The Octocat" {
+		t.Fatalf("exchange = %+v, %v", user, err)
+	}
+	if _, err := provider.Exchange(context.Background(), "bad", ""); err == nil || !strings.Contains(err.Error(), "bad_verification_code") {
+		t.Errorf("bad code: %v", err)
+	}
+
+	if _, err := (LocalProvider{}).Exchange(context.Background(), "../etc", ""); err == nil {
+		t.Error("local provider accepted a path as a username")
+	}
+}
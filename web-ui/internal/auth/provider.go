@@ -0,0 +1,150 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"web-ui/internal/config"
+)
+
+// Provider is an OAuth identity provider. Signing in sends the browser to
+// AuthCodeURL; the provider sends it back to redirectURI with a code and the
+// state, and Exchange turns the code into the user.
+type Provider interface {
+	Name() string
+	AuthCodeURL(state, redirectURI string) string
+	Exchange(ctx context.Context, code, redirectURI string) (User, error)
+}
+
+// NewProvider returns the provider selected by auth.provider
+func NewProvider(cfg *config.Config) Provider {
+	if cfg.Auth.Provider == "github" {
+		return &GitHubProvider{
+			clientID:     cfg.Auth.GitHubClientID,
+			clientSecret: cfg.Auth.GitHubClientSecret,
+			webURL:       cfg.Auth.GitHubURL,
+			apiURL:       cfg.Auth.GitHubAPIURL,
+			client:       &http.Client{Timeout: 10 * time.Second},
+		}
+	}
+	return LocalProvider{}
+}
+
+// GitHubProvider signs in with a GitHub OAuth app. The web and API URLs are
+// configurable so a stub server can stand in for GitHub.
+type GitHubProvider struct {
+	clientID     string
+	clientSecret string
+	webURL       string
+	apiURL       string
+	client       *http.Client
+}
+
+func (p *GitHubProvider) Name() string { return "github" }
+
+func (p *GitHubProvider) AuthCodeURL(state, redirectURI string) string {
+	return p.webURL + "/login/oauth/authorize?" + url.Values{
+		"client_id":    {p.clientID},
+		"redirect_uri": {redirectURI},
+		"state":        {state},
+	}.Encode()
+}
+
+func (p *GitHubProvider) Exchange(ctx context.Context, code, redirectURI string) (User, error) {
+	form := url.Values{
+		"client_id":     {p.clientID},
+		"client_secret": {p.clientSecret},
+		"code":          {code},
+		"redirect_uri":  {redirectURI},
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", p.webURL+"/login/oauth/access_token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return User{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	// GitHub reports a bad code with 200 and an error field
+	var token struct {
+		AccessToken      string `json:"access_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := p.do(req, &token); err != nil {
+		return User{}, fmt.Errorf("exchanging code: %w", err)
+	}
+	if token.AccessToken == "" {
+		return User{}, fmt.Errorf("exchanging code: %s %s", token.Error, token.ErrorDescription)
+	}
+
+	req, err = http.NewRequestWithContext(ctx, "GET", p.apiURL+"/user", nil)
+	if err != nil {
+		return User{}, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	var profile struct {
+		Login     string `json:"login"`
+		Name      string `json:"name"`
+		AvatarURL string `json:"avatar_url"`
+	}
+	if err := p.do(req, &profile); err != nil {
+		return User{}, fmt.Errorf("fetching user: %w", err)
+	}
+	if profile.Login == "" {
+		return User{}, errors.New("fetching user: no login in response")
+	}
+	return User{Login: profile.Login, Name: profile.Name, AvatarURL: profile.AvatarURL}, nil
+}
+
+func (p *GitHubProvider) do(req *http.Request, v interface{}) error {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s %s: %s", req.Method, req.URL.Path, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// LocalProvider signs in as whatever GitHub username is typed into
+// /auth/local, without a password. It keeps the OAuth round trip, state check
+// included, so the site behaves the same as with GitHub, and is only meant for
+// running on your own machine.
+type LocalProvider struct{}
+
+// LocalSignInPath serves the local provider's sign-in form, which submits to
+// the callback as GitHub would
+const LocalSignInPath = "/auth/local"
+
+func (LocalProvider) Name() string { return "local" }
+
+func (LocalProvider) AuthCodeURL(state, redirectURI string) string {
+	return LocalSignInPath + "?" + url.Values{"state": {state}}.Encode()
+}
+
+// The code is the username itself
+func (LocalProvider) Exchange(ctx context.Context, code, redirectURI string) (User, error) {
+	if !ValidLogin(code) {
+		return User{}, fmt.Errorf("%q is not a GitHub username", code)
+	}
+	return User{Login: code, AvatarURL: "https://github.com/" + code + ".png"}, nil
+}
+
+var loginPattern = regexp.MustCompile(`^[A-Za-z0-9]+(?:-[A-Za-z0-9]+)*$`)
+
+// ValidLogin reports whether name is a well-formed GitHub username, which is
+// also what keeps it safe to use as a submissions directory name
+func ValidLogin(name string) bool {
+	return len(name) <= 39 && loginPattern.MatchString(name)
+}
@@ -0,0 +1,219 @@
+// Package auth signs visitors in through an OAuth provider and keeps them
+// signed in with a signed, HttpOnly session cookie. Middleware puts the
+// signed-in user on every request; handlers read it with Username or UserFrom
+// and never take a username from the request itself.
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"web-ui/internal/config"
+)
+
+// User is a signed-in visitor
+type User struct {
+	Login     string `json:"username"`
+	Name      string `json:"name,omitempty"`
+	AvatarURL string `json:"avatarUrl,omitempty"`
+}
+
+const (
+	sessionCookie = "session"
+	stateCookie   = "oauth_state"
+	stateTTL      = 10 * time.Minute
+)
+
+// Sessions issues and checks session cookies. A cookie holds the user and an
+// expiry, signed with HMAC-SHA256, so sessions need no server-side storage;
+// signing out clears the cookie.
+type Sessions struct {
+	key    []byte
+	ttl    time.Duration
+	secure bool // the site is served over HTTPS
+}
+
+// NewSessions signs with auth.session_secret, or with a key generated once
+// and kept in the data directory so sessions survive restarts.
+func NewSessions(cfg *config.Config) (*Sessions, error) {
+	key := []byte(cfg.Auth.SessionSecret)
+	if len(key) == 0 {
+		var err error
+		if key, err = loadOrCreateKey(filepath.Join(cfg.Server.DataDir, "session.key")); err != nil {
+			return nil, err
+		}
+	}
+	return &Sessions{
+		key:    key,
+		ttl:    cfg.Auth.SessionTTL,
+		secure: strings.HasPrefix(cfg.Auth.PublicURL, "https://"),
+	}, nil
+}
+
+func loadOrCreateKey(path string) ([]byte, error) {
+	if data, err := os.ReadFile(path); err == nil {
+		if key, err := hex.DecodeString(strings.TrimSpace(string(data))); err == nil && len(key) >= 32 {
+			return key, nil
+		}
+		return nil, fmt.Errorf("session key %s is not 32 hex-encoded bytes", path)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, []byte(hex.EncodeToString(key)+"\n"), 0600); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+type sessionPayload struct {
+	User
+	Expires int64 `json:"exp"`
+}
+
+// Start signs the user in for the configured session lifetime
+func (s *Sessions) Start(w http.ResponseWriter, r *http.Request, user User) {
+	expires := time.Now().Add(s.ttl)
+	payload, _ := json.Marshal(sessionPayload{User: user, Expires: expires.Unix()})
+	s.setCookie(w, r, sessionCookie, s.sign(payload), expires)
+}
+
+// End signs the visitor out
+func (s *Sessions) End(w http.ResponseWriter, r *http.Request) {
+	s.setCookie(w, r, sessionCookie, "", time.Unix(0, 0))
+}
+
+// User returns the signed-in user, if the request carries a valid session
+func (s *Sessions) User(r *http.Request) (User, bool) {
+	cookie, err := r.Cookie(sessionCookie)
+	if err != nil {
+		return User{}, false
+	}
+	payload, ok := s.verify(cookie.Value)
+	if !ok {
+		return User{}, false
+	}
+	var session sessionPayload
+	if err := json.Unmarshal(payload, &session); err != nil || session.Login == "" {
+		return User{}, false
+	}
+	if time.Now().Unix() >= session.Expires {
+		return User{}, false
+	}
+	return session.User, true
+}
+
+// NewState starts a sign-in: it returns a random OAuth state and remembers it,
+// with the page to return to, in a short-lived cookie.
+func (s *Sessions) NewState(w http.ResponseWriter, r *http.Request, next string) string {
+	nonce := make([]byte, 16)
+	rand.Read(nonce)
+	state := hex.EncodeToString(nonce)
+	s.setCookie(w, r, stateCookie, s.sign([]byte(state+"|"+next)), time.Now().Add(stateTTL))
+	return state
+}
+
+// CheckState finishes a sign-in started by NewState, returning the page to go
+// back to. It fails if the state does not match, so a callback cannot be
+// replayed into another browser.
+func (s *Sessions) CheckState(w http.ResponseWriter, r *http.Request, state string) (next string, ok bool) {
+	cookie, err := r.Cookie(stateCookie)
+	if err != nil {
+		return "", false
+	}
+	s.setCookie(w, r, stateCookie, "", time.Unix(0, 0))
+
+	payload, ok := s.verify(cookie.Value)
+	if !ok {
+		return "", false
+	}
+	want, next, _ := strings.Cut(string(payload), "|")
+	if state == "" || !hmac.Equal([]byte(state), []byte(want)) {
+		return "", false
+	}
+	return next, true
+}
+
+func (s *Sessions) setCookie(w http.ResponseWriter, r *http.Request, name, value string, expires time.Time) {
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   s.secure || r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	}
+	if value == "" {
+		cookie.MaxAge = -1
+	}
+	http.SetCookie(w, cookie)
+}
+
+// sign returns payload.mac, both base64url-encoded
+func (s *Sessions) sign(payload []byte) string {
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(s.mac(encoded))
+}
+
+func (s *Sessions) verify(value string) ([]byte, bool) {
+	encoded, signature, found := strings.Cut(value, ".")
+	if !found {
+		return nil, false
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, s.mac(encoded)) {
+		return nil, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	return payload, err == nil
+}
+
+func (s *Sessions) mac(data string) []byte {
+	h := hmac.New(sha256.New, s.key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+type contextKey struct{}
+
+// Middleware puts the signed-in user, if any, on the request's context
+func (s *Sessions) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, ok := s.User(r); ok {
+			r = r.WithContext(context.WithValue(r.Context(), contextKey{}, user))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// UserFrom returns the user Middleware found on the request
+func UserFrom(ctx context.Context) (User, bool) {
+	user, ok := ctx.Value(contextKey{}).(User)
+	return user, ok
+}
+
+// Username is the signed-in user's login, or "" for anonymous requests
+func Username(r *http.Request) string {
+	user, _ := UserFrom(r.Context())
+	return user.Login
+}
@@ -0,0 +1,399 @@
+// Package config holds the server's settings. A Config is built once at start
+// up from, in increasing order of precedence, built-in defaults, an optional
+// TOML file, environment variables and command-line flags, and is then handed
+// to every service that needs a path, a limit or a toggle.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config is the complete server configuration
+type Config struct {
+	Server   ServerConfig
+	Auth     AuthConfig
+	Content  ContentConfig
+	Runner   RunnerConfig
+	Activity ActivityConfig
+	AI       AIConfig
+	Features FeatureConfig
+
+	// PrintConfig is set by --print-config: print the configuration and exit
+	PrintConfig bool
+
+	// File is the configuration file that was read, if any
+	File string
+
+	sources map[string]string // setting key → where its value came from
+}
+
+// ServerConfig covers the HTTP server and its own state
+type ServerConfig struct {
+	Port         int
+	DataDir      string // submissions, attempts and caches; defaults to web-ui/data
+	AdminToken   string // bearer token for /api/admin; admin routes are off without it
+	TeamsFile    string // team definitions; defaults to <data_dir>/teams.json
+	WebhooksFile string // outgoing webhooks; defaults to <data_dir>/webhooks.json
+}
+
+// AuthConfig selects how visitors sign in and how long they stay signed in
+type AuthConfig struct {
+	Provider           string // github or local; empty means github, so sign-in fails closed
+	GitHubClientID     string
+	GitHubClientSecret string
+	GitHubURL          string // OAuth endpoints; point at a stub server to test locally
+	GitHubAPIURL       string
+	SessionSecret      string        // signs session cookies; generated into the data dir when empty
+	SessionTTL         time.Duration // how long a sign-in lasts
+	PublicURL          string        // base of the OAuth callback URL; empty uses the request's host
+}
+
+// ContentConfig locates the challenges, packages and releases
+type ContentConfig struct {
+	Root         string        // repository root holding challenge-*, packages/ and releases/
+	PollInterval time.Duration // how often to look for changed content; 0 disables
+}
+
+// RunnerConfig limits how submitted code is run
+type RunnerConfig struct {
+	Timeout       time.Duration // per run, including compilation
+	MaxConcurrent int           // runs in flight at once; the rest queue
+}
+
+// ActivityConfig shapes daily activity and streaks
+type ActivityConfig struct {
+	DefaultTimezone string // IANA name used for users who have not chosen one
+	StreakFreezes   int    // missed days a week a streak survives; 0 disables
+}
+
+// AIConfig selects the LLM behind code review, hints and the interviewer
+type AIConfig struct {
+	Provider string // gemini, openai or claude
+	APIKey   string
+	Model    string // empty picks the provider's default
+}
+
+// FeatureConfig switches optional parts of the site on and off
+type FeatureConfig struct {
+	AI             bool // AI review, hints and interviewer endpoints
+	Badges         bool // /badges profile badges
+	ReleasesRunner bool // running release-track challenges (the pages stay up)
+}
+
+// AuthProviders lists the supported values of auth.provider. The local
+// provider signs in as any name without a password and is meant for running
+// the site on your own machine.
+var AuthProviders = []string{"github", "local"}
+
+// Providers lists the supported values of ai.provider
+var Providers = []string{"gemini", "openai", "claude"}
+
+// providerKeyEnv is the provider-specific API key variable, which takes
+// precedence over AI_API_KEY
+var providerKeyEnv = map[string]string{
+	"gemini": "GEMINI_API_KEY",
+	"openai": "OPENAI_API_KEY",
+	"claude": "CLAUDE_API_KEY",
+}
+
+// Default returns the built-in configuration. Content.Root and Server.DataDir
+// are left empty and resolved by Load.
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{Port: 8080},
+		Auth: AuthConfig{
+			GitHubURL:    "https://github.com",
+			GitHubAPIURL: "https://api.github.com",
+			SessionTTL:   30 * 24 * time.Hour,
+		},
+		Content:  ContentConfig{PollInterval: 10 * time.Second},
+		Runner:   RunnerConfig{Timeout: 3 * time.Minute, MaxConcurrent: 4},
+		Activity: ActivityConfig{DefaultTimezone: "UTC"},
+		AI:       AIConfig{Provider: "gemini"},
+		Features: FeatureConfig{
+			AI:             true,
+			Badges:         true,
+			ReleasesRunner: true,
+		},
+	}
+}
+
+// Load builds the configuration for the command line args (without the
+// program name). The file is taken from --config or WEB_UI_CONFIG. Load does
+// not validate; call Validate once the caller is done adjusting the result.
+func Load(args []string) (*Config, error) {
+	cfg := Default()
+	cfg.sources = make(map[string]string)
+	for _, opt := range options {
+		cfg.sources[opt.key] = "default"
+	}
+
+	flags := flag.NewFlagSet("web-ui", flag.ContinueOnError)
+	configFile := flags.String("config", os.Getenv("WEB_UI_CONFIG"), "TOML configuration file")
+	flags.BoolVar(&cfg.PrintConfig, "print-config", false, "print the resolved configuration and exit")
+	values := make(map[string]*string)
+	for _, opt := range options {
+		if opt.flag != "" {
+			values[opt.key] = flags.String(opt.flag, "", opt.usage)
+		}
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if flags.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected argument %q", flags.Arg(0))
+	}
+
+	if *configFile != "" {
+		settings, err := readFile(*configFile)
+		if err != nil {
+			return nil, err
+		}
+		for _, opt := range options {
+			if value, ok := settings[opt.key]; ok {
+				if err := cfg.apply(opt, value, "file"); err != nil {
+					return nil, fmt.Errorf("%s: %w", *configFile, err)
+				}
+				delete(settings, opt.key)
+			}
+		}
+		for key := range settings {
+			return nil, fmt.Errorf("%s: unknown setting %q", *configFile, key)
+		}
+		cfg.File = *configFile
+	}
+
+	for _, opt := range options {
+		if opt.env == "" {
+			continue
+		}
+		if value, ok := os.LookupEnv(opt.env); ok && value != "" {
+			if err := cfg.apply(opt, value, "env "+opt.env); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var flagErr error
+	flags.Visit(func(f *flag.Flag) {
+		for _, opt := range options {
+			if opt.flag == f.Name && flagErr == nil {
+				flagErr = cfg.apply(opt, *values[opt.key], "flag --"+opt.flag)
+			}
+		}
+	})
+	if flagErr != nil {
+		return nil, flagErr
+	}
+
+	cfg.resolve()
+	return cfg, nil
+}
+
+// apply sets one option, recording where the value came from
+func (c *Config) apply(opt option, value, source string) error {
+	if err := opt.set(c, value); err != nil {
+		return fmt.Errorf("%s: %w", opt.key, err)
+	}
+	c.sources[opt.key] = source
+	return nil
+}
+
+// resolve fills in the settings that default to something derived from others
+func (c *Config) resolve() {
+	c.AI.Provider = strings.ToLower(c.AI.Provider)
+
+	// The provider's own key variable wins over AI_API_KEY, as it always has,
+	// but not over a key given in the file or on the command line.
+	if source := c.sources["ai.api_key"]; source == "default" || strings.HasPrefix(source, "env ") {
+		if env := providerKeyEnv[c.AI.Provider]; os.Getenv(env) != "" {
+			c.AI.APIKey = os.Getenv(env)
+			c.sources["ai.api_key"] = "env " + env
+		}
+	}
+
+	// The local provider signs anyone in as anyone, so it is never picked for
+	// a server that was not told to use it: without GitHub credentials,
+	// ValidateSignIn refuses to start.
+	c.Auth.Provider = strings.ToLower(c.Auth.Provider)
+	if c.Auth.Provider == "" {
+		c.Auth.Provider = "github"
+	}
+	c.Auth.GitHubURL = strings.TrimSuffix(c.Auth.GitHubURL, "/")
+	c.Auth.GitHubAPIURL = strings.TrimSuffix(c.Auth.GitHubAPIURL, "/")
+	c.Auth.PublicURL = strings.TrimSuffix(c.Auth.PublicURL, "/")
+
+	if c.Content.Root == "" {
+		c.Content.Root = findContentRoot()
+	}
+	if root, err := filepath.Abs(c.Content.Root); err == nil {
+		c.Content.Root = root
+	}
+	if c.Server.DataDir == "" {
+		c.Server.DataDir = filepath.Join(c.Content.Root, "web-ui", "data")
+	}
+	if dir, err := filepath.Abs(c.Server.DataDir); err == nil {
+		c.Server.DataDir = dir
+	}
+	if c.Server.TeamsFile == "" {
+		c.Server.TeamsFile = filepath.Join(c.Server.DataDir, "teams.json")
+	}
+	if file, err := filepath.Abs(c.Server.TeamsFile); err == nil {
+		c.Server.TeamsFile = file
+	}
+	if c.Server.WebhooksFile == "" {
+		c.Server.WebhooksFile = filepath.Join(c.Server.DataDir, "webhooks.json")
+	}
+	if file, err := filepath.Abs(c.Server.WebhooksFile); err == nil {
+		c.Server.WebhooksFile = file
+	}
+}
+
+// findContentRoot looks for the repository root in the working directory and
+// its parents, so the server runs from web-ui/ or from the repository root.
+func findContentRoot() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ".."
+	}
+	for {
+		if hasChallenges(dir) {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ".." // historical default; Validate reports it
+		}
+		dir = parent
+	}
+}
+
+func hasChallenges(dir string) bool {
+	matches, _ := filepath.Glob(filepath.Join(dir, "challenge-*"))
+	for _, match := range matches {
+		if info, err := os.Stat(match); err == nil && info.IsDir() {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate reports every setting that cannot work, not just the first
+func (c *Config) Validate() error {
+	var errs []error
+	if !hasChallenges(c.Content.Root) {
+		errs = append(errs, fmt.Errorf("content.root: no challenge-* directories in %s", c.Content.Root))
+	}
+	if c.Content.PollInterval < 0 {
+		errs = append(errs, fmt.Errorf("content.poll_interval: must not be negative"))
+	}
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		errs = append(errs, fmt.Errorf("server.port: %d is not a valid port", c.Server.Port))
+	}
+	if info, err := os.Stat(c.Server.DataDir); err == nil && !info.IsDir() {
+		errs = append(errs, fmt.Errorf("server.data_dir: %s is not a directory", c.Server.DataDir))
+	}
+	if c.Runner.Timeout <= 0 {
+		errs = append(errs, fmt.Errorf("runner.timeout: must be positive"))
+	}
+	if c.Runner.MaxConcurrent < 1 {
+		errs = append(errs, fmt.Errorf("runner.max_concurrent: must be at least 1"))
+	}
+	if _, err := time.LoadLocation(c.Activity.DefaultTimezone); err != nil || c.Activity.DefaultTimezone == "" {
+		errs = append(errs, fmt.Errorf("activity.default_timezone: %q is not an IANA time zone such as \"Europe/Berlin\"", c.Activity.DefaultTimezone))
+	}
+	if c.Activity.StreakFreezes < 0 {
+		errs = append(errs, fmt.Errorf("activity.streak_freezes: must not be negative"))
+	}
+	if _, ok := providerKeyEnv[c.AI.Provider]; !ok {
+		errs = append(errs, fmt.Errorf("ai.provider: %q is not one of %s", c.AI.Provider, strings.Join(Providers, ", ")))
+	}
+	return errors.Join(errs...)
+}
+
+// ValidateSignIn reports sign-in settings that cannot work. Only the server
+// signs anyone in, so the scoreboard and badges commands skip it.
+func (c *Config) ValidateSignIn() error {
+	var errs []error
+	switch c.Auth.Provider {
+	case "github":
+		if c.Auth.GitHubClientID == "" || c.Auth.GitHubClientSecret == "" {
+			errs = append(errs, fmt.Errorf("auth.github_client_id, auth.github_client_secret: both are required for GitHub sign-in; "+
+				"set auth.provider = \"local\" instead only to run the site on your own machine"))
+		}
+	case "local":
+	default:
+		errs = append(errs, fmt.Errorf("auth.provider: %q is not one of %s", c.Auth.Provider, strings.Join(AuthProviders, ", ")))
+	}
+	if c.Auth.SessionTTL <= 0 {
+		errs = append(errs, fmt.Errorf("auth.session_ttl: must be positive"))
+	}
+	return errors.Join(errs...)
+}
+
+// ChallengeDir is the directory of classic challenge id
+func (c *Config) ChallengeDir(id int) string {
+	return filepath.Join(c.Content.Root, "challenge-"+strconv.Itoa(id))
+}
+
+// PackagesDir holds one directory per package track
+func (c *Config) PackagesDir() string {
+	return filepath.Join(c.Content.Root, "packages")
+}
+
+// PackageChallengeDir is the directory of a package challenge
+func (c *Config) PackageChallengeDir(packageName, challengeID string) string {
+	return filepath.Join(c.PackagesDir(), packageName, challengeID)
+}
+
+// ReleasesDir holds one directory per Go release
+func (c *Config) ReleasesDir() string {
+	return filepath.Join(c.Content.Root, "releases")
+}
+
+// CurriculaDir holds one JSON file per curriculum
+func (c *Config) CurriculaDir() string {
+	return filepath.Join(c.Content.Root, "curricula")
+}
+
+// RubricsDir holds one JSON file per interview scoring rubric
+func (c *Config) RubricsDir() string {
+	return filepath.Join(c.Content.Root, "rubrics")
+}
+
+// Print writes the configuration as a TOML file that Load would accept,
+// noting where each value came from. Secrets are redacted.
+func (c *Config) Print(w io.Writer) {
+	section := ""
+	for _, opt := range options {
+		name := opt.key[:strings.Index(opt.key, ".")]
+		if name != section {
+			if section != "" {
+				fmt.Fprintln(w)
+			}
+			fmt.Fprintf(w, "[%s]\n", name)
+			section = name
+		}
+
+		value := opt.get(c)
+		if opt.secret && value != "" {
+			value = "<redacted>"
+		}
+		if opt.quoted {
+			value = strconv.Quote(value)
+		}
+		source := c.sources[opt.key]
+		if source == "" {
+			source = "default"
+		}
+		fmt.Fprintf(w, "%s = %s # %s\n", opt.key[len(name)+1:], value, source)
+	}
+}
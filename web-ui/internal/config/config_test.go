@@ -0,0 +1,142 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadLayersFileEnvAndFlags(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "challenge-1"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(root, "web-ui.toml")
+	os.WriteFile(file, []byte(`
+[server]
+port = 9000 # overridden by the flag
+admin_token = "s3cret"
+
+[content]
+root = '`+root+`'
+poll_interval = "1m"
+
+[runner]
+timeout = "30s"
+`), 0644)
+
+	t.Setenv("WEB_UI_CONFIG", file)
+	t.Setenv("RUNNER_TIMEOUT", "45s")
+	t.Setenv("RELEASES_RUNNER", "off")
+	t.Setenv("AI_PROVIDER", "openai")
+	t.Setenv("AI_API_KEY", "generic")
+	t.Setenv("OPENAI_API_KEY", "specific")
+	t.Setenv("AUTH_PROVIDER", "local")
+
+	cfg, err := Load([]string{"--port", "9100", "--print-config"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := errors.Join(cfg.Validate(), cfg.ValidateSignIn()); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Server.Port != 9100 {
+		t.Errorf("port = %d, want the flag's 9100", cfg.Server.Port)
+	}
+	if cfg.Runner.Timeout != 45*time.Second {
+		t.Errorf("timeout = %s, want the environment's 45s", cfg.Runner.Timeout)
+	}
+	if cfg.Content.PollInterval != time.Minute {
+		t.Errorf("poll interval = %s, want the file's 1m", cfg.Content.PollInterval)
+	}
+	if cfg.Features.ReleasesRunner {
+		t.Error("RELEASES_RUNNER=off did not disable the runner")
+	}
+	if cfg.AI.APIKey != "specific" {
+		t.Errorf("api key = %q, want OPENAI_API_KEY to win over AI_API_KEY", cfg.AI.APIKey)
+	}
+	if want := filepath.Join(root, "web-ui", "data"); cfg.Server.DataDir != want {
+		t.Errorf("data dir = %s, want %s", cfg.Server.DataDir, want)
+	}
+	if cfg.ChallengeDir(1) != filepath.Join(root, "challenge-1") {
+		t.Errorf("challenge dir = %s", cfg.ChallengeDir(1))
+	}
+	if !cfg.PrintConfig {
+		t.Error("--print-config was not recorded")
+	}
+
+	var out bytes.Buffer
+	cfg.Print(&out)
+	if strings.Contains(out.String(), "s3cret") || strings.Contains(out.String(), "specific") {
+		t.Errorf("Print leaked a secret:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "port = 9100 # flag --port") {
+		t.Errorf("Print does not attribute the port to its flag:\n%s", out.String())
+	}
+
+	// What Print writes, Load reads back.
+	reread := filepath.Join(root, "printed.toml")
+	os.WriteFile(reread, bytes.ReplaceAll(out.Bytes(), []byte("<redacted>"), nil), 0644)
+	again, err := Load([]string{"--config", reread})
+	if err != nil {
+		t.Fatalf("reading printed config: %v", err)
+	}
+	if again.Server.Port != 9100 || again.Content.Root != root {
+		t.Errorf("printed config read back as port %d, root %s", again.Server.Port, again.Content.Root)
+	}
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	cfg := Default()
+	cfg.Content.Root = t.TempDir()
+	cfg.Server.DataDir = t.TempDir()
+	cfg.Server.Port = 0
+	cfg.Runner.MaxConcurrent = 0
+	cfg.AI.Provider = "eliza"
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("invalid configuration passed validation")
+	}
+	for _, key := range []string{"content.root", "server.port", "runner.max_concurrent", "ai.provider"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("error does not mention %s: %v", key, err)
+		}
+	}
+}
+
+func TestSignInFailsClosed(t *testing.T) {
+	root := t.TempDir()
+	os.Mkdir(filepath.Join(root, "challenge-1"), 0755)
+	t.Setenv("WEB_UI_CONFIG", "")
+	t.Setenv("AUTH_PROVIDER", "")
+	t.Setenv("GITHUB_CLIENT_ID", "")
+	t.Setenv("GITHUB_CLIENT_SECRET", "")
+
+	// With nothing configured, sign-in is GitHub's and the server refuses to
+	// start for want of credentials, rather than letting anyone in as anyone
+	cfg, err := Load([]string{"--content-root", root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Auth.Provider == "local" {
+		t.Fatal("an empty configuration signs in with the local provider")
+	}
+	if err := cfg.ValidateSignIn(); err == nil || !strings.Contains(err.Error(), "auth.github_client_id") {
+		t.Errorf("ValidateSignIn without credentials = %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("the commands' validation checks sign-in: %v", err)
+	}
+
+	t.Setenv("AUTH_PROVIDER", "local")
+	cfg, _ = Load([]string{"--content-root", root})
+	if err := cfg.ValidateSignIn(); cfg.Auth.Provider != "local" || err != nil {
+		t.Errorf("explicit local provider = %q, %v", cfg.Auth.Provider, err)
+	}
+}
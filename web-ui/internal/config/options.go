@@ -0,0 +1,148 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// option binds one setting to its key in the configuration file, its
+// environment variable and its flag. Values arrive as strings from all three.
+type option struct {
+	key    string // section.name in the configuration file
+	env    string // empty if the setting has no environment variable
+	flag   string // empty if the setting has no flag (secrets would leak via ps)
+	usage  string
+	secret bool // redacted by Print
+	quoted bool // printed as a TOML string
+	get    func(*Config) string
+	set    func(*Config, string) error
+}
+
+// options lists every setting, in the order Print writes them. The
+// environment variable names predate this package and are kept as they were.
+var options = []option{
+	intOption("server.port", "PORT", "port", "port to listen on",
+		func(c *Config) *int { return &c.Server.Port }),
+	stringOption("server.data_dir", "DATA_DIR", "data-dir", "directory for submissions, attempts and caches (default <content.root>/web-ui/data)",
+		func(c *Config) *string { return &c.Server.DataDir }),
+	secretOption("server.admin_token", "ADMIN_TOKEN",
+		func(c *Config) *string { return &c.Server.AdminToken }),
+	stringOption("server.teams_file", "TEAMS_FILE", "teams-file", "JSON file defining teams for the team dashboards (default <data_dir>/teams.json)",
+		func(c *Config) *string { return &c.Server.TeamsFile }),
+	stringOption("server.webhooks_file", "WEBHOOKS_FILE", "webhooks-file", "JSON file defining outgoing webhooks (default <data_dir>/webhooks.json)",
+		func(c *Config) *string { return &c.Server.WebhooksFile }),
+
+	stringOption("auth.provider", "AUTH_PROVIDER", "auth-provider", "sign-in provider: github, or local to sign in as any name on your own machine",
+		func(c *Config) *string { return &c.Auth.Provider }),
+	stringOption("auth.github_client_id", "GITHUB_CLIENT_ID", "github-client-id", "GitHub OAuth app client ID",
+		func(c *Config) *string { return &c.Auth.GitHubClientID }),
+	secretOption("auth.github_client_secret", "GITHUB_CLIENT_SECRET",
+		func(c *Config) *string { return &c.Auth.GitHubClientSecret }),
+	stringOption("auth.github_url", "GITHUB_URL", "github-url", "GitHub web URL for the OAuth authorize and token endpoints",
+		func(c *Config) *string { return &c.Auth.GitHubURL }),
+	stringOption("auth.github_api_url", "GITHUB_API_URL", "github-api-url", "GitHub API URL used to look up the signed-in user",
+		func(c *Config) *string { return &c.Auth.GitHubAPIURL }),
+	secretOption("auth.session_secret", "SESSION_SECRET",
+		func(c *Config) *string { return &c.Auth.SessionSecret }),
+	durationOption("auth.session_ttl", "SESSION_TTL", "session-ttl", "how long a sign-in lasts",
+		func(c *Config) *time.Duration { return &c.Auth.SessionTTL }),
+	stringOption("auth.public_url", "PUBLIC_URL", "public-url", "URL the site is served at, for the OAuth callback (default: from the request)",
+		func(c *Config) *string { return &c.Auth.PublicURL }),
+
+	stringOption("content.root", "CONTENT_ROOT", "content-root", "repository root with the challenges, packages and releases (default: found from the working directory)",
+		func(c *Config) *string { return &c.Content.Root }),
+	durationOption("content.poll_interval", "CONTENT_POLL_INTERVAL", "content-poll-interval", "how often to check content for changes; 0 disables",
+		func(c *Config) *time.Duration { return &c.Content.PollInterval }),
+
+	durationOption("runner.timeout", "RUNNER_TIMEOUT", "runner-timeout", "time limit for one run of submitted code",
+		func(c *Config) *time.Duration { return &c.Runner.Timeout }),
+	intOption("runner.max_concurrent", "RUNNER_MAX_CONCURRENT", "runner-max-concurrent", "runs of submitted code allowed at once",
+		func(c *Config) *int { return &c.Runner.MaxConcurrent }),
+
+	stringOption("activity.default_timezone", "DEFAULT_TIMEZONE", "default-timezone", "time zone for daily activity of users who have not chosen one",
+		func(c *Config) *string { return &c.Activity.DefaultTimezone }),
+	intOption("activity.streak_freezes", "STREAK_FREEZES", "streak-freezes", "missed days a week a streak survives; 0 disables streak freezes",
+		func(c *Config) *int { return &c.Activity.StreakFreezes }),
+
+	stringOption("ai.provider", "AI_PROVIDER", "ai-provider", "LLM provider: gemini, openai or claude",
+		func(c *Config) *string { return &c.AI.Provider }),
+	secretOption("ai.api_key", "AI_API_KEY",
+		func(c *Config) *string { return &c.AI.APIKey }),
+	stringOption("ai.model", "AI_MODEL", "ai-model", "LLM model (default: the provider's)",
+		func(c *Config) *string { return &c.AI.Model }),
+
+	boolOption("features.ai", "FEATURE_AI", "feature-ai", "serve the AI endpoints",
+		func(c *Config) *bool { return &c.Features.AI }),
+	boolOption("features.badges", "FEATURE_BADGES", "feature-badges", "serve profile badges",
+		func(c *Config) *bool { return &c.Features.Badges }),
+	boolOption("features.releases_runner", "RELEASES_RUNNER", "feature-releases-runner", "run release-track challenges",
+		func(c *Config) *bool { return &c.Features.ReleasesRunner }),
+}
+
+func stringOption(key, env, flag, usage string, field func(*Config) *string) option {
+	return option{
+		key: key, env: env, flag: flag, usage: usage, quoted: true,
+		get: func(c *Config) string { return *field(c) },
+		set: func(c *Config, value string) error {
+			*field(c) = value
+			return nil
+		},
+	}
+}
+
+func secretOption(key, env string, field func(*Config) *string) option {
+	opt := stringOption(key, env, "", "", field)
+	opt.secret = true
+	return opt
+}
+
+func intOption(key, env, flag, usage string, field func(*Config) *int) option {
+	return option{
+		key: key, env: env, flag: flag, usage: usage,
+		get: func(c *Config) string { return strconv.Itoa(*field(c)) },
+		set: func(c *Config, value string) error {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("%q is not a number", value)
+			}
+			*field(c) = n
+			return nil
+		},
+	}
+}
+
+func durationOption(key, env, flag, usage string, field func(*Config) *time.Duration) option {
+	return option{
+		key: key, env: env, flag: flag, usage: usage, quoted: true,
+		get: func(c *Config) string { return field(c).String() },
+		set: func(c *Config, value string) error {
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("%q is not a duration such as \"30s\"", value)
+			}
+			*field(c) = d
+			return nil
+		},
+	}
+}
+
+func boolOption(key, env, flag, usage string, field func(*Config) *bool) option {
+	return option{
+		key: key, env: env, flag: flag, usage: usage,
+		get: func(c *Config) string { return strconv.FormatBool(*field(c)) },
+		set: func(c *Config, value string) error {
+			// RELEASES_RUNNER=off predates this package
+			switch strings.ToLower(value) {
+			case "true", "on", "1", "yes":
+				*field(c) = true
+			case "false", "off", "0", "no":
+				*field(c) = false
+			default:
+				return fmt.Errorf("%q is not true or false", value)
+			}
+			return nil
+		},
+	}
+}
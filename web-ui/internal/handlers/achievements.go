@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"web-ui/internal/models"
+	"web-ui/internal/services"
+)
+
+// awardAchievements evaluates the achievement rules after a submit and
+// announces new awards to webhooks. Like recordAttempt, it logs failures
+// instead of failing the submit.
+func awardAchievements(achievements *services.AchievementService, webhooks *services.WebhookService, username string) {
+	earned, err := achievements.Evaluate(username)
+	if err != nil {
+		log.Printf("achievements: failed to award %s: %v", username, err)
+	}
+	for _, e := range earned {
+		log.Printf("achievements: %s earned %s", username, e.ID)
+		webhooks.Emit(models.EventAchievementAwarded, models.AchievementEvent{Username: username, Achievement: e})
+	}
+}
+
+// GetAchievements lists every achievement and what earns it
+func (h *APIHandler) GetAchievements(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.achievements.Rules())
+}
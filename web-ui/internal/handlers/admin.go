@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"web-ui/internal/auth"
+	"web-ui/internal/models"
+	"web-ui/internal/services"
+)
+
+// AdminHandler serves maintenance endpoints. They are disabled unless an admin
+// token is configured, and every request must carry it as a bearer token.
+type AdminHandler struct {
+	watcher  *services.ContentWatcher
+	teams    *services.TeamService
+	webhooks *services.WebhookService
+	token    string
+}
+
+func NewAdminHandler(watcher *services.ContentWatcher, teams *services.TeamService, webhooks *services.WebhookService, token string) *AdminHandler {
+	return &AdminHandler{watcher: watcher, teams: teams, webhooks: webhooks, token: token}
+}
+
+// authorized checks the request's bearer token, answering it if it fails
+func (h *AdminHandler) authorized(w http.ResponseWriter, r *http.Request) bool {
+	if h.token == "" {
+		http.Error(w, "Admin endpoints are disabled (ADMIN_TOKEN is not set)", http.StatusForbidden)
+		return false
+	}
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) != 1 {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+// Reload reloads challenges, packages and releases from disk.
+//
+//	POST /api/admin/reload → reload everything now and report the outcome
+//	GET  /api/admin/reload → the outcome of the last reload, forced or polled
+//
+// Content that fails to load is reported under "errors" with 422 Unprocessable
+// Entity; everything else is still swapped in.
+func (h *AdminHandler) Reload(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(w, r) {
+		return
+	}
+
+	var report services.ReloadReport
+	switch r.Method {
+	case "POST":
+		report = h.watcher.ReloadAll()
+	case "GET":
+		report = h.watcher.LastReport()
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if len(report.Errors) > 0 {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}
+	json.NewEncoder(w).Encode(report)
+}
+
+// Teams manages the teams behind the team dashboards.
+//
+//	GET    /api/admin/teams        → every team
+//	GET    /api/admin/teams/{name} → one team
+//	PUT    /api/admin/teams/{name} → create or replace it from {"displayName", "members", "leads"}
+//	DELETE /api/admin/teams/{name} → remove it
+func (h *AdminHandler) Teams(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(w, r) {
+		return
+	}
+
+	name := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/admin/teams"), "/")
+	if name == "" {
+		if r.Method != "GET" {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(h.teams.Teams())
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		team, ok := h.teams.Team(name)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(team)
+
+	case "PUT":
+		var team models.Team
+		if err := json.NewDecoder(r.Body).Decode(&team); err != nil {
+			http.Error(w, "Invalid request data", http.StatusBadRequest)
+			return
+		}
+		team.Name = name
+		if !services.ValidTeamName(name) {
+			http.Error(w, "Team names are lowercase letters, digits and dashes", http.StatusBadRequest)
+			return
+		}
+		for _, username := range append(append([]string{}, team.Members...), team.Leads...) {
+			if !auth.ValidLogin(strings.TrimSpace(username)) {
+				http.Error(w, fmt.Sprintf("%q is not a GitHub username", username), http.StatusBadRequest)
+				return
+			}
+		}
+		team, err := h.teams.Put(team)
+		if err != nil {
+			log.Printf("teams: %v", err)
+			http.Error(w, "Failed to save teams", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(team)
+
+	case "DELETE":
+		found, err := h.teams.Delete(name)
+		if err != nil {
+			log.Printf("teams: %v", err)
+			http.Error(w, "Failed to save teams", http.StatusInternalServerError)
+			return
+		}
+		if !found {
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// redactWebhook hides a webhook's secret, which is write-only over the API
+func redactWebhook(hook models.Webhook) models.Webhook {
+	if hook.Secret != "" {
+		hook.Secret = "<redacted>"
+	}
+	return hook
+}
+
+// Webhooks manages the outgoing webhooks.
+//
+//	GET    /api/admin/webhooks                   → every webhook
+//	GET    /api/admin/webhooks/{name}            → one webhook
+//	PUT    /api/admin/webhooks/{name}            → create or replace it from {"url", "secret", "events"}
+//	DELETE /api/admin/webhooks/{name}            → remove it
+//	POST   /api/admin/webhooks/{name}/ping       → send it a ping now and report the delivery
+//	GET    /api/admin/webhooks/{name}/deliveries → its delivery log, newest first (?limit=, default 50)
+//
+// Secrets are never returned. A PUT without a secret keeps the current one.
+func (h *AdminHandler) Webhooks(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(w, r) {
+		return
+	}
+
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/admin/webhooks"), "/")
+	name, action, _ := strings.Cut(path, "/")
+	switch {
+	case name == "" && r.Method == "GET":
+		hooks := h.webhooks.Webhooks()
+		for i := range hooks {
+			hooks[i] = redactWebhook(hooks[i])
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(hooks)
+
+	case name != "" && action == "deliveries" && r.Method == "GET":
+		limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+		if err != nil || limit <= 0 {
+			limit = 50
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(h.webhooks.Deliveries(name, limit))
+
+	case name != "" && action == "ping" && r.Method == "POST":
+		hook, ok := h.webhooks.Webhook(name)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		delivery := h.webhooks.Ping(hook)
+		w.Header().Set("Content-Type", "application/json")
+		if !delivery.Delivered {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+		json.NewEncoder(w).Encode(delivery)
+
+	case name != "" && action == "" && r.Method == "GET":
+		hook, ok := h.webhooks.Webhook(name)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(redactWebhook(hook))
+
+	case name != "" && action == "" && r.Method == "PUT":
+		var hook models.Webhook
+		if err := json.NewDecoder(r.Body).Decode(&hook); err != nil {
+			http.Error(w, "Invalid request data", http.StatusBadRequest)
+			return
+		}
+		hook.Name = name
+		if previous, ok := h.webhooks.Webhook(name); ok && hook.Secret == "" {
+			hook.Secret = previous.Secret
+		}
+		if err := services.ValidateWebhook(hook); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		hook, err := h.webhooks.Put(hook)
+		if err != nil {
+			log.Printf("webhooks: %v", err)
+			http.Error(w, "Failed to save webhooks", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(redactWebhook(hook))
+
+	case name != "" && action == "" && r.Method == "DELETE":
+		found, err := h.webhooks.Delete(name)
+		if err != nil {
+			log.Printf("webhooks: %v", err)
+			http.Error(w, "Failed to save webhooks", http.StatusInternalServerError)
+			return
+		}
+		if !found {
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	case action == "" || action == "deliveries" || action == "ping":
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+
+	default:
+		http.NotFound(w, r)
+	}
+}
@@ -5,145 +5,15 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
-	"os"
-	"path/filepath"
-	"regexp"
-	"sort"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
+	"web-ui/internal/auth"
 	"web-ui/internal/models"
 	"web-ui/internal/services"
-	"web-ui/internal/utils"
 )
 
-// GitHubSponsorsResponse represents the GitHub GraphQL response for sponsors
-type GitHubSponsorsResponse struct {
-	Data struct {
-		Viewer struct {
-			SponsorshipsAsMaintainer struct {
-				Nodes []struct {
-					SponsorEntity struct {
-						Login string `json:"login"`
-					} `json:"sponsorEntity"`
-				} `json:"nodes"`
-			} `json:"sponsorshipsAsMaintainer"`
-		} `json:"viewer"`
-	} `json:"data"`
-	Errors []struct {
-		Message string `json:"message"`
-	} `json:"errors"`
-}
-
-// SponsorCache caches sponsor data to avoid hitting API limits
-type SponsorCache struct {
-	sponsors    map[string]bool
-	lastUpdated time.Time
-	mutex       sync.RWMutex
-}
-
-var sponsorCache = &SponsorCache{
-	sponsors: make(map[string]bool),
-}
-
-// LoadSponsors loads sponsor list by scraping the public GitHub sponsors page
-func (h *APIHandler) LoadSponsors() map[string]bool {
-	sponsorCache.mutex.RLock()
-	// Return cached data if it's less than 1 hour old
-	if time.Since(sponsorCache.lastUpdated) < time.Hour && len(sponsorCache.sponsors) > 0 {
-		defer sponsorCache.mutex.RUnlock()
-		return sponsorCache.sponsors
-	}
-	sponsorCache.mutex.RUnlock()
-
-	// Scrape sponsors from the public GitHub sponsors page
-	sponsors := h.scrapeSponsorsFromGitHub()
-
-	// Update cache
-	sponsorCache.mutex.Lock()
-	sponsorCache.sponsors = sponsors
-	sponsorCache.lastUpdated = time.Now()
-	sponsorCache.mutex.Unlock()
-	return sponsors
-}
-
-// scrapeSponsorsFromGitHub scrapes the public GitHub sponsors page
-func (h *APIHandler) scrapeSponsorsFromGitHub() map[string]bool {
-	sponsorMap := make(map[string]bool)
-
-	// Create HTTP client with timeout
-	client := &http.Client{Timeout: 10 * time.Second}
-
-	// Fetch the public sponsors page
-	url := "https://github.com/sponsors/RezaSi"
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		fmt.Printf("Error creating request: %v\n", err)
-		return sponsorMap
-	}
-
-	// Set user agent to avoid being blocked
-	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; GoSponsorScraper/1.0)")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		fmt.Printf("Error fetching sponsors page: %v\n", err)
-		return sponsorMap
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		fmt.Printf("GitHub sponsors page returned status %d\n", resp.StatusCode)
-		return sponsorMap
-	}
-
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		fmt.Printf("Error reading response body: %v\n", err)
-		return sponsorMap
-	}
-
-	html := string(body)
-
-	// Extract usernames from the HTML using regex - look for avatar images with alt="@username"
-	avatarRegex := regexp.MustCompile(`alt="@([a-zA-Z0-9][a-zA-Z0-9\-]*)"`)
-	matches := avatarRegex.FindAllStringSubmatch(html, -1)
-
-	for _, match := range matches {
-		if len(match) >= 2 {
-			username := match[1]
-			// Filter out the repository owner from sponsors list
-			if username != "RezaSi" {
-				sponsorMap[username] = true
-			}
-		}
-	}
-
-	// Fallback: if no sponsors found with avatar method, try href patterns
-	if len(sponsorMap) == 0 {
-		// Look for href="/username" patterns that aren't common GitHub paths
-		linkRegex := regexp.MustCompile(`href="/([a-zA-Z0-9][a-zA-Z0-9\-]+)"`)
-		linkMatches := linkRegex.FindAllStringSubmatch(html, -1)
-
-		for _, match := range linkMatches {
-			if len(match) >= 2 {
-				username := match[1]
-				// Filter out common GitHub paths that aren't usernames
-				if username != "sponsors" && username != "github" && username != "RezaSi" &&
-					!strings.HasPrefix(username, "orgs/") &&
-					!strings.Contains(username, "/") &&
-					len(username) > 2 { // reasonable username length
-					sponsorMap[username] = true
-				}
-			}
-		}
-	}
-
-	return sponsorMap
-}
-
 // APIHandler handles all API endpoints
 type APIHandler struct {
 	challengeService  *services.ChallengeService
@@ -152,7 +22,12 @@ type APIHandler struct {
 	executionService  *services.ExecutionService
 	packageService    *services.PackageService
 	aiService         *services.AIService
-	submissions       []models.Submission
+	leaderboards      *services.LeaderboardService
+	store             services.SubmissionStore
+	attempts          services.AttemptStore
+	achievements      *services.AchievementService
+	webhooks          *services.WebhookService
+	profiles          *services.ProfileService
 }
 
 // NewAPIHandler creates a new API handler
@@ -163,6 +38,12 @@ func NewAPIHandler(
 	executionService *services.ExecutionService,
 	packageService *services.PackageService,
 	aiService *services.AIService,
+	leaderboards *services.LeaderboardService,
+	store services.SubmissionStore,
+	attempts services.AttemptStore,
+	achievements *services.AchievementService,
+	webhooks *services.WebhookService,
+	profiles *services.ProfileService,
 ) *APIHandler {
 	return &APIHandler{
 		challengeService:  challengeService,
@@ -171,7 +52,12 @@ func NewAPIHandler(
 		executionService:  executionService,
 		packageService:    packageService,
 		aiService:         aiService,
-		submissions:       make([]models.Submission, 0),
+		leaderboards:      leaderboards,
+		store:             store,
+		attempts:          attempts,
+		achievements:      achievements,
+		webhooks:          webhooks,
+		profiles:          profiles,
 	}
 }
 
@@ -182,16 +68,8 @@ func (h *APIHandler) GetAllChallenges(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	challenges := h.challengeService.GetChallenges()
-
-	// Convert map to slice for JSON response
-	var challengeList []*models.Challenge
-	for _, challenge := range challenges {
-		challengeList = append(challengeList, challenge)
-	}
-
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(challengeList)
+	json.NewEncoder(w).Encode(h.challengeService.ListChallenges())
 }
 
 // GetChallengeByID returns a specific challenge by ID
@@ -233,6 +111,11 @@ func (h *APIHandler) HandleSubmissions(w http.ResponseWriter, r *http.Request) {
 
 // createSubmission creates a new submission
 func (h *APIHandler) createSubmission(w http.ResponseWriter, r *http.Request) {
+	username, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
 	var submission models.Submission
 	err := json.NewDecoder(r.Body).Decode(&submission)
 	if err != nil {
@@ -240,7 +123,8 @@ func (h *APIHandler) createSubmission(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Set submission timestamp
+	// The submission is always the signed-in user's
+	submission.Username = username
 	submission.SubmittedAt = time.Now()
 
 	// Validate challenge exists
@@ -256,22 +140,128 @@ func (h *APIHandler) createSubmission(w http.ResponseWriter, r *http.Request) {
 	submission.TestOutput = result.Output
 	submission.ExecutionMs = result.ExecutionMs
 
+	recordAttempt(h.attempts, h.webhooks, services.NewAttempt(submission.Username, models.TrackClassic,
+		strconv.Itoa(submission.ChallengeID), "submit", submission.Code, result.Passed, result.Output, result.ExecutionMs))
+
 	// Store submission
-	h.submissions = append(h.submissions, submission)
+	if err := h.store.AddSubmission(submission); err != nil {
+		http.Error(w, "Failed to store submission", http.StatusInternalServerError)
+		return
+	}
 
 	// Add to scoreboard if passed
 	if submission.Passed {
 		h.scoreboardService.AddSubmission(submission)
 	}
+	awardAchievements(h.achievements, h.webhooks, submission.Username)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(submission)
 }
 
-// getSubmissions returns all submissions
+// getSubmissions returns a page of stored submissions.
+//
+// Query parameters: username, challenge, since and until (RFC 3339), limit and
+// offset. Source code and test output, which often repeats lines of the
+// source, are only included in the caller's own submissions, and users with
+// private profiles are left out for everyone but themselves.
 func (h *APIHandler) getSubmissions(w http.ResponseWriter, r *http.Request) {
+	query, err := parseSubmissionQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	viewer := auth.Username(r)
+	query.Visible = h.profiles.VisibleTo(viewer)
+
+	var (
+		submissions interface{}
+		total       int
+	)
+
+	switch track := r.URL.Query().Get("track"); track {
+	case "", "classic":
+		list, n := h.store.Submissions(query)
+		for i := range list {
+			if list[i].Username != viewer {
+				list[i].Code = ""
+				list[i].TestOutput = ""
+			}
+		}
+		submissions, total = list, n
+	case "package":
+		list, n := h.store.PackageSubmissions(query)
+		for i := range list {
+			if list[i].Username != viewer {
+				list[i].Code = ""
+				list[i].TestOutput = ""
+			}
+		}
+		submissions, total = list, n
+	case "release":
+		list, n := h.store.ReleaseSubmissions(query)
+		for i := range list {
+			if list[i].Username != viewer {
+				list[i].Code = ""
+				list[i].Output = ""
+			}
+		}
+		submissions, total = list, n
+	default:
+		http.Error(w, "Invalid track. Must be 'classic', 'package' or 'release'", http.StatusBadRequest)
+		return
+	}
+
+	response := struct {
+		Submissions interface{} `json:"submissions"`
+		Total       int         `json:"total"`
+		Limit       int         `json:"limit"`
+		Offset      int         `json:"offset"`
+	}{
+		Submissions: submissions,
+		Total:       total,
+		Limit:       query.Limit,
+		Offset:      query.Offset,
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(h.submissions)
+	json.NewEncoder(w).Encode(response)
+}
+
+// parseSubmissionQuery builds a store query from the request's query string
+func parseSubmissionQuery(r *http.Request) (services.SubmissionQuery, error) {
+	values := r.URL.Query()
+	query := services.SubmissionQuery{
+		Username:  values.Get("username"),
+		Challenge: values.Get("challenge"),
+		Limit:     services.DefaultSubmissionPageSize,
+	}
+
+	for name, target := range map[string]*time.Time{"since": &query.Since, "until": &query.Until} {
+		if raw := values.Get(name); raw != "" {
+			t, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				return query, fmt.Errorf("invalid %s: expected RFC 3339 timestamp", name)
+			}
+			*target = t
+		}
+	}
+
+	for name, target := range map[string]*int{"limit": &query.Limit, "offset": &query.Offset} {
+		if raw := values.Get(name); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil || n < 0 {
+				return query, fmt.Errorf("invalid %s", name)
+			}
+			*target = n
+		}
+	}
+	if query.Limit < 1 {
+		return query, fmt.Errorf("invalid limit: pages hold 1 to %d submissions", services.MaxSubmissionPageSize)
+	}
+	query.Limit = min(query.Limit, services.MaxSubmissionPageSize)
+
+	return query, nil
 }
 
 // GetScoreboard returns the scoreboard for a challenge
@@ -324,6 +314,9 @@ func (h *APIHandler) RunCode(w http.ResponseWriter, r *http.Request) {
 
 	result := h.executionService.RunCode(request.Code, challenge)
 
+	recordAttempt(h.attempts, h.webhooks, services.NewAttempt(auth.Username(r), models.TrackClassic,
+		strconv.Itoa(challenge.ID), "run", request.Code, result.Passed, result.Output, result.ExecutionMs))
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(result)
 }
@@ -335,20 +328,18 @@ func (h *APIHandler) SaveSubmissionToFilesystem(w http.ResponseWriter, r *http.R
 		return
 	}
 
+	username, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
 	var request services.SaveSubmissionRequest
 	err := json.NewDecoder(r.Body).Decode(&request)
 	if err != nil {
 		http.Error(w, "Invalid request data", http.StatusBadRequest)
 		return
 	}
-
-	if request.Username == "" {
-		http.Error(w, "Username is required", http.StatusBadRequest)
-		return
-	}
-
-	// Set username cookie
-	h.setUsernameCookie(w, request.Username)
+	request.Username = username
 
 	// Validate challenge exists
 	_, exists := h.challengeService.GetChallenge(request.ChallengeID)
@@ -373,22 +364,12 @@ func (h *APIHandler) RefreshUserAttempts(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	var request struct {
-		Username string `json:"username"`
-	}
-
-	err := json.NewDecoder(r.Body).Decode(&request)
-	if err != nil {
-		http.Error(w, "Invalid request data", http.StatusBadRequest)
-		return
-	}
-
-	if request.Username == "" {
-		http.Error(w, "Username is required", http.StatusBadRequest)
+	username, ok := requireUser(w, r)
+	if !ok {
 		return
 	}
 
-	attempts := h.userService.RefreshUserAttempts(request.Username, h.challengeService.GetChallenges())
+	attempts := h.userService.RefreshUserAttempts(username, h.challengeService.GetChallenges())
 
 	response := struct {
 		Username     string       `json:"username"`
@@ -396,7 +377,7 @@ func (h *APIHandler) RefreshUserAttempts(w http.ResponseWriter, r *http.Request)
 		Scores       map[int]int  `json:"scores"`
 		Success      bool         `json:"success"`
 	}{
-		Username:     request.Username,
+		Username:     username,
 		AttemptedIDs: attempts.AttemptedIDs,
 		Scores:       attempts.Scores,
 		Success:      true,
@@ -406,45 +387,6 @@ func (h *APIHandler) RefreshUserAttempts(w http.ResponseWriter, r *http.Request)
 	json.NewEncoder(w).Encode(response)
 }
 
-// GetGitUsername returns the username extracted from git configuration
-func (h *APIHandler) GetGitUsername(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "GET" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	gitInfo := utils.GetGitUsername()
-
-	response := struct {
-		Username string `json:"username"`
-		Email    string `json:"email"`
-		Source   string `json:"source"`
-		Success  bool   `json:"success"`
-	}{
-		Username: gitInfo.Username,
-		Email:    gitInfo.Email,
-		Source:   gitInfo.Source,
-		Success:  gitInfo.Username != "",
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
-}
-
-// setUsernameCookie sets the username cookie
-func (h *APIHandler) setUsernameCookie(w http.ResponseWriter, username string) {
-	// Cookie expires in 30 days
-	expiration := time.Now().Add(30 * 24 * time.Hour)
-	cookie := http.Cookie{
-		Name:     "username",
-		Value:    username,
-		Expires:  expiration,
-		Path:     "/",
-		HttpOnly: false, // Allow JavaScript to access it
-	}
-	http.SetCookie(w, &cookie)
-}
-
 // GetMainScoreboardRank returns the user's rank in the main scoreboard
 func (h *APIHandler) GetMainScoreboardRank(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "GET" {
@@ -452,14 +394,13 @@ func (h *APIHandler) GetMainScoreboardRank(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	username := r.URL.Query().Get("username")
-	if username == "" {
-		http.Error(w, "Username parameter required", http.StatusBadRequest)
+	username, ok := requireUser(w, r)
+	if !ok {
 		return
 	}
 
 	// Calculate user's rank in main scoreboard
-	rank := h.calculateMainScoreboardRank(username)
+	rank := h.leaderboards.MainRank(username)
 
 	response := struct {
 		Username string `json:"username"`
@@ -475,76 +416,6 @@ func (h *APIHandler) GetMainScoreboardRank(w http.ResponseWriter, r *http.Reques
 	json.NewEncoder(w).Encode(response)
 }
 
-// calculateMainScoreboardRank calculates the user's rank based on completed challenges
-func (h *APIHandler) calculateMainScoreboardRank(username string) int {
-	// Get all users and their completion counts (only count if ALL tests passed)
-	challenges := h.challengeService.GetChallenges()
-	userCompletions := make(map[string]int)
-
-	// Process all challenge scoreboards to count actual completions
-	for challengeID := range challenges {
-		// Read scoreboard file directly to check test results
-		scoreboardPath := fmt.Sprintf("../challenge-%d/SCOREBOARD.md", challengeID)
-		content, err := ioutil.ReadFile(scoreboardPath)
-		if err != nil {
-			// Try alternative path
-			scoreboardPath = fmt.Sprintf("challenge-%d/SCOREBOARD.md", challengeID)
-			content, err = ioutil.ReadFile(scoreboardPath)
-			if err != nil {
-				continue
-			}
-		}
-
-		// Parse scoreboard to find users who passed ALL tests
-		lines := strings.Split(string(content), "\n")
-		for _, line := range lines {
-			// Skip header and separator lines
-			if !strings.Contains(line, "|") || strings.Contains(line, "Username") || strings.Contains(line, "---") {
-				continue
-			}
-
-			parts := strings.Split(line, "|")
-			if len(parts) < 4 {
-				continue
-			}
-
-			username := strings.TrimSpace(parts[1])
-			passedTestsStr := strings.TrimSpace(parts[2])
-			totalTestsStr := strings.TrimSpace(parts[3])
-
-			// Skip empty usernames or placeholders
-			if username == "" || username == "------" {
-				continue
-			}
-
-			// Parse test counts
-			passedTests, err1 := strconv.Atoi(passedTestsStr)
-			totalTests, err2 := strconv.Atoi(totalTestsStr)
-
-			// Only count as completed if ALL tests passed
-			if err1 == nil && err2 == nil && passedTests > 0 && passedTests == totalTests {
-				userCompletions[username]++
-			}
-		}
-	}
-
-	// Get the target user's completion count
-	targetCompletions := userCompletions[username]
-	if targetCompletions == 0 {
-		return 0 // User is unranked
-	}
-
-	// Count how many users have more completions (following Python script logic)
-	rank := 1
-	for user, completions := range userCompletions {
-		if user != username && completions > targetCompletions {
-			rank++
-		}
-	}
-
-	return rank
-}
-
 // GetMainLeaderboard returns the main leaderboard data
 func (h *APIHandler) GetMainLeaderboard(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "GET" {
@@ -553,15 +424,15 @@ func (h *APIHandler) GetMainLeaderboard(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Calculate leaderboard data
-	leaderboard := h.calculateMainLeaderboard()
+	leaderboard := h.leaderboards.MainLeaderboard()
 
 	// Include total number of classic challenges for dynamic UI rendering
 	totalChallenges := len(h.challengeService.GetChallenges())
 
 	response := struct {
-		Leaderboard     []LeaderboardUser `json:"leaderboard"`
-		Success         bool              `json:"success"`
-		TotalChallenges int               `json:"totalChallenges"`
+		Leaderboard     []models.LeaderboardUser `json:"leaderboard"`
+		Success         bool                     `json:"success"`
+		TotalChallenges int                      `json:"totalChallenges"`
 	}{
 		Leaderboard:     leaderboard,
 		Success:         true,
@@ -605,7 +476,7 @@ func (h *APIHandler) GetPackageLeaderboard(w http.ResponseWriter, r *http.Reques
 	}
 
 	// Reuse existing creator to gather leaderboard
-	leaderboard := h.createPackageLeaderboard(packageName, challenges)
+	leaderboard := h.leaderboards.PackageLeaderboard(packageName, challenges)
 
 	response := map[string]interface{}{
 		"success":         true,
@@ -619,204 +490,6 @@ func (h *APIHandler) GetPackageLeaderboard(w http.ResponseWriter, r *http.Reques
 	json.NewEncoder(w).Encode(response)
 }
 
-// createPackageLeaderboard builds the package leaderboard based on filesystem submissions
-func (h *APIHandler) createPackageLeaderboard(packageName string, challenges []*models.PackageChallenge) []models.PackageScoreboardEntry {
-	var leaderboard []models.PackageScoreboardEntry
-	type userPackageStats struct {
-		username            string
-		completedCount      int
-		lastSubmission      time.Time
-		challengesCompleted map[string]bool
-	}
-	userStats := make(map[string]*userPackageStats)
-
-	// Load sponsors for package leaderboard
-	sponsors := h.LoadSponsors()
-
-	for _, challenge := range challenges {
-		submissionsDir := filepath.Join("..", "packages", packageName, challenge.ID, "submissions")
-		if _, err := os.Stat(submissionsDir); os.IsNotExist(err) {
-			continue
-		}
-		entries, err := ioutil.ReadDir(submissionsDir)
-		if err != nil {
-			continue
-		}
-		for _, entry := range entries {
-			if entry.IsDir() {
-				username := entry.Name()
-				userDir := filepath.Join(submissionsDir, username)
-				solutionPath := filepath.Join(userDir, "solution.go")
-				altSolutionPath := filepath.Join(userDir, "solution-template.go")
-
-				var modTime time.Time
-				if stat, err := os.Stat(solutionPath); err == nil {
-					modTime = stat.ModTime()
-				} else if stat, err := os.Stat(altSolutionPath); err == nil {
-					modTime = stat.ModTime()
-				} else {
-					continue
-				}
-
-				if userStats[username] == nil {
-					userStats[username] = &userPackageStats{
-						username:            username,
-						completedCount:      0,
-						lastSubmission:      modTime,
-						challengesCompleted: make(map[string]bool),
-					}
-				}
-				if !userStats[username].challengesCompleted[challenge.ID] {
-					userStats[username].completedCount++
-					userStats[username].challengesCompleted[challenge.ID] = true
-					if modTime.After(userStats[username].lastSubmission) {
-						userStats[username].lastSubmission = modTime
-					}
-				}
-			}
-		}
-	}
-
-	for username, stats := range userStats {
-		if stats.completedCount > 0 {
-			leaderboard = append(leaderboard, models.PackageScoreboardEntry{
-				Username:    username,
-				PackageName: packageName,
-				ChallengeID: "",
-				SubmittedAt: stats.lastSubmission,
-				TestsPassed: stats.completedCount,
-				TestsTotal:  len(challenges),
-				IsSponsor:   sponsors[username],
-			})
-		}
-	}
-
-	sort.Slice(leaderboard, func(i, j int) bool {
-		if leaderboard[i].TestsPassed != leaderboard[j].TestsPassed {
-			return leaderboard[i].TestsPassed > leaderboard[j].TestsPassed
-		}
-		return leaderboard[i].SubmittedAt.Before(leaderboard[j].SubmittedAt)
-	})
-
-	return leaderboard
-}
-
-// LeaderboardUser represents a user in the leaderboard
-type LeaderboardUser struct {
-	Username            string       `json:"username"`
-	CompletedCount      int          `json:"completedCount"`
-	CompletionRate      float64      `json:"completionRate"`
-	CompletedChallenges map[int]bool `json:"completedChallenges"`
-	Achievement         string       `json:"achievement"`
-	Rank                int          `json:"rank"`
-	IsSponsor           bool         `json:"isSponsor"`
-}
-
-// calculateMainLeaderboard calculates the main leaderboard data
-func (h *APIHandler) calculateMainLeaderboard() []LeaderboardUser {
-	challenges := h.challengeService.GetChallenges()
-	totalChallenges := len(challenges)
-	userCompletions := make(map[string]map[int]bool)
-
-	// Load sponsor information
-	sponsors := h.LoadSponsors()
-
-	// Process all challenge scoreboards to find completions
-	for challengeID := range challenges {
-		// Read scoreboard file directly to check test results
-		scoreboardPath := fmt.Sprintf("../challenge-%d/SCOREBOARD.md", challengeID)
-		content, err := ioutil.ReadFile(scoreboardPath)
-		if err != nil {
-			// Try alternative path
-			scoreboardPath = fmt.Sprintf("challenge-%d/SCOREBOARD.md", challengeID)
-			content, err = ioutil.ReadFile(scoreboardPath)
-			if err != nil {
-				continue
-			}
-		}
-
-		// Parse scoreboard to find users who passed ALL tests
-		lines := strings.Split(string(content), "\n")
-		for _, line := range lines {
-			// Skip header and separator lines
-			if !strings.Contains(line, "|") || strings.Contains(line, "Username") || strings.Contains(line, "---") {
-				continue
-			}
-
-			parts := strings.Split(line, "|")
-			if len(parts) < 4 {
-				continue
-			}
-
-			username := strings.TrimSpace(parts[1])
-			passedTestsStr := strings.TrimSpace(parts[2])
-			totalTestsStr := strings.TrimSpace(parts[3])
-
-			// Skip empty usernames or placeholders
-			if username == "" || username == "------" {
-				continue
-			}
-
-			// Parse test counts
-			passedTests, err1 := strconv.Atoi(passedTestsStr)
-			totalTests, err2 := strconv.Atoi(totalTestsStr)
-
-			// Only count as completed if ALL tests passed
-			if err1 == nil && err2 == nil && passedTests > 0 && passedTests == totalTests {
-				if userCompletions[username] == nil {
-					userCompletions[username] = make(map[int]bool)
-				}
-				userCompletions[username][challengeID] = true
-			}
-		}
-	}
-
-	// Convert to leaderboard format
-	var leaderboard []LeaderboardUser
-	for username, completions := range userCompletions {
-		completedCount := len(completions)
-		completionRate := float64(completedCount) / float64(totalChallenges) * 100
-
-		// Determine achievement
-		achievement := "🌱 Beginner"
-		if completedCount >= 20 {
-			achievement = "🔥 Master"
-		} else if completedCount >= 15 {
-			achievement = "⭐ Expert"
-		} else if completedCount >= 10 {
-			achievement = "💪 Advanced"
-		} else if completedCount >= 5 {
-			achievement = "🚀 Intermediate"
-		}
-
-		leaderboard = append(leaderboard, LeaderboardUser{
-			Username:            username,
-			CompletedCount:      completedCount,
-			CompletionRate:      completionRate,
-			CompletedChallenges: completions,
-			Achievement:         achievement,
-			IsSponsor:           sponsors[username],
-		})
-	}
-
-	// Sort by completion count (descending), then by username
-	for i := 0; i < len(leaderboard); i++ {
-		for j := i + 1; j < len(leaderboard); j++ {
-			if leaderboard[j].CompletedCount > leaderboard[i].CompletedCount ||
-				(leaderboard[j].CompletedCount == leaderboard[i].CompletedCount && leaderboard[j].Username < leaderboard[i].Username) {
-				leaderboard[i], leaderboard[j] = leaderboard[j], leaderboard[i]
-			}
-		}
-	}
-
-	// Assign ranks
-	for i := range leaderboard {
-		leaderboard[i].Rank = i + 1
-	}
-
-	return leaderboard
-}
-
 // HandlePackageChallenge handles package challenge test and submit requests
 func (h *APIHandler) HandlePackageChallenge(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
@@ -845,8 +518,7 @@ func (h *APIHandler) HandlePackageChallenge(w http.ResponseWriter, r *http.Reque
 
 	// Parse request body
 	var request struct {
-		Code     string `json:"code"`
-		Username string `json:"username"`
+		Code string `json:"code"`
 	}
 
 	body, err := ioutil.ReadAll(r.Body)
@@ -897,14 +569,46 @@ func (h *APIHandler) HandlePackageChallenge(w http.ResponseWriter, r *http.Reque
 	response["tests_passed"] = testsPassed
 	response["tests_total"] = testsTotal
 
+	username := auth.Username(r)
+	attemptAction := "run"
+	if action == "submit" {
+		attemptAction = "submit"
+	}
+	completedBefore := action == "submit" && result.Passed && username != "" &&
+		h.achievements.PackageProgress(username, packageName).Score == 100
+	recordAttempt(h.attempts, h.webhooks, services.NewAttempt(username, models.TrackPackage,
+		packageName+"/"+challengeId, attemptAction, request.Code, result.Passed, result.Output, result.ExecutionMs))
+
+	if action == "submit" && username != "" {
+		if err := h.store.AddPackageSubmission(models.PackageSubmission{
+			Username:    username,
+			PackageName: packageName,
+			ChallengeID: challengeId,
+			Code:        request.Code,
+			SubmittedAt: time.Now(),
+			Passed:      result.Passed,
+			TestOutput:  result.Output,
+			ExecutionMs: result.ExecutionMs,
+			TestsPassed: testsPassed,
+			TestsTotal:  testsTotal,
+		}); err != nil {
+			fmt.Printf("Error storing package submission: %v\n", err)
+		}
+		awardAchievements(h.achievements, h.webhooks, username)
+	}
+	if action == "submit" && result.Passed && username != "" && !completedBefore {
+		if progress := h.achievements.PackageProgress(username, packageName); progress.Score == 100 {
+			h.webhooks.Emit(models.EventPackageCompleted, models.PackageEvent{
+				Username:   username,
+				Package:    packageName,
+				Challenges: len(progress.CompletedChallenges),
+			})
+		}
+	}
+
 	if action == "submit" && result.Passed {
 		response["message"] = "Solution submitted successfully!"
 		response["show_pr_instructions"] = true
-
-		// Set username cookie if provided
-		if request.Username != "" {
-			h.setUsernameCookie(w, request.Username)
-		}
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -913,15 +617,14 @@ func (h *APIHandler) HandlePackageChallenge(w http.ResponseWriter, r *http.Reque
 
 // parseTestResults parses Go test output to count passed and total tests
 func (h *APIHandler) parseTestResults(output string) (passed int, total int) {
-	lines := strings.Split(output, "\n")
-
-	for _, line := range lines {
-		// Look for test result lines like "--- PASS: TestGetUsers" or "--- FAIL: TestCreateUser"
-		if strings.Contains(line, "--- PASS:") {
+	// Count test result lines like "--- PASS: TestGetUsers" or "--- FAIL: TestCreateUser"
+	for _, test := range services.ParseTestOutput(output) {
+		if test.Skipped {
+			continue
+		}
+		total++
+		if test.Passed {
 			passed++
-			total++
-		} else if strings.Contains(line, "--- FAIL:") {
-			total++
 		}
 	}
 
@@ -948,8 +651,12 @@ func (h *APIHandler) SavePackageChallengeToFilesystem(w http.ResponseWriter, r *
 		return
 	}
 
+	username, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
 	var request struct {
-		Username    string `json:"username"`
 		PackageName string `json:"packageName"`
 		ChallengeID string `json:"challengeId"`
 		Code        string `json:"code"`
@@ -961,14 +668,6 @@ func (h *APIHandler) SavePackageChallengeToFilesystem(w http.ResponseWriter, r *
 		return
 	}
 
-	if request.Username == "" {
-		http.Error(w, "Username is required", http.StatusBadRequest)
-		return
-	}
-
-	// Set username cookie
-	h.setUsernameCookie(w, request.Username)
-
 	// Validate challenge exists
 	_, err = h.packageService.GetPackageChallenge(request.PackageName, request.ChallengeID)
 	if err != nil {
@@ -977,75 +676,12 @@ func (h *APIHandler) SavePackageChallengeToFilesystem(w http.ResponseWriter, r *
 	}
 
 	// Save to filesystem
-	response := h.savePackageChallengeToFilesystem(request)
+	response := h.packageService.SaveSubmission(username, request.PackageName, request.ChallengeID, request.Code)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
 
-// savePackageChallengeToFilesystem handles the actual file saving for package challenges
-func (h *APIHandler) savePackageChallengeToFilesystem(request struct {
-	Username    string `json:"username"`
-	PackageName string `json:"packageName"`
-	ChallengeID string `json:"challengeId"`
-	Code        string `json:"code"`
-}) services.SaveSubmissionResponse {
-	// Get working directory for correct relative paths
-	workDir, _ := os.Getwd()
-
-	// Try different path approaches to handle potential path issues
-	var submissionDir string
-	var fileSaved bool
-
-	// Try multiple path options for package challenges
-	pathOptions := []string{
-		// Option 1: From web-ui directory (standard case)
-		filepath.Join("..", "packages", request.PackageName, request.ChallengeID, "submissions", request.Username),
-		// Option 2: From root workspace
-		filepath.Join("packages", request.PackageName, request.ChallengeID, "submissions", request.Username),
-		// Option 3: Absolute path from detected workspace root
-		filepath.Join(workDir, "..", "packages", request.PackageName, request.ChallengeID, "submissions", request.Username),
-	}
-
-	for _, dirPath := range pathOptions {
-		err := os.MkdirAll(dirPath, 0755)
-		if err != nil {
-			continue
-		}
-
-		solutionFile := filepath.Join(dirPath, "solution.go")
-		err = ioutil.WriteFile(solutionFile, []byte(request.Code), 0644)
-		if err != nil {
-			continue
-		}
-
-		submissionDir = dirPath
-		fileSaved = true
-		break
-	}
-
-	if !fileSaved {
-		return services.SaveSubmissionResponse{
-			Success: false,
-			Message: "Failed to save solution to any available path",
-		}
-	}
-
-	// Return success response with git commands
-	relativePath := filepath.Join("packages", request.PackageName, request.ChallengeID, "submissions", request.Username, "solution.go")
-	return services.SaveSubmissionResponse{
-		Success:  true,
-		Message:  "Solution saved to filesystem",
-		FilePath: filepath.Join(submissionDir, "solution.go"),
-		GitCommands: []string{
-			"cd " + filepath.Join(workDir, ".."),
-			fmt.Sprintf("git add %s", relativePath),
-			fmt.Sprintf("git commit -m \"Add solution for %s %s by %s\"", request.PackageName, request.ChallengeID, request.Username),
-			"git push origin main",
-		},
-	}
-}
-
 // AICodeReview performs AI-powered code review
 func (h *APIHandler) AICodeReview(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
@@ -1246,10 +882,7 @@ func (h *APIHandler) GitHubWebhookHandler(w http.ResponseWriter, r *http.Request
 	eventType := r.Header.Get("X-GitHub-Event")
 	if eventType == "sponsorship" {
 		// Clear the sponsor cache to force a refresh on next request
-		sponsorCache.mutex.Lock()
-		sponsorCache.sponsors = make(map[string]bool)
-		sponsorCache.lastUpdated = time.Time{} // Reset to zero time to force refresh
-		sponsorCache.mutex.Unlock()
+		services.ResetSponsors()
 
 		fmt.Printf("Sponsor cache cleared due to webhook event: %s\n", eventType)
 	}
@@ -1266,7 +899,7 @@ func (h *APIHandler) GetSponsorsDebug(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sponsors := h.LoadSponsors()
+	sponsors := services.LoadSponsors()
 
 	response := struct {
 		Sponsors map[string]bool `json:"sponsors"`
@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"embed"
+	"encoding/json"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"web-ui/internal/auth"
+	"web-ui/internal/models"
+	"web-ui/internal/services"
+	"web-ui/internal/utils"
+)
+
+// AssignmentHandler serves assignments: an inbox for assignees, and creation
+// and reports for the leads who assign them
+type AssignmentHandler struct {
+	content     embed.FS
+	assignments *services.AssignmentService
+}
+
+func NewAssignmentHandler(content embed.FS, assignments *services.AssignmentService) *AssignmentHandler {
+	return &AssignmentHandler{content: content, assignments: assignments}
+}
+
+// render executes an assignment template with base.html
+func (h *AssignmentHandler) render(w http.ResponseWriter, page string, data interface{}) {
+	tmpl, err := template.New("").Funcs(utils.GetTemplateFuncs()).ParseFS(h.content, "templates/base.html", "templates/"+page)
+	if err != nil {
+		log.Printf("Template error: %v", err)
+		http.Error(w, "Failed to parse template: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := tmpl.ExecuteTemplate(w, "base", data); err != nil {
+		log.Printf("Template execution error: %v", err)
+	}
+}
+
+// managedAssignment looks up an assignment, answering the request if the
+// caller may not manage it
+func (h *AssignmentHandler) managedAssignment(w http.ResponseWriter, r *http.Request, id string) (models.Assignment, bool) {
+	username, ok := requireUser(w, r)
+	if !ok {
+		return models.Assignment{}, false
+	}
+	assignment, ok := h.assignments.Assignment(id)
+	if !ok {
+		http.NotFound(w, r)
+		return assignment, false
+	}
+	if !h.assignments.CanManage(username, assignment) {
+		http.Error(w, "Only the assignment's creator and the leads of its assignees can see its report", http.StatusForbidden)
+		return assignment, false
+	}
+	return assignment, true
+}
+
+// AssignmentsPage renders /assignments, the signed-in user's inbox and the
+// assignments they manage, and /assignments/{id}, one assignment's report
+func (h *AssignmentHandler) AssignmentsPage(w http.ResponseWriter, r *http.Request) {
+	username := auth.Username(r)
+	if username == "" {
+		http.Redirect(w, r, "/auth/login?next="+url.QueryEscape(r.URL.Path), http.StatusFound)
+		return
+	}
+
+	now := time.Now()
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/assignments"), "/")
+	if id == "" {
+		h.render(w, "assignments.html", struct {
+			Inbox    []models.AssignmentInboxItem
+			Managed  []*models.AssignmentReport
+			LedTeams []models.Team
+			Now      time.Time
+		}{
+			Inbox:    h.assignments.Inbox(username, now),
+			Managed:  h.managedReports(username, now),
+			LedTeams: h.assignments.LedTeams(username),
+			Now:      now,
+		})
+		return
+	}
+
+	assignment, ok := h.managedAssignment(w, r, id)
+	if !ok {
+		return
+	}
+	h.render(w, "assignment.html", struct {
+		Report *models.AssignmentReport
+	}{
+		Report: h.assignments.Report(assignment, now),
+	})
+}
+
+// managedReports reports on every assignment username manages
+func (h *AssignmentHandler) managedReports(username string, now time.Time) []*models.AssignmentReport {
+	reports := []*models.AssignmentReport{}
+	for _, a := range h.assignments.Managed(username) {
+		reports = append(reports, h.assignments.Report(a, now))
+	}
+	return reports
+}
+
+// HandleAssignments serves the assignment API.
+//
+//	GET    /api/assignments                   → the assignments you manage, with reports
+//	POST   /api/assignments                   → assign challenges to users and teams you lead
+//	GET    /api/assignments/inbox             → the assignments given to you, with your progress
+//	GET    /api/assignments/reports/overdue   → assignees past due, on assignments you manage
+//	GET    /api/assignments/reports/completed → assignees who passed everything, on time or late
+//	GET    /api/assignments/{id}              → one assignment's report
+//	DELETE /api/assignments/{id}              → withdraw an assignment
+//
+// The reports are CSV with ?format=csv.
+func (h *AssignmentHandler) HandleAssignments(w http.ResponseWriter, r *http.Request) {
+	username, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/assignments"), "/")
+	switch {
+	case path == "" && r.Method == "GET":
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(h.managedReports(username, now))
+	case path == "" && r.Method == "POST":
+		h.create(w, r, username)
+	case path == "inbox" && r.Method == "GET":
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(h.assignments.Inbox(username, now))
+	case strings.HasPrefix(path, "reports/") && r.Method == "GET":
+		h.statusReport(w, r, username, strings.TrimPrefix(path, "reports/"), now)
+	case path != "" && !strings.Contains(path, "/") && (r.Method == "GET" || r.Method == "DELETE"):
+		assignment, ok := h.managedAssignment(w, r, path)
+		if !ok {
+			return
+		}
+		if r.Method == "GET" {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(h.assignments.Report(assignment, now))
+			return
+		}
+		if _, err := h.assignments.Delete(assignment.ID); err != nil {
+			log.Printf("assignments: %v", err)
+			http.Error(w, "Failed to save assignments", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case path == "" || path == "inbox" || strings.HasPrefix(path, "reports/") || !strings.Contains(path, "/"):
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// create validates and saves a new assignment from the request body
+func (h *AssignmentHandler) create(w http.ResponseWriter, r *http.Request, username string) {
+	var assignment models.Assignment
+	if err := json.NewDecoder(r.Body).Decode(&assignment); err != nil {
+		http.Error(w, "Invalid request data: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := h.assignments.ValidateAssignment(&assignment); err != nil {
+		http.Error(w, "Invalid assignment: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !h.assignments.CanAssign(username, assignment) {
+		http.Error(w, "You can only assign to teams you lead and their members", http.StatusForbidden)
+		return
+	}
+
+	created, err := h.assignments.Create(assignment, username)
+	if err != nil {
+		log.Printf("assignments: %v", err)
+		http.Error(w, "Failed to save assignments", http.StatusInternalServerError)
+		return
+	}
+	log.Printf("assignments: %s assigned %q (%s), due %s", username, created.Title, created.ID, created.DueAt.Format(time.RFC3339))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+// statusReport answers the overdue and completed reports
+func (h *AssignmentHandler) statusReport(w http.ResponseWriter, r *http.Request, username, report string, now time.Time) {
+	var statuses []string
+	switch report {
+	case "overdue":
+		statuses = []string{models.AssignmentOverdue}
+	case "completed":
+		statuses = []string{models.AssignmentCompleted, models.AssignmentLate}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	rows := h.assignments.StatusReport(username, now, statuses...)
+
+	if r.URL.Query().Get("format") != "csv" {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(rows)
+		return
+	}
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="assignments-`+report+`.csv"`)
+	if err := services.WriteAssignmentReportCSV(w, rows); err != nil {
+		log.Printf("assignments: %s report: %v", report, err)
+	}
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"web-ui/internal/models"
+	"web-ui/internal/services"
+	"web-ui/internal/utils"
+)
+
+// recordAttempt stores a run or submit in the user's attempt history and
+// announces it to webhooks: every attempt is a run.completed, and a passing
+// submit is also a submission.passed. Failures are logged rather than
+// surfaced: history must never break running tests.
+func recordAttempt(store services.AttemptStore, webhooks *services.WebhookService, attempt *models.Attempt) {
+	if attempt.Username == "" {
+		return
+	}
+	if err := store.AddAttempt(attempt); err != nil {
+		log.Printf("attempts: failed to record %s %s/%s for %s: %v",
+			attempt.Action, attempt.Track, attempt.ChallengeID, attempt.Username, err)
+	}
+
+	event := attemptEvent(attempt)
+	webhooks.Emit(models.EventRunCompleted, event)
+	if attempt.Action == "submit" && attempt.Passed {
+		webhooks.Emit(models.EventSubmissionPassed, event)
+	}
+}
+
+// attemptEvent is the webhook data for an attempt
+func attemptEvent(attempt *models.Attempt) models.AttemptEvent {
+	return models.AttemptEvent{
+		Username:    attempt.Username,
+		Track:       attempt.Track,
+		ChallengeID: attempt.ChallengeID,
+		Action:      attempt.Action,
+		Passed:      attempt.Passed,
+		TestsPassed: attempt.TestsPassed,
+		TestsTotal:  attempt.TestsTotal,
+		ExecutionMs: attempt.ExecutionMs,
+		AttemptID:   attempt.ID,
+	}
+}
+
+// attemptSummary is an attempt without its code, for listings
+type attemptSummary struct {
+	models.Attempt
+	Code string `json:"code,omitempty"`
+}
+
+// HandleAttempts serves a user's attempt history.
+//
+//	GET /api/attempts?track=classic&challenge=12  → the caller's attempts, oldest first
+//	GET /api/attempts/{id}                        → one attempt, including its code
+//	GET /api/attempts/diff?from={id}&to={id}      → unified diff between two attempts
+func (h *APIHandler) HandleAttempts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	username, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	switch path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/attempts"), "/"); path {
+	case "":
+		h.listAttempts(w, r, username)
+	case "diff":
+		h.diffAttempts(w, r, username)
+	default:
+		attempt, ok := h.attempts.GetAttempt(path)
+		if !ok || attempt.Username != username {
+			http.Error(w, "Attempt not found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(attempt)
+	}
+}
+
+// listAttempts returns the caller's attempts for one challenge
+func (h *APIHandler) listAttempts(w http.ResponseWriter, r *http.Request, username string) {
+	track := r.URL.Query().Get("track")
+	if track == "" {
+		track = models.TrackClassic
+	}
+	challengeID := r.URL.Query().Get("challenge")
+	if challengeID == "" {
+		http.Error(w, "challenge parameter required", http.StatusBadRequest)
+		return
+	}
+
+	attempts := h.attempts.ListAttempts(username, track, challengeID)
+	summaries := make([]attemptSummary, len(attempts))
+	for i, attempt := range attempts {
+		summaries[i] = attemptSummary{Attempt: attempt}
+	}
+
+	response := struct {
+		Attempts []attemptSummary `json:"attempts"`
+		Success  bool             `json:"success"`
+	}{
+		Attempts: summaries,
+		Success:  true,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// diffAttempts returns a unified diff between two of the caller's attempts
+func (h *APIHandler) diffAttempts(w http.ResponseWriter, r *http.Request, username string) {
+	fromID, toID := r.URL.Query().Get("from"), r.URL.Query().Get("to")
+	from, okFrom := h.attempts.GetAttempt(fromID)
+	to, okTo := h.attempts.GetAttempt(toID)
+	if !okFrom || !okTo || from.Username != username || to.Username != username {
+		http.Error(w, "Attempt not found", http.StatusNotFound)
+		return
+	}
+
+	label := func(a *models.Attempt) string {
+		return fmt.Sprintf("%s (%s %s)", a.ID, a.Action, a.CreatedAt.Format("2006-01-02 15:04:05"))
+	}
+
+	response := struct {
+		From    string `json:"from"`
+		To      string `json:"to"`
+		Diff    string `json:"diff"`
+		Success bool   `json:"success"`
+	}{
+		From:    from.ID,
+		To:      to.ID,
+		Diff:    utils.UnifiedDiff(from.Code, to.Code, label(from), label(to)),
+		Success: true,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"embed"
+	"encoding/json"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+
+	"web-ui/internal/auth"
+	"web-ui/internal/utils"
+)
+
+// callbackPath is where the provider sends the browser back to
+const callbackPath = "/auth/callback"
+
+// AuthHandler signs visitors in and out
+type AuthHandler struct {
+	content   embed.FS
+	sessions  *auth.Sessions
+	provider  auth.Provider
+	publicURL string // empty: derive the callback URL from the request
+}
+
+func NewAuthHandler(content embed.FS, sessions *auth.Sessions, provider auth.Provider, publicURL string) *AuthHandler {
+	return &AuthHandler{content: content, sessions: sessions, provider: provider, publicURL: publicURL}
+}
+
+// Login starts a sign-in. GET /auth/login?next=/challenge/3 returns to that
+// page afterwards.
+func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	state := h.sessions.NewState(w, r, localPath(r.URL.Query().Get("next")))
+	http.Redirect(w, r, h.provider.AuthCodeURL(state, h.callbackURL(r)), http.StatusFound)
+}
+
+// Callback finishes a sign-in: it checks the state, exchanges the code for the
+// user and starts their session.
+func (h *AuthHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	next, ok := h.sessions.CheckState(w, r, query.Get("state"))
+	if !ok {
+		http.Error(w, "Sign-in expired or was started in another browser; please sign in again", http.StatusBadRequest)
+		return
+	}
+	if reason := query.Get("error"); reason != "" {
+		http.Error(w, "Sign-in was not completed: "+reason, http.StatusUnauthorized)
+		return
+	}
+
+	user, err := h.provider.Exchange(r.Context(), query.Get("code"), h.callbackURL(r))
+	if err != nil {
+		log.Printf("auth: %s sign-in failed: %v", h.provider.Name(), err)
+		http.Error(w, "Sign-in failed", http.StatusBadGateway)
+		return
+	}
+
+	h.sessions.Start(w, r, user)
+	http.Redirect(w, r, next, http.StatusSeeOther)
+}
+
+// Logout ends the session. It only accepts POST so that another site cannot
+// sign visitors out with a link or an image.
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	h.sessions.End(w, r)
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// LocalSignIn renders the local provider's sign-in form
+func (h *AuthHandler) LocalSignIn(w http.ResponseWriter, r *http.Request) {
+	tmpl, err := template.New("").Funcs(utils.GetTemplateFuncs()).ParseFS(h.content, "templates/base.html", "templates/sign_in.html")
+	if err != nil {
+		log.Printf("Template error: %v", err)
+		http.Error(w, "Failed to parse template: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data := struct {
+		State        string
+		CallbackPath string
+	}{
+		State:        r.URL.Query().Get("state"),
+		CallbackPath: callbackPath,
+	}
+
+	if err := tmpl.ExecuteTemplate(w, "base", data); err != nil {
+		log.Printf("Template execution error: %v", err)
+	}
+}
+
+// Me returns the signed-in user, for the navigation bar
+func (h *AuthHandler) Me(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, ok := auth.UserFrom(r.Context())
+	response := struct {
+		Authenticated bool   `json:"authenticated"`
+		Provider      string `json:"provider"`
+		*auth.User
+	}{
+		Authenticated: ok,
+		Provider:      h.provider.Name(),
+	}
+	if ok {
+		response.User = &user
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// callbackURL is the absolute URL of the callback, which GitHub checks against
+// the OAuth app's registered callback
+func (h *AuthHandler) callbackURL(r *http.Request) string {
+	if h.publicURL != "" {
+		return h.publicURL + callbackPath
+	}
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + callbackPath
+}
+
+// localPath keeps a post-sign-in redirect on this site
+func localPath(next string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		return "/"
+	}
+	return next
+}
+
+// requireUser returns the signed-in user's login, answering 401 if there is none
+func requireUser(w http.ResponseWriter, r *http.Request) (string, bool) {
+	username := auth.Username(r)
+	if username == "" {
+		http.Error(w, "Sign in to continue", http.StatusUnauthorized)
+		return "", false
+	}
+	return username, true
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"web-ui/internal/services"
+)
+
+// BadgeHandler serves contributor profile badges rendered from the live
+// leaderboards, for embedding in GitHub profiles and personal sites.
+type BadgeHandler struct {
+	badges   *services.BadgeService
+	profiles *services.ProfileService
+}
+
+func NewBadgeHandler(badges *services.BadgeService, profiles *services.ProfileService) *BadgeHandler {
+	return &BadgeHandler{badges: badges, profiles: profiles}
+}
+
+// ServeBadge dispatches everything under /badges.
+//
+//	/badges/{user}.svg          → full-size profile card
+//	/badges/{user}/compact.svg  → compact horizontal badge
+//	/badges/{user}.json         → shields.io endpoint badge
+//	/badges/{user}/achievements.json → the contributor's achievements
+//
+// Private profiles have no badges: they would show the achievements the
+// profile hides.
+func (h *BadgeHandler) ServeBadge(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" && r.Method != "HEAD" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/badges/")
+	var username, contentType string
+	var render func(services.BadgeStats) []byte
+	switch {
+	case strings.HasSuffix(path, "/achievements.json"):
+		username, contentType, render = strings.TrimSuffix(path, "/achievements.json"), "application/json", services.BadgeStats.AchievementsJSON
+	case strings.HasSuffix(path, "/compact.svg"):
+		username, contentType, render = strings.TrimSuffix(path, "/compact.svg"), "image/svg+xml", services.BadgeStats.CompactSVG
+	case strings.HasSuffix(path, ".svg"):
+		username, contentType, render = strings.TrimSuffix(path, ".svg"), "image/svg+xml", services.BadgeStats.CardSVG
+	case strings.HasSuffix(path, ".json"):
+		username, contentType, render = strings.TrimSuffix(path, ".json"), "application/json", services.BadgeStats.ShieldsJSON
+	}
+	if username == "" || strings.Contains(username, "/") {
+		http.NotFound(w, r)
+		return
+	}
+
+	stats, ok := h.badges.Stats(username)
+	if !ok || !h.profiles.IsPublic(username) {
+		http.Error(w, "No badge for this user yet", http.StatusNotFound)
+		return
+	}
+	writeCached(w, r, contentType, render(stats))
+}
+
+// writeCached writes body with an ETag derived from its content, answering
+// 304 Not Modified when the client already has it. Badges are fetched through
+// image proxies (GitHub's camo, shields.io) that revalidate on every view.
+func writeCached(w http.ResponseWriter, r *http.Request, contentType string, body []byte) {
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	for _, match := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+		match = strings.TrimPrefix(strings.TrimSpace(match), "W/")
+		if match == etag || match == "*" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	if r.Method == "HEAD" {
+		return
+	}
+	w.Write(body)
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"embed"
+	"encoding/json"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+
+	"web-ui/internal/auth"
+	"web-ui/internal/models"
+	"web-ui/internal/services"
+	"web-ui/internal/utils"
+)
+
+// CurriculumHandler serves curricula, with the signed-in user's progress
+type CurriculumHandler struct {
+	content   embed.FS
+	curricula *services.CurriculumService
+}
+
+func NewCurriculumHandler(content embed.FS, curricula *services.CurriculumService) *CurriculumHandler {
+	return &CurriculumHandler{content: content, curricula: curricula}
+}
+
+// render executes a curriculum template with base.html
+func (h *CurriculumHandler) render(w http.ResponseWriter, page string, data interface{}) {
+	tmpl, err := template.New("").Funcs(utils.GetTemplateFuncs()).ParseFS(h.content, "templates/base.html", "templates/"+page)
+	if err != nil {
+		log.Printf("Template error: %v", err)
+		http.Error(w, "Failed to parse template: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := tmpl.ExecuteTemplate(w, "base", data); err != nil {
+		log.Printf("Template execution error: %v", err)
+	}
+}
+
+// CurriculaPage renders /curricula, every curriculum, and /curricula/{name},
+// one curriculum's modules
+func (h *CurriculumHandler) CurriculaPage(w http.ResponseWriter, r *http.Request) {
+	username := auth.Username(r)
+	name := strings.Trim(strings.TrimPrefix(r.URL.Path, "/curricula"), "/")
+	if name == "" {
+		h.render(w, "curricula.html", struct {
+			Curricula []*models.CurriculumProgress
+			Username  string
+		}{
+			Curricula: h.curricula.AllProgress(username),
+			Username:  username,
+		})
+		return
+	}
+
+	curriculum, ok := h.curricula.Curriculum(name)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	h.render(w, "curriculum.html", struct {
+		Curriculum *models.CurriculumProgress
+		Username   string
+	}{
+		Curriculum: h.curricula.Progress(curriculum, username),
+		Username:   username,
+	})
+}
+
+// HandleCurricula serves the curriculum API. Progress is the signed-in
+// user's, and empty for anonymous callers.
+//
+//	GET /api/curricula        → every curriculum with progress
+//	GET /api/curricula/{name} → one curriculum with progress on each entry
+func (h *CurriculumHandler) HandleCurricula(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	username := auth.Username(r)
+	var body interface{}
+	if name := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/curricula"), "/"); name == "" {
+		body = h.curricula.AllProgress(username)
+	} else {
+		curriculum, ok := h.curricula.Curriculum(name)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		body = h.curricula.Progress(curriculum, username)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(body)
+}
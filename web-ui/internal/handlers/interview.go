@@ -0,0 +1,442 @@
+package handlers
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"web-ui/internal/auth"
+	"web-ui/internal/models"
+	"web-ui/internal/services"
+	"web-ui/internal/utils"
+)
+
+// InterviewHandler serves mock interview sessions. Runs and AI help during an
+// interview go through here rather than /api/run and /api/ai, so that they
+// are recorded and refused once time is up.
+type InterviewHandler struct {
+	content          embed.FS
+	interviews       *services.InterviewService
+	loops            *services.InterviewLoopService
+	rubrics          *services.RubricService
+	challengeService *services.ChallengeService
+	executionService *services.ExecutionService
+	aiService        *services.AIService
+	aiEnabled        bool
+	attempts         services.AttemptStore
+	webhooks         *services.WebhookService
+}
+
+func NewInterviewHandler(
+	content embed.FS,
+	interviews *services.InterviewService,
+	loops *services.InterviewLoopService,
+	rubrics *services.RubricService,
+	challengeService *services.ChallengeService,
+	executionService *services.ExecutionService,
+	aiService *services.AIService,
+	aiEnabled bool,
+	attempts services.AttemptStore,
+	webhooks *services.WebhookService,
+) *InterviewHandler {
+	return &InterviewHandler{
+		content:          content,
+		interviews:       interviews,
+		loops:            loops,
+		rubrics:          rubrics,
+		challengeService: challengeService,
+		executionService: executionService,
+		aiService:        aiService,
+		aiEnabled:        aiEnabled,
+		attempts:         attempts,
+		webhooks:         webhooks,
+	}
+}
+
+// ReportPage renders /interview/{id}, an interview's report and replay, for
+// the candidate and the leads of their teams
+func (h *InterviewHandler) ReportPage(w http.ResponseWriter, r *http.Request) {
+	username := auth.Username(r)
+	if username == "" {
+		http.Redirect(w, r, "/auth/login?next="+url.QueryEscape(r.URL.Path), http.StatusFound)
+		return
+	}
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/interview/"), "/")
+	session, ok := h.interviews.Session(id)
+	if !ok || !h.interviews.CanWatch(username, session.Username) {
+		http.NotFound(w, r)
+		return
+	}
+
+	tmpl, err := template.New("").Funcs(utils.GetTemplateFuncs()).ParseFS(h.content, "templates/base.html", "templates/interview_report.html")
+	if err != nil {
+		log.Printf("Template error: %v", err)
+		http.Error(w, "Failed to parse template: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	// Each challenge is shown with its scorecard, or the rubric to score it on
+	rubrics := make(map[int]models.Rubric)
+	for _, id := range session.ChallengeIDs {
+		if rubric, ok := h.rubrics.RubricFor(id); ok {
+			rubrics[id] = rubric
+		}
+	}
+	for _, scorecard := range session.Scorecards {
+		rubrics[scorecard.ChallengeID] = scorecard.Rubric
+	}
+	data := struct {
+		Session   models.InterviewSession
+		Username  string
+		Rubrics   map[int]models.Rubric
+		CanScore  bool
+		AIEnabled bool
+	}{
+		Session:   session,
+		Username:  username,
+		Rubrics:   rubrics,
+		CanScore:  h.interviews.CanScore(username, session.Username),
+		AIEnabled: h.aiEnabled,
+	}
+	if err := tmpl.ExecuteTemplate(w, "base", data); err != nil {
+		log.Printf("Template execution error: %v", err)
+	}
+}
+
+// HandleInterviews serves the interview API.
+//
+//	GET  /api/interviews                → your interviews, newest first; ?user= for a member of a team you lead
+//	POST /api/interviews                → start an interview
+//	GET  /api/interviews/active         → your interview in progress
+//	GET  /api/interviews/{id}           → one interview, with its recordings and report
+//	GET  /api/interviews/{id}/replay    → its timeline; ?at=RFC3339 or ?offset=seconds for the code at a moment
+//	GET  /api/interviews/{id}/export    → its report as Markdown, or with ?format=html as a printable page
+//	POST /api/interviews/{id}/snapshot  → save the code of a challenge
+//	POST /api/interviews/{id}/run       → run a challenge's tests
+//	POST /api/interviews/{id}/hint      → ask the AI for a hint
+//	POST /api/interviews/{id}/review    → ask the AI for a review
+//	POST /api/interviews/{id}/questions → ask the AI for follow-up questions
+//	POST /api/interviews/{id}/finish    → end the interview and score it
+//
+//	PUT  /api/interviews/{id}/scorecards/{challengeId}         → score a challenge of an ended interview against its rubric
+//	POST /api/interviews/{id}/scorecards/{challengeId}/suggest → ask the AI to suggest scores from the final code
+//
+// Recording into an interview that is over is answered 409. The leads of the
+// candidate's teams may watch an interview but not act in it; once it has
+// ended, they score it.
+func (h *InterviewHandler) HandleInterviews(w http.ResponseWriter, r *http.Request) {
+	username, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/interviews"), "/")
+	id, action, _ := strings.Cut(path, "/")
+	switch {
+	case path == "" && r.Method == "GET":
+		candidate := username
+		if user := r.URL.Query().Get("user"); user != "" {
+			candidate = user
+		}
+		if !h.interviews.CanWatch(username, candidate) {
+			http.Error(w, "Only the candidate and the leads of their teams can see their interviews", http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(h.interviews.Sessions(candidate))
+	case path == "" && r.Method == "POST":
+		h.start(w, r, username)
+	case path == "active" && r.Method == "GET":
+		session, ok := h.interviews.Active(username)
+		if !ok {
+			http.Error(w, "No interview in progress", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(session)
+	case action == "" && r.Method == "GET":
+		session, ok := h.watchedSession(w, r, username, id)
+		if !ok {
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(session)
+	case action == "replay" && r.Method == "GET":
+		session, ok := h.watchedSession(w, r, username, id)
+		if !ok {
+			return
+		}
+		h.replay(w, r, session)
+	case action == "export" && r.Method == "GET":
+		session, ok := h.watchedSession(w, r, username, id)
+		if !ok {
+			return
+		}
+		h.export(w, r, session)
+	case strings.HasPrefix(action, "scorecards/"):
+		h.scorecard(w, r, username, id, strings.TrimPrefix(action, "scorecards/"))
+	case action != "" && !strings.Contains(action, "/") && r.Method == "POST":
+		session, ok := h.ownSession(w, r, username, id)
+		if !ok {
+			return
+		}
+		h.act(w, r, session, action)
+	case path == "" || path == "active" || !strings.Contains(action, "/"):
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// ownSession looks up one of username's interviews, answering the request if
+// there is no such interview
+func (h *InterviewHandler) ownSession(w http.ResponseWriter, r *http.Request, username, id string) (models.InterviewSession, bool) {
+	session, ok := h.interviews.Session(id)
+	if !ok || !strings.EqualFold(session.Username, username) {
+		http.Error(w, "Interview not found", http.StatusNotFound)
+		return session, false
+	}
+	return session, true
+}
+
+// watchedSession looks up an interview username may watch, answering the
+// request if there is no such interview
+func (h *InterviewHandler) watchedSession(w http.ResponseWriter, r *http.Request, username, id string) (models.InterviewSession, bool) {
+	session, ok := h.interviews.Session(id)
+	if !ok || !h.interviews.CanWatch(username, session.Username) {
+		http.Error(w, "Interview not found", http.StatusNotFound)
+		return session, false
+	}
+	return session, true
+}
+
+// replay answers an interview's timeline, or with ?at= or ?offset= the code
+// of every challenge at that moment
+func (h *InterviewHandler) replay(w http.ResponseWriter, r *http.Request, session models.InterviewSession) {
+	query := r.URL.Query()
+	var response interface{}
+	switch {
+	case query.Get("at") != "":
+		at, err := time.Parse(time.RFC3339, query.Get("at"))
+		if err != nil {
+			http.Error(w, "Invalid at: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		response = h.interviews.CodeAt(session, at)
+	case query.Get("offset") != "":
+		offset, err := strconv.Atoi(query.Get("offset"))
+		if err != nil || offset < 0 {
+			http.Error(w, "Invalid offset: it is a number of seconds", http.StatusBadRequest)
+			return
+		}
+		response = h.interviews.CodeAt(session, session.StartedAt.Add(time.Duration(offset)*time.Second))
+	default:
+		response = h.interviews.Replay(session)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// start validates the request body and starts an interview
+func (h *InterviewHandler) start(w http.ResponseWriter, r *http.Request, username string) {
+	var request struct {
+		ChallengeIDs []int  `json:"challengeIds"`
+		Random       int    `json:"random"` // how many to pick when none are chosen
+		Difficulty   string `json:"difficulty"`
+		LoopID       string `json:"loopId"` // instead of the above
+		TimeLimit    int    `json:"timeLimitMinutes"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request data: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if request.LoopID != "" {
+		loop, ok := h.loops.Loop(request.LoopID)
+		if !ok {
+			http.Error(w, "Invalid interview: there is no loop "+request.LoopID, http.StatusBadRequest)
+			return
+		}
+		ids, err := loopChallengeIDs(loop)
+		if err != nil {
+			http.Error(w, "Invalid interview: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		request.ChallengeIDs = ids
+		if request.TimeLimit == 0 {
+			request.TimeLimit = max(services.MinInterviewMinutes, min(loop.TotalMinutes, services.MaxInterviewMinutes))
+		}
+	}
+	if active, ok := h.interviews.Active(username); ok {
+		http.Error(w, fmt.Sprintf("Finish interview %s before starting another", active.ID), http.StatusConflict)
+		return
+	}
+	if request.TimeLimit < services.MinInterviewMinutes || request.TimeLimit > services.MaxInterviewMinutes {
+		http.Error(w, fmt.Sprintf("Invalid interview: the time limit must be %d to %d minutes",
+			services.MinInterviewMinutes, services.MaxInterviewMinutes), http.StatusBadRequest)
+		return
+	}
+	ids, err := h.interviews.PickChallenges(request.ChallengeIDs, request.Random, request.Difficulty)
+	if err != nil {
+		http.Error(w, "Invalid interview: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	session, err := h.interviews.Start(username, ids, len(request.ChallengeIDs) == 0, request.TimeLimit)
+	if err != nil {
+		log.Printf("interviews: %v", err)
+		http.Error(w, "Failed to save interview", http.StatusInternalServerError)
+		return
+	}
+	log.Printf("interviews: %s started %s with challenges %v for %d minutes", username, session.ID, ids, session.TimeLimit)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(session)
+}
+
+// act performs one of the POST actions on an interview
+func (h *InterviewHandler) act(w http.ResponseWriter, r *http.Request, session models.InterviewSession, action string) {
+	now := time.Now()
+	if action == "finish" {
+		ended, err := h.interviews.Finish(session.ID, now)
+		if err != nil {
+			log.Printf("interviews: %v", err)
+			http.Error(w, "Failed to save interview", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ended)
+		return
+	}
+
+	switch action {
+	case "snapshot", "run":
+	case "hint", "review", "questions":
+		if !h.aiEnabled {
+			http.NotFound(w, r)
+			return
+		}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	var request struct {
+		ChallengeID int    `json:"challengeId"`
+		Code        string `json:"code"`
+		HintLevel   int    `json:"hintLevel"`
+		Context     string `json:"context"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request data", http.StatusBadRequest)
+		return
+	}
+	if session.Status != models.InterviewActive || now.After(session.EndsAt) {
+		http.Error(w, "The interview is over", http.StatusConflict)
+		return
+	}
+	challenge, ok := h.challengeService.GetChallenge(request.ChallengeID)
+	if !ok || !containsInt(session.ChallengeIDs, request.ChallengeID) {
+		http.Error(w, "Challenge is not part of this interview", http.StatusBadRequest)
+		return
+	}
+
+	var response interface{}
+	var err error
+	switch action {
+	case "snapshot":
+		err = h.interviews.Snapshot(session.ID, request.ChallengeID, request.Code, now)
+		response = struct {
+			Saved bool `json:"saved"`
+		}{Saved: err == nil}
+	case "run":
+		result := h.executionService.RunCode(request.Code, challenge)
+		var attempt *models.Attempt
+		attempt, err = h.interviews.RecordRun(session.ID, session.Username, request.ChallengeID, request.Code, result, now)
+		if err == nil {
+			// A run refused as past the time limit is not part of the history
+			recordAttempt(h.attempts, h.webhooks, attempt)
+		}
+		response = result
+	default:
+		response, err = h.askAI(session, challenge, action, request.Code, request.HintLevel, request.Context, now)
+		var aiErr aiError
+		if errors.As(err, &aiErr) {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if errors.Is(err, services.ErrInterviewOver) {
+		http.Error(w, "The interview is over", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("interviews: %v", err)
+		http.Error(w, "Failed to save interview", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// aiError is a failure of the AI itself, as opposed to of recording its answer
+type aiError struct{ error }
+
+// askAI asks for a hint, review or questions and records the answer. The
+// response has the shape of the matching /api/ai endpoint's.
+func (h *InterviewHandler) askAI(session models.InterviewSession, challenge *models.Challenge, kind, code string, level int, context string, at time.Time) (interface{}, error) {
+	request := models.InterviewAIRequest{ChallengeID: challenge.ID, Kind: kind, At: at}
+	var response interface{}
+	switch kind {
+	case models.InterviewHint:
+		if level < 1 || level > 4 {
+			level = 1
+		}
+		hint, err := h.aiService.GetCodeHint(code, challenge, level)
+		if err != nil {
+			return nil, aiError{fmt.Errorf("AI hint failed: %v", err)}
+		}
+		request.Level, request.Response = level, hint
+		response = struct {
+			Hint      string `json:"hint"`
+			HintLevel int    `json:"hintLevel"`
+			Success   bool   `json:"success"`
+		}{Hint: hint, HintLevel: level, Success: true}
+	case models.InterviewReview:
+		review, err := h.aiService.ReviewCode(code, challenge, context)
+		if err != nil {
+			return nil, aiError{fmt.Errorf("AI review failed: %v", err)}
+		}
+		raw, _ := json.Marshal(review)
+		request.Response = string(raw)
+		response = review
+	case models.InterviewQuestions:
+		questions, err := h.aiService.GetInterviewerQuestions(code, challenge, context)
+		if err != nil {
+			return nil, aiError{fmt.Errorf("AI questions failed: %v", err)}
+		}
+		request.Response = strings.Join(questions, "\n")
+		response = struct {
+			Questions []string `json:"questions"`
+			Success   bool     `json:"success"`
+		}{Questions: questions, Success: true}
+	}
+	return response, h.interviews.RecordAI(session.ID, request)
+}
+
+// containsInt reports whether ids contains id
+func containsInt(ids []int, id int) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"errors"
+	htmltemplate "html/template"
+	"log"
+	"net/http"
+	"strings"
+	texttemplate "text/template"
+
+	"web-ui/internal/models"
+	"web-ui/internal/services"
+	"web-ui/internal/utils"
+)
+
+// export answers an ended interview's report as Markdown (?format=markdown,
+// the default) or as a standalone HTML page laid out for printing
+// (?format=html). With ?download=1 the browser saves it as a file.
+func (h *InterviewHandler) export(w http.ResponseWriter, r *http.Request, session models.InterviewSession) {
+	export, err := h.interviews.Export(session)
+	if errors.Is(err, services.ErrInterviewNotEnded) {
+		http.Error(w, "The interview has not ended yet", http.StatusConflict)
+		return
+	}
+
+	var render func() error
+	var contentType, extension string
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "markdown", "md":
+		tmpl, err := texttemplate.New("").Funcs(markdownExportFuncs()).ParseFS(h.content, "templates/interview_export.md")
+		if err != nil {
+			log.Printf("Template error: %v", err)
+			http.Error(w, "Failed to parse template: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		render = func() error { return tmpl.ExecuteTemplate(w, "export", export) }
+		contentType, extension = "text/markdown; charset=utf-8", "md"
+	case "html":
+		tmpl, err := htmltemplate.New("").Funcs(utils.GetTemplateFuncs()).ParseFS(h.content, "templates/interview_export.html")
+		if err != nil {
+			log.Printf("Template error: %v", err)
+			http.Error(w, "Failed to parse template: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		render = func() error { return tmpl.ExecuteTemplate(w, "export", export) }
+		contentType, extension = "text/html; charset=utf-8", "html"
+	default:
+		http.Error(w, "Unknown format "+format+": use markdown or html", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	if r.URL.Query().Get("download") == "1" {
+		filename := "interview-" + session.StartedAt.Format("2006-01-02") + "-" + session.ID + "." + extension
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	}
+	if err := render(); err != nil {
+		log.Printf("Template execution error: %v", err)
+	}
+}
+
+// markdownExportFuncs are the functions the Markdown report uses besides the
+// usual template functions
+func markdownExportFuncs() texttemplate.FuncMap {
+	funcs := texttemplate.FuncMap(utils.GetTemplateFuncs())
+	// fence wraps code in a fence longer than any run of backticks inside it
+	funcs["fence"] = func(code, language string) string {
+		longest, run := 0, 0
+		for _, c := range code {
+			if c == '`' {
+				run++
+				longest = max(longest, run)
+			} else {
+				run = 0
+			}
+		}
+		fence := strings.Repeat("`", max(3, longest+1))
+		return fence + language + "\n" + strings.TrimRight(code, "\n") + "\n" + fence
+	}
+	// demote nests a Markdown document's headings under the report's own,
+	// leaving code blocks alone
+	funcs["demote"] = func(markdown string) string {
+		lines := strings.Split(strings.TrimSpace(markdown), "\n")
+		inCode := false
+		for i, line := range lines {
+			if strings.HasPrefix(line, "```") {
+				inCode = !inCode
+			} else if !inCode && strings.HasPrefix(line, "#") {
+				lines[i] = "###" + line
+			}
+		}
+		return strings.Join(lines, "\n")
+	}
+	// cell makes text safe for a table cell
+	funcs["cell"] = func(text string) string {
+		return strings.NewReplacer("|", `\|`, "\r", "", "\n", " ").Replace(text)
+	}
+	return funcs
+}
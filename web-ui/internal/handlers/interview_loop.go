@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"web-ui/internal/models"
+)
+
+// HandleLoops serves interview loops.
+//
+//	POST /api/interview-loops      → draw a loop from {"seed", "constraints"}; a seed of 0 or none picks one
+//	GET  /api/interview-loops/{id} → a loop, to share or start an interview from
+func (h *InterviewHandler) HandleLoops(w http.ResponseWriter, r *http.Request) {
+	username, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/interview-loops"), "/")
+	switch {
+	case id == "" && r.Method == "POST":
+		var request struct {
+			Seed        int64                           `json:"seed"`
+			Constraints models.InterviewLoopConstraints `json:"constraints"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, "Invalid request data: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		loop, err := h.loops.Create(username, request.Seed, request.Constraints)
+		if err != nil {
+			http.Error(w, "Invalid loop: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(loop)
+	case id != "" && !strings.Contains(id, "/") && r.Method == "GET":
+		loop, ok := h.loops.Loop(id)
+		if !ok {
+			http.Error(w, "Loop not found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(loop)
+	case id == "" || !strings.Contains(id, "/"):
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// loopChallengeIDs returns the challenges of a loop an interview can run on;
+// interviews run classic challenges only
+func loopChallengeIDs(loop models.InterviewLoop) ([]int, error) {
+	ids := make([]int, 0, len(loop.Items))
+	for _, item := range loop.Items {
+		id, err := strconv.Atoi(item.ChallengeID)
+		if item.Track != models.TrackClassic || err != nil {
+			return nil, fmt.Errorf("interviews run classic challenges only, and loop %s has %s", loop.ID, item.URL)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"web-ui/internal/models"
+	"web-ui/internal/services"
+)
+
+// HandleRubrics serves the interview scoring rubrics.
+//
+//	GET /api/rubrics              → every rubric, by title
+//	GET /api/rubrics?challenge=ID → the rubric that scores a challenge
+func (h *InterviewHandler) HandleRubrics(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireUser(w, r); !ok {
+		return
+	}
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var response interface{} = h.rubrics.Rubrics()
+	if challenge := r.URL.Query().Get("challenge"); challenge != "" {
+		id, err := strconv.Atoi(challenge)
+		if err != nil {
+			http.Error(w, "Invalid challenge ID", http.StatusBadRequest)
+			return
+		}
+		rubric, ok := h.rubrics.RubricFor(id)
+		if !ok {
+			http.Error(w, "No rubric scores challenge "+challenge, http.StatusNotFound)
+			return
+		}
+		response = rubric
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// scorecard scores a challenge of an ended interview against the rubric for
+// it, or asks the AI to suggest scores from the challenge's final code. Only
+// the leads of the candidate's teams score an interview.
+func (h *InterviewHandler) scorecard(w http.ResponseWriter, r *http.Request, username, id, rest string) {
+	challenge, action, _ := strings.Cut(rest, "/")
+	challengeID, err := strconv.Atoi(challenge)
+	if err != nil || (action != "" && action != "suggest") {
+		http.NotFound(w, r)
+		return
+	}
+	if (action == "" && r.Method != "PUT") || (action == "suggest" && r.Method != "POST") {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if action == "suggest" && !h.aiEnabled {
+		http.NotFound(w, r)
+		return
+	}
+
+	session, ok := h.watchedSession(w, r, username, id)
+	if !ok {
+		return
+	}
+	if !h.interviews.CanScore(username, session.Username) {
+		http.Error(w, "Only the leads of the candidate's teams can score their interviews", http.StatusForbidden)
+		return
+	}
+	if !containsInt(session.ChallengeIDs, challengeID) {
+		http.Error(w, "Challenge is not part of this interview", http.StatusNotFound)
+		return
+	}
+	if session.Report == nil {
+		http.Error(w, "The interview has not ended yet", http.StatusConflict)
+		return
+	}
+	rubric, ok := h.rubrics.RubricFor(challengeID)
+	if !ok {
+		http.Error(w, "No rubric scores challenge "+challenge+": add rubrics/default.json", http.StatusNotFound)
+		return
+	}
+
+	var scorecard models.InterviewScorecard
+	if action == "" {
+		var request struct {
+			Scores []models.RubricScore `json:"scores"`
+			Notes  string               `json:"notes"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, "Invalid request data", http.StatusBadRequest)
+			return
+		}
+		if _, err := services.WeightedRubricScore(rubric, request.Scores); err != nil {
+			http.Error(w, "Invalid scores: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		scorecard, err = h.interviews.Score(session.ID, challengeID, rubric, request.Scores, strings.TrimSpace(request.Notes), username, time.Now())
+	} else {
+		var code string
+		for _, cr := range session.Report.Challenges {
+			if cr.ChallengeID == challengeID {
+				code = cr.FinalCode
+			}
+		}
+		c, ok := h.challengeService.GetChallenge(challengeID)
+		if code == "" || !ok {
+			http.Error(w, "No code was saved for this challenge", http.StatusBadRequest)
+			return
+		}
+		suggestions, aiErr := h.aiService.SuggestRubricScores(code, c, rubric)
+		if aiErr != nil {
+			http.Error(w, "AI suggestions failed: "+aiErr.Error(), http.StatusInternalServerError)
+			return
+		}
+		scorecard, err = h.interviews.Suggest(session.ID, challengeID, rubric, suggestions, time.Now())
+	}
+	if err != nil {
+		log.Printf("interviews: %v", err)
+		http.Error(w, "Failed to save interview", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(scorecard)
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"embed"
+	"encoding/json"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"web-ui/internal/auth"
+	"web-ui/internal/models"
+	"web-ui/internal/services"
+	"web-ui/internal/utils"
+)
+
+// pairPingInterval keeps idle pairing connections open through proxies
+const pairPingInterval = 30 * time.Second
+
+// PairingHandler serves live pairing rooms: the pages, room creation and the
+// WebSocket each participant's editor talks to
+type PairingHandler struct {
+	content          embed.FS
+	pairing          *services.PairingService
+	challengeService *services.ChallengeService
+}
+
+func NewPairingHandler(content embed.FS, pairing *services.PairingService, challengeService *services.ChallengeService) *PairingHandler {
+	return &PairingHandler{
+		content:          content,
+		pairing:          pairing,
+		challengeService: challengeService,
+	}
+}
+
+// PairPage renders /pair, to open a room, and /pair/{id}, a room. The
+// interviewer link carries ?key=; without it you join as a candidate.
+func (h *PairingHandler) PairPage(w http.ResponseWriter, r *http.Request) {
+	username := auth.Username(r)
+	if username == "" {
+		http.Redirect(w, r, "/auth/login?next="+url.QueryEscape(r.URL.RequestURI()), http.StatusFound)
+		return
+	}
+
+	data := struct {
+		Challenges  []*models.Challenge
+		Username    string
+		Room        *models.PairRoom
+		Interviewer bool
+		Key         string
+	}{
+		Challenges: h.challengeService.ListChallenges(),
+		Username:   username,
+	}
+	if id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/pair"), "/"); id != "" {
+		room, ok := h.pairing.Room(id)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		data.Room = &room
+		data.Key = r.URL.Query().Get("key")
+		data.Interviewer = h.pairing.IsInterviewer(id, username, data.Key)
+	}
+
+	tmpl, err := template.New("").Funcs(utils.GetTemplateFuncs()).ParseFS(h.content, "templates/base.html", "templates/pair.html")
+	if err != nil {
+		log.Printf("Template error: %v", err)
+		http.Error(w, "Failed to parse template: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := tmpl.ExecuteTemplate(w, "base", data); err != nil {
+		log.Printf("Template execution error: %v", err)
+	}
+}
+
+// HandleRooms serves the pairing API.
+//
+//	POST /api/pair/rooms          → open a room on {"challengeId"}; answers its links
+//	GET  /api/pair/rooms/{id}/ws  → join a room over WebSocket; ?key= to join as an interviewer
+func (h *PairingHandler) HandleRooms(w http.ResponseWriter, r *http.Request) {
+	username, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/pair/rooms"), "/")
+	id, action, _ := strings.Cut(path, "/")
+	switch {
+	case path == "" && r.Method == "POST":
+		h.create(w, r, username)
+	case action == "ws" && r.Method == "GET":
+		h.serveSocket(w, r, username, id)
+	case path == "" || action == "ws":
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// create opens a room with the signed-in user as interviewer
+func (h *PairingHandler) create(w http.ResponseWriter, r *http.Request, username string) {
+	var request struct {
+		ChallengeID int `json:"challengeId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	room, err := h.pairing.Create(username, request.ChallengeID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(struct {
+		models.PairRoom
+		CandidateURL   string `json:"candidateUrl"`
+		InterviewerURL string `json:"interviewerUrl"`
+	}{
+		PairRoom:       room,
+		CandidateURL:   "/pair/" + room.ID,
+		InterviewerURL: "/pair/" + room.ID + "?key=" + room.InterviewerKey,
+	})
+}
+
+// serveSocket joins a room and relays messages between the WebSocket and the
+// room until either side goes away
+func (h *PairingHandler) serveSocket(w http.ResponseWriter, r *http.Request, username, id string) {
+	if _, ok := h.pairing.Room(id); !ok {
+		http.Error(w, "Room not found", http.StatusNotFound)
+		return
+	}
+	client, err := h.pairing.Join(id, username, r.URL.Query().Get("key"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	ws, err := utils.UpgradeWebSocket(w, r)
+	if err != nil {
+		log.Printf("pairing: %v", err)
+		h.pairing.Leave(client)
+		return
+	}
+
+	// The writer stops when the room closes Send; closing the socket then
+	// ends the reader
+	go func() {
+		defer ws.Close()
+		ticker := time.NewTicker(pairPingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case message, ok := <-client.Send:
+				if !ok || ws.WriteMessage(message) != nil {
+					return
+				}
+			case <-ticker.C:
+				if ws.Ping() != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		message, err := ws.ReadMessage()
+		if err != nil {
+			break
+		}
+		h.pairing.Receive(client, message)
+	}
+	h.pairing.Leave(client)
+	ws.Close()
+}
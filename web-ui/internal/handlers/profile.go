@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"embed"
+	"encoding/json"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+
+	"web-ui/internal/auth"
+	"web-ui/internal/models"
+	"web-ui/internal/services"
+	"web-ui/internal/utils"
+)
+
+// ProfileHandler serves user profile pages and their API
+type ProfileHandler struct {
+	content  embed.FS
+	profiles *services.ProfileService
+}
+
+func NewProfileHandler(content embed.FS, profiles *services.ProfileService) *ProfileHandler {
+	return &ProfileHandler{content: content, profiles: profiles}
+}
+
+// profile looks up the profile named by the last path segment after prefix,
+// answering 404 if there is none
+func (h *ProfileHandler) profile(w http.ResponseWriter, r *http.Request, prefix string) (*models.UserProfile, bool) {
+	username := strings.Trim(strings.TrimPrefix(r.URL.Path, prefix), "/")
+	if !auth.ValidLogin(username) {
+		http.NotFound(w, r)
+		return nil, false
+	}
+	profile, ok := h.profiles.Profile(username, auth.Username(r))
+	if !ok {
+		http.NotFound(w, r)
+		return nil, false
+	}
+	return profile, true
+}
+
+// UserPage renders /users/{username}
+func (h *ProfileHandler) UserPage(w http.ResponseWriter, r *http.Request) {
+	profile, ok := h.profile(w, r, "/users/")
+	if !ok {
+		return
+	}
+
+	tmpl, err := template.New("").Funcs(utils.GetTemplateFuncs()).ParseFS(h.content, "templates/base.html", "templates/user_profile.html")
+	if err != nil {
+		log.Printf("Template error: %v", err)
+		http.Error(w, "Failed to parse template: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data := struct {
+		Profile *models.UserProfile
+		IsOwner bool
+	}{
+		Profile: profile,
+		IsOwner: auth.Username(r) == profile.Username,
+	}
+
+	if err := tmpl.ExecuteTemplate(w, "base", data); err != nil {
+		log.Printf("Template execution error: %v", err)
+	}
+}
+
+// HandleUser serves the profile API.
+//
+//	GET   /api/users/{username}          → the profile, as the caller may see it
+//	GET   /api/users/{username}/activity → streaks and, unless private, the calendar
+//	PATCH /api/users/{username}          → {"public": false} makes your own profile private,
+//	                                       {"timezone": "Europe/Berlin"} sets the day boundary
+func (h *ProfileHandler) HandleUser(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		if username, ok := strings.CutSuffix(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/users/"), "/"), "/activity"); ok {
+			h.activity(w, r, username)
+			return
+		}
+	case "PATCH":
+		h.updateSettings(w, r)
+		return
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	profile, ok := h.profile(w, r, "/api/users/")
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(profile)
+}
+
+// activity serves a user's daily activity
+func (h *ProfileHandler) activity(w http.ResponseWriter, r *http.Request, username string) {
+	if !auth.ValidLogin(username) {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.profiles.Activity(username, auth.Username(r)))
+}
+
+// updateSettings changes the signed-in user's own profile settings
+func (h *ProfileHandler) updateSettings(w http.ResponseWriter, r *http.Request) {
+	username, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+	if strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/users/"), "/") != username {
+		http.Error(w, "You can only change your own profile", http.StatusForbidden)
+		return
+	}
+
+	var request struct {
+		Public   *bool   `json:"public"`
+		Timezone *string `json:"timezone"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || (request.Public == nil && request.Timezone == nil) {
+		http.Error(w, "Invalid request data: expected {\"public\": true|false} or {\"timezone\": \"Area/City\"}", http.StatusBadRequest)
+		return
+	}
+	if request.Timezone != nil && !services.ValidTimezone(*request.Timezone) {
+		http.Error(w, "Unknown time zone: use an IANA name such as \"Europe/Berlin\"", http.StatusBadRequest)
+		return
+	}
+
+	if request.Public != nil {
+		if err := h.profiles.SetPublic(username, *request.Public); err != nil {
+			log.Printf("profiles: %v", err)
+			http.Error(w, "Failed to save profile settings", http.StatusInternalServerError)
+			return
+		}
+	}
+	if request.Timezone != nil {
+		if err := h.profiles.SetTimezone(username, *request.Timezone); err != nil {
+			log.Printf("profiles: %v", err)
+			http.Error(w, "Failed to save profile settings", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	profile, _ := h.profiles.Profile(username, username)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(profile)
+}
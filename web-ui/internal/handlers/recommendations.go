@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"web-ui/internal/services"
+)
+
+// RecommendationHandler serves next-challenge recommendations
+type RecommendationHandler struct {
+	recommender *services.RecommendationService
+}
+
+func NewRecommendationHandler(recommender *services.RecommendationService) *RecommendationHandler {
+	return &RecommendationHandler{recommender: recommender}
+}
+
+// GetRecommendations answers GET /api/recommendations with the three
+// challenges the signed-in user should try next, each with its reason
+func (h *RecommendationHandler) GetRecommendations(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	username, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.recommender.Recommend(username))
+}
@@ -7,7 +7,10 @@ import (
 	"log"
 	"net/http"
 	"strings"
+	"time"
 
+	"web-ui/internal/auth"
+	"web-ui/internal/models"
 	"web-ui/internal/services"
 	"web-ui/internal/utils"
 )
@@ -17,10 +20,14 @@ import (
 type ReleaseHandler struct {
 	content        embed.FS
 	releaseService *services.ReleaseService
+	store          services.SubmissionStore
+	attempts       services.AttemptStore
+	achievements   *services.AchievementService
+	webhooks       *services.WebhookService
 }
 
-func NewReleaseHandler(content embed.FS, releaseService *services.ReleaseService) *ReleaseHandler {
-	return &ReleaseHandler{content: content, releaseService: releaseService}
+func NewReleaseHandler(content embed.FS, releaseService *services.ReleaseService, store services.SubmissionStore, attempts services.AttemptStore, achievements *services.AchievementService, webhooks *services.WebhookService) *ReleaseHandler {
+	return &ReleaseHandler{content: content, releaseService: releaseService, store: store, attempts: attempts, achievements: achievements, webhooks: webhooks}
 }
 
 // Route dispatches everything under /releases.
@@ -158,5 +165,34 @@ func (h *ReleaseHandler) RunChallenge(w http.ResponseWriter, r *http.Request) {
 	}
 
 	result := h.releaseService.RunChallenge(req.Code, challenge)
+
+	username := auth.Username(r)
+	attempt := services.NewAttempt(username, models.TrackRelease,
+		challenge.ReleaseVersion+"/"+challenge.FeatureSlug+"/"+challenge.Slug,
+		"run", req.Code, result.Passed, result.Output, result.ExecutionMs)
+	recordAttempt(h.attempts, h.webhooks, attempt)
+
+	if username != "" {
+		if err := h.store.AddReleaseSubmission(models.ReleaseSubmission{
+			Username:    username,
+			Release:     challenge.ReleaseVersion,
+			Feature:     challenge.FeatureSlug,
+			Challenge:   challenge.Slug,
+			Code:        req.Code,
+			SubmittedAt: time.Now(),
+			Passed:      result.Passed,
+			Output:      result.Output,
+			ExecutionMs: result.ExecutionMs,
+			Toolchain:   result.Toolchain,
+		}); err != nil {
+			log.Printf("releases: failed to store run: %v", err)
+		}
+		// Release runs are stored as submissions, so a passing one is announced as one
+		if result.Passed {
+			h.webhooks.Emit(models.EventSubmissionPassed, attemptEvent(attempt))
+		}
+		awardAchievements(h.achievements, h.webhooks, username)
+	}
+
 	json.NewEncoder(w).Encode(result)
 }
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"web-ui/internal/services"
+)
+
+// SearchHandler serves search across the classic, package and release tracks
+type SearchHandler struct {
+	search *services.SearchService
+}
+
+func NewSearchHandler(search *services.SearchService) *SearchHandler {
+	return &SearchHandler{search: search}
+}
+
+// maxSearchLimit caps the page size a client can ask for
+const maxSearchLimit = 100
+
+// Search answers GET /api/search.
+//
+//	q       free text over titles, tags, objectives, READMEs and learning.md
+//	track   classic, package or release
+//	difficulty, tag, package, go, kind
+//	        facet filters; repeat a parameter or separate values with commas
+//	        to accept any of them
+//	limit   page size (default 20, at most 100)
+//	offset  results to skip
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	params := r.URL.Query()
+	query := services.SearchQuery{
+		Text:    params.Get("q"),
+		Filters: make(map[string][]string),
+	}
+	for _, facet := range services.SearchFacets {
+		for _, value := range params[facet] {
+			for _, v := range strings.Split(value, ",") {
+				if v = strings.TrimSpace(v); v != "" {
+					query.Filters[facet] = append(query.Filters[facet], v)
+				}
+			}
+		}
+	}
+
+	var err error
+	if v := params.Get("limit"); v != "" {
+		if query.Limit, err = strconv.Atoi(v); err != nil || query.Limit < 1 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		query.Limit = min(query.Limit, maxSearchLimit)
+	}
+	if v := params.Get("offset"); v != "" {
+		if query.Offset, err = strconv.Atoi(v); err != nil || query.Offset < 0 {
+			http.Error(w, "Invalid offset", http.StatusBadRequest)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.search.Search(query))
+}
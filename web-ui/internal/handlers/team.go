@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"embed"
+	"encoding/json"
+	"html/template"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"web-ui/internal/auth"
+	"web-ui/internal/models"
+	"web-ui/internal/services"
+	"web-ui/internal/utils"
+)
+
+// TeamHandler serves team dashboards to the team's members and leads
+type TeamHandler struct {
+	content embed.FS
+	teams   *services.TeamService
+}
+
+func NewTeamHandler(content embed.FS, teams *services.TeamService) *TeamHandler {
+	return &TeamHandler{content: content, teams: teams}
+}
+
+// render executes a team template with base.html
+func (h *TeamHandler) render(w http.ResponseWriter, page string, data interface{}) {
+	tmpl, err := template.New("").Funcs(utils.GetTemplateFuncs()).ParseFS(h.content, "templates/base.html", "templates/"+page)
+	if err != nil {
+		log.Printf("Template error: %v", err)
+		http.Error(w, "Failed to parse template: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := tmpl.ExecuteTemplate(w, "base", data); err != nil {
+		log.Printf("Template execution error: %v", err)
+	}
+}
+
+// viewableTeam looks up the named team, answering the request if the caller
+// may not see it
+func (h *TeamHandler) viewableTeam(w http.ResponseWriter, r *http.Request, name string) (models.Team, bool) {
+	username, ok := requireUser(w, r)
+	if !ok {
+		return models.Team{}, false
+	}
+	team, ok := h.teams.Team(name)
+	if !ok {
+		http.NotFound(w, r)
+		return team, false
+	}
+	if !h.teams.CanView(team, username) {
+		http.Error(w, "Only the team's members and leads can see its dashboard", http.StatusForbidden)
+		return team, false
+	}
+	return team, true
+}
+
+// TeamsPage renders /teams, the teams the signed-in user belongs to or leads,
+// and /teams/{name}, one team's dashboard
+func (h *TeamHandler) TeamsPage(w http.ResponseWriter, r *http.Request) {
+	if auth.Username(r) == "" {
+		http.Redirect(w, r, "/auth/login?next="+url.QueryEscape(r.URL.Path), http.StatusFound)
+		return
+	}
+
+	name := strings.Trim(strings.TrimPrefix(r.URL.Path, "/teams"), "/")
+	if name == "" {
+		h.render(w, "teams.html", struct {
+			Teams []models.Team
+		}{
+			Teams: h.teams.TeamsFor(auth.Username(r)),
+		})
+		return
+	}
+
+	team, ok := h.viewableTeam(w, r, name)
+	if !ok {
+		return
+	}
+	h.render(w, "team.html", struct {
+		Dashboard *models.TeamDashboard
+	}{
+		Dashboard: h.teams.Dashboard(team, auth.Username(r)),
+	})
+}
+
+// HandleTeams serves the team dashboard API.
+//
+//	GET /api/teams                          → the teams you belong to or lead
+//	GET /api/teams/{name}                   → the team's dashboard
+//	GET /api/teams/{name}/members.csv       → one row per member
+//	GET /api/teams/{name}/challenges.csv    → one row per member and challenge worked on
+func (h *TeamHandler) HandleTeams(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/teams"), "/")
+	if path == "" {
+		username, ok := requireUser(w, r)
+		if !ok {
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(h.teams.TeamsFor(username))
+		return
+	}
+
+	name, export, _ := strings.Cut(path, "/")
+	team, ok := h.viewableTeam(w, r, name)
+	if !ok {
+		return
+	}
+	dashboard := h.teams.Dashboard(team, auth.Username(r))
+
+	var write func(io.Writer, *models.TeamDashboard) error
+	switch export {
+	case "":
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(dashboard)
+		return
+	case "members.csv":
+		write = services.WriteMembersCSV
+	case "challenges.csv":
+		write = services.WriteChallengesCSV
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+team.Name+"-"+export+`"`)
+	if err := write(w, dashboard); err != nil {
+		log.Printf("teams: %s export: %v", team.Name, err)
+	}
+}
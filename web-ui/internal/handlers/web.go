@@ -8,11 +8,11 @@ import (
 	"sort"
 	"strconv"
 	"strings"
-	"time"
 
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"web-ui/internal/auth"
 	"web-ui/internal/models"
 	"web-ui/internal/services"
 	"web-ui/internal/utils"
@@ -25,6 +25,8 @@ type WebHandler struct {
 	scoreboardService *services.ScoreboardService
 	userService       *services.UserService
 	packageService    *services.PackageService
+	leaderboards      *services.LeaderboardService
+	attempts          services.AttemptStore
 }
 
 // NewWebHandler creates a new web handler
@@ -34,6 +36,8 @@ func NewWebHandler(
 	scoreboardService *services.ScoreboardService,
 	userService *services.UserService,
 	packageService *services.PackageService,
+	leaderboards *services.LeaderboardService,
+	attempts services.AttemptStore,
 ) *WebHandler {
 	return &WebHandler{
 		content:           content,
@@ -41,6 +45,8 @@ func NewWebHandler(
 		scoreboardService: scoreboardService,
 		userService:       userService,
 		packageService:    packageService,
+		leaderboards:      leaderboards,
+		attempts:          attempts,
 	}
 }
 
@@ -58,11 +64,7 @@ func (h *WebHandler) HomePage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Convert map to slice for template
-	var challengeList []*models.Challenge
-	for _, challenge := range h.challengeService.GetChallenges() {
-		challengeList = append(challengeList, challenge)
-	}
+	challengeList := h.challengeService.ListChallenges()
 
 	// Get packages for the Package Mastery tab
 	packages := h.packageService.GetPackages()
@@ -86,8 +88,7 @@ func (h *WebHandler) HomePage(w http.ResponseWriter, r *http.Request) {
 		return packagesList[i].Stars > packagesList[j].Stars
 	})
 
-	// Get the username from cookie if available
-	username := h.getUsernameFromCookie(r)
+	username := auth.Username(r)
 
 	// Get user attempts if username is set
 	var userAttempt *models.UserAttemptedChallenges
@@ -153,24 +154,22 @@ func (h *WebHandler) ChallengePage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get username from cookie first
-	username := h.getUsernameFromCookie(r)
-
-	// If no username from cookie, try to get it from Git config
-	if username == "" {
-		gitInfo := utils.GetGitUsername()
-		if gitInfo.Username != "" {
-			username = gitInfo.Username
-			// Set the cookie for future requests
-			h.setUsernameCookie(w, username)
-		}
-	}
+	username := auth.Username(r)
 
 	existingSolution := ""
+	restoredAttempt := ""
 	hasAttempted := false
 
 	if username != "" {
 		existingSolution = h.userService.GetExistingSolution(username, id)
+		// ?attempt={id} restores an earlier attempt into the editor instead
+		if attemptID := r.URL.Query().Get("attempt"); attemptID != "" {
+			if attempt, ok := h.attempts.GetAttempt(attemptID); ok && attempt.Username == username &&
+				attempt.Track == models.TrackClassic && attempt.ChallengeID == strconv.Itoa(id) {
+				existingSolution = attempt.Code
+				restoredAttempt = attempt.ID
+			}
+		}
 		// Check if user has attempted this challenge
 		userAttempts := h.userService.GetUserAttempts(username, h.challengeService.GetChallenges())
 		hasAttempted = userAttempts.AttemptedIDs[id]
@@ -187,11 +186,13 @@ func (h *WebHandler) ChallengePage(w http.ResponseWriter, r *http.Request) {
 		Challenge        *models.Challenge
 		Username         string
 		ExistingSolution string
+		RestoredAttempt  string
 		HasAttempted     bool
 	}{
 		Challenge:        challenge,
 		Username:         username,
 		ExistingSolution: existingSolution,
+		RestoredAttempt:  restoredAttempt,
 		HasAttempted:     hasAttempted,
 	}
 
@@ -302,7 +303,7 @@ func (h *WebHandler) PackageScoreboardPage(w http.ResponseWriter, r *http.Reques
 	}
 
 	// Create leaderboard
-	leaderboard := h.createPackageLeaderboard(packageName, challenges)
+	leaderboard := h.leaderboards.PackageLeaderboard(packageName, challenges)
 
 	tmpl, err := template.New("").Funcs(utils.GetTemplateFuncs()).ParseFS(h.content, "templates/base.html", "templates/package_scoreboard.html")
 	if err != nil {
@@ -336,14 +337,9 @@ func (h *WebHandler) InterviewPage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Convert map to slice for template
-	var challengeList []*models.Challenge
-	for _, challenge := range h.challengeService.GetChallenges() {
-		challengeList = append(challengeList, challenge)
-	}
+	challengeList := h.challengeService.ListChallenges()
 
-	// Get username from cookie if available
-	username := h.getUsernameFromCookie(r)
+	username := auth.Username(r)
 
 	data := struct {
 		Challenges []*models.Challenge
@@ -360,29 +356,6 @@ func (h *WebHandler) InterviewPage(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// getUsernameFromCookie retrieves the username from cookie
-func (h *WebHandler) getUsernameFromCookie(r *http.Request) string {
-	cookie, err := r.Cookie("username")
-	if err != nil {
-		return ""
-	}
-	return cookie.Value
-}
-
-// setUsernameCookie sets the username cookie
-func (h *WebHandler) setUsernameCookie(w http.ResponseWriter, username string) {
-	// Cookie expires in 30 days
-	expiration := time.Now().Add(30 * 24 * time.Hour)
-	cookie := http.Cookie{
-		Name:     "username",
-		Value:    username,
-		Expires:  expiration,
-		Path:     "/",
-		HttpOnly: false, // Allow JavaScript to access it
-	}
-	http.SetCookie(w, &cookie)
-}
-
 // PackageDetailPage renders the package detail page
 func (h *WebHandler) PackageDetailPage(w http.ResponseWriter, r *http.Request) {
 	// Extract package name from URL: /packages/gin
@@ -433,8 +406,7 @@ func (h *WebHandler) PackageDetailPage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get the username from cookie if available
-	username := h.getUsernameFromCookie(r)
+	username := auth.Username(r)
 
 	// Check which package challenges the user has attempted
 	packageAttempts := make(map[string]bool)
@@ -464,8 +436,8 @@ func (h *WebHandler) PackageDetailPage(w http.ResponseWriter, r *http.Request) {
 		submissionCounts[challenge.ID] = h.countPackageChallengeSubmissions(packageName, challenge.ID)
 	}
 
-	// Create actual leaderboard using submission data
-	leaderboard := h.createPackageLeaderboard(packageName, challenges)
+	// Create leaderboard from the package's challenge scoreboards
+	leaderboard := h.leaderboards.PackageLeaderboard(packageName, challenges)
 
 	data := struct {
 		Package          *models.Package
@@ -529,18 +501,7 @@ func (h *WebHandler) PackageChallengePage(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	// Get username from cookie first
-	username := h.getUsernameFromCookie(r)
-
-	// If no username from cookie, try to get it from Git config
-	if username == "" {
-		gitInfo := utils.GetGitUsername()
-		if gitInfo.Username != "" {
-			username = gitInfo.Username
-			// Set the cookie for future requests
-			h.setUsernameCookie(w, username)
-		}
-	}
+	username := auth.Username(r)
 
 	// Check if user has attempted this challenge
 	hasAttempted := false
@@ -575,19 +536,7 @@ func (h *WebHandler) PackageChallengePage(w http.ResponseWriter, r *http.Request
 
 // hasUserAttemptedPackageChallenge checks if a user has attempted a package challenge
 func (h *WebHandler) hasUserAttemptedPackageChallenge(username, packageName, challengeID string) bool {
-	// Check if submission file exists in ../packages/{packageName}/{challengeID}/submissions/{username}/solution.go
-	submissionPath := filepath.Join("..", "packages", packageName, challengeID, "submissions", username, "solution.go")
-	if _, err := os.Stat(submissionPath); err == nil {
-		return true
-	}
-
-	// Try alternative path in case of different file naming
-	altSubmissionPath := filepath.Join("..", "packages", packageName, challengeID, "submissions", username, "solution-template.go")
-	if _, err := os.Stat(altSubmissionPath); err == nil {
-		return true
-	}
-
-	return false
+	return h.getUserPackageChallengeSolution(username, packageName, challengeID) != ""
 }
 
 // getUserPackageChallengeSolution retrieves a user's existing solution for a package challenge
@@ -596,26 +545,19 @@ func (h *WebHandler) getUserPackageChallengeSolution(username, packageName, chal
 		return ""
 	}
 
-	// Try solution.go first
-	submissionPath := filepath.Join("..", "packages", packageName, challengeID, "submissions", username, "solution.go")
-	content, err := ioutil.ReadFile(submissionPath)
-	if err == nil {
-		return string(content)
-	}
-
-	// Try solution-template.go as fallback
-	altSubmissionPath := filepath.Join("..", "packages", packageName, challengeID, "submissions", username, "solution-template.go")
-	content, err = ioutil.ReadFile(altSubmissionPath)
-	if err == nil {
-		return string(content)
+	// Solutions saved from the site are solution.go; older ones kept the template's name
+	submissionDir := filepath.Join(h.packageService.SubmissionsDir(packageName, challengeID), username)
+	for _, name := range []string{"solution.go", "solution-template.go"} {
+		if content, err := ioutil.ReadFile(filepath.Join(submissionDir, name)); err == nil {
+			return string(content)
+		}
 	}
-
 	return ""
 }
 
 // countPackageChallengeSubmissions counts the number of submissions for a package challenge
 func (h *WebHandler) countPackageChallengeSubmissions(packageName, challengeID string) int {
-	submissionsDir := filepath.Join("..", "packages", packageName, challengeID, "submissions")
+	submissionsDir := h.packageService.SubmissionsDir(packageName, challengeID)
 
 	// Check if submissions directory exists
 	if _, err := os.Stat(submissionsDir); os.IsNotExist(err) {
@@ -646,103 +588,3 @@ func (h *WebHandler) countPackageChallengeSubmissions(packageName, challengeID s
 
 	return count
 }
-
-// createPackageLeaderboard creates a leaderboard for package challenges similar to classic challenges
-func (h *WebHandler) createPackageLeaderboard(packageName string, challenges []*models.PackageChallenge) []models.PackageScoreboardEntry {
-	var leaderboard []models.PackageScoreboardEntry
-	userStats := make(map[string]*userPackageStats)
-	
-	// Load sponsors for package leaderboard (reuse from API handler)
-	// Create a temporary API handler instance to access LoadSponsors
-	tempHandler := &APIHandler{}
-	sponsors := tempHandler.LoadSponsors()
-
-	// Collect submission data for each challenge
-	for _, challenge := range challenges {
-		submissionsDir := filepath.Join("..", "packages", packageName, challenge.ID, "submissions")
-
-		// Check if submissions directory exists
-		if _, err := os.Stat(submissionsDir); os.IsNotExist(err) {
-			continue
-		}
-
-		// Read the submissions directory
-		entries, err := ioutil.ReadDir(submissionsDir)
-		if err != nil {
-			continue
-		}
-
-		for _, entry := range entries {
-			if entry.IsDir() {
-				username := entry.Name()
-				userDir := filepath.Join(submissionsDir, username)
-				solutionPath := filepath.Join(userDir, "solution.go")
-				altSolutionPath := filepath.Join(userDir, "solution-template.go")
-
-				// Check if user has a solution file (either solution.go or solution-template.go)
-				var modTime time.Time
-				if stat, err := os.Stat(solutionPath); err == nil {
-					modTime = stat.ModTime()
-				} else if stat, err := os.Stat(altSolutionPath); err == nil {
-					modTime = stat.ModTime()
-				} else {
-					continue
-				}
-
-				if userStats[username] == nil {
-					userStats[username] = &userPackageStats{
-						username:            username,
-						completedCount:      0,
-						lastSubmission:      modTime,
-						challengesCompleted: make(map[string]bool),
-					}
-				}
-
-				// Only count if not already counted
-				if !userStats[username].challengesCompleted[challenge.ID] {
-					userStats[username].completedCount++
-					userStats[username].challengesCompleted[challenge.ID] = true
-
-					// Update last submission time if this is more recent
-					if modTime.After(userStats[username].lastSubmission) {
-						userStats[username].lastSubmission = modTime
-					}
-				}
-			}
-		}
-	}
-
-	// Convert to leaderboard entries and sort
-	for username, stats := range userStats {
-		if stats.completedCount > 0 {
-			entry := models.PackageScoreboardEntry{
-				Username:    username,
-				PackageName: packageName,
-				ChallengeID: "", // Not specific to one challenge
-				SubmittedAt: stats.lastSubmission,
-				TestsPassed: stats.completedCount,
-				TestsTotal:  len(challenges),
-				IsSponsor:   sponsors[username],
-			}
-			leaderboard = append(leaderboard, entry)
-		}
-	}
-
-	// Sort by completed count (descending), then by submission time (ascending for earliest)
-	sort.Slice(leaderboard, func(i, j int) bool {
-		if leaderboard[i].TestsPassed != leaderboard[j].TestsPassed {
-			return leaderboard[i].TestsPassed > leaderboard[j].TestsPassed
-		}
-		return leaderboard[i].SubmittedAt.Before(leaderboard[j].SubmittedAt)
-	})
-
-	return leaderboard
-}
-
-// userPackageStats helper struct for collecting user statistics
-type userPackageStats struct {
-	username            string
-	completedCount      int
-	lastSubmission      time.Time
-	challengesCompleted map[string]bool
-}
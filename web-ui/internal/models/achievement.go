@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// AchievementRule declares one achievement and what earns it. Which of the
+// other fields apply depends on Kind.
+type AchievementRule struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Icon        string `json:"icon"`
+	Kind        string `json:"kind"`
+	Track       string `json:"track,omitempty"`   // solve_count, solve_tagged: limit to one track; empty counts all
+	Package     string `json:"package,omitempty"` // complete_package
+	Tag         string `json:"tag,omitempty"`     // solve_tagged
+	Count       int    `json:"count,omitempty"`   // solve_count, solve_tagged: challenges; streak: days
+}
+
+// Achievement rule kinds
+const (
+	RuleSolveCount        = "solve_count"         // solve Count challenges
+	RuleSolveTagged       = "solve_tagged"        // solve Count challenges tagged Tag
+	RuleCompletePackage   = "complete_package"    // solve every challenge of Package
+	RuleFirstReleaseSolve = "first_release_solve" // be the first to pass a release challenge
+	RuleStreak            = "streak"              // be active Count days in a row
+)
+
+// AwardedAchievement records that a user earned an achievement. Awards are
+// permanent, even if the rule later changes.
+type AwardedAchievement struct {
+	Username    string    `json:"username"`
+	Achievement string    `json:"achievement"` // AchievementRule.ID
+	AwardedAt   time.Time `json:"awardedAt"`
+}
+
+// EarnedAchievement is an award with its rule's description, for display
+type EarnedAchievement struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Icon        string    `json:"icon"`
+	Package     string    `json:"package,omitempty"`
+	AwardedAt   time.Time `json:"awardedAt"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// UserActivity is a user's daily activity and streaks, in their time zone
+type UserActivity struct {
+	Username      string            `json:"username"`
+	Timezone      string            `json:"timezone"`
+	TimezoneSaved bool              `json:"timezoneSaved"` // false when Timezone is the site default
+	Today         string            `json:"today"`         // YYYY-MM-DD in Timezone
+	CurrentStreak int               `json:"currentStreak"`
+	LongestStreak int               `json:"longestStreak"`
+	ActiveDays    int               `json:"activeDays"`    // over all time
+	StreakFreezes int               `json:"streakFreezes"` // missed days a week a streak survives
+	Calendar      *ActivityCalendar `json:"calendar,omitempty"`
+	Private       bool              `json:"private,omitempty"` // the profile is private: nothing but the username is filled in
+}
+
+// ActivityCalendar is a contributions-style calendar: whole weeks, Sunday
+// first, ending with the current week
+type ActivityCalendar struct {
+	Start string          `json:"start"` // YYYY-MM-DD of the first Sunday
+	End   string          `json:"end"`   // today
+	Max   int             `json:"max"`   // busiest day's total
+	Weeks [][]ActivityDay `json:"weeks"` // the current week stops at today
+}
+
+// ActivityDay is one day's runs, passes and submits across all tracks
+type ActivityDay struct {
+	Date    string `json:"date"` // YYYY-MM-DD
+	Runs    int    `json:"runs"`
+	Submits int    `json:"submits"`
+	Passes  int    `json:"passes"` // runs and submits whose tests passed
+	Total   int    `json:"total"`  // runs + submits
+	Level   int    `json:"level"`  // 0-4, relative to the busiest day
+}
+
+// ActivitySettings are a user's activity preferences. The latest record for a
+// user wins.
+type ActivitySettings struct {
+	Username  string    `json:"username"`
+	Timezone  string    `json:"timezone"` // IANA name
+	UpdatedAt time.Time `json:"updatedAt"`
+}
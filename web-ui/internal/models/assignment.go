@@ -0,0 +1,83 @@
+package models
+
+import "time"
+
+// Assignment is a set of challenges a lead asks users or whole teams to pass
+// by a due date
+type Assignment struct {
+	ID         string         `json:"id"`
+	Title      string         `json:"title"`
+	Notes      string         `json:"notes,omitempty"`
+	Challenges []ChallengeRef `json:"challenges"`
+	Users      []string       `json:"users,omitempty"`
+	Teams      []string       `json:"teams,omitempty"` // every current member is an assignee
+	StartsAt   time.Time      `json:"startsAt"`
+	DueAt      time.Time      `json:"dueAt"`
+	CreatedBy  string         `json:"createdBy"`
+	CreatedAt  time.Time      `json:"createdAt"`
+}
+
+// ChallengeRef names a challenge on any track, as in Attempt
+type ChallengeRef struct {
+	Track string `json:"track"`
+	ID    string `json:"id"`
+}
+
+// Assignee statuses
+const (
+	AssignmentUpcoming  = "upcoming"  // not started yet
+	AssignmentOpen      = "open"      // started and not yet due
+	AssignmentCompleted = "completed" // every challenge passed by the due date
+	AssignmentLate      = "late"      // every challenge passed, some after the due date
+	AssignmentOverdue   = "overdue"   // due, and not every challenge passed
+)
+
+// AssigneeProgress is one assignee's standing on an assignment
+type AssigneeProgress struct {
+	Username    string                    `json:"username"`
+	Status      string                    `json:"status"`
+	OnTime      int                       `json:"onTime"` // challenges passed by the due date
+	Passed      int                       `json:"passed"` // challenges passed at all
+	Total       int                       `json:"total"`
+	CompletedAt *time.Time                `json:"completedAt,omitempty"` // when the last challenge was first passed
+	Challenges  []AssignedChallengeStatus `json:"challenges"`
+}
+
+// AssignedChallengeStatus is an assignee's attempts at one assigned challenge
+type AssignedChallengeStatus struct {
+	Track       string     `json:"track"`
+	ID          string     `json:"id"`
+	Title       string     `json:"title"`
+	URL         string     `json:"url"`
+	Attempts    int        `json:"attempts"`
+	TestsPassed int        `json:"testsPassed"` // in the best attempt
+	TestsTotal  int        `json:"testsTotal"`
+	PassedAt    *time.Time `json:"passedAt,omitempty"` // first passing attempt
+	OnTime      bool       `json:"onTime"`
+}
+
+// AssignmentReport is an assignment with every assignee's progress
+type AssignmentReport struct {
+	Assignment Assignment         `json:"assignment"`
+	Assignees  []AssigneeProgress `json:"assignees"`
+	Counts     map[string]int     `json:"counts"` // assignees per status
+}
+
+// AssignmentInboxItem is an assignment as its assignee sees it
+type AssignmentInboxItem struct {
+	Assignment Assignment       `json:"assignment"`
+	Progress   AssigneeProgress `json:"progress"`
+}
+
+// AssignmentReportRow is one assignee of one assignment in an overdue or
+// completed report
+type AssignmentReportRow struct {
+	AssignmentID string     `json:"assignmentId"`
+	Title        string     `json:"title"`
+	Username     string     `json:"username"`
+	Status       string     `json:"status"`
+	DueAt        time.Time  `json:"dueAt"`
+	CompletedAt  *time.Time `json:"completedAt,omitempty"`
+	Passed       int        `json:"passed"`
+	Total        int        `json:"total"`
+}
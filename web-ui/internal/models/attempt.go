@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+)
+
+// Attempt is one run or submit of a solution. Every attempt is kept so users
+// can look back at how their code evolved and restore an earlier version.
+type Attempt struct {
+	ID          string       `json:"id"`
+	Username    string       `json:"username"`
+	Track       string       `json:"track"`       // classic | package | release
+	ChallengeID string       `json:"challengeId"` // "12", "gin/challenge-1-basic-routing" or "1.26/new-expr/challenge-1"
+	Action      string       `json:"action"`      // run | submit
+	Code        string       `json:"code,omitempty"`
+	Passed      bool         `json:"passed"`
+	TestsPassed int          `json:"testsPassed"`
+	TestsTotal  int          `json:"testsTotal"`
+	Tests       []TestResult `json:"tests"`
+	ExecutionMs int64        `json:"executionMs"`
+	CreatedAt   time.Time    `json:"createdAt"`
+}
+
+// TestResult is the outcome of a single test (or subtest) in a `go test -v` run
+type TestResult struct {
+	Name      string  `json:"name"`
+	Passed    bool    `json:"passed"`
+	Skipped   bool    `json:"skipped,omitempty"`
+	ElapsedMs float64 `json:"elapsedMs"`
+}
+
+// Attempt tracks
+const (
+	TrackClassic = "classic"
+	TrackPackage = "package"
+	TrackRelease = "release"
+)
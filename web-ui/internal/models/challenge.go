@@ -4,7 +4,9 @@ import (
 	"time"
 )
 
-// Challenge represents a coding challenge
+// Challenge represents a coding challenge. The metadata fields come from the
+// challenge's optional metadata.json, which uses the same format as package
+// challenges (see ChallengeMetadata).
 type Challenge struct {
 	ID                int    `json:"id"`
 	Title             string `json:"title"`
@@ -14,6 +16,17 @@ type Challenge struct {
 	TestFile          string `json:"testFile"`
 	LearningMaterials string `json:"learningMaterials"`
 	Hints             string `json:"hints"`
+
+	ShortDescription    string   `json:"shortDescription,omitempty"`
+	EstimatedTime       string   `json:"estimatedTime,omitempty"`
+	LearningObjectives  []string `json:"learningObjectives"`
+	Prerequisites       []string `json:"prerequisites"`
+	Tags                []string `json:"tags"`
+	RealWorldConnection string   `json:"realWorldConnection,omitempty"`
+	Requirements        []string `json:"requirements,omitempty"`
+	BonusPoints         []string `json:"bonusPoints,omitempty"`
+	Icon                string   `json:"icon,omitempty"`
+	Order               int      `json:"order"` // position in the challenge list; defaults to the ID
 }
 
 // Submission represents a user's submitted solution
@@ -34,6 +47,17 @@ type ScoreboardEntry struct {
 	SubmittedAt time.Time `json:"submittedAt"`
 }
 
+// LeaderboardUser represents a user in the main (classic challenges) leaderboard
+type LeaderboardUser struct {
+	Username            string       `json:"username"`
+	CompletedCount      int          `json:"completedCount"`
+	CompletionRate      float64      `json:"completionRate"`
+	CompletedChallenges map[int]bool `json:"completedChallenges"`
+	Achievement         string       `json:"achievement"`
+	Rank                int          `json:"rank"`
+	IsSponsor           bool         `json:"isSponsor"`
+}
+
 // UserAttemptedChallenges tracks attempted challenges by username
 type UserAttemptedChallenges struct {
 	Username     string       `json:"username"`
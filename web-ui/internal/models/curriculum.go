@@ -0,0 +1,62 @@
+package models
+
+// Curriculum sequences challenges from any track into modules. Curricula are
+// read from curricula/<name>.json in the repository.
+type Curriculum struct {
+	Name        string             `json:"name"` // the file name, without .json
+	Title       string             `json:"title"`
+	Description string             `json:"description"`
+	Modules     []CurriculumModule `json:"modules"`
+}
+
+// CurriculumModule is one step of a curriculum
+type CurriculumModule struct {
+	Title       string            `json:"title"`
+	Description string            `json:"description,omitempty"`
+	Entries     []CurriculumEntry `json:"entries"`
+}
+
+// CurriculumEntry is one challenge in a module, identified as in Attempt
+type CurriculumEntry struct {
+	Track    string `json:"track"` // classic, package or release
+	ID       string `json:"id"`    // "12", "gin/challenge-1-basic-routing" or "1.26/new-expr/challenge-1-..."
+	Optional bool   `json:"optional,omitempty"`
+	Notes    string `json:"notes,omitempty"`
+}
+
+// CurriculumProgress is a curriculum with one user's progress through it
+type CurriculumProgress struct {
+	Name           string           `json:"name"`
+	Title          string           `json:"title"`
+	Description    string           `json:"description"`
+	Modules        []ModuleProgress `json:"modules"`
+	Required       int              `json:"required"`
+	RequiredSolved int              `json:"requiredSolved"`
+	Optional       int              `json:"optional"`
+	OptionalSolved int              `json:"optionalSolved"`
+	Percent        int              `json:"percent"` // of required entries solved
+	Complete       bool             `json:"complete"`
+	Next           *EntryProgress   `json:"next,omitempty"` // the first unsolved required entry
+}
+
+// ModuleProgress is one module of a CurriculumProgress
+type ModuleProgress struct {
+	Title          string          `json:"title"`
+	Description    string          `json:"description,omitempty"`
+	Entries        []EntryProgress `json:"entries"`
+	Required       int             `json:"required"`
+	RequiredSolved int             `json:"requiredSolved"`
+	Complete       bool            `json:"complete"`
+}
+
+// EntryProgress is one curriculum entry, resolved against the content, with
+// the user's status on it
+type EntryProgress struct {
+	CurriculumEntry
+	Title      string `json:"title"`
+	Difficulty string `json:"difficulty"`
+	URL        string `json:"url"`
+	Missing    bool   `json:"missing,omitempty"` // the challenge no longer exists
+	Solved     bool   `json:"solved"`
+	Attempted  bool   `json:"attempted"`
+}
@@ -0,0 +1,195 @@
+package models
+
+import "time"
+
+// Interview session statuses
+const (
+	InterviewActive   = "active"
+	InterviewFinished = "finished"  // ended by the candidate
+	InterviewTimedOut = "timed_out" // ended by the time limit
+)
+
+// Interview AI request kinds
+const (
+	InterviewHint      = "hint"
+	InterviewReview    = "review"
+	InterviewQuestions = "questions"
+)
+
+// InterviewSession is a timed mock interview on a set of classic challenges.
+// Everything the candidate does during it is recorded on the server.
+type InterviewSession struct {
+	ID           string               `json:"id"`
+	Username     string               `json:"username"`
+	ChallengeIDs []int                `json:"challengeIds"`
+	Random       bool                 `json:"random,omitempty"` // the challenges were picked at random
+	TimeLimit    int                  `json:"timeLimitMinutes"`
+	StartedAt    time.Time            `json:"startedAt"`
+	EndsAt       time.Time            `json:"endsAt"`
+	EndedAt      *time.Time           `json:"endedAt,omitempty"`
+	Status       string               `json:"status"`
+	Snapshots    []InterviewSnapshot  `json:"snapshots"`
+	Runs         []InterviewRun       `json:"runs"`
+	AIRequests   []InterviewAIRequest `json:"aiRequests"`
+	Report       *InterviewReport     `json:"report,omitempty"`     // once ended
+	Scorecards   []InterviewScorecard `json:"scorecards,omitempty"` // rubric scoring, once ended
+}
+
+// InterviewSnapshot is the candidate's code for one challenge at a moment
+type InterviewSnapshot struct {
+	ChallengeID int       `json:"challengeId"`
+	Code        string    `json:"code"`
+	At          time.Time `json:"at"`
+}
+
+// InterviewRun is one test run during an interview
+type InterviewRun struct {
+	ChallengeID int       `json:"challengeId"`
+	Passed      bool      `json:"passed"`
+	TestsPassed int       `json:"testsPassed"`
+	TestsTotal  int       `json:"testsTotal"`
+	ExecutionMs int64     `json:"executionMs"`
+	Output      string    `json:"output"`
+	At          time.Time `json:"at"`
+}
+
+// InterviewAIRequest is a hint, review or set of follow-up questions the
+// candidate asked for, with what the AI answered
+type InterviewAIRequest struct {
+	ChallengeID int       `json:"challengeId"`
+	Kind        string    `json:"kind"`
+	Level       int       `json:"level,omitempty"` // hints only
+	Response    string    `json:"response"`
+	At          time.Time `json:"at"`
+}
+
+// InterviewReport scores an ended interview
+type InterviewReport struct {
+	Score          int                        `json:"score"` // percent of challenges solved, less the hint penalty
+	Solved         int                        `json:"solved"`
+	Total          int                        `json:"total"`
+	TestsPassed    int                        `json:"testsPassed"` // in the best run of each challenge
+	TestsTotal     int                        `json:"testsTotal"`
+	Runs           int                        `json:"runs"`
+	Hints          int                        `json:"hints"`
+	HintPenalty    int                        `json:"hintPenalty"`
+	ElapsedSeconds int                        `json:"elapsedSeconds"`
+	RubricScore    *int                       `json:"rubricScore,omitempty"` // mean weighted rubric score of the scored challenges
+	Challenges     []InterviewChallengeReport `json:"challenges"`
+}
+
+// InterviewChallengeReport is how the candidate did on one challenge
+type InterviewChallengeReport struct {
+	ChallengeID        int    `json:"challengeId"`
+	Title              string `json:"title"`
+	Difficulty         string `json:"difficulty"`
+	Solved             bool   `json:"solved"`
+	SolvedAfterSeconds int    `json:"solvedAfterSeconds,omitempty"` // from the start of the interview
+	TestsPassed        int    `json:"testsPassed"`                  // in the best run
+	TestsTotal         int    `json:"testsTotal"`
+	Runs               int    `json:"runs"`
+	Hints              int    `json:"hints"`
+	RubricScore        *int   `json:"rubricScore,omitempty"` // weighted, once scored against a rubric
+	FinalCode          string `json:"finalCode,omitempty"`   // the last snapshot
+}
+
+// InterviewSummary is a session without its recordings, for listings
+type InterviewSummary struct {
+	ID           string    `json:"id"`
+	ChallengeIDs []int     `json:"challengeIds"`
+	TimeLimit    int       `json:"timeLimitMinutes"`
+	StartedAt    time.Time `json:"startedAt"`
+	EndsAt       time.Time `json:"endsAt"`
+	Status       string    `json:"status"`
+	Score        *int      `json:"score,omitempty"`
+	Solved       int       `json:"solved"`
+}
+
+// Interview replay event kinds, besides the AI request kinds
+const (
+	InterviewEdit    = "edit"
+	InterviewTestRun = "run"
+)
+
+// Test run transitions in a replay
+const (
+	InterviewNowPassing = "passing" // the tests pass where the last run failed
+	InterviewNowFailing = "failing" // the tests fail where the last run passed
+)
+
+// InterviewReplay is an interview's timeline, for watching how the
+// candidate's solutions evolved
+type InterviewReplay struct {
+	ID           string                 `json:"id"`
+	Username     string                 `json:"username"`
+	ChallengeIDs []int                  `json:"challengeIds"`
+	StartedAt    time.Time              `json:"startedAt"`
+	EndsAt       time.Time              `json:"endsAt"`
+	EndedAt      *time.Time             `json:"endedAt,omitempty"`
+	Events       []InterviewReplayEvent `json:"events"` // oldest first
+}
+
+// InterviewReplayEvent is an edit, test run or AI request in a replay
+type InterviewReplayEvent struct {
+	At            time.Time `json:"at"`
+	OffsetSeconds int       `json:"offsetSeconds"` // from the start of the interview
+	ChallengeID   int       `json:"challengeId"`
+	Kind          string    `json:"kind"`
+	Diff          string    `json:"diff,omitempty"` // edits: unified diff from the challenge's previous code
+	Passed        bool      `json:"passed,omitempty"`
+	TestsPassed   int       `json:"testsPassed,omitempty"`
+	TestsTotal    int       `json:"testsTotal,omitempty"`
+	Transition    string    `json:"transition,omitempty"` // runs: InterviewNowPassing or InterviewNowFailing
+	Level         int       `json:"level,omitempty"`      // hints
+}
+
+// InterviewCodeAt is the candidate's code for every challenge at a moment of
+// an interview
+type InterviewCodeAt struct {
+	At            time.Time       `json:"at"`
+	OffsetSeconds int             `json:"offsetSeconds"`
+	Challenges    []InterviewCode `json:"challenges"`
+}
+
+// InterviewCode is the code of one challenge as last saved
+type InterviewCode struct {
+	ChallengeID int        `json:"challengeId"`
+	Code        string     `json:"code"`              // the challenge's template until first saved
+	SavedAt     *time.Time `json:"savedAt,omitempty"` // unset until first saved
+	Passing     bool       `json:"passing"`           // the last run so far passed
+}
+
+// InterviewLoopConstraints are what an interview loop's challenges are drawn
+// under. Constraints left empty do not narrow the draw, except that no tracks
+// means the classic track, which interviews run.
+type InterviewLoopConstraints struct {
+	Count         int            `json:"count,omitempty"`           // how many challenges, when there is no difficulty mix
+	DifficultyMix map[string]int `json:"difficultyMix,omitempty"`   // how many of each difficulty, such as {"Beginner": 1, "Intermediate": 2}
+	Tags          []string       `json:"tags,omitempty"`            // every challenge has at least one of these
+	Tracks        []string       `json:"tracks,omitempty"`          // TrackClassic, TrackPackage, TrackRelease; only TrackClassic if empty
+	ExcludeSolved string         `json:"excludeSolvedBy,omitempty"` // a candidate whose solved challenges are left out
+	BudgetMinutes int            `json:"budgetMinutes,omitempty"`   // the challenges' estimated times fit in this
+}
+
+// InterviewLoop is a set of challenges drawn at random under constraints. The
+// same seed and constraints draw the same loop from the same content.
+type InterviewLoop struct {
+	ID           string                   `json:"id"`
+	Seed         int64                    `json:"seed"`
+	Constraints  InterviewLoopConstraints `json:"constraints"`
+	Items        []InterviewLoopItem      `json:"items"` // easiest first
+	TotalMinutes int                      `json:"totalMinutes"`
+	CreatedBy    string                   `json:"createdBy"`
+	CreatedAt    time.Time                `json:"createdAt"`
+}
+
+// InterviewLoopItem is one challenge of a loop, identified as in Attempt
+type InterviewLoopItem struct {
+	Track            string   `json:"track"`
+	ChallengeID      string   `json:"challengeId"`
+	Title            string   `json:"title"`
+	Difficulty       string   `json:"difficulty"`
+	Tags             []string `json:"tags"`
+	EstimatedMinutes int      `json:"estimatedMinutes"`
+	URL              string   `json:"url"`
+}
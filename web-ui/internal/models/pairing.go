@@ -0,0 +1,84 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Pairing roles. Interviewers share a notes pane that candidates never see.
+const (
+	PairInterviewer = "interviewer"
+	PairCandidate   = "candidate"
+)
+
+// PairRoom is a live pairing session on one challenge. Anyone signed in with
+// its ID joins as a candidate; the interviewer link adds the key.
+type PairRoom struct {
+	ID             string    `json:"id"`
+	ChallengeID    int       `json:"challengeId"`
+	CreatedBy      string    `json:"createdBy"`
+	CreatedAt      time.Time `json:"createdAt"`
+	InterviewerKey string    `json:"interviewerKey,omitempty"` // only for interviewers
+}
+
+// PairParticipant is someone connected to a room
+type PairParticipant struct {
+	ID       string      `json:"id"` // one per connection, so a user may join twice
+	Username string      `json:"username"`
+	Role     string      `json:"role"`
+	Cursor   *PairCursor `json:"cursor,omitempty"`
+}
+
+// PairCursor is a participant's caret and selection, as offsets into the
+// shared text in UTF-16 code units
+type PairCursor struct {
+	Index        int `json:"index"`
+	SelectionEnd int `json:"selectionEnd"`
+}
+
+// PairChatMessage is a line of a room's chat
+type PairChatMessage struct {
+	From string    `json:"from"`
+	Text string    `json:"text"`
+	At   time.Time `json:"at"`
+}
+
+// PairRun is the result of running the shared code
+type PairRun struct {
+	From        string    `json:"from"`
+	Passed      bool      `json:"passed"`
+	Output      string    `json:"output"`
+	ExecutionMs int64     `json:"executionMs"`
+	At          time.Time `json:"at"`
+}
+
+// PairMessage is a message on a room's WebSocket, either way. Which fields are
+// set depends on the type:
+//
+//	welcome      → you, role, room, revision, code, participants, chat, run; notes for interviewers
+//	op           → revision, op; from, when sent to the others
+//	ack          → revision: the sender's op was applied
+//	challenge    → challengeId: an interviewer switched challenge, after the op that swaps in its template
+//	cursor       → cursor; from, when sent to the others
+//	presence     → participants
+//	chat         → chat
+//	run, running → run or from
+//	notes        → notes; interviewers only
+//	error        → error
+type PairMessage struct {
+	Type         string            `json:"type"`
+	From         string            `json:"from,omitempty"` // a participant ID
+	You          *PairParticipant  `json:"you,omitempty"`
+	Room         *PairRoom         `json:"room,omitempty"`
+	Revision     int               `json:"revision,omitempty"`
+	Op           json.RawMessage   `json:"op,omitempty"`
+	Code         *string           `json:"code,omitempty"`
+	ChallengeID  int               `json:"challengeId,omitempty"`
+	Cursor       *PairCursor       `json:"cursor,omitempty"`
+	Participants []PairParticipant `json:"participants,omitempty"`
+	Chat         []PairChatMessage `json:"chat,omitempty"`
+	Text         string            `json:"text,omitempty"` // a chat line sent by a client
+	Run          *PairRun          `json:"run,omitempty"`
+	Notes        *string           `json:"notes,omitempty"`
+	Error        string            `json:"error,omitempty"`
+}
@@ -0,0 +1,86 @@
+package models
+
+import "time"
+
+// UserProfile is what the site knows about one user across the three tracks
+type UserProfile struct {
+	Username        string                `json:"username"`
+	Public          bool                  `json:"public"`
+	Limited         bool                  `json:"limited"` // private sections were left out for this viewer
+	Sponsor         bool                  `json:"sponsor"`
+	Rank            int                   `json:"rank"` // main leaderboard; 0 if unranked
+	Achievement     string                `json:"achievement"`
+	AchievementIcon string                `json:"achievementIcon"`
+	Classic         ClassicProgress       `json:"classic"`
+	Packages        []PackagePathProgress `json:"packages"`
+	Releases        []ReleaseProgress     `json:"releases"`
+	Badges          *ProfileBadges        `json:"badges,omitempty"`
+	Achievements    []EarnedAchievement   `json:"achievements"` // newest first
+	Activity        *UserActivity         `json:"activity"`     // streaks; the calendar only when not Limited
+	RecentActivity  []ProfileActivity     `json:"recentActivity"`
+}
+
+// ClassicProgress is a user's progress through the classic challenges
+type ClassicProgress struct {
+	Solved     int                        `json:"solved"`
+	Total      int                        `json:"total"`
+	Challenges []ClassicChallengeProgress `json:"challenges"` // completed or attempted, in challenge order
+}
+
+// ClassicChallengeProgress is one classic challenge a user has worked on
+type ClassicChallengeProgress struct {
+	ID         int    `json:"id"`
+	Title      string `json:"title"`
+	Difficulty string `json:"difficulty"`
+	Completed  bool   `json:"completed"`
+	Score      int    `json:"score"` // 0-100
+}
+
+// PackagePathProgress is a user's progress through one package's learning path
+type PackagePathProgress struct {
+	Name        string           `json:"name"`
+	DisplayName string           `json:"displayName"`
+	Solved      int              `json:"solved"`
+	Total       int              `json:"total"`
+	Completed   []string         `json:"completed"` // challenge IDs
+	Progress    *PackageProgress `json:"progress,omitempty"`
+}
+
+// ReleaseProgress summarises a user's runs of one release challenge
+type ReleaseProgress struct {
+	Release   string    `json:"release"`
+	Feature   string    `json:"feature"`
+	Challenge string    `json:"challenge"`
+	Title     string    `json:"title"`
+	URL       string    `json:"url"`
+	Runs      int       `json:"runs"`
+	Passed    bool      `json:"passed"` // any run passed
+	LastRunAt time.Time `json:"lastRunAt"`
+}
+
+// ProfileBadges are the URLs of a user's profile badges
+type ProfileBadges struct {
+	Card    string `json:"card"`
+	Compact string `json:"compact"`
+	Shields string `json:"shields"`
+}
+
+// ProfileActivity is one recent run or submit, without its code
+type ProfileActivity struct {
+	Track       string    `json:"track"`
+	ChallengeID string    `json:"challengeId"`
+	URL         string    `json:"url"`
+	Action      string    `json:"action"`
+	Passed      bool      `json:"passed"`
+	TestsPassed int       `json:"testsPassed"`
+	TestsTotal  int       `json:"testsTotal"`
+	At          time.Time `json:"at"`
+}
+
+// ProfileSettings are a user's profile preferences. The latest record for a
+// user wins.
+type ProfileSettings struct {
+	Username  string    `json:"username"`
+	Public    bool      `json:"public"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
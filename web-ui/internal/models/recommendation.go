@@ -0,0 +1,23 @@
+package models
+
+// Recommendation is a challenge suggested to a user as their next one, and why
+type Recommendation struct {
+	Track       string   `json:"track"`       // classic, package or release
+	ChallengeID string   `json:"challengeId"` // as in Attempt
+	Title       string   `json:"title"`
+	Difficulty  string   `json:"difficulty"`
+	Tags        []string `json:"tags"`
+	URL         string   `json:"url"`
+	Reason      string   `json:"reason"`
+	Kind        string   `json:"kind"` // which signal the reason comes from
+}
+
+// Recommendation kinds, strongest first
+const (
+	RecommendResume       = "resume"       // a challenge the user nearly passed
+	RecommendStepping     = "stepping"     // an easier challenge on what the user is stuck on
+	RecommendPrerequisite = "prerequisite" // its prerequisites are challenges the user solved
+	RecommendTags         = "tags"         // practises tags the user has solved challenges on
+	RecommendLevel        = "level"        // at the difficulty the user is ready for
+	RecommendStart        = "start"        // a first challenge for a newcomer
+)
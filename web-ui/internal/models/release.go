@@ -1,6 +1,9 @@
 package models
 
-import "html/template"
+import (
+	"html/template"
+	"time"
+)
 
 // Release is one Go release (e.g. Go 1.27) and the features we teach from it.
 // It is loaded from releases/<version>/release.json.
@@ -72,7 +75,6 @@ type ReleaseFeature struct {
 	HasProposal          bool                `json:"has_proposal"`
 	DiagramSVG           template.HTML       `json:"-"`
 	HasDiagram           bool                `json:"has_diagram"`
-	DiagramCaption       string              `json:"diagram_caption"`
 	Challenges           []*ReleaseChallenge `json:"challenge_details,omitempty"`
 }
 
@@ -122,6 +124,20 @@ type ReleaseRunResult struct {
 	Toolchain   string `json:"toolchain"`
 }
 
+// ReleaseSubmission records one run of a release challenge by a user.
+type ReleaseSubmission struct {
+	Username    string    `json:"username"`
+	Release     string    `json:"release"`
+	Feature     string    `json:"feature"`
+	Challenge   string    `json:"challenge"`
+	Code        string    `json:"code"`
+	SubmittedAt time.Time `json:"submitted_at"`
+	Passed      bool      `json:"passed"`
+	Output      string    `json:"output"`
+	ExecutionMs int64     `json:"execution_ms"`
+	Toolchain   string    `json:"toolchain"`
+}
+
 // Hint is one step of a challenge's progressive hints. The site reveals them one
 // at a time, so hints.md is split on its "## Hint N: title" headings, the same
 // convention the packages/ challenges use.
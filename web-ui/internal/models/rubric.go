@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// Rubric is a way of scoring an interview challenge: weighted criteria, each
+// marked on levels that describe what earns them. Rubrics are read from
+// rubrics/<name>.json in the repository; rubrics/default.json scores every
+// challenge no other rubric lists.
+type Rubric struct {
+	Name        string            `json:"name"` // the file name, without .json
+	Title       string            `json:"title"`
+	Description string            `json:"description,omitempty"`
+	Challenges  []int             `json:"challenges,omitempty"` // the classic challenges it scores; none for the default
+	Criteria    []RubricCriterion `json:"criteria"`
+}
+
+// RubricCriterion is one thing a rubric scores, such as correctness or Go
+// idioms
+type RubricCriterion struct {
+	ID          string        `json:"id"`
+	Title       string        `json:"title"`
+	Description string        `json:"description,omitempty"`
+	Weight      int           `json:"weight"`
+	Levels      []RubricLevel `json:"levels"` // lowest score first
+}
+
+// RubricLevel is one mark a criterion can be given
+type RubricLevel struct {
+	Score       int    `json:"score"`
+	Label       string `json:"label"`
+	Description string `json:"description"`
+}
+
+// RubricScore is the mark given for one criterion
+type RubricScore struct {
+	Criterion     string `json:"criterion"` // RubricCriterion.ID
+	Score         int    `json:"score"`
+	Justification string `json:"justification,omitempty"`
+}
+
+// InterviewScorecard is an interviewer's scoring of one challenge of an
+// interview against a rubric, kept as it was when scored
+type InterviewScorecard struct {
+	ChallengeID   int           `json:"challengeId"`
+	Rubric        Rubric        `json:"rubric"`
+	Scores        []RubricScore `json:"scores"`                // by the interviewer
+	Suggestions   []RubricScore `json:"suggestions,omitempty"` // by the AI, from the final code
+	WeightedScore *int          `json:"weightedScore,omitempty"`
+	Notes         string        `json:"notes,omitempty"`
+	ScoredBy      string        `json:"scoredBy,omitempty"`
+	ScoredAt      *time.Time    `json:"scoredAt,omitempty"`
+	SuggestedAt   *time.Time    `json:"suggestedAt,omitempty"`
+}
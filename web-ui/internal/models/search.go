@@ -0,0 +1,27 @@
+package models
+
+// SearchResult is one challenge found by a search, from any track
+type SearchResult struct {
+	Track      string   `json:"track"` // classic, package or release
+	ID         string   `json:"id"`
+	Title      string   `json:"title"`
+	URL        string   `json:"url"`
+	Summary    string   `json:"summary,omitempty"`
+	Difficulty string   `json:"difficulty,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	Package    string   `json:"package,omitempty"`
+	GoVersion  string   `json:"goVersion,omitempty"`
+	Kind       string   `json:"kind,omitempty"` // release feature kind: language, stdlib, toolchain or runtime
+	Score      float64  `json:"score"`
+	Snippet    string   `json:"snippet,omitempty"` // HTML-escaped, with matches wrapped in <mark>
+}
+
+// SearchResponse is a page of search results with facet counts. Each facet
+// counts the results the other filters allow, so the values of a filtered
+// facet stay selectable.
+type SearchResponse struct {
+	Query   string                    `json:"query"`
+	Total   int                       `json:"total"`
+	Results []SearchResult            `json:"results"`
+	Facets  map[string]map[string]int `json:"facets"`
+}
@@ -0,0 +1,77 @@
+package models
+
+import "time"
+
+// Team is a named group of users whose progress is followed together, such as
+// an onboarding cohort
+type Team struct {
+	Name        string   `json:"name"` // lowercase slug used in URLs
+	DisplayName string   `json:"displayName,omitempty"`
+	Members     []string `json:"members"`         // usernames shown on the dashboard
+	Leads       []string `json:"leads,omitempty"` // may view the dashboard without being on it
+}
+
+// Title is the team's display name, or its name if it has none
+func (t Team) Title() string {
+	if t.DisplayName != "" {
+		return t.DisplayName
+	}
+	return t.Name
+}
+
+// TeamDashboard is a team's progress across the three tracks
+type TeamDashboard struct {
+	Team        Team                 `json:"team"`
+	Members     []TeamMemberProgress `json:"members"`
+	Heatmap     []TeamHeatmapRow     `json:"heatmap"` // cells follow the order of Members
+	Stuck       []StuckMember        `json:"stuck"`
+	GeneratedAt time.Time            `json:"generatedAt"`
+}
+
+// TeamMemberProgress summarises one member's progress
+type TeamMemberProgress struct {
+	Username          string    `json:"username"`
+	Rank              int       `json:"rank"` // main leaderboard; 0 if unranked
+	ClassicSolved     int       `json:"classicSolved"`
+	ClassicTotal      int       `json:"classicTotal"`
+	PackagesSolved    int       `json:"packagesSolved"` // package challenges completed, over all packages
+	ReleasesPassed    int       `json:"releasesPassed"`
+	ReleasesAttempted int       `json:"releasesAttempted"`
+	Attempts          int       `json:"attempts"` // runs and submits on every track
+	LastActiveAt      time.Time `json:"lastActiveAt"`
+	Limited           bool      `json:"limited"` // the profile is private to the viewer, so only leaderboard figures are shown
+}
+
+// TeamHeatmapRow is one challenge and how far each member got with it
+type TeamHeatmapRow struct {
+	Track       string            `json:"track"`
+	ChallengeID string            `json:"challengeId"` // as in Attempt
+	Title       string            `json:"title"`
+	URL         string            `json:"url"`
+	Solved      int               `json:"solved"` // members who completed it
+	Cells       []TeamHeatmapCell `json:"cells"`
+}
+
+// TeamHeatmapCell is one member's standing on one challenge
+type TeamHeatmapCell struct {
+	Status   string `json:"status"`   // solved, attempted or empty
+	Failures int    `json:"failures"` // failed attempts since the last passing one
+	Stuck    bool   `json:"stuck"`    // unsolved after too many failures in a row
+}
+
+// Heatmap cell statuses
+const (
+	CellSolved    = "solved"
+	CellAttempted = "attempted"
+)
+
+// StuckMember is a member who keeps failing a challenge they have not solved
+type StuckMember struct {
+	Username      string    `json:"username"`
+	Track         string    `json:"track"`
+	ChallengeID   string    `json:"challengeId"`
+	Title         string    `json:"title"`
+	URL           string    `json:"url"`
+	Failures      int       `json:"failures"`
+	LastAttemptAt time.Time `json:"lastAttemptAt"`
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Webhook is an outgoing webhook: events of the listed types are POSTed to URL
+// as JSON, signed with Secret
+type Webhook struct {
+	Name   string   `json:"name"` // lowercase slug used in the admin API
+	URL    string   `json:"url"`
+	Secret string   `json:"secret,omitempty"` // HMAC-SHA256 key; unsigned without one
+	Events []string `json:"events"`           // event types, or "*" for all of them
+}
+
+// Webhook event types
+const (
+	EventRunCompleted       = "run.completed"       // a signed-in user ran the tests, passing or not
+	EventSubmissionPassed   = "submission.passed"   // a submission passed every test
+	EventAchievementAwarded = "achievement.awarded" // a user earned an achievement
+	EventPackageCompleted   = "package.completed"   // a user passed every challenge of a package
+	EventPing               = "ping"                // sent on request to check a webhook
+)
+
+// WebhookEvents lists the event types a webhook can subscribe to
+var WebhookEvents = []string{EventRunCompleted, EventSubmissionPassed, EventAchievementAwarded, EventPackageCompleted}
+
+// WebhookEvent is the JSON body of every delivery
+type WebhookEvent struct {
+	ID        string      `json:"id"` // the same for every webhook and retry of one event
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"createdAt"`
+	Data      interface{} `json:"data"`
+}
+
+// AttemptEvent is the data of run.completed and submission.passed
+type AttemptEvent struct {
+	Username    string `json:"username"`
+	Track       string `json:"track"`
+	ChallengeID string `json:"challengeId"`
+	Action      string `json:"action"` // run or submit
+	Passed      bool   `json:"passed"`
+	TestsPassed int    `json:"testsPassed"`
+	TestsTotal  int    `json:"testsTotal"`
+	ExecutionMs int64  `json:"executionMs"`
+	AttemptID   string `json:"attemptId,omitempty"`
+}
+
+// AchievementEvent is the data of achievement.awarded
+type AchievementEvent struct {
+	Username    string            `json:"username"`
+	Achievement EarnedAchievement `json:"achievement"`
+}
+
+// PackageEvent is the data of package.completed
+type PackageEvent struct {
+	Username   string `json:"username"`
+	Package    string `json:"package"`
+	Challenges int    `json:"challenges"`
+}
+
+// WebhookDelivery records the delivery of one event to one webhook, with
+// every try. A delivery still being retried is recorded as pending, with the
+// body to send, and again once it is finished; the latest record counts.
+type WebhookDelivery struct {
+	ID         string                   `json:"id"`
+	Webhook    string                   `json:"webhook"`
+	URL        string                   `json:"url"`
+	EventID    string                   `json:"eventId"`
+	Event      string                   `json:"event"`
+	Delivered  bool                     `json:"delivered"`
+	Pending    bool                     `json:"pending,omitempty"`
+	Payload    json.RawMessage          `json:"payload,omitempty"` // kept while pending
+	Tries      []WebhookDeliveryAttempt `json:"tries"`
+	CreatedAt  time.Time                `json:"createdAt"`
+	FinishedAt time.Time                `json:"finishedAt"`
+}
+
+// WebhookDeliveryAttempt is one try at a delivery
+type WebhookDeliveryAttempt struct {
+	At         time.Time `json:"at"`
+	StatusCode int       `json:"statusCode,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"durationMs"`
+}
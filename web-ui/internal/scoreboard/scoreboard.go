@@ -0,0 +1,315 @@
+// Package scoreboard owns the canonical per-challenge scoreboard format.
+//
+// Every challenge directory (classic challenge-N/ and packages/<pkg>/<challenge>/)
+// carries a scoreboard.json with one entry per user: how many tests they passed,
+// out of how many, when, and at which commit. SCOREBOARD.md is generated from it
+// for humans browsing GitHub and is never the source of truth.
+//
+// Load still falls back to parsing SCOREBOARD.md for a directory without
+// scoreboard.json, such as a challenge added by hand, so every consumer reads
+// scoreboards through this package and agrees on what they contain.
+package scoreboard
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// JSONFile is the canonical scoreboard file name.
+	JSONFile = "scoreboard.json"
+	// MarkdownFile is the generated, human-readable scoreboard.
+	MarkdownFile = "SCOREBOARD.md"
+)
+
+// Entry is one user's result for a challenge. Timestamp and Commit name the
+// judge run at which the result last went from failing to passing or back;
+// for a passing entry, that is when the user passed.
+type Entry struct {
+	Username  string    `json:"username"`
+	Passed    int       `json:"passed"`
+	Total     int       `json:"total"`
+	Timestamp time.Time `json:"timestamp,omitempty"`
+	Commit    string    `json:"commit,omitempty"`
+}
+
+// MarshalJSON leaves out a zero timestamp, which omitempty does not do for
+// time.Time.
+func (e Entry) MarshalJSON() ([]byte, error) {
+	type entry Entry
+	var timestamp *time.Time
+	if !e.Timestamp.IsZero() {
+		timestamp = &e.Timestamp
+	}
+	return json.Marshal(struct {
+		entry
+		Timestamp *time.Time `json:"timestamp,omitempty"`
+	}{entry(e), timestamp})
+}
+
+// Completed reports whether the user passed every test.
+func (e Entry) Completed() bool {
+	return e.Total > 0 && e.Passed == e.Total
+}
+
+// Score is the percentage of tests passed, 0-100.
+func (e Entry) Score() int {
+	if e.Total <= 0 {
+		return 0
+	}
+	return e.Passed * 100 / e.Total
+}
+
+// Board is the scoreboard of a single challenge.
+type Board struct {
+	// Challenge names the challenge in the Markdown heading, e.g. "challenge-1"
+	// or "gin challenge-1-basic-routing".
+	Challenge string  `json:"challenge"`
+	Entries   []Entry `json:"entries"`
+}
+
+// Find returns the entry for username.
+func (b *Board) Find(username string) (Entry, bool) {
+	for _, e := range b.Entries {
+		if e.Username == username {
+			return e, true
+		}
+	}
+	return Entry{}, false
+}
+
+// Upsert adds an entry or replaces the existing one for the same user, keeping
+// the original timestamp and commit when the new entry has none.
+func (b *Board) Upsert(entry Entry) {
+	for i, e := range b.Entries {
+		if e.Username != entry.Username {
+			continue
+		}
+		if entry.Timestamp.IsZero() {
+			entry.Timestamp = e.Timestamp
+		}
+		if entry.Commit == "" {
+			entry.Commit = e.Commit
+		}
+		b.Entries[i] = entry
+		return
+	}
+	b.Entries = append(b.Entries, entry)
+}
+
+// Sort orders entries the way SCOREBOARD.md lists them: most tests passed
+// first, then by username, case-sensitively.
+func (b *Board) Sort() {
+	sort.SliceStable(b.Entries, func(i, j int) bool {
+		if b.Entries[i].Passed != b.Entries[j].Passed {
+			return b.Entries[i].Passed > b.Entries[j].Passed
+		}
+		return b.Entries[i].Username < b.Entries[j].Username
+	})
+}
+
+// Load reads the scoreboard in dir, preferring scoreboard.json and falling back
+// to SCOREBOARD.md. It returns an error satisfying os.IsNotExist when the
+// directory has neither.
+func Load(dir string) (*Board, error) {
+	raw, err := os.ReadFile(filepath.Join(dir, JSONFile))
+	if err == nil {
+		var board Board
+		if err := json.Unmarshal(raw, &board); err != nil {
+			return nil, fmt.Errorf("invalid %s in %s: %v", JSONFile, dir, err)
+		}
+		return &board, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	raw, err = os.ReadFile(filepath.Join(dir, MarkdownFile))
+	if err != nil {
+		return nil, err
+	}
+	return ParseMarkdown(string(raw)), nil
+}
+
+// Save writes scoreboard.json and regenerates SCOREBOARD.md from it.
+func Save(dir string, board *Board) error {
+	board.Sort()
+
+	raw, err := json.MarshalIndent(board, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, JSONFile), append(raw, '\n'), 0644); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, MarkdownFile), []byte(board.Markdown()), 0644)
+}
+
+// ParseResults reads the results of a judge run, one "username passed total"
+// line per judged submission.
+func ParseResults(r io.Reader) ([]Entry, error) {
+	var results []Entry
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("line %d: want \"username passed total\", got %q", line, scanner.Text())
+		}
+		passed, err1 := strconv.Atoi(fields[1])
+		total, err2 := strconv.Atoi(fields[2])
+		if err1 != nil || err2 != nil || passed < 0 || passed > total {
+			return nil, fmt.Errorf("line %d: invalid test counts %q", line, scanner.Text())
+		}
+		if seen[fields[0]] {
+			return nil, fmt.Errorf("line %d: %s was judged twice", line, fields[0])
+		}
+		seen[fields[0]] = true
+		results = append(results, Entry{Username: fields[0], Passed: passed, Total: total})
+	}
+	return results, scanner.Err()
+}
+
+// Merge replaces the entries of the scoreboard in dir with the results of a
+// judge run made at the given time and commit. Users the run did not judge
+// are dropped. The others keep their recorded timestamp and commit unless
+// they are new or went from failing to passing (or back), in which case they
+// are dated by this run, so a passing entry records the run at which the user
+// passed. A directory without a scoreboard yet starts one for challenge. The
+// caller saves the returned board.
+func Merge(dir, challenge string, results []Entry, at time.Time, commit string) (*Board, error) {
+	merged := &Board{Challenge: challenge, Entries: results}
+	previous, err := Load(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if previous != nil && previous.Challenge != "" {
+		merged.Challenge = previous.Challenge
+	}
+	for i, e := range merged.Entries {
+		if previous != nil {
+			if old, ok := previous.Find(e.Username); ok && old.Completed() == e.Completed() {
+				merged.Entries[i].Timestamp = old.Timestamp
+				merged.Entries[i].Commit = old.Commit
+				continue
+			}
+		}
+		merged.Entries[i].Timestamp = at
+		merged.Entries[i].Commit = commit
+	}
+	return merged, nil
+}
+
+// Markdown renders the board as the SCOREBOARD.md table.
+func (b *Board) Markdown() string {
+	var out strings.Builder
+	fmt.Fprintf(&out, "# Scoreboard for %s\n", b.Challenge)
+	out.WriteString("| Username   | Passed Tests | Total Tests |\n")
+	out.WriteString("|------------|--------------|-------------|\n")
+	for _, e := range b.Entries {
+		fmt.Fprintf(&out, "| %s | %d | %d |\n", e.Username, e.Passed, e.Total)
+	}
+	return out.String()
+}
+
+// ParseMarkdown reads a SCOREBOARD.md table. Two layouts exist in the wild:
+//
+//	| Username | Passed Tests | Total Tests |
+//	| Rank | Username | Solution | Date Submitted |
+//
+// Rows of the second, older layout only record that a solution was accepted, so
+// they are read as fully passed (1 of 1), dated when the date parses.
+func ParseMarkdown(content string) *Board {
+	board := &Board{}
+	rankFormat := false
+	headerSeen := false
+
+	for _, line := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "# ") && board.Challenge == "" {
+			board.Challenge = strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(trimmed, "# "), "Scoreboard for"))
+			continue
+		}
+		if !strings.HasPrefix(trimmed, "|") || strings.Contains(trimmed, "---") {
+			continue
+		}
+
+		cells := splitRow(trimmed)
+		if !headerSeen {
+			headerSeen = true
+			rankFormat = len(cells) > 1 && strings.EqualFold(cells[0], "Rank")
+			continue
+		}
+
+		var entry Entry
+		if rankFormat {
+			if len(cells) < 2 {
+				continue
+			}
+			entry = Entry{Username: cells[1], Passed: 1, Total: 1}
+			if len(cells) >= 4 {
+				entry.Timestamp = parseDate(cells[3])
+			}
+		} else {
+			if len(cells) < 3 {
+				continue
+			}
+			passed, err1 := strconv.Atoi(cells[1])
+			total, err2 := strconv.Atoi(cells[2])
+			if err1 != nil || err2 != nil {
+				continue
+			}
+			entry = Entry{Username: cells[0], Passed: passed, Total: total}
+		}
+
+		// Skip empty usernames and placeholders
+		if entry.Username == "" || entry.Username == "------" || isNumeric(entry.Username) {
+			continue
+		}
+		board.Upsert(entry)
+	}
+
+	return board
+}
+
+// splitRow returns the trimmed cells of a Markdown table row
+func splitRow(row string) []string {
+	row = strings.TrimSuffix(strings.TrimPrefix(row, "|"), "|")
+	cells := strings.Split(row, "|")
+	for i := range cells {
+		cells[i] = strings.TrimSpace(cells[i])
+	}
+	return cells
+}
+
+// parseDate accepts the date layouts used in older scoreboards
+func parseDate(s string) time.Time {
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02", "Jan 2, 2006"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// isNumeric checks if a string contains only digits
+func isNumeric(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,129 @@
+package scoreboard
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseMarkdownBothLayouts(t *testing.T) {
+	classic := "# Scoreboard for challenge-1\n" +
+		"| Username   | Passed Tests | Total Tests |\n" +
+		"|------------|--------------|-------------|\n" +
+		"| alice | 6 | 6 |\n" +
+		"| bob | 3 | 6 |\n" +
+		"| 123 | 6 | 6 |\n"
+	board := ParseMarkdown(classic)
+	if board.Challenge != "challenge-1" || len(board.Entries) != 2 {
+		t.Fatalf("classic layout: got %+v", board)
+	}
+	if e, _ := board.Find("alice"); !e.Completed() || e.Score() != 100 {
+		t.Errorf("alice should have completed: %+v", e)
+	}
+	if e, _ := board.Find("bob"); e.Completed() || e.Score() != 50 {
+		t.Errorf("bob should be at 50%%: %+v", e)
+	}
+
+	ranked := "# Scoreboard for challenge-2\n\n" +
+		"| Rank | Username | Solution | Date Submitted |\n" +
+		"|------|----------|----------|----------------|\n" +
+		"| 1 | carol | [link](x) | 2024-03-01 |\n"
+	board = ParseMarkdown(ranked)
+	e, ok := board.Find("carol")
+	if !ok || !e.Completed() || !e.Timestamp.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("rank layout: got %+v", board)
+	}
+}
+
+func TestSaveRegeneratesMarkdownAndLoadPrefersJSON(t *testing.T) {
+	dir := t.TempDir()
+	board := &Board{Challenge: "challenge-7"}
+	board.Upsert(Entry{Username: "zed", Passed: 4, Total: 5, Commit: "abc123"})
+	board.Upsert(Entry{Username: "bob", Passed: 3, Total: 5})
+	board.Upsert(Entry{Username: "amy", Passed: 5, Total: 5})
+	board.Upsert(Entry{Username: "zed", Passed: 5, Total: 5}) // keeps commit
+
+	if err := Save(dir, board); err != nil {
+		t.Fatal(err)
+	}
+
+	md, _ := os.ReadFile(dir + "/" + MarkdownFile)
+	want := "# Scoreboard for challenge-7\n" +
+		"| Username   | Passed Tests | Total Tests |\n" +
+		"|------------|--------------|-------------|\n" +
+		"| amy | 5 | 5 |\n" +
+		"| zed | 5 | 5 |\n" +
+		"| bob | 3 | 5 |\n"
+	if string(md) != want {
+		t.Errorf("generated markdown:\n%s\nwant:\n%s", md, want)
+	}
+
+	if raw, _ := os.ReadFile(dir + "/" + JSONFile); strings.Contains(string(raw), "timestamp") {
+		t.Errorf("undated entries were saved with a timestamp:\n%s", raw)
+	}
+
+	// A hand-edited SCOREBOARD.md must not win over scoreboard.json.
+	os.WriteFile(dir+"/"+MarkdownFile, []byte("# Scoreboard for x\n| Username | Passed Tests | Total Tests |\n|--|--|--|\n| mallory | 9 | 9 |\n"), 0644)
+	loaded, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := loaded.Find("mallory"); ok {
+		t.Error("Load read SCOREBOARD.md even though scoreboard.json exists")
+	}
+	if e, _ := loaded.Find("zed"); e.Commit != "abc123" || !e.Completed() {
+		t.Errorf("zed: %+v", e)
+	}
+}
+
+func TestMergeKeepsDatesOfUnchangedResults(t *testing.T) {
+	dir := t.TempDir()
+	stamped := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	board := &Board{Challenge: "challenge-3"}
+	board.Upsert(Entry{Username: "amy", Passed: 5, Total: 5, Timestamp: stamped, Commit: "aaa"})
+	board.Upsert(Entry{Username: "bob", Passed: 2, Total: 5, Timestamp: stamped, Commit: "bbb"})
+	board.Upsert(Entry{Username: "gone", Passed: 5, Total: 5, Timestamp: stamped})
+	board.Upsert(Entry{Username: "carl", Passed: 5, Total: 5}) // recorded before runs were dated
+	if err := Save(dir, board); err != nil {
+		t.Fatal(err)
+	}
+
+	// A judge run reports every submission it tested, in its own order
+	results, err := ParseResults(strings.NewReader("bob 5 5\namy 5 5\n\nnew 1 5\ncarl 5 5\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	judged := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	merged, err := Merge(dir, "ignored", results, judged, "ccc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := merged.Find("gone"); ok || len(merged.Entries) != 4 || merged.Challenge != "challenge-3" {
+		t.Errorf("merged = %+v", merged)
+	}
+	if e, _ := merged.Find("amy"); !e.Timestamp.Equal(stamped) || e.Commit != "aaa" {
+		t.Errorf("amy should keep the recorded date: %+v", e)
+	}
+	if e, _ := merged.Find("bob"); !e.Completed() || !e.Timestamp.Equal(judged) || e.Commit != "ccc" {
+		t.Errorf("bob now passes and should be dated by this run: %+v", e)
+	}
+	if e, _ := merged.Find("carl"); !e.Timestamp.IsZero() {
+		t.Errorf("carl did not change and should not be dated by this run: %+v", e)
+	}
+	if e, _ := merged.Find("new"); !e.Timestamp.Equal(judged) || e.Commit != "ccc" {
+		t.Errorf("new should be dated by this run: %+v", e)
+	}
+
+	// A challenge without a scoreboard starts one
+	fresh, err := Merge(t.TempDir(), "gin challenge-1-basic-routing", results, judged, "ccc")
+	if err != nil || fresh.Challenge != "gin challenge-1-basic-routing" || len(fresh.Entries) != 4 {
+		t.Errorf("fresh = %+v, %v", fresh, err)
+	}
+
+	for _, bad := range []string{"amy 5", "amy five 5", "amy 6 5", "amy 1 5\namy 2 5"} {
+		if _, err := ParseResults(strings.NewReader(bad)); err == nil {
+			t.Errorf("ParseResults(%q) succeeded", bad)
+		}
+	}
+}
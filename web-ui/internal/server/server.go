@@ -7,78 +7,92 @@ import (
 	"io/fs"
 	"log"
 	"net/http"
-	"os"
 	"strings"
 
+	"web-ui/internal/auth"
+	"web-ui/internal/config"
 	"web-ui/internal/handlers"
 	"web-ui/internal/services"
 )
 
 // Server represents the web server with all its dependencies
 type Server struct {
-	content           embed.FS
-	challengeService  *services.ChallengeService
-	scoreboardService *services.ScoreboardService
-	userService       *services.UserService
-	executionService  *services.ExecutionService
-	packageService    *services.PackageService
-	aiService         *services.AIService
+	content embed.FS
+	cfg     *config.Config
+	svc     *services.Services
 }
 
 // NewServer creates a new server instance
-func NewServer(
-	content embed.FS,
-	challengeService *services.ChallengeService,
-	scoreboardService *services.ScoreboardService,
-	userService *services.UserService,
-	executionService *services.ExecutionService,
-	packageService *services.PackageService,
-	aiService *services.AIService,
-) *Server {
+func NewServer(content embed.FS, cfg *config.Config, svc *services.Services) *Server {
 	return &Server{
-		content:           content,
-		challengeService:  challengeService,
-		scoreboardService: scoreboardService,
-		userService:       userService,
-		executionService:  executionService,
-		packageService:    packageService,
-		aiService:         aiService,
+		content: content,
+		cfg:     cfg,
+		svc:     svc,
 	}
 }
 
-// SetupRoutes configures all HTTP routes
-func (s *Server) SetupRoutes() *http.ServeMux {
+// SetupRoutes configures all HTTP routes. Every request passes through the
+// session middleware, so handlers see the signed-in user.
+func (s *Server) SetupRoutes() (http.Handler, error) {
 	mux := http.NewServeMux()
+	svc := s.svc
 
 	// Setup static file handling
-	s.setupStaticFiles(mux)
+	if err := s.setupStaticFiles(mux); err != nil {
+		return nil, err
+	}
 
 	// Initialize handlers
 	apiHandler := handlers.NewAPIHandler(
-		s.challengeService,
-		s.scoreboardService,
-		s.userService,
-		s.executionService,
-		s.packageService,
-		s.aiService,
+		svc.Challenges,
+		svc.Scoreboards,
+		svc.Users,
+		svc.Execution,
+		svc.Packages,
+		svc.AI,
+		svc.Leaderboards,
+		svc.Submissions,
+		svc.Attempts,
+		svc.Achievements,
+		svc.Webhooks,
+		svc.Profiles,
 	)
 
 	webHandler := handlers.NewWebHandler(
 		s.content,
-		s.challengeService,
-		s.scoreboardService,
-		s.userService,
-		s.packageService,
+		svc.Challenges,
+		svc.Scoreboards,
+		svc.Users,
+		svc.Packages,
+		svc.Leaderboards,
+		svc.Attempts,
 	)
 
-	// "New in Go" release track. The service is self-contained (it only reads the
-	// releases/ directory), so it is constructed here rather than threaded through
-	// NewServer — that keeps main.go untouched.
-	releaseService := services.NewReleaseService()
-	if err := releaseService.Load(); err != nil {
-		log.Printf("releases: %v", err)
+	releaseHandler := handlers.NewReleaseHandler(s.content, svc.Releases, svc.Submissions, svc.Attempts, svc.Achievements, svc.Webhooks)
+	searchHandler := handlers.NewSearchHandler(svc.Search)
+	recommendationHandler := handlers.NewRecommendationHandler(svc.Recommendations)
+	curriculumHandler := handlers.NewCurriculumHandler(s.content, svc.Curricula)
+	badgeHandler := handlers.NewBadgeHandler(svc.Badges, svc.Profiles)
+
+	// Sign-in. The local provider lets anyone sign in as anyone, which is only
+	// acceptable on your own machine.
+	sessions, err := auth.NewSessions(s.cfg)
+	if err != nil {
+		return nil, fmt.Errorf("sessions: %v", err)
+	}
+	provider := auth.NewProvider(s.cfg)
+	if provider.Name() == "local" {
+		log.Printf("auth: local sign-in is enabled; set GITHUB_CLIENT_ID and GITHUB_CLIENT_SECRET before exposing this server")
 	}
-	releaseHandler := handlers.NewReleaseHandler(s.content, releaseService)
+	authHandler := handlers.NewAuthHandler(s.content, sessions, provider, s.cfg.Auth.PublicURL)
+
+	profileHandler := handlers.NewProfileHandler(s.content, svc.Profiles)
+	teamHandler := handlers.NewTeamHandler(s.content, svc.Teams)
+	adminHandler := handlers.NewAdminHandler(svc.ContentWatcher, svc.Teams, svc.Webhooks, s.cfg.Server.AdminToken)
+	assignmentHandler := handlers.NewAssignmentHandler(s.content, svc.Assignments)
+	interviewHandler := handlers.NewInterviewHandler(s.content, svc.Interviews, svc.InterviewLoops, svc.Rubrics, svc.Challenges,
+		svc.Execution, svc.AI, s.cfg.Features.AI, svc.Attempts, svc.Webhooks)
+	pairingHandler := handlers.NewPairingHandler(s.content, svc.Pairing, svc.Challenges)
 
 	// API routes
 	mux.HandleFunc("/api/challenges", apiHandler.GetAllChallenges)
@@ -88,23 +102,64 @@ func (s *Server) SetupRoutes() *http.ServeMux {
 	mux.HandleFunc("/api/run", apiHandler.RunCode)
 	mux.HandleFunc("/api/save-to-filesystem", apiHandler.SaveSubmissionToFilesystem)
 	mux.HandleFunc("/api/refresh-attempts", apiHandler.RefreshUserAttempts)
-	mux.HandleFunc("/api/git-username", apiHandler.GetGitUsername)
+	mux.HandleFunc("/api/attempts", apiHandler.HandleAttempts)
+	mux.HandleFunc("/api/attempts/", apiHandler.HandleAttempts)
+	mux.HandleFunc("/api/me", authHandler.Me)
 	mux.HandleFunc("/api/main-scoreboard-rank", apiHandler.GetMainScoreboardRank)
 	mux.HandleFunc("/api/main-leaderboard", apiHandler.GetMainLeaderboard)
+	mux.HandleFunc("/api/search", searchHandler.Search)
+	mux.HandleFunc("/api/users/", profileHandler.HandleUser)
+	mux.HandleFunc("/api/achievements", apiHandler.GetAchievements)
+	mux.HandleFunc("/api/recommendations", recommendationHandler.GetRecommendations)
+	mux.HandleFunc("/api/curricula", curriculumHandler.HandleCurricula)
+	mux.HandleFunc("/api/curricula/", curriculumHandler.HandleCurricula)
+	mux.HandleFunc("/api/assignments", assignmentHandler.HandleAssignments)
+	mux.HandleFunc("/api/assignments/", assignmentHandler.HandleAssignments)
+	mux.HandleFunc("/api/interviews", interviewHandler.HandleInterviews)
+	mux.HandleFunc("/api/interviews/", interviewHandler.HandleInterviews)
+	mux.HandleFunc("/api/interview-loops", interviewHandler.HandleLoops)
+	mux.HandleFunc("/api/interview-loops/", interviewHandler.HandleLoops)
+	mux.HandleFunc("/api/rubrics", interviewHandler.HandleRubrics)
+	mux.HandleFunc("/api/pair/rooms", pairingHandler.HandleRooms)
+	mux.HandleFunc("/api/pair/rooms/", pairingHandler.HandleRooms)
+	mux.HandleFunc("/api/teams", teamHandler.HandleTeams)
+	mux.HandleFunc("/api/teams/", teamHandler.HandleTeams)
 
 	// Package challenge API routes
 	mux.HandleFunc("/api/package-leaderboard", apiHandler.GetPackageLeaderboard)
 	mux.HandleFunc("/api/packages/", apiHandler.HandlePackageChallenge)
 	mux.HandleFunc("/api/packages-save-to-filesystem", apiHandler.SavePackageChallengeToFilesystem)
 
+	// Sign-in routes
+	mux.HandleFunc("/auth/login", authHandler.Login)
+	mux.HandleFunc("/auth/callback", authHandler.Callback)
+	mux.HandleFunc("/auth/logout", authHandler.Logout)
+	if provider.Name() == "local" {
+		mux.HandleFunc(auth.LocalSignInPath, authHandler.LocalSignIn)
+	}
+
 	// Release track API routes
 	mux.HandleFunc("/api/releases/run", releaseHandler.RunChallenge)
 
 	// AI-powered API routes
-	mux.HandleFunc("/api/ai/code-review", apiHandler.AICodeReview)
-	mux.HandleFunc("/api/ai/interviewer-questions", apiHandler.AIInterviewerQuestions)
-	mux.HandleFunc("/api/ai/code-hint", apiHandler.AICodeHint)
-	mux.HandleFunc("/api/ai/debug", apiHandler.AIDebugResponse)
+	if s.cfg.Features.AI {
+		mux.HandleFunc("/api/ai/code-review", apiHandler.AICodeReview)
+		mux.HandleFunc("/api/ai/interviewer-questions", apiHandler.AIInterviewerQuestions)
+		mux.HandleFunc("/api/ai/code-hint", apiHandler.AICodeHint)
+		mux.HandleFunc("/api/ai/debug", apiHandler.AIDebugResponse)
+	}
+
+	// Admin routes
+	mux.HandleFunc("/api/admin/reload", adminHandler.Reload)
+	mux.HandleFunc("/api/admin/teams", adminHandler.Teams)
+	mux.HandleFunc("/api/admin/teams/", adminHandler.Teams)
+	mux.HandleFunc("/api/admin/webhooks", adminHandler.Webhooks)
+	mux.HandleFunc("/api/admin/webhooks/", adminHandler.Webhooks)
+
+	// Contributor profile badges
+	if s.cfg.Features.Badges {
+		mux.HandleFunc("/badges/", badgeHandler.ServeBadge)
+	}
 
 	// GitHub webhook route
 	mux.HandleFunc("/webhook/github", apiHandler.GitHubWebhookHandler)
@@ -124,25 +179,14 @@ func (s *Server) SetupRoutes() *http.ServeMux {
 	mux.HandleFunc("/api/debug/sponsors", apiHandler.GetSponsorsDebug)
 	mux.HandleFunc("/api/ai/status", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
-		provider := os.Getenv("AI_PROVIDER")
-		if provider == "" {
-			provider = "gemini"
-		}
-		var apiKey string
-		switch strings.ToLower(provider) {
-		case "openai":
-			apiKey = os.Getenv("OPENAI_API_KEY")
-		case "claude":
-			apiKey = os.Getenv("CLAUDE_API_KEY")
-		default:
-			apiKey = os.Getenv("GEMINI_API_KEY")
-		}
+		provider, apiKey := s.cfg.AI.Provider, s.cfg.AI.APIKey
 
 		// Check if API key looks valid
 		hasValidKey := apiKey != "" && !strings.Contains(apiKey, "Example") && len(apiKey) > 30
 
 		response := map[string]interface{}{
 			"provider":    provider,
+			"enabled":     s.cfg.Features.AI,
 			"status":      "ready",
 			"message":     fmt.Sprintf("AI provider set to: %s", provider),
 			"has_api_key": apiKey != "",
@@ -163,6 +207,16 @@ func (s *Server) SetupRoutes() *http.ServeMux {
 	mux.HandleFunc("/", webHandler.HomePage)
 	mux.HandleFunc("/challenge/", webHandler.ChallengePage)
 	mux.HandleFunc("/interview", webHandler.InterviewPage)
+	mux.HandleFunc("/interview/", interviewHandler.ReportPage)
+	mux.HandleFunc("/pair", pairingHandler.PairPage)
+	mux.HandleFunc("/pair/", pairingHandler.PairPage)
+	mux.HandleFunc("/users/", profileHandler.UserPage)
+	mux.HandleFunc("/teams", teamHandler.TeamsPage)
+	mux.HandleFunc("/teams/", teamHandler.TeamsPage)
+	mux.HandleFunc("/assignments", assignmentHandler.AssignmentsPage)
+	mux.HandleFunc("/assignments/", assignmentHandler.AssignmentsPage)
+	mux.HandleFunc("/curricula", curriculumHandler.CurriculaPage)
+	mux.HandleFunc("/curricula/", curriculumHandler.CurriculaPage)
 	mux.HandleFunc("/releases", releaseHandler.Route)
 	mux.HandleFunc("/releases/", releaseHandler.Route)
 	mux.HandleFunc("/scoreboard", webHandler.ScoreboardPage)
@@ -186,14 +240,14 @@ func (s *Server) SetupRoutes() *http.ServeMux {
 		}
 	})
 
-	return mux
+	return sessions.Middleware(mux), nil
 }
 
 // setupStaticFiles configures static file serving
-func (s *Server) setupStaticFiles(mux *http.ServeMux) {
+func (s *Server) setupStaticFiles(mux *http.ServeMux) error {
 	fsys, err := fs.Sub(s.content, "static")
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
 	staticHandler := http.FileServer(http.FS(fsys))
@@ -206,4 +260,5 @@ func (s *Server) setupStaticFiles(mux *http.ServeMux) {
 		}
 		staticHandler.ServeHTTP(w, r)
 	})))
+	return nil
 }
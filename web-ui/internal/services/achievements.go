@@ -0,0 +1,498 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"web-ui/internal/config"
+	"web-ui/internal/models"
+)
+
+// defaultAchievementRules are used when the repository has no achievements.json
+var defaultAchievementRules = []models.AchievementRule{
+	{ID: "first-steps", Name: "First Steps", Icon: "🐣", Kind: models.RuleSolveCount, Count: 1,
+		Description: "Solve your first challenge"},
+	{ID: "classic-10", Name: "Double Digits", Icon: "🔟", Kind: models.RuleSolveCount, Track: models.TrackClassic, Count: 10,
+		Description: "Solve 10 classic challenges"},
+	{ID: "classic-25", Name: "Quarter Century", Icon: "🏛️", Kind: models.RuleSolveCount, Track: models.TrackClassic, Count: 25,
+		Description: "Solve 25 classic challenges"},
+	{ID: "concurrency-5", Name: "Gopher Juggler", Icon: "🧵", Kind: models.RuleSolveTagged, Tag: "concurrency", Count: 5,
+		Description: "Solve 5 concurrency challenges"},
+	{ID: "generics-3", Name: "Type Parametric", Icon: "🧬", Kind: models.RuleSolveTagged, Tag: "generics", Count: 3,
+		Description: "Solve 3 generics challenges"},
+	{ID: "algorithms-5", Name: "Algorithmist", Icon: "🧮", Kind: models.RuleSolveTagged, Tag: "algorithms", Count: 5,
+		Description: "Solve 5 algorithms challenges"},
+	{ID: "package-cobra", Name: "Cobra Commander", Icon: "🐍", Kind: models.RuleCompletePackage, Package: "cobra",
+		Description: "Complete every cobra challenge"},
+	{ID: "package-echo", Name: "Echo Chamber", Icon: "📣", Kind: models.RuleCompletePackage, Package: "echo",
+		Description: "Complete every echo challenge"},
+	{ID: "package-fiber", Name: "Fiber Optic", Icon: "⚡", Kind: models.RuleCompletePackage, Package: "fiber",
+		Description: "Complete every fiber challenge"},
+	{ID: "package-gin", Name: "Gin Master", Icon: "🍸", Kind: models.RuleCompletePackage, Package: "gin",
+		Description: "Complete every gin challenge"},
+	{ID: "package-gorm", Name: "ORM Whisperer", Icon: "🗄️", Kind: models.RuleCompletePackage, Package: "gorm",
+		Description: "Complete every gorm challenge"},
+	{ID: "package-mongodb", Name: "Document Keeper", Icon: "🍃", Kind: models.RuleCompletePackage, Package: "mongodb",
+		Description: "Complete every mongodb challenge"},
+	{ID: "release-pioneer", Name: "Release Pioneer", Icon: "🚩", Kind: models.RuleFirstReleaseSolve,
+		Description: "Be the first to pass a New in Go challenge"},
+	{ID: "streak-7", Name: "Week Streak", Icon: "📅", Kind: models.RuleStreak, Count: 7,
+		Description: "Run or submit code 7 days in a row"},
+	{ID: "streak-30", Name: "Month Streak", Icon: "🗓️", Kind: models.RuleStreak, Count: 30,
+		Description: "Run or submit code 30 days in a row"},
+}
+
+// AchievementService awards achievements by evaluating declarative rules
+// against a user's completions and attempt history.
+//
+// The rules come from achievements.json in the repository root when there is
+// one, and from defaultAchievementRules otherwise. Awards are kept in
+// achievements.jsonl in the data directory and are never taken back.
+type AchievementService struct {
+	cfg              *config.Config
+	challengeService *ChallengeService
+	packageService   *PackageService
+	releaseService   *ReleaseService
+	leaderboards     *LeaderboardService
+	activity         *ActivityService
+	store            SubmissionStore
+	attempts         AttemptStore
+	awards           *jsonlLog[models.AwardedAchievement]
+
+	mutex sync.RWMutex // guards rules
+	rules []models.AchievementRule
+
+	evaluating sync.Mutex // one evaluation at a time, so nothing is awarded twice
+}
+
+// NewAchievementService opens the award log in the data directory. Call
+// LoadRules before use.
+func NewAchievementService(
+	cfg *config.Config,
+	challengeService *ChallengeService,
+	packageService *PackageService,
+	releaseService *ReleaseService,
+	leaderboards *LeaderboardService,
+	activity *ActivityService,
+	store SubmissionStore,
+	attempts AttemptStore,
+) (*AchievementService, error) {
+	awards, err := openJSONL[models.AwardedAchievement](filepath.Join(cfg.Server.DataDir, "achievements.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	return &AchievementService{
+		cfg:              cfg,
+		challengeService: challengeService,
+		packageService:   packageService,
+		releaseService:   releaseService,
+		leaderboards:     leaderboards,
+		activity:         activity,
+		store:            store,
+		attempts:         attempts,
+		awards:           awards,
+		rules:            defaultAchievementRules,
+	}, nil
+}
+
+// LoadRules reads achievements.json from the repository root, falling back to
+// the built-in rules when there is none. The current rules are kept if the
+// file is invalid.
+func (as *AchievementService) LoadRules() error {
+	rules := defaultAchievementRules
+	raw, err := os.ReadFile(filepath.Join(as.cfg.Content.Root, "achievements.json"))
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return err
+	default:
+		var file struct {
+			Achievements []models.AchievementRule `json:"achievements"`
+		}
+		if err := json.Unmarshal(raw, &file); err != nil {
+			return fmt.Errorf("achievements.json: %v", err)
+		}
+		if err := validateAchievementRules(file.Achievements); err != nil {
+			return fmt.Errorf("achievements.json: %v", err)
+		}
+		rules = file.Achievements
+	}
+
+	as.mutex.Lock()
+	as.rules = rules
+	as.mutex.Unlock()
+	return nil
+}
+
+// validateAchievementRules checks that every rule has an ID and what its kind needs
+func validateAchievementRules(rules []models.AchievementRule) error {
+	seen := make(map[string]bool)
+	for i, rule := range rules {
+		switch {
+		case rule.ID == "":
+			return fmt.Errorf("achievement %d has no id", i+1)
+		case seen[rule.ID]:
+			return fmt.Errorf("achievement %q is defined twice", rule.ID)
+		case rule.Name == "":
+			return fmt.Errorf("achievement %q has no name", rule.ID)
+		}
+		seen[rule.ID] = true
+
+		switch rule.Kind {
+		case models.RuleSolveCount, models.RuleStreak:
+			if rule.Count < 1 {
+				return fmt.Errorf("achievement %q needs a count", rule.ID)
+			}
+		case models.RuleSolveTagged:
+			if rule.Tag == "" || rule.Count < 1 {
+				return fmt.Errorf("achievement %q needs a tag and a count", rule.ID)
+			}
+		case models.RuleCompletePackage:
+			if rule.Package == "" {
+				return fmt.Errorf("achievement %q needs a package", rule.ID)
+			}
+		case models.RuleFirstReleaseSolve:
+		default:
+			return fmt.Errorf("achievement %q has unknown kind %q", rule.ID, rule.Kind)
+		}
+	}
+	return nil
+}
+
+// Rules returns the current rules
+func (as *AchievementService) Rules() []models.AchievementRule {
+	as.mutex.RLock()
+	defer as.mutex.RUnlock()
+	return as.rules
+}
+
+// solvedChallenge is a challenge a user has solved, identified as in models.Attempt
+type solvedChallenge struct {
+	track string
+	id    string
+	tags  []string
+}
+
+// userFacts is what the rules are evaluated against
+type userFacts struct {
+	solved   map[string]solvedChallenge // track + ":" + id
+	releases []models.ReleaseSubmission // passing release runs
+}
+
+// Evaluate awards username every achievement they have newly earned, and
+// returns those. It runs after each submit.
+func (as *AchievementService) Evaluate(username string) ([]models.EarnedAchievement, error) {
+	if username == "" {
+		return nil, nil
+	}
+	as.evaluating.Lock()
+	defer as.evaluating.Unlock()
+
+	held := make(map[string]bool)
+	for _, award := range as.userAwards(username) {
+		held[award.Achievement] = true
+	}
+
+	var facts *userFacts
+	var earned []models.EarnedAchievement
+	for _, rule := range as.Rules() {
+		if held[rule.ID] {
+			continue
+		}
+		if facts == nil {
+			facts = as.facts(username)
+		}
+		if !as.satisfies(rule, username, facts) {
+			continue
+		}
+
+		award := models.AwardedAchievement{Username: username, Achievement: rule.ID, AwardedAt: time.Now()}
+		if err := as.awards.Append(award); err != nil {
+			return earned, err
+		}
+		earned = append(earned, earnedFrom(rule, award))
+	}
+	return earned, nil
+}
+
+// satisfies reports whether the user meets the rule
+func (as *AchievementService) satisfies(rule models.AchievementRule, username string, facts *userFacts) bool {
+	switch rule.Kind {
+	case models.RuleSolveCount:
+		count := 0
+		for _, s := range facts.solved {
+			if rule.Track == "" || s.track == rule.Track {
+				count++
+			}
+		}
+		return count >= rule.Count
+
+	case models.RuleSolveTagged:
+		count := 0
+		for _, s := range facts.solved {
+			if (rule.Track == "" || s.track == rule.Track) && hasTag(s.tags, rule.Tag) {
+				count++
+			}
+		}
+		return count >= rule.Count
+
+	case models.RuleCompletePackage:
+		challenges, err := as.leaderboards.PackageChallenges(rule.Package)
+		if err != nil || len(challenges) == 0 {
+			return false
+		}
+		for _, c := range challenges {
+			if _, ok := facts.solved[models.TrackPackage+":"+rule.Package+"/"+c.ID]; !ok {
+				return false
+			}
+		}
+		return true
+
+	case models.RuleFirstReleaseSolve:
+		for _, run := range facts.releases {
+			if as.firstReleaseSolver(run.Release, run.Feature, run.Challenge) == username {
+				return true
+			}
+		}
+		return false
+
+	case models.RuleStreak:
+		return as.activity.LongestStreak(username) >= rule.Count
+	}
+	return false
+}
+
+// facts gathers everything the rules look at for one user
+func (as *AchievementService) facts(username string) *userFacts {
+	facts := &userFacts{solved: make(map[string]solvedChallenge)}
+	solve := func(track, id string) {
+		facts.solved[track+":"+id] = solvedChallenge{track: track, id: id, tags: as.challengeTags(track, id)}
+	}
+
+	for id := range as.leaderboards.MainCompletions()[username] {
+		solve(models.TrackClassic, strconv.Itoa(id))
+	}
+	for name := range as.packageService.GetPackages() {
+		challenges, err := as.leaderboards.PackageChallenges(name)
+		if err != nil {
+			continue
+		}
+		for id := range as.leaderboards.PackageCompletions(name, challenges)[username] {
+			solve(models.TrackPackage, name+"/"+id)
+		}
+	}
+
+	// In-browser submits count as soon as they pass, before any pull request.
+	// Release challenges have no submit, so a passing run counts.
+	for _, a := range as.attempts.UserAttempts(username) {
+		if a.Passed && (a.Action == "submit" || a.Track == models.TrackRelease) {
+			solve(a.Track, a.ChallengeID)
+		}
+	}
+
+	query := SubmissionQuery{Username: username, Limit: MaxSubmissionPageSize}
+	for {
+		runs, total := as.store.ReleaseSubmissions(query)
+		for _, run := range runs {
+			if run.Passed {
+				facts.releases = append(facts.releases, run)
+				solve(models.TrackRelease, run.Release+"/"+run.Feature+"/"+run.Challenge)
+			}
+		}
+		query.Offset += len(runs)
+		if len(runs) == 0 || query.Offset >= total {
+			break
+		}
+	}
+	return facts
+}
+
+// challengeTags looks up the tags of a challenge identified as in models.Attempt
+func (as *AchievementService) challengeTags(track, id string) []string {
+	switch track {
+	case models.TrackClassic:
+		if n, err := strconv.Atoi(id); err == nil {
+			if c, ok := as.challengeService.GetChallenge(n); ok {
+				return c.Tags
+			}
+		}
+	case models.TrackPackage:
+		if pkg, slug, ok := strings.Cut(id, "/"); ok {
+			if c, err := as.packageService.GetPackageChallenge(pkg, slug); err == nil {
+				return c.Tags
+			}
+		}
+	case models.TrackRelease:
+		if parts := strings.SplitN(id, "/", 3); len(parts) == 3 {
+			if c := as.releaseService.GetChallenge(parts[0], parts[1], parts[2]); c != nil {
+				return c.Tags
+			}
+		}
+	}
+	return nil
+}
+
+// firstReleaseSolver returns who passed a release challenge first
+func (as *AchievementService) firstReleaseSolver(release, feature, challenge string) string {
+	first, firstAt := "", time.Time{}
+	query := SubmissionQuery{Challenge: challenge, Limit: MaxSubmissionPageSize}
+	for {
+		runs, total := as.store.ReleaseSubmissions(query)
+		for _, run := range runs {
+			if run.Passed && run.Release == release && run.Feature == feature &&
+				(first == "" || run.SubmittedAt.Before(firstAt)) {
+				first, firstAt = run.Username, run.SubmittedAt
+			}
+		}
+		query.Offset += len(runs)
+		if len(runs) == 0 || query.Offset >= total {
+			return first
+		}
+	}
+}
+
+// hasTag reports whether tags contains tag, ignoring case
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// userAwards returns a user's awards, newest first
+func (as *AchievementService) userAwards(username string) []models.AwardedAchievement {
+	return as.awards.Filter(func(a models.AwardedAchievement) bool { return a.Username == username })
+}
+
+// Earned returns the achievements a user holds, newest first. Awards whose
+// rule has since been removed are shown by their ID.
+func (as *AchievementService) Earned(username string) []models.EarnedAchievement {
+	rules := make(map[string]models.AchievementRule)
+	for _, rule := range as.Rules() {
+		rules[rule.ID] = rule
+	}
+
+	earned := []models.EarnedAchievement{}
+	for _, award := range as.userAwards(username) {
+		rule, ok := rules[award.Achievement]
+		if !ok {
+			rule = models.AchievementRule{ID: award.Achievement, Name: award.Achievement, Icon: "🏅"}
+		}
+		earned = append(earned, earnedFrom(rule, award))
+	}
+	return earned
+}
+
+// EarnedByUser returns every user's achievements, newest first
+func (as *AchievementService) EarnedByUser() map[string][]models.EarnedAchievement {
+	users := make(map[string][]models.EarnedAchievement)
+	for _, award := range as.awards.Filter(func(models.AwardedAchievement) bool { return true }) {
+		if _, ok := users[award.Username]; !ok {
+			users[award.Username] = as.Earned(award.Username)
+		}
+	}
+	return users
+}
+
+func earnedFrom(rule models.AchievementRule, award models.AwardedAchievement) models.EarnedAchievement {
+	return models.EarnedAchievement{
+		ID:          rule.ID,
+		Name:        rule.Name,
+		Description: rule.Description,
+		Icon:        rule.Icon,
+		Package:     rule.Package,
+		AwardedAt:   award.AwardedAt,
+	}
+}
+
+// packageSessionGap is the longest pause between two attempts that still
+// counts as time spent on a package
+const packageSessionGap = 30 * time.Minute
+
+// PackageProgress returns a user's progress through one package's learning
+// path. TotalTime is estimated from the attempt history: pauses longer than
+// packageSessionGap are not counted. Score is the percentage of the path
+// completed.
+func (as *AchievementService) PackageProgress(username, packageName string) *models.PackageProgress {
+	challenges, err := as.leaderboards.PackageChallenges(packageName)
+	if err != nil {
+		return packageProgress(username, packageName, nil, nil, nil, nil)
+	}
+	completed := as.leaderboards.PackageCompletions(packageName, challenges)[username]
+	return packageProgress(username, packageName, challenges, completed, as.attempts.UserAttempts(username), as.Earned(username))
+}
+
+// packageProgress works out PackageProgress from the package's challenges, the
+// user's completions in it, attempts and achievements, which callers building
+// progress for several packages read once
+func packageProgress(username, packageName string, challenges []*models.PackageChallenge, completed map[string]time.Time,
+	attempts []models.Attempt, earned []models.EarnedAchievement) *models.PackageProgress {
+	progress := &models.PackageProgress{
+		Username:            username,
+		PackageName:         packageName,
+		CompletedChallenges: []string{},
+		Achievements:        []string{},
+	}
+	if challenges == nil {
+		return progress
+	}
+
+	// Passing submits since the scoreboard was last updated count too
+	if completed != nil {
+		completed = maps.Clone(completed)
+	}
+	var previous time.Time
+	latest := "" // the challenge last worked on
+	for _, a := range attempts {
+		slug, ok := strings.CutPrefix(a.ChallengeID, packageName+"/")
+		if a.Track != models.TrackPackage || !ok {
+			continue
+		}
+		if progress.StartedAt.IsZero() {
+			progress.StartedAt = a.CreatedAt
+		}
+		if gap := a.CreatedAt.Sub(previous); !previous.IsZero() && gap <= packageSessionGap {
+			progress.TotalTime += gap
+		}
+		previous = a.CreatedAt
+		progress.LastActivity = a.CreatedAt
+
+		if a.Passed && a.Action == "submit" {
+			if completed == nil {
+				completed = make(map[string]time.Time)
+			}
+			completed[slug] = a.CreatedAt
+		}
+		latest = slug
+	}
+
+	for _, c := range challenges {
+		if _, ok := completed[c.ID]; ok {
+			progress.CompletedChallenges = append(progress.CompletedChallenges, c.ID)
+		}
+	}
+	if _, done := completed[latest]; latest != "" && !done {
+		progress.InProgress = latest
+	}
+	if len(challenges) > 0 {
+		progress.Score = len(progress.CompletedChallenges) * 100 / len(challenges)
+	}
+
+	for _, earned := range earned {
+		if earned.Package == packageName {
+			progress.Achievements = append(progress.Achievements, earned.ID)
+		}
+	}
+	sort.Strings(progress.Achievements)
+	return progress
+}
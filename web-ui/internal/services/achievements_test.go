@@ -0,0 +1,85 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"web-ui/internal/config"
+	"web-ui/internal/models"
+)
+
+func TestAchievementRulesAwardOnce(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"challenge-1", "challenge-2"} {
+		os.MkdirAll(filepath.Join(root, dir), 0755)
+		os.WriteFile(filepath.Join(root, dir, "README.md"), []byte("# Challenge\n"), 0644)
+		os.WriteFile(filepath.Join(root, dir, "solution-template.go"), []byte("package main\n"), 0644)
+	}
+	os.WriteFile(filepath.Join(root, "challenge-1", "metadata.json"), []byte(`{"title": "Fan Out", "tags": ["Concurrency"]}`), 0644)
+	os.WriteFile(filepath.Join(root, "achievements.json"), []byte(`{"achievements": [
+		{"id": "tagged", "name": "Juggler", "kind": "solve_tagged", "tag": "concurrency", "count": 1},
+		{"id": "streak", "name": "Three Days", "kind": "streak", "count": 3},
+		{"id": "pioneer", "name": "Pioneer", "kind": "first_release_solve"}
+	]}`), 0644)
+
+	cfg := config.Default()
+	cfg.Content.Root = root
+	cfg.Server.DataDir = t.TempDir()
+	challenges := NewChallengeService(cfg)
+	if err := challenges.LoadChallenges(); err != nil {
+		t.Fatal(err)
+	}
+	packages := NewPackageService(cfg)
+	leaderboards := NewLeaderboardService(cfg, challenges, packages, nil)
+	store, _ := NewFileSubmissionStore(cfg.Server.DataDir)
+	attempts, _ := NewFileAttemptStore(cfg.Server.DataDir)
+	activity, _ := NewActivityService(cfg, attempts)
+	achievements, err := NewAchievementService(cfg, challenges, packages, NewReleaseService(cfg, nil), leaderboards, activity, store, attempts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := achievements.LoadRules(); err != nil {
+		t.Fatal(err)
+	}
+
+	// alice submits challenge 1 on the third of three days in a row
+	start := time.Date(2026, 3, 1, 22, 0, 0, 0, time.UTC)
+	for day := 0; day < 3; day++ {
+		attempt := NewAttempt("alice", models.TrackClassic, "1", "submit", "package main", day == 2, "", 10)
+		attempt.CreatedAt = start.AddDate(0, 0, day)
+		attempts.AddAttempt(attempt)
+	}
+	// bob passes the release challenge before alice does
+	for i, username := range []string{"bob", "alice"} {
+		store.AddReleaseSubmission(models.ReleaseSubmission{Username: username, Release: "1.26", Feature: "new-expr",
+			Challenge: "challenge-1", Passed: true, SubmittedAt: start.Add(time.Duration(i) * time.Hour)})
+	}
+
+	earned, err := achievements.Evaluate("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(earned) != 2 || earned[0].ID != "tagged" || earned[1].ID != "streak" {
+		t.Errorf("alice earned %+v, want tagged and streak", earned)
+	}
+	if again, _ := achievements.Evaluate("alice"); len(again) != 0 {
+		t.Errorf("alice was awarded again: %+v", again)
+	}
+	if earned, _ := achievements.Evaluate("bob"); len(earned) != 1 || earned[0].ID != "pioneer" {
+		t.Errorf("bob earned %+v, want pioneer", earned)
+	}
+	if held := achievements.Earned("alice"); len(held) != 2 || held[0].Name == "" || held[0].AwardedAt.IsZero() {
+		t.Errorf("alice holds %+v", held)
+	}
+
+	// A broken rules file keeps the rules that were loaded
+	os.WriteFile(filepath.Join(root, "achievements.json"), []byte(`{"achievements": [{"id": "x", "name": "X", "kind": "nope"}]}`), 0644)
+	if err := achievements.LoadRules(); err == nil {
+		t.Error("a rule of unknown kind was accepted")
+	}
+	if len(achievements.Rules()) != 3 {
+		t.Errorf("rules = %+v", achievements.Rules())
+	}
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"path/filepath"
+	"sort"
+	"time"
+
+	"web-ui/internal/config"
+	"web-ui/internal/models"
+)
+
+// calendarWeeks is how many weeks the activity calendar spans, the current one included
+const calendarWeeks = 53
+
+// ActivityService turns the attempt history into daily activity, streaks and
+// a contributions-style calendar, and keeps each user's time zone.
+//
+// Days are civil dates in the user's time zone, so a run at 23:30 in Berlin
+// counts for that day however far it is from UTC. Each date is numbered
+// rather than measured in hours, so days lost or gained to daylight saving
+// time do not break a streak.
+type ActivityService struct {
+	cfg      *config.Config
+	attempts AttemptStore
+	settings *jsonlLog[models.ActivitySettings]
+}
+
+// NewActivityService opens the activity settings log in the data directory
+func NewActivityService(cfg *config.Config, attempts AttemptStore) (*ActivityService, error) {
+	settings, err := openJSONL[models.ActivitySettings](filepath.Join(cfg.Server.DataDir, "activity_settings.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	return &ActivityService{cfg: cfg, attempts: attempts, settings: settings}, nil
+}
+
+// ValidTimezone reports whether name is an IANA time zone such as "Europe/Berlin"
+func ValidTimezone(name string) bool {
+	if name == "" || name == "Local" {
+		return false
+	}
+	_, err := time.LoadLocation(name)
+	return err == nil
+}
+
+// Timezone returns the user's time zone and whether they chose it; users who
+// have not fall back to the configured default.
+func (as *ActivityService) Timezone(username string) (string, bool) {
+	latest := as.settings.Filter(func(s models.ActivitySettings) bool { return s.Username == username })
+	if len(latest) > 0 && ValidTimezone(latest[0].Timezone) {
+		return latest[0].Timezone, true
+	}
+	return as.cfg.Activity.DefaultTimezone, false
+}
+
+// SetTimezone saves the user's time zone. Check it with ValidTimezone first.
+func (as *ActivityService) SetTimezone(username, timezone string) error {
+	return as.settings.Append(models.ActivitySettings{Username: username, Timezone: timezone, UpdatedAt: time.Now()})
+}
+
+// location loads the user's time zone, falling back to UTC
+func (as *ActivityService) location(username string) (*time.Location, string, bool) {
+	name, saved := as.Timezone(username)
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC, "UTC", saved
+	}
+	return loc, name, saved
+}
+
+// Activity summarizes the user's daily activity and streaks, with the
+// calendar when withCalendar is set
+func (as *ActivityService) Activity(username string, withCalendar bool) *models.UserActivity {
+	loc, name, saved := as.location(username)
+	days := as.days(username, loc)
+	today := dayNumber(time.Now().In(loc))
+
+	active := make([]int, 0, len(days))
+	for day := range days {
+		active = append(active, day)
+	}
+	sort.Ints(active)
+
+	freezes := as.cfg.Activity.StreakFreezes
+	current, longest := streaks(active, today, freezes)
+	activity := &models.UserActivity{
+		Username:      username,
+		Timezone:      name,
+		TimezoneSaved: saved,
+		Today:         dayDate(today),
+		CurrentStreak: current,
+		LongestStreak: longest,
+		ActiveDays:    len(active),
+		StreakFreezes: freezes,
+	}
+	if withCalendar {
+		activity.Calendar = calendar(days, today)
+	}
+	return activity
+}
+
+// LongestStreak is the user's longest streak of active days
+func (as *ActivityService) LongestStreak(username string) int {
+	return as.Activity(username, false).LongestStreak
+}
+
+// days totals the user's runs, submits and passes per day in loc, keyed by dayNumber
+func (as *ActivityService) days(username string, loc *time.Location) map[int]*models.ActivityDay {
+	days := make(map[int]*models.ActivityDay)
+	for _, a := range as.attempts.UserAttempts(username) {
+		n := dayNumber(a.CreatedAt.In(loc))
+		day, ok := days[n]
+		if !ok {
+			day = &models.ActivityDay{Date: dayDate(n)}
+			days[n] = day
+		}
+		switch a.Action {
+		case "run":
+			day.Runs++
+		case "submit":
+			day.Submits++
+		}
+		if a.Passed {
+			day.Passes++
+		}
+		day.Total = day.Runs + day.Submits
+	}
+	return days
+}
+
+// streaks returns the current and longest streaks over active, which are
+// distinct day numbers in order. Each calendar week, Sunday first, forgives
+// up to freezes missed days: a streak carries on across them without adding
+// to its length, and the allowance comes back the next week. The current
+// streak is still alive while today has no activity yet.
+func streaks(active []int, today, freezes int) (current, longest int) {
+	week, used := -1, 0
+	forgive := func(from, to int) bool {
+		for day := from; day <= to; day++ {
+			// Day 0, 1 January 1970, was a Thursday
+			if w := (day + 4) / 7; w != week {
+				week, used = w, 0
+			}
+			if used == freezes {
+				return false
+			}
+			used++
+		}
+		return true
+	}
+
+	run := 0
+	for i, day := range active {
+		if i > 0 && forgive(active[i-1]+1, day-1) {
+			run++
+		} else {
+			run = 1
+		}
+		longest = max(longest, run)
+	}
+	if len(active) > 0 && forgive(active[len(active)-1]+1, today-1) {
+		current = run
+	}
+	return current, longest
+}
+
+// calendar lays days out in whole weeks, Sunday first, ending today
+func calendar(days map[int]*models.ActivityDay, today int) *models.ActivityCalendar {
+	// Day 0, 1 January 1970, was a Thursday
+	start := today - (today+4)%7 - (calendarWeeks-1)*7
+	cal := &models.ActivityCalendar{Start: dayDate(start), End: dayDate(today)}
+	for n := start; n <= today; n++ {
+		if day, ok := days[n]; ok {
+			cal.Max = max(cal.Max, day.Total)
+		}
+	}
+
+	for n := start; n <= today; n++ {
+		if (n-start)%7 == 0 {
+			cal.Weeks = append(cal.Weeks, make([]models.ActivityDay, 0, 7))
+		}
+		day := models.ActivityDay{Date: dayDate(n)}
+		if d, ok := days[n]; ok {
+			day = *d
+			if cal.Max > 0 {
+				day.Level = min(4, (day.Total*4+cal.Max-1)/cal.Max)
+			}
+		}
+		week := len(cal.Weeks) - 1
+		cal.Weeks[week] = append(cal.Weeks[week], day)
+	}
+	return cal
+}
+
+// dayNumber numbers t's civil date, counting days since 1 January 1970
+func dayNumber(t time.Time) int {
+	y, m, d := t.Date()
+	return int(time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix() / 86400)
+}
+
+// dayDate formats a dayNumber as YYYY-MM-DD
+func dayDate(n int) string {
+	return time.Unix(int64(n)*86400, 0).UTC().Format("2006-01-02")
+}
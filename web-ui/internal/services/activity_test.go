@@ -0,0 +1,74 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"web-ui/internal/config"
+	"web-ui/internal/models"
+)
+
+func TestActivityStreaksInUserTimezone(t *testing.T) {
+	cfg := config.Default()
+	cfg.Server.DataDir = t.TempDir()
+	attempts, _ := NewFileAttemptStore(cfg.Server.DataDir)
+	activity, err := NewActivityService(cfg, attempts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Around the switch to summer time. In Berlin these are 28, 29 and 31
+	// March; in UTC the last one is still 30 March.
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	for _, at := range []time.Time{
+		time.Date(2026, 3, 28, 23, 30, 0, 0, berlin),
+		time.Date(2026, 3, 29, 23, 30, 0, 0, berlin),
+		time.Date(2026, 3, 29, 23, 45, 0, 0, berlin),
+		time.Date(2026, 3, 31, 0, 30, 0, 0, berlin),
+	} {
+		attempt := NewAttempt("alice", models.TrackClassic, "1", "run", "package main", false, "", 10)
+		attempt.CreatedAt = at
+		attempts.AddAttempt(attempt)
+	}
+	if utc := activity.Activity("alice", false); utc.Timezone != "UTC" || utc.TimezoneSaved || utc.LongestStreak != 3 || utc.Calendar != nil {
+		t.Errorf("activity in UTC = %+v", utc)
+	}
+
+	if !ValidTimezone("Europe/Berlin") || ValidTimezone("Mars/Olympus") || ValidTimezone("") {
+		t.Error("ValidTimezone accepts the wrong names")
+	}
+	if err := activity.SetTimezone("alice", "Europe/Berlin"); err != nil {
+		t.Fatal(err)
+	}
+	got := activity.Activity("alice", true)
+	if !got.TimezoneSaved || got.ActiveDays != 3 || got.LongestStreak != 2 || got.CurrentStreak != 0 {
+		t.Errorf("activity in Berlin = %+v", got)
+	}
+	if weeks := got.Calendar.Weeks; len(weeks) != calendarWeeks || weeks[0][0].Date != got.Calendar.Start || len(weeks[0]) != 7 {
+		t.Errorf("calendar starts %s with %d weeks", got.Calendar.Start, len(weeks))
+	}
+	if start, _ := time.Parse("2006-01-02", got.Calendar.Start); start.Weekday() != time.Sunday {
+		t.Errorf("calendar starts on a %s", start.Weekday())
+	}
+
+	today := dayNumber(time.Now())
+	sunday := today - (today+4)%7 - 14
+	for _, tc := range []struct {
+		active           []int
+		today, freezes   int
+		current, longest int
+	}{
+		{[]int{today - 2, today - 1}, today, 0, 2, 2},                   // today not yet active
+		{[]int{today - 3, today - 2}, today, 0, 0, 2},                   // yesterday missed
+		{[]int{today - 5, today - 3, today - 2}, today, 1, 3, 3},        // one frozen day
+		{[]int{today - 9, today - 8, today - 7, today}, today, 1, 1, 3}, // too many missed
+		// every other day uses up the week's freeze
+		{[]int{sunday, sunday + 2, sunday + 4, sunday + 6, sunday + 8, sunday + 10, sunday + 12}, sunday + 13, 1, 1, 2},
+		// the freeze comes back each week
+		{[]int{sunday, sunday + 1, sunday + 2, sunday + 4, sunday + 5, sunday + 6, sunday + 7, sunday + 8, sunday + 9, sunday + 11, sunday + 12}, sunday + 13, 1, 11, 11},
+	} {
+		if current, longest := streaks(tc.active, tc.today, tc.freezes); current != tc.current || longest != tc.longest {
+			t.Errorf("streaks(%v, freezes %d) = %d, %d; want %d, %d", tc.active, tc.freezes, current, longest, tc.current, tc.longest)
+		}
+	}
+}
@@ -6,10 +6,10 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
-	"os"
 	"strings"
 	"time"
 
+	"web-ui/internal/config"
 	"web-ui/internal/models"
 )
 
@@ -38,13 +38,12 @@ type AIService struct {
 	httpClient *http.Client
 }
 
-// NewAIService creates a new AI service with the specified provider
-func NewAIService() *AIService {
-	cfgProvider := getProviderFromEnv()
+// NewAIService creates a new AI service for the configured provider
+func NewAIService(cfg *config.Config) *AIService {
 	config := LLMConfig{
-		Provider:    cfgProvider,
-		APIKey:      getAPIKeyFromEnvFor(cfgProvider),
-		Model:       getModelFromEnv(),
+		Provider:    LLMProvider(cfg.AI.Provider),
+		APIKey:      cfg.AI.APIKey,
+		Model:       cfg.AI.Model,
 		MaxTokens:   4000, // Increased for longer responses
 		Temperature: 0.3,
 	}
@@ -84,44 +83,6 @@ func NewAIService() *AIService {
 	}
 }
 
-// Helper functions to get configuration from environment
-func getProviderFromEnv() LLMProvider {
-	provider := strings.ToLower(os.Getenv("AI_PROVIDER"))
-	switch provider {
-	case "gemini":
-		return ProviderGemini
-	case "openai":
-		return ProviderOpenAI
-	case "claude":
-		return ProviderClaude
-	default:
-		return ProviderGemini // Default to Gemini
-	}
-}
-
-func getAPIKeyFromEnvFor(provider LLMProvider) string {
-	switch provider {
-	case ProviderGemini:
-		if key := os.Getenv("GEMINI_API_KEY"); key != "" {
-			return key
-		}
-	case ProviderOpenAI:
-		if key := os.Getenv("OPENAI_API_KEY"); key != "" {
-			return key
-		}
-	case ProviderClaude:
-		if key := os.Getenv("CLAUDE_API_KEY"); key != "" {
-			return key
-		}
-	}
-	// Fall back to generic AI_API_KEY
-	return os.Getenv("AI_API_KEY")
-}
-
-func getModelFromEnv() string {
-	return os.Getenv("AI_MODEL")
-}
-
 // AICodeReview represents the response from AI code review
 type AICodeReview struct {
 	OverallScore        float64            `json:"overall_score"`        // 0-100 score
@@ -341,6 +302,30 @@ func (ai *AIService) GetCodeHint(code string, challenge *models.Challenge, hintL
 	return ai.parseHint(response), nil
 }
 
+// SuggestRubricScores suggests a score and justification for each criterion
+// of a rubric, for an interviewer to start from. Unlike the other requests it
+// has no fallback answer, since a made-up score would be taken for the AI's:
+// without an API key, or when the AI fails or answers nothing usable, it
+// returns an error.
+func (ai *AIService) SuggestRubricScores(code string, challenge *models.Challenge, rubric models.Rubric) ([]models.RubricScore, error) {
+	if ai.config.APIKey == "" {
+		return nil, fmt.Errorf("AI features require an API key")
+	}
+
+	prompt := ai.buildRubricPrompt(code, challenge, rubric)
+
+	response, err := ai.callLLMWithOpts(prompt, true /* expectJSON */)
+	if err != nil {
+		return nil, fmt.Errorf("AI service unavailable: %v", err)
+	}
+
+	suggestions := ai.parseRubricScores(response, rubric)
+	if len(suggestions) == 0 {
+		return nil, fmt.Errorf("the AI suggested no usable scores")
+	}
+	return suggestions, nil
+}
+
 // BuildCodeReviewPrompt exposes the prompt builder for debugging
 func (ai *AIService) BuildCodeReviewPrompt(code string, challenge *models.Challenge, context string) string {
 	return ai.buildCodeReviewPrompt(code, challenge, context)
@@ -433,6 +418,41 @@ Provide %s (level %d/4). Be encouraging and educational, not just giving the ans
 Return only the hint text.`, challenge.Title, code, hintTypes[hintLevel], hintLevel)
 }
 
+// buildRubricPrompt creates the prompt for suggesting rubric scores
+func (ai *AIService) buildRubricPrompt(code string, challenge *models.Challenge, rubric models.Rubric) string {
+	var criteria strings.Builder
+	for _, criterion := range rubric.Criteria {
+		fmt.Fprintf(&criteria, "- %s (%s): %s\n", criterion.ID, criterion.Title, criterion.Description)
+		for _, level := range criterion.Levels {
+			fmt.Fprintf(&criteria, "    %d = %s: %s\n", level.Score, level.Label, level.Description)
+		}
+	}
+
+	return fmt.Sprintf(`You are a senior Go interviewer scoring a candidate's solution against a rubric. Respond ONLY with a single JSON object. Do NOT include markdown or code fences. All numeric fields must be JSON numbers, not strings.
+
+SCHEMA:
+{
+  "scores": [
+    {
+      "criterion": string (a criterion id below),
+      "score": integer (one of the criterion's level scores),
+      "justification": string (one or two sentences citing the code)
+    }
+  ]
+}
+
+RUBRIC CRITERIA:
+%s
+CHALLENGE: %s
+
+CODE (Go):
+BEGIN_CODE
+%s
+END_CODE
+
+Score only what the code shows. Leave out criteria the code cannot show, such as communication, or concurrency in a challenge without any.`, criteria.String(), challenge.Title, code)
+}
+
 // callLLM makes a request to the configured LLM provider
 func (ai *AIService) callLLM(prompt string) (string, error) {
 	return ai.callLLMWithOpts(prompt, false)
@@ -783,3 +803,32 @@ func (ai *AIService) parseHint(response string) string {
 	}
 	return hint
 }
+
+// parseRubricScores extracts suggested scores from AI response, keeping only
+// those for a criterion of the rubric at one of its levels
+func (ai *AIService) parseRubricScores(response string, rubric models.Rubric) []models.RubricScore {
+	start := strings.Index(response, "{")
+	end := strings.LastIndex(response, "}")
+	if start == -1 || end < start {
+		return nil
+	}
+
+	var parsed struct {
+		Scores []models.RubricScore `json:"scores"`
+	}
+	if err := json.Unmarshal([]byte(response[start:end+1]), &parsed); err != nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	scores := []models.RubricScore{}
+	for _, score := range parsed.Scores {
+		criterion, ok := rubricCriterion(rubric, score.Criterion)
+		if !ok || seen[score.Criterion] || !hasRubricLevel(criterion, score.Score) {
+			continue
+		}
+		seen[score.Criterion] = true
+		scores = append(scores, score)
+	}
+	return scores
+}
@@ -0,0 +1,424 @@
+package services
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"web-ui/internal/models"
+)
+
+// assignmentsFile is the on-disk form of the assignments
+type assignmentsFile struct {
+	Assignments []models.Assignment `json:"assignments"`
+}
+
+// AssignmentService keeps the challenges leads assign to users and teams and
+// tracks whether each assignee passed them in time.
+//
+// Assignments live in one JSON file in the data directory, rewritten on every
+// change. A challenge counts as passed by the first attempt whose tests all
+// passed, on any day; it is on time if that was no later than the due date.
+// Team assignments follow the team: whoever is a member now is an assignee.
+type AssignmentService struct {
+	path      string
+	teams     *TeamService
+	curricula *CurriculumService // resolves challenges the way curricula do
+	attempts  AttemptStore
+
+	mutex       sync.RWMutex
+	assignments map[string]models.Assignment
+}
+
+// NewAssignmentService creates an assignment service for the file at path.
+// Call Load before use.
+func NewAssignmentService(path string, teams *TeamService, curricula *CurriculumService, attempts AttemptStore) *AssignmentService {
+	return &AssignmentService{
+		path:        path,
+		teams:       teams,
+		curricula:   curricula,
+		attempts:    attempts,
+		assignments: make(map[string]models.Assignment),
+	}
+}
+
+// Load reads the assignments file. A missing file means there are none.
+func (as *AssignmentService) Load() error {
+	raw, err := os.ReadFile(as.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var file assignmentsFile
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return fmt.Errorf("%s: %v", filepath.Base(as.path), err)
+	}
+
+	assignments := make(map[string]models.Assignment, len(file.Assignments))
+	for _, a := range file.Assignments {
+		assignments[a.ID] = a
+	}
+	as.mutex.Lock()
+	as.assignments = assignments
+	as.mutex.Unlock()
+	return nil
+}
+
+// save writes the assignments file. The caller holds the write lock.
+func (as *AssignmentService) save() error {
+	file := assignmentsFile{Assignments: make([]models.Assignment, 0, len(as.assignments))}
+	for _, a := range as.assignments {
+		file.Assignments = append(file.Assignments, a)
+	}
+	sort.Slice(file.Assignments, func(i, j int) bool { return file.Assignments[i].ID < file.Assignments[j].ID })
+
+	raw, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(as.path), 0755); err != nil {
+		return fmt.Errorf("failed to create assignments directory: %v", err)
+	}
+	tmp := as.path + ".tmp"
+	if err := os.WriteFile(tmp, append(raw, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %v", as.path, err)
+	}
+	return os.Rename(tmp, as.path)
+}
+
+// Assignments returns every assignment, soonest due first
+func (as *AssignmentService) Assignments() []models.Assignment {
+	as.mutex.RLock()
+	defer as.mutex.RUnlock()
+	all := make([]models.Assignment, 0, len(as.assignments))
+	for _, a := range as.assignments {
+		all = append(all, a)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if !all[i].DueAt.Equal(all[j].DueAt) {
+			return all[i].DueAt.Before(all[j].DueAt)
+		}
+		return all[i].ID < all[j].ID
+	})
+	return all
+}
+
+// Assignment looks up an assignment by ID
+func (as *AssignmentService) Assignment(id string) (models.Assignment, bool) {
+	as.mutex.RLock()
+	defer as.mutex.RUnlock()
+	a, ok := as.assignments[id]
+	return a, ok
+}
+
+// ValidateAssignment tidies a new assignment and checks it names existing
+// challenges and teams and sensible dates. Its error is meant for the user.
+func (as *AssignmentService) ValidateAssignment(a *models.Assignment) error {
+	a.Title = strings.TrimSpace(a.Title)
+	a.Users = uniqueNames(a.Users)
+	a.Teams = uniqueNames(a.Teams)
+	for i := range a.Teams {
+		a.Teams[i] = strings.ToLower(a.Teams[i])
+	}
+	if a.Title == "" {
+		return fmt.Errorf("an assignment needs a title")
+	}
+	if len(a.Challenges) == 0 {
+		return fmt.Errorf("an assignment needs at least one challenge")
+	}
+	if len(a.Users) == 0 && len(a.Teams) == 0 {
+		return fmt.Errorf("an assignment needs at least one user or team")
+	}
+	if a.DueAt.IsZero() {
+		return fmt.Errorf("an assignment needs a due date")
+	}
+	if a.StartsAt.IsZero() {
+		a.StartsAt = time.Now()
+	}
+	if !a.DueAt.After(a.StartsAt) {
+		return fmt.Errorf("the due date must be after the start date")
+	}
+
+	seen := make(map[models.ChallengeRef]bool)
+	challenges := a.Challenges[:0]
+	for _, c := range a.Challenges {
+		c.ID = strings.Trim(c.ID, "/")
+		if c.Track == models.TrackRelease {
+			c.ID = strings.TrimPrefix(c.ID, "go")
+		}
+		if as.curricula.resolve(models.CurriculumEntry{Track: c.Track, ID: c.ID}).Missing {
+			return fmt.Errorf("there is no %s challenge %q", c.Track, c.ID)
+		}
+		if !seen[c] {
+			seen[c] = true
+			challenges = append(challenges, c)
+		}
+	}
+	a.Challenges = challenges
+
+	for _, name := range a.Teams {
+		if _, ok := as.teams.Team(name); !ok {
+			return fmt.Errorf("there is no team %q", name)
+		}
+	}
+	return nil
+}
+
+// LedTeams returns the teams username leads, and so may assign to
+func (as *AssignmentService) LedTeams(username string) []models.Team {
+	led := []models.Team{}
+	for _, team := range as.teams.Teams() {
+		if username != "" && containsName(team.Leads, username) {
+			led = append(led, team)
+		}
+	}
+	return led
+}
+
+// CanAssign reports whether username may assign to everyone the assignment
+// names: they must lead each team, and each user must be on a team they lead.
+func (as *AssignmentService) CanAssign(username string, a models.Assignment) bool {
+	led := as.LedTeams(username)
+	if len(led) == 0 {
+		return false
+	}
+	for _, name := range a.Teams {
+		if team, ok := as.teams.Team(name); !ok || !containsName(team.Leads, username) {
+			return false
+		}
+	}
+	for _, user := range a.Users {
+		onLedTeam := false
+		for _, team := range led {
+			onLedTeam = onLedTeam || containsName(team.Members, user)
+		}
+		if !onLedTeam {
+			return false
+		}
+	}
+	return true
+}
+
+// CanManage reports whether username may see an assignment's report and
+// delete it: its creator, or someone who could have assigned it
+func (as *AssignmentService) CanManage(username string, a models.Assignment) bool {
+	return username != "" && (strings.EqualFold(a.CreatedBy, username) || as.CanAssign(username, a))
+}
+
+// Create saves a validated assignment, giving it an ID
+func (as *AssignmentService) Create(a models.Assignment, createdBy string) (models.Assignment, error) {
+	a.ID = newAttemptID()
+	a.CreatedBy = createdBy
+	a.CreatedAt = time.Now()
+
+	as.mutex.Lock()
+	defer as.mutex.Unlock()
+	as.assignments[a.ID] = a
+	if err := as.save(); err != nil {
+		delete(as.assignments, a.ID)
+		return a, err
+	}
+	return a, nil
+}
+
+// Delete removes an assignment. It reports false if there was none.
+func (as *AssignmentService) Delete(id string) (bool, error) {
+	as.mutex.Lock()
+	defer as.mutex.Unlock()
+	a, ok := as.assignments[id]
+	if !ok {
+		return false, nil
+	}
+	delete(as.assignments, id)
+	if err := as.save(); err != nil {
+		as.assignments[id] = a
+		return true, err
+	}
+	return true, nil
+}
+
+// Assignees lists the assignment's users and its teams' current members
+func (as *AssignmentService) Assignees(a models.Assignment) []string {
+	names := append([]string{}, a.Users...)
+	for _, name := range a.Teams {
+		if team, ok := as.teams.Team(name); ok {
+			names = append(names, team.Members...)
+		}
+	}
+	names = uniqueNames(names)
+	sort.Strings(names)
+	return names
+}
+
+// Report works out every assignee's progress as of now
+func (as *AssignmentService) Report(a models.Assignment, now time.Time) *models.AssignmentReport {
+	report := &models.AssignmentReport{Assignment: a, Assignees: []models.AssigneeProgress{}, Counts: make(map[string]int)}
+	for _, username := range as.Assignees(a) {
+		progress := as.progress(a, username, now)
+		report.Assignees = append(report.Assignees, progress)
+		report.Counts[progress.Status]++
+	}
+	return report
+}
+
+// Inbox lists the assignments username is an assignee of, soonest due first,
+// with their progress
+func (as *AssignmentService) Inbox(username string, now time.Time) []models.AssignmentInboxItem {
+	inbox := []models.AssignmentInboxItem{}
+	for _, a := range as.Assignments() {
+		if containsName(as.Assignees(a), username) {
+			inbox = append(inbox, models.AssignmentInboxItem{Assignment: a, Progress: as.progress(a, username, now)})
+		}
+	}
+	return inbox
+}
+
+// Managed lists the assignments username may manage, soonest due first
+func (as *AssignmentService) Managed(username string) []models.Assignment {
+	managed := []models.Assignment{}
+	for _, a := range as.Assignments() {
+		if as.CanManage(username, a) {
+			managed = append(managed, a)
+		}
+	}
+	return managed
+}
+
+// StatusReport lists, across the assignments username manages, each assignee
+// whose status is one of statuses
+func (as *AssignmentService) StatusReport(username string, now time.Time, statuses ...string) []models.AssignmentReportRow {
+	rows := []models.AssignmentReportRow{}
+	for _, a := range as.Managed(username) {
+		for _, p := range as.Report(a, now).Assignees {
+			if !containsName(statuses, p.Status) {
+				continue
+			}
+			rows = append(rows, models.AssignmentReportRow{
+				AssignmentID: a.ID,
+				Title:        a.Title,
+				Username:     p.Username,
+				Status:       p.Status,
+				DueAt:        a.DueAt,
+				CompletedAt:  p.CompletedAt,
+				Passed:       p.Passed,
+				Total:        p.Total,
+			})
+		}
+	}
+	return rows
+}
+
+// progress works out one assignee's standing from their attempts. Attempts
+// made before the assignment starts do not count, so a challenge solved
+// beforehand has to be passed again.
+func (as *AssignmentService) progress(a models.Assignment, username string, now time.Time) models.AssigneeProgress {
+	statuses := make(map[models.ChallengeRef]*models.AssignedChallengeStatus, len(a.Challenges))
+	progress := models.AssigneeProgress{Username: username, Total: len(a.Challenges), Challenges: []models.AssignedChallengeStatus{}}
+	for _, c := range a.Challenges {
+		resolved := as.curricula.resolve(models.CurriculumEntry{Track: c.Track, ID: c.ID})
+		statuses[c] = &models.AssignedChallengeStatus{Track: c.Track, ID: c.ID, Title: resolved.Title, URL: resolved.URL}
+	}
+
+	for _, attempt := range as.attempts.UserAttempts(username) {
+		status, ok := statuses[models.ChallengeRef{Track: attempt.Track, ID: attempt.ChallengeID}]
+		if !ok || attempt.CreatedAt.Before(a.StartsAt) {
+			continue
+		}
+		status.Attempts++
+		if attempt.TestsPassed > status.TestsPassed || status.TestsTotal == 0 {
+			status.TestsPassed, status.TestsTotal = attempt.TestsPassed, attempt.TestsTotal
+		}
+		if attempt.Passed && attempt.TestsPassed == attempt.TestsTotal && status.PassedAt == nil {
+			at := attempt.CreatedAt
+			status.PassedAt = &at
+			status.OnTime = !at.After(a.DueAt)
+		}
+	}
+
+	for _, c := range a.Challenges {
+		status := statuses[c]
+		if status.PassedAt != nil {
+			progress.Passed++
+			if status.OnTime {
+				progress.OnTime++
+			}
+			if progress.CompletedAt == nil || status.PassedAt.After(*progress.CompletedAt) {
+				progress.CompletedAt = status.PassedAt
+			}
+		}
+		progress.Challenges = append(progress.Challenges, *status)
+	}
+	if progress.Passed < progress.Total {
+		progress.CompletedAt = nil
+	}
+
+	switch {
+	case progress.OnTime == progress.Total:
+		progress.Status = models.AssignmentCompleted
+	case progress.Passed == progress.Total:
+		progress.Status = models.AssignmentLate
+	case now.After(a.DueAt):
+		progress.Status = models.AssignmentOverdue
+	case now.Before(a.StartsAt):
+		progress.Status = models.AssignmentUpcoming
+	default:
+		progress.Status = models.AssignmentOpen
+	}
+	return progress
+}
+
+// containsName reports whether names contains name, ignoring case as GitHub does
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteAssignmentReportCSV writes an overdue or completed report, one row per
+// assignee of each assignment. Titles are written so spreadsheets do not run
+// them as formulas.
+func WriteAssignmentReportCSV(w io.Writer, rows []models.AssignmentReportRow) error {
+	out := csv.NewWriter(w)
+	out.Write([]string{"assignment", "title", "username", "status", "due_at", "completed_at", "passed", "total"})
+	for _, row := range rows {
+		completedAt := ""
+		if row.CompletedAt != nil {
+			completedAt = row.CompletedAt.UTC().Format(time.RFC3339)
+		}
+		out.Write([]string{
+			spreadsheetText(row.AssignmentID),
+			spreadsheetText(row.Title),
+			spreadsheetText(row.Username),
+			spreadsheetText(row.Status),
+			row.DueAt.UTC().Format(time.RFC3339),
+			completedAt,
+			strconv.Itoa(row.Passed),
+			strconv.Itoa(row.Total),
+		})
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// spreadsheetText quotes a CSV cell that a spreadsheet would otherwise read
+// as a formula, such as a title starting with "=", by prefixing it with '.
+// Every text cell of an export goes through it.
+func spreadsheetText(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
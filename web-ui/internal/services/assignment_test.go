@@ -0,0 +1,126 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"web-ui/internal/config"
+	"web-ui/internal/models"
+)
+
+func TestAssignmentProgressAndReports(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"challenge-1", "challenge-2"} {
+		os.MkdirAll(filepath.Join(root, dir), 0755)
+		os.WriteFile(filepath.Join(root, dir, "README.md"), []byte("# Challenge\n"), 0644)
+		os.WriteFile(filepath.Join(root, dir, "solution-template.go"), []byte("package main\n"), 0644)
+	}
+
+	cfg := config.Default()
+	cfg.Content.Root = root
+	cfg.Server.DataDir = t.TempDir()
+	challenges := NewChallengeService(cfg)
+	if err := challenges.LoadChallenges(); err != nil {
+		t.Fatal(err)
+	}
+	packages := NewPackageService(cfg)
+	releases := NewReleaseService(cfg, nil)
+	leaderboards := NewLeaderboardService(cfg, challenges, packages, nil)
+	store, _ := NewFileSubmissionStore(cfg.Server.DataDir)
+	attempts, _ := NewFileAttemptStore(cfg.Server.DataDir)
+	activity, _ := NewActivityService(cfg, attempts)
+	achievements, _ := NewAchievementService(cfg, challenges, packages, releases, leaderboards, activity, store, attempts)
+	profiles, err := NewProfileService(cfg, challenges, packages, releases, NewUserService(cfg),
+		leaderboards, NewBadgeService(leaderboards, achievements), achievements, activity, store, attempts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	curricula := NewCurriculumService(cfg, challenges, packages, releases, achievements, attempts)
+
+	teamsPath := filepath.Join(cfg.Server.DataDir, "teams.json")
+	os.WriteFile(teamsPath, []byte(`{"teams": [{"name": "backend", "members": ["Alice", "bob", "dave"], "leads": ["carol"]}]}`), 0644)
+	teams := NewTeamService(teamsPath, profiles, challenges, releases, leaderboards, attempts)
+	if err := teams.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(cfg.Server.DataDir, "assignments.json")
+	assignments := NewAssignmentService(path, teams, curricula, attempts)
+	due := time.Now().Add(-time.Hour)
+	assignment := models.Assignment{
+		Title:      "Warm-up",
+		Challenges: []models.ChallengeRef{{Track: models.TrackClassic, ID: "1"}, {Track: models.TrackClassic, ID: "2"}},
+		Teams:      []string{"Backend"},
+		StartsAt:   due.Add(-48 * time.Hour),
+		DueAt:      due,
+	}
+	if err := assignments.ValidateAssignment(&models.Assignment{Title: "Bad", Challenges: []models.ChallengeRef{{Track: models.TrackClassic, ID: "99"}}, Users: []string{"bob"}, DueAt: due}); err == nil {
+		t.Error("an assignment of a missing challenge was accepted")
+	}
+	if err := assignments.ValidateAssignment(&assignment); err != nil {
+		t.Fatal(err)
+	}
+	if !assignments.CanAssign("carol", assignment) || assignments.CanAssign("alice", assignment) ||
+		assignments.CanAssign("carol", models.Assignment{Users: []string{"mallory"}}) {
+		t.Error("only a team's leads may assign to it and its members")
+	}
+	created, err := assignments.Create(assignment, "carol")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// alice, listed as Alice, passes both on time, bob passes the second late,
+	// dave only tries. dave's pass from before the assignment started does not
+	// count.
+	pass := func(username, id string, at time.Time) {
+		attempt := NewAttempt(username, models.TrackClassic, id, "submit", "package main", true, "", 10)
+		attempt.CreatedAt = at
+		attempts.AddAttempt(attempt)
+	}
+	pass("alice", "1", due.Add(-3*time.Hour))
+	pass("alice", "2", due.Add(-2*time.Hour))
+	pass("bob", "1", due.Add(-2*time.Hour))
+	pass("bob", "2", due.Add(30*time.Minute))
+	attempts.AddAttempt(NewAttempt("dave", models.TrackClassic, "1", "run", "package main", false, "", 10))
+	pass("dave", "2", assignment.StartsAt.Add(-time.Hour))
+
+	// The report survives a reload
+	assignments = NewAssignmentService(path, teams, curricula, attempts)
+	if err := assignments.Load(); err != nil {
+		t.Fatal(err)
+	}
+	loaded, ok := assignments.Assignment(created.ID)
+	if !ok {
+		t.Fatalf("assignment %s was not saved", created.ID)
+	}
+	report := assignments.Report(loaded, time.Now())
+	if report.Counts[models.AssignmentCompleted] != 1 || report.Counts[models.AssignmentLate] != 1 || report.Counts[models.AssignmentOverdue] != 1 {
+		t.Errorf("counts = %v", report.Counts)
+	}
+
+	inbox := assignments.Inbox("dave", time.Now())
+	if len(inbox) != 1 || inbox[0].Progress.Status != models.AssignmentOverdue || inbox[0].Progress.Passed != 0 ||
+		inbox[0].Progress.Challenges[0].Attempts != 1 || inbox[0].Progress.Challenges[1].Attempts != 0 {
+		t.Errorf("dave's inbox = %+v", inbox)
+	}
+	if overdue := assignments.StatusReport("carol", time.Now(), models.AssignmentOverdue); len(overdue) != 1 || overdue[0].Username != "dave" {
+		t.Errorf("overdue report = %+v", overdue)
+	}
+	if completed := assignments.StatusReport("carol", time.Now(), models.AssignmentCompleted, models.AssignmentLate); len(completed) != 2 {
+		t.Errorf("completed report = %+v", completed)
+	}
+	if len(assignments.StatusReport("alice", time.Now(), models.AssignmentOverdue)) != 0 {
+		t.Error("an assignee saw the overdue report")
+	}
+
+	var csv strings.Builder
+	if err := WriteAssignmentReportCSV(&csv, []models.AssignmentReportRow{{AssignmentID: "a1", Title: "=HYPERLINK(\"http://x\")", Username: "dave", DueAt: due}}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(csv.String(), `"'=HYPERLINK(""http://x"")"`) {
+		t.Errorf("a formula title was not quoted:\n%s", csv.String())
+	}
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"web-ui/internal/models"
+)
+
+// AttemptStore keeps every run and submit a user makes, across all tracks.
+// Usernames are matched ignoring case, as GitHub logins are, so a user listed
+// on a team or assignment in other capitals still finds their attempts.
+// Implementations must be safe for concurrent handlers.
+type AttemptStore interface {
+	// AddAttempt assigns the attempt an ID and timestamp if it has none and stores it.
+	AddAttempt(attempt *models.Attempt) error
+	// GetAttempt returns a single attempt, including its code.
+	GetAttempt(id string) (*models.Attempt, bool)
+	// ListAttempts returns a user's attempts for one challenge, oldest first.
+	ListAttempts(username, track, challengeID string) []models.Attempt
+	// UserAttempts returns all of a user's attempts across every challenge, oldest first.
+	UserAttempts(username string) []models.Attempt
+}
+
+// FileAttemptStore is the embedded AttemptStore, a JSON-lines file under the
+// data directory. Attempts carry whole source files and test results, so only
+// whose attempt each one is is kept in memory; the attempts a lookup selects
+// are read from disk.
+type FileAttemptStore struct {
+	index *jsonlIndex[models.Attempt, attemptKey]
+}
+
+// attemptKey is what lookups select attempts by
+type attemptKey struct {
+	id          string
+	username    string // lower-cased
+	track       string
+	challengeID string
+	createdAt   time.Time
+}
+
+// NewFileAttemptStore opens (or creates) the attempt log in dataDir.
+func NewFileAttemptStore(dataDir string) (*FileAttemptStore, error) {
+	index, err := openJSONLIndex(filepath.Join(dataDir, "attempts.jsonl"), func(a models.Attempt) attemptKey {
+		return attemptKey{a.ID, strings.ToLower(a.Username), a.Track, a.ChallengeID, a.CreatedAt}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &FileAttemptStore{index: index}, nil
+}
+
+// AddAttempt stores an attempt
+func (s *FileAttemptStore) AddAttempt(attempt *models.Attempt) error {
+	if attempt.ID == "" {
+		attempt.ID = newAttemptID()
+	}
+	if attempt.CreatedAt.IsZero() {
+		attempt.CreatedAt = time.Now()
+	}
+	return s.index.Append(*attempt)
+}
+
+// GetAttempt looks up an attempt by ID
+func (s *FileAttemptStore) GetAttempt(id string) (*models.Attempt, bool) {
+	matches := s.index.Filter(func(key attemptKey) bool { return key.id == id })
+	if len(matches) == 0 {
+		return nil, false
+	}
+	records, err := s.index.Read(matches[:1])
+	if err != nil || len(records) == 0 {
+		log.Printf("attempts: %v", err)
+		return nil, false
+	}
+	return &records[0], true
+}
+
+// ListAttempts returns a user's attempts for a challenge, oldest first
+func (s *FileAttemptStore) ListAttempts(username, track, challengeID string) []models.Attempt {
+	username = strings.ToLower(username)
+	return s.oldestFirst(func(key attemptKey) bool {
+		return key.username == username && key.track == track && key.challengeID == challengeID
+	})
+}
+
+// UserAttempts returns all of a user's attempts, oldest first
+func (s *FileAttemptStore) UserAttempts(username string) []models.Attempt {
+	username = strings.ToLower(username)
+	return s.oldestFirst(func(key attemptKey) bool { return key.username == username })
+}
+
+// oldestFirst reads the attempts keep selects from disk, in the order they
+// were made
+func (s *FileAttemptStore) oldestFirst(keep func(attemptKey) bool) []models.Attempt {
+	matches := s.index.Filter(keep)
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].key.createdAt.Before(matches[j].key.createdAt)
+	})
+	records, err := s.index.Read(matches)
+	if err != nil {
+		log.Printf("attempts: %v", err)
+	}
+	return records
+}
+
+// newAttemptID returns a short, URL-safe, roughly time-ordered identifier
+func newAttemptID() string {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return fmt.Sprintf("%x-%s", time.Now().UnixMilli(), hex.EncodeToString(suffix))
+}
+
+// NewAttempt builds an attempt record from a test run, breaking the output down
+// into per-test results.
+func NewAttempt(username, track, challengeID, action, code string, passed bool, output string, executionMs int64) *models.Attempt {
+	attempt := &models.Attempt{
+		Username:    username,
+		Track:       track,
+		ChallengeID: challengeID,
+		Action:      action,
+		Code:        code,
+		Passed:      passed,
+		Tests:       ParseTestOutput(output),
+		ExecutionMs: executionMs,
+	}
+	for _, test := range attempt.Tests {
+		if test.Skipped {
+			continue
+		}
+		attempt.TestsTotal++
+		if test.Passed {
+			attempt.TestsPassed++
+		}
+	}
+	return attempt
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"web-ui/internal/models"
+)
+
+func TestFileAttemptStoreReadsAttemptsBackAfterReopen(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileAttemptStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	// Appended out of order, as concurrent runs finish
+	for _, a := range []models.Attempt{
+		{ID: "b", Username: "alice", Track: models.TrackClassic, ChallengeID: "1", Code: "two", CreatedAt: base.Add(2 * time.Hour)},
+		{ID: "a", Username: "alice", Track: models.TrackClassic, ChallengeID: "1", Code: "one", CreatedAt: base.Add(time.Hour)},
+		{ID: "c", Username: "alice", Track: models.TrackPackage, ChallengeID: "gin/challenge-1-basic-routing", CreatedAt: base},
+		{ID: "d", Username: "bob", Track: models.TrackClassic, ChallengeID: "1", CreatedAt: base},
+	} {
+		a := a
+		if err := store.AddAttempt(&a); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	store, err = NewFileAttemptStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, ok := store.GetAttempt("b")
+	if !ok || got.Code != "two" {
+		t.Fatalf("GetAttempt(b) = %+v, %v", got, ok)
+	}
+	if _, ok := store.GetAttempt("missing"); ok {
+		t.Fatal("found an attempt that was never stored")
+	}
+
+	list := store.ListAttempts("alice", models.TrackClassic, "1")
+	if len(list) != 2 || list[0].Code != "one" || list[1].Code != "two" {
+		t.Fatalf("ListAttempts: %+v, want one then two", list)
+	}
+
+	all := store.UserAttempts("alice")
+	if len(all) != 3 || all[0].ID != "c" || all[2].ID != "b" {
+		t.Fatalf("UserAttempts: %+v, want c, a, b", all)
+	}
+}
@@ -0,0 +1,308 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"sort"
+	"sync"
+	"text/template"
+	"time"
+
+	"web-ui/internal/models"
+)
+
+// BadgeStats is what a contributor's profile badges show
+type BadgeStats struct {
+	Username      string
+	Solved        int            // classic challenges completed
+	Total         int            // classic challenges available
+	PackageSolved map[string]int // package name -> challenges completed
+	Achievements  []models.EarnedAchievement
+}
+
+// achievement is the contributor's main leaderboard level
+func (s BadgeStats) achievement() achievementTier {
+	return achievementFor(classicAchievements, s.Solved)
+}
+
+// Rate is the classic completion rate, 0-100
+func (s BadgeStats) Rate() float64 {
+	if s.Total == 0 {
+		return 0
+	}
+	return float64(s.Solved) * 100 / float64(s.Total)
+}
+
+// PackageChallenges is the number of package challenges completed
+func (s BadgeStats) PackageChallenges() int {
+	count := 0
+	for _, solved := range s.PackageSolved {
+		count += solved
+	}
+	return count
+}
+
+// badgeScheme colours a badge by achievement level
+type badgeScheme struct {
+	Shields   string // shields.io colour name or hex
+	Primary   string
+	Secondary string
+	Accent    string
+}
+
+// badgeSchemes maps each classic achievement level to its colours
+var badgeSchemes = map[string]badgeScheme{
+	"Master":       {"gold", "#FFD700", "#FFA500", "#FF8C00"},
+	"Expert":       {"blue", "#4A90E2", "#357ABD", "#2E5F87"},
+	"Advanced":     {"orange", "#FF8C42", "#FF6B1A", "#E55A00"},
+	"Intermediate": {"yellowgreen", "#A4C639", "#8DB600", "#6B8E23"},
+	"Beginner":     {"97ca00", "#97CA00", "#7BA428", "#5F7E1F"},
+}
+
+// BadgeService renders contributor profile badges from the leaderboards, so a
+// badge always agrees with the site.
+type BadgeService struct {
+	leaderboards *LeaderboardService
+	achievements *AchievementService
+
+	mutex    sync.Mutex
+	stats    map[string]BadgeStats
+	loadedAt time.Time
+}
+
+// badgeStatsTTL bounds how stale a served badge can be. Badges are embedded in
+// profile READMEs and fetched far more often than scoreboards change.
+const badgeStatsTTL = time.Minute
+
+// NewBadgeService creates a new badge service
+func NewBadgeService(leaderboards *LeaderboardService, achievements *AchievementService) *BadgeService {
+	return &BadgeService{leaderboards: leaderboards, achievements: achievements}
+}
+
+// Stats returns a contributor's badge stats. Users who have neither completed
+// a challenge nor earned an achievement have no badge.
+func (bs *BadgeService) Stats(username string) (BadgeStats, bool) {
+	stats, ok := bs.snapshot()[username]
+	return stats, ok
+}
+
+// AllStats returns the stats of every contributor, sorted by username
+func (bs *BadgeService) AllStats() []BadgeStats {
+	snapshot := bs.snapshot()
+	all := make([]BadgeStats, 0, len(snapshot))
+	for _, stats := range snapshot {
+		all = append(all, stats)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Username < all[j].Username })
+	return all
+}
+
+// snapshot returns the cached stats, recomputing them once they expire
+func (bs *BadgeService) snapshot() map[string]BadgeStats {
+	bs.mutex.Lock()
+	defer bs.mutex.Unlock()
+
+	if bs.stats == nil || time.Since(bs.loadedAt) > badgeStatsTTL {
+		bs.stats = bs.collect()
+		bs.loadedAt = time.Now()
+	}
+	return bs.stats
+}
+
+// collect reads every scoreboard into per-contributor stats
+func (bs *BadgeService) collect() map[string]BadgeStats {
+	ls := bs.leaderboards
+	total := len(ls.challengeService.GetChallenges())
+	stats := make(map[string]BadgeStats)
+
+	contributor := func(username string) BadgeStats {
+		s, ok := stats[username]
+		if !ok {
+			s = BadgeStats{Username: username, Total: total, PackageSolved: make(map[string]int)}
+		}
+		return s
+	}
+
+	for username, completed := range ls.MainCompletions() {
+		s := contributor(username)
+		s.Solved = len(completed)
+		stats[username] = s
+	}
+
+	for name := range ls.packageService.GetPackages() {
+		challenges, err := ls.PackageChallenges(name)
+		if err != nil {
+			continue
+		}
+		for username, completed := range ls.PackageCompletions(name, challenges) {
+			s := contributor(username)
+			s.PackageSolved[name] = len(completed)
+			stats[username] = s
+		}
+	}
+
+	for username, earned := range bs.achievements.EarnedByUser() {
+		s := contributor(username)
+		s.Achievements = earned
+		stats[username] = s
+	}
+
+	return stats
+}
+
+// shieldsBadge is the shields.io endpoint badge schema
+type shieldsBadge struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Label         string `json:"label"`
+	Message       string `json:"message"`
+	Color         string `json:"color"`
+	Style         string `json:"style"`
+}
+
+// ShieldsJSON renders the badge for https://img.shields.io/endpoint
+func (s BadgeStats) ShieldsJSON() []byte {
+	tier := s.achievement()
+	raw, _ := json.MarshalIndent(shieldsBadge{
+		SchemaVersion: 1,
+		Label:         "Go Interview Practice",
+		Message:       fmt.Sprintf("%s %s (%d/%d)", tier.icon, tier.name, s.Solved, s.Total),
+		Color:         badgeSchemes[tier.name].Shields,
+		Style:         "for-the-badge",
+	}, "", "  ")
+	return raw
+}
+
+// AchievementsJSON lists the contributor's achievements, newest first
+func (s BadgeStats) AchievementsJSON() []byte {
+	achievements := s.Achievements
+	if achievements == nil {
+		achievements = []models.EarnedAchievement{}
+	}
+	raw, _ := json.MarshalIndent(struct {
+		Username     string                     `json:"username"`
+		Achievements []models.EarnedAchievement `json:"achievements"`
+	}{s.Username, achievements}, "", "  ")
+	return raw
+}
+
+// CardSVG renders the full-size 350×120 profile card
+func (s BadgeStats) CardSVG() []byte {
+	return s.render(cardTemplate, 140)
+}
+
+// CompactSVG renders the 400×60 horizontal badge
+func (s BadgeStats) CompactSVG() []byte {
+	return s.render(compactTemplate, 100)
+}
+
+// render fills a badge template; barWidth is the full width of its progress bar
+func (s BadgeStats) render(tmpl *template.Template, barWidth int) []byte {
+	tier := s.achievement()
+	progress := 0
+	if s.Total > 0 {
+		progress = s.Solved * barWidth / s.Total
+	}
+
+	var out bytes.Buffer
+	tmpl.Execute(&out, map[string]interface{}{
+		"Username":          html.EscapeString(s.Username),
+		"Level":             tier.name,
+		"Icon":              tier.icon,
+		"Scheme":            badgeSchemes[tier.name],
+		"Solved":            s.Solved,
+		"Total":             s.Total,
+		"Rate":              fmt.Sprintf("%.1f", s.Rate()),
+		"Progress":          progress,
+		"Packages":          len(s.PackageSolved),
+		"PackageChallenges": s.PackageChallenges(),
+		"Master":            tier.name == "Master",
+		"Achievements":      len(s.Achievements),
+	})
+	return out.Bytes()
+}
+
+var cardTemplate = template.Must(template.New("card").Parse(`<svg width="350" height="120" xmlns="http://www.w3.org/2000/svg">
+  <defs>
+    <linearGradient id="cardGradient" x1="0%" y1="0%" x2="100%" y2="100%">
+      <stop offset="0%" style="stop-color:#f8f9fa;stop-opacity:1" />
+      <stop offset="100%" style="stop-color:#e9ecef;stop-opacity:1" />
+    </linearGradient>
+    <linearGradient id="headerGradient" x1="0%" y1="0%" x2="100%" y2="0%">
+      <stop offset="0%" style="stop-color:{{.Scheme.Primary}};stop-opacity:1" />
+      <stop offset="100%" style="stop-color:{{.Scheme.Secondary}};stop-opacity:1" />
+    </linearGradient>
+    <linearGradient id="progressGradient" x1="0%" y1="0%" x2="100%" y2="0%">
+      <stop offset="0%" style="stop-color:{{.Scheme.Accent}};stop-opacity:1" />
+      <stop offset="100%" style="stop-color:{{.Scheme.Primary}};stop-opacity:1" />
+    </linearGradient>
+    <filter id="dropshadow" x="-20%" y="-20%" width="140%" height="140%">
+      <feDropShadow dx="2" dy="2" stdDeviation="3" flood-color="#00000020"/>
+    </filter>
+  </defs>
+
+  <rect width="350" height="120" fill="url(#cardGradient)" rx="12" filter="url(#dropshadow)"/>
+  <rect width="350" height="35" fill="url(#headerGradient)" rx="12"/>
+  <rect width="350" height="25" fill="url(#headerGradient)"/>
+
+  <text x="15" y="15" font-family="SF Pro Display,-apple-system,BlinkMacSystemFont,Segoe UI,sans-serif" font-size="10" font-weight="600" fill="white" opacity="0.9">GO INTERVIEW PRACTICE</text>
+  <text x="15" y="27" font-family="SF Pro Display,-apple-system,BlinkMacSystemFont,Segoe UI,sans-serif" font-size="8" fill="white" opacity="0.8">github.com/RezaSi/go-interview-practice</text>
+  <text x="320" y="22" font-family="SF Pro Display,-apple-system,BlinkMacSystemFont,Segoe UI,sans-serif" font-size="16" text-anchor="middle" fill="white">{{.Icon}}</text>
+
+  <text x="15" y="58" font-family="SF Pro Display,-apple-system,BlinkMacSystemFont,Segoe UI,sans-serif" font-size="14" font-weight="700" fill="#212529">@{{.Username}}</text>
+  <text x="15" y="75" font-family="SF Pro Display,-apple-system,BlinkMacSystemFont,Segoe UI,sans-serif" font-size="12" font-weight="600" fill="{{.Scheme.Primary}}">{{.Icon}} {{.Level}} Developer</text>
+
+  <text x="15" y="95" font-family="SF Pro Display,-apple-system,BlinkMacSystemFont,Segoe UI,sans-serif" font-size="10" font-weight="500" fill="#6c757d">Classic Challenges</text>
+  <rect x="15" y="100" width="140" height="6" fill="#e9ecef" rx="3"/>
+  <rect x="15" y="100" width="{{.Progress}}" height="6" fill="url(#progressGradient)" rx="3"/>
+  <text x="160" y="106" font-family="SF Pro Display,-apple-system,BlinkMacSystemFont,Segoe UI,sans-serif" font-size="9" font-weight="600" fill="#495057">{{.Solved}}/{{.Total}} ({{.Rate}}%)</text>
+{{if .Packages}}
+  <text x="190" y="58" font-family="SF Pro Display,-apple-system,BlinkMacSystemFont,Segoe UI,sans-serif" font-size="10" font-weight="500" fill="#6c757d">Package Challenges</text>
+  <text x="190" y="72" font-family="SF Pro Display,-apple-system,BlinkMacSystemFont,Segoe UI,sans-serif" font-size="12" font-weight="600" fill="{{.Scheme.Secondary}}">{{.PackageChallenges}} across {{.Packages}} packages</text>
+  <circle cx="195" cy="82" r="3" fill="{{.Scheme.Primary}}" opacity="0.8"/>
+  <circle cx="205" cy="82" r="3" fill="{{.Scheme.Secondary}}" opacity="0.8"/>
+  <circle cx="215" cy="82" r="3" fill="{{.Scheme.Accent}}" opacity="0.8"/>
+{{else}}
+  <text x="190" y="65" font-family="SF Pro Display,-apple-system,BlinkMacSystemFont,Segoe UI,sans-serif" font-size="10" font-weight="500" fill="#6c757d">Ready for</text>
+  <text x="190" y="78" font-family="SF Pro Display,-apple-system,BlinkMacSystemFont,Segoe UI,sans-serif" font-size="12" font-weight="600" fill="{{.Scheme.Secondary}}">Package Challenges!</text>
+{{end}}{{if .Achievements}}
+  <text x="335" y="106" font-family="SF Pro Display,-apple-system,BlinkMacSystemFont,Segoe UI,sans-serif" font-size="9" font-weight="600" text-anchor="end" fill="{{.Scheme.Secondary}}">🏅 {{.Achievements}} achievement{{if gt .Achievements 1}}s{{end}}</text>
+{{end}}{{if .Master}}
+  <circle cx="320" cy="85" r="8" fill="{{.Scheme.Primary}}" opacity="0.2"/>
+  <text x="320" y="89" font-family="SF Pro Display,-apple-system,BlinkMacSystemFont,Segoe UI,sans-serif" font-size="10" text-anchor="middle" fill="{{.Scheme.Primary}}" font-weight="700">★</text>
+{{end}}</svg>
+`))
+
+var compactTemplate = template.Must(template.New("compact").Parse(`<svg width="400" height="60" xmlns="http://www.w3.org/2000/svg">
+  <defs>
+    <linearGradient id="compactGradient" x1="0%" y1="0%" x2="100%" y2="0%">
+      <stop offset="0%" style="stop-color:{{.Scheme.Primary}};stop-opacity:1" />
+      <stop offset="100%" style="stop-color:{{.Scheme.Secondary}};stop-opacity:1" />
+    </linearGradient>
+    <linearGradient id="compactBg" x1="0%" y1="0%" x2="0%" y2="100%">
+      <stop offset="0%" style="stop-color:#ffffff;stop-opacity:1" />
+      <stop offset="100%" style="stop-color:#f8f9fa;stop-opacity:1" />
+    </linearGradient>
+    <filter id="shadow" x="-10%" y="-10%" width="120%" height="120%">
+      <feDropShadow dx="1" dy="1" stdDeviation="2" flood-color="#00000015"/>
+    </filter>
+  </defs>
+
+  <rect width="400" height="60" fill="url(#compactBg)" rx="8" filter="url(#shadow)"/>
+  <rect width="6" height="60" fill="url(#compactGradient)" rx="8"/>
+
+  <text x="20" y="20" font-family="-apple-system,BlinkMacSystemFont,Segoe UI,sans-serif" font-size="12" font-weight="700" fill="#212529">🐹 Go Interview Practice</text>
+  <text x="20" y="38" font-family="-apple-system,BlinkMacSystemFont,Segoe UI,sans-serif" font-size="11" fill="#6c757d">@{{.Username}}</text>
+  <text x="20" y="52" font-family="-apple-system,BlinkMacSystemFont,Segoe UI,sans-serif" font-size="10" font-weight="600" fill="{{.Scheme.Primary}}">{{.Icon}} {{.Level}} Developer</text>
+
+  <text x="220" y="20" font-family="-apple-system,BlinkMacSystemFont,Segoe UI,sans-serif" font-size="10" font-weight="500" fill="#495057">Classic Progress</text>
+  <rect x="220" y="25" width="100" height="4" fill="#e9ecef" rx="2"/>
+  <rect x="220" y="25" width="{{.Progress}}" height="4" fill="url(#compactGradient)" rx="2"/>
+  <text x="220" y="42" font-family="-apple-system,BlinkMacSystemFont,Segoe UI,sans-serif" font-size="10" font-weight="600" fill="#495057">{{.Solved}}/{{.Total}} ({{.Rate}}%)</text>
+{{if .Packages}}  <text x="220" y="54" font-family="-apple-system,BlinkMacSystemFont,Segoe UI,sans-serif" font-size="9" fill="{{.Scheme.Secondary}}">📦 {{.PackageChallenges}} package challenges</text>
+{{end}}
+  <circle cx="365" cy="30" r="12" fill="{{.Scheme.Primary}}" opacity="0.1"/>
+  <text x="365" y="34" font-family="-apple-system,BlinkMacSystemFont,Segoe UI,sans-serif" font-size="12" text-anchor="middle" fill="{{.Scheme.Primary}}">{{.Icon}}</text>
+</svg>
+`))
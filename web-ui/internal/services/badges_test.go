@@ -0,0 +1,31 @@
+package services
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestBadgesFollowLeaderboardTiers(t *testing.T) {
+	stats := BadgeStats{Username: "alice", Solved: 15, Total: 30, PackageSolved: map[string]int{"gin": 2, "cobra": 1}}
+
+	var badge shieldsBadge
+	if err := json.Unmarshal(stats.ShieldsJSON(), &badge); err != nil {
+		t.Fatal(err)
+	}
+	if badge.Message != "⭐ Expert (15/30)" || badge.Color != "blue" {
+		t.Errorf("shields badge = %+v", badge)
+	}
+
+	card := string(stats.CardSVG())
+	for _, want := range []string{"@alice", "⭐ Expert Developer", "15/30 (50.0%)", `width="70"`, "3 across 2 packages"} {
+		if !strings.Contains(card, want) {
+			t.Errorf("card is missing %q", want)
+		}
+	}
+
+	stats.Username = `<script>`
+	if compact := string(stats.CompactSVG()); strings.Contains(compact, "<script>") {
+		t.Error("username is not escaped")
+	}
+}
@@ -1,37 +1,51 @@
 package services
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
+	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 
+	"web-ui/internal/config"
 	"web-ui/internal/models"
 )
 
 // ChallengeService handles challenge-related operations
 type ChallengeService struct {
-	challenges models.ChallengeMap
+	cfg        *config.Config
+	mutex      sync.RWMutex
+	challenges models.ChallengeMap // replaced wholesale on reload, never modified in place
 }
 
 // NewChallengeService creates a new challenge service
-func NewChallengeService() *ChallengeService {
+func NewChallengeService(cfg *config.Config) *ChallengeService {
 	return &ChallengeService{
+		cfg:        cfg,
 		challenges: make(models.ChallengeMap),
 	}
 }
 
-// LoadChallenges loads all challenges from the filesystem
+// LoadChallenges loads all challenges from the filesystem and swaps them in at
+// once. A challenge that fails to load keeps its previously loaded version, and
+// the failures are returned together.
 func (cs *ChallengeService) LoadChallenges() error {
 	// Find challenge directories (challenge-1, challenge-2, etc.)
-	challengeDirs, err := filepath.Glob("../challenge-*")
+	challengeDirs, err := filepath.Glob(filepath.Join(cs.cfg.Content.Root, "challenge-*"))
 	if err != nil {
 		return fmt.Errorf("failed to find challenge directories: %v", err)
 	}
 
+	previous := cs.GetChallenges()
+	challenges := make(models.ChallengeMap, len(challengeDirs))
+	var errs []error
 	for _, dir := range challengeDirs {
 		// Extract challenge number
 		re := regexp.MustCompile(`challenge-(\d+)`)
@@ -48,14 +62,22 @@ func (cs *ChallengeService) LoadChallenges() error {
 		challenge, err := cs.loadSingleChallenge(id, dir)
 		if err != nil {
 			log.Printf("Warning: Could not load challenge %d: %v", id, err)
+			errs = append(errs, fmt.Errorf("challenge %d: %v", id, err))
+			if old, ok := previous[id]; ok {
+				challenges[id] = old
+			}
 			continue
 		}
 
-		cs.challenges[id] = challenge
+		challenges[id] = challenge
 	}
 
-	log.Printf("Loaded %d challenges", len(cs.challenges))
-	return nil
+	cs.mutex.Lock()
+	cs.challenges = challenges
+	cs.mutex.Unlock()
+
+	log.Printf("Loaded %d challenges", len(challenges))
+	return errors.Join(errs...)
 }
 
 // loadSingleChallenge loads a single challenge from a directory
@@ -67,11 +89,10 @@ func (cs *ChallengeService) loadSingleChallenge(id int, dir string) (*models.Cha
 		return nil, fmt.Errorf("could not read README: %v", err)
 	}
 
-	// Extract title from README (first heading)
-	title := cs.extractTitle(string(readmeContent), id)
-
-	// Determine difficulty level
-	difficulty := cs.determineDifficulty(id)
+	metadata, err := readChallengeMetadata(dir)
+	if err != nil {
+		return nil, err
+	}
 
 	// Read solution template
 	templatePath := filepath.Join(dir, "solution-template.go")
@@ -103,19 +124,61 @@ func (cs *ChallengeService) loadSingleChallenge(id int, dir string) (*models.Cha
 
 	// Create challenge
 	challenge := &models.Challenge{
-		ID:                id,
-		Title:             title,
-		Description:       cs.filterWebUIDescription(string(readmeContent)),
-		Difficulty:        difficulty,
-		Template:          string(templateContent),
-		TestFile:          string(testContent),
-		LearningMaterials: string(learningContent),
-		Hints:             string(hintsContent),
+		ID:                  id,
+		Description:         cs.filterWebUIDescription(string(readmeContent)),
+		Template:            string(templateContent),
+		TestFile:            string(testContent),
+		LearningMaterials:   string(learningContent),
+		Hints:               string(hintsContent),
+		Title:               metadata.Title,
+		Difficulty:          metadata.Difficulty,
+		ShortDescription:    metadata.ShortDescription,
+		EstimatedTime:       metadata.EstimatedTime,
+		LearningObjectives:  metadata.LearningObjectives,
+		Prerequisites:       metadata.Prerequisites,
+		Tags:                metadata.Tags,
+		RealWorldConnection: metadata.RealWorldConnection,
+		Requirements:        metadata.Requirements,
+		BonusPoints:         metadata.BonusPoints,
+		Icon:                metadata.Icon,
+		Order:               metadata.Order,
+	}
+
+	// Challenges without metadata.json (or with a partial one) fall back to the
+	// README heading, the built-in difficulty table and their number.
+	if challenge.Title == "" {
+		challenge.Title = cs.extractTitle(string(readmeContent), id)
+	}
+	if challenge.Difficulty == "" {
+		challenge.Difficulty = cs.determineDifficulty(id)
+	}
+	if challenge.ShortDescription == "" {
+		challenge.ShortDescription = metadata.Description
+	}
+	if challenge.Order == 0 {
+		challenge.Order = id
 	}
 
 	return challenge, nil
 }
 
+// readChallengeMetadata reads dir/metadata.json. A missing file yields empty
+// metadata; a malformed one is an error, so a typo does not silently drop it.
+func readChallengeMetadata(dir string) (*models.ChallengeMetadata, error) {
+	var metadata models.ChallengeMetadata
+	content, err := os.ReadFile(filepath.Join(dir, "metadata.json"))
+	if os.IsNotExist(err) {
+		return &metadata, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, &metadata); err != nil {
+		return nil, fmt.Errorf("metadata.json: %v", err)
+	}
+	return &metadata, nil
+}
+
 // extractTitle extracts the title from README content
 func (cs *ChallengeService) extractTitle(readmeContent string, id int) string {
 	titleRe := regexp.MustCompile(`#\s+(.+)`)
@@ -131,7 +194,8 @@ func (cs *ChallengeService) extractTitle(readmeContent string, id int) string {
 	return fmt.Sprintf("Challenge %d", id)
 }
 
-// determineDifficulty determines the difficulty level based on challenge ID
+// determineDifficulty is the difficulty of challenges that do not set one in
+// metadata.json
 func (cs *ChallengeService) determineDifficulty(id int) string {
 	switch {
 	case id <= 3 || id == 6 || id == 18 || id == 21 || id == 22:
@@ -179,13 +243,32 @@ func (cs *ChallengeService) filterWebUIDescription(content string) string {
 	return strings.Join(filteredLines, "\n")
 }
 
-// GetChallenges returns all challenges
+// GetChallenges returns all challenges. The map must not be modified.
 func (cs *ChallengeService) GetChallenges() models.ChallengeMap {
+	cs.mutex.RLock()
+	defer cs.mutex.RUnlock()
 	return cs.challenges
 }
 
+// ListChallenges returns the challenges in list order: by their metadata
+// order, then by number
+func (cs *ChallengeService) ListChallenges() []*models.Challenge {
+	challenges := cs.GetChallenges()
+	list := make([]*models.Challenge, 0, len(challenges))
+	for _, challenge := range challenges {
+		list = append(list, challenge)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Order != list[j].Order {
+			return list[i].Order < list[j].Order
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
+
 // GetChallenge returns a specific challenge by ID
 func (cs *ChallengeService) GetChallenge(id int) (*models.Challenge, bool) {
-	challenge, exists := cs.challenges[id]
+	challenge, exists := cs.GetChallenges()[id]
 	return challenge, exists
 }
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"web-ui/internal/config"
+)
+
+func TestChallengeMetadataOverridesHeuristics(t *testing.T) {
+	root := t.TempDir()
+	write := func(dir, name, content string) {
+		os.MkdirAll(filepath.Join(root, dir), 0755)
+		if err := os.WriteFile(filepath.Join(root, dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, dir := range []string{"challenge-1", "challenge-2"} {
+		write(dir, "README.md", "# Challenge: From the README\n")
+		write(dir, "solution-template.go", "package main\n")
+	}
+	write("challenge-2", "metadata.json", `{
+		"title": "From metadata",
+		"difficulty": "Advanced",
+		"tags": ["maps"],
+		"learning_objectives": ["Count things"],
+		"order": 0
+	}`)
+
+	cfg := config.Default()
+	cfg.Content.Root = root
+	cs := NewChallengeService(cfg)
+	if err := cs.LoadChallenges(); err != nil {
+		t.Fatal(err)
+	}
+
+	// No metadata.json: README title, difficulty table, numbered order
+	plain, _ := cs.GetChallenge(1)
+	if plain.Title != "Challenge: From the README" || plain.Difficulty != "Beginner" || plain.Order != 1 {
+		t.Errorf("challenge 1 = %q, %q, order %d", plain.Title, plain.Difficulty, plain.Order)
+	}
+
+	described, _ := cs.GetChallenge(2)
+	if described.Title != "From metadata" || described.Difficulty != "Advanced" {
+		t.Errorf("challenge 2 = %q, %q; metadata.json was ignored", described.Title, described.Difficulty)
+	}
+	if len(described.Tags) != 1 || len(described.LearningObjectives) != 1 || described.Order != 2 {
+		t.Errorf("challenge 2 tags %v, objectives %v, order %d", described.Tags, described.LearningObjectives, described.Order)
+	}
+
+	// A broken metadata.json fails the reload and keeps the loaded version
+	write("challenge-2", "metadata.json", `{"title": `)
+	if err := cs.LoadChallenges(); err == nil {
+		t.Error("malformed metadata.json was accepted")
+	}
+	if kept, _ := cs.GetChallenge(2); kept.Title != "From metadata" {
+		t.Errorf("challenge 2 after a failed reload = %q", kept.Title)
+	}
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"log"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ContentWatcher reloads services when the content they were loaded from
+// changes on disk, so new challenges, edited hints and merged submissions show
+// up without a restart.
+//
+// It polls rather than using inotify: the content trees are a few thousand
+// files, stat-ing them is cheap, and polling behaves the same on every
+// platform and on network mounts.
+type ContentWatcher struct {
+	root    string
+	sources []*contentSource
+
+	mutex sync.Mutex // serializes reloads
+	last  ReloadReport
+}
+
+// contentSource is a set of paths and the reload that depends on them
+type contentSource struct {
+	name        string
+	patterns    []string // globs relative to the repository root
+	reload      func() error
+	fingerprint uint64
+}
+
+// ReloadReport is the outcome of a reload
+type ReloadReport struct {
+	At       time.Time         `json:"at"`
+	Reloaded []string          `json:"reloaded"`
+	Errors   map[string]string `json:"errors,omitempty"`
+}
+
+// NewContentWatcher creates a watcher for content under the repository at root
+func NewContentWatcher(root string) *ContentWatcher {
+	return &ContentWatcher{root: root}
+}
+
+// Watch registers a reload to run whenever files matching patterns change.
+// The content is assumed to be loaded already.
+func (w *ContentWatcher) Watch(name string, patterns []string, reload func() error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	source := &contentSource{name: name, patterns: patterns, reload: reload}
+	source.fingerprint = w.fingerprint(source)
+	w.sources = append(w.sources, source)
+}
+
+// Start polls for changes every interval until the process exits
+func (w *ContentWatcher) Start(interval time.Duration) {
+	go func() {
+		for range time.Tick(interval) {
+			w.Poll()
+		}
+	}()
+	log.Printf("Watching content for changes every %s", interval)
+}
+
+// Poll reloads the sources whose files changed since they were last loaded
+func (w *ContentWatcher) Poll() ReloadReport {
+	return w.reload(false)
+}
+
+// ReloadAll reloads every source, changed or not
+func (w *ContentWatcher) ReloadAll() ReloadReport {
+	return w.reload(true)
+}
+
+// LastReport returns the outcome of the most recent reload
+func (w *ContentWatcher) LastReport() ReloadReport {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.last
+}
+
+func (w *ContentWatcher) reload(force bool) ReloadReport {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	report := ReloadReport{At: time.Now(), Reloaded: []string{}}
+	for _, source := range w.sources {
+		// Fingerprint before reloading: a change made during the reload is
+		// picked up by the next poll.
+		fingerprint := w.fingerprint(source)
+		if !force && fingerprint == source.fingerprint {
+			continue
+		}
+		source.fingerprint = fingerprint
+
+		report.Reloaded = append(report.Reloaded, source.name)
+		if err := source.reload(); err != nil {
+			if report.Errors == nil {
+				report.Errors = make(map[string]string)
+			}
+			report.Errors[source.name] = err.Error()
+			log.Printf("Reloading %s: %v", source.name, err)
+		}
+	}
+
+	if force || len(report.Reloaded) > 0 {
+		log.Printf("Reloaded content: %v", report.Reloaded)
+		w.last = report
+	}
+	return report
+}
+
+// fingerprint hashes the path, size and modification time of every file the
+// source covers
+func (w *ContentWatcher) fingerprint(source *contentSource) uint64 {
+	var paths []string
+	for _, pattern := range source.patterns {
+		matches, _ := filepath.Glob(filepath.Join(w.root, pattern))
+		paths = append(paths, matches...)
+	}
+	sort.Strings(paths)
+
+	hash := fnv.New64a()
+	for _, root := range paths {
+		filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			fmt.Fprintf(hash, "%s\x00%d\x00%d\n", path, info.Size(), info.ModTime().UnixNano())
+			return nil
+		})
+	}
+	return hash.Sum64()
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestContentWatcherReloadsChangedSources(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"challenge-1", "releases"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	hints := filepath.Join(root, "challenge-1", "hints.md")
+	os.WriteFile(hints, []byte("one"), 0644)
+
+	reloads := map[string]int{}
+	var releaseErr error
+	watcher := NewContentWatcher(root)
+	watcher.Watch("challenges", []string{"challenge-*"}, func() error { reloads["challenges"]++; return nil })
+	watcher.Watch("releases", []string{"releases"}, func() error { reloads["releases"]++; return releaseErr })
+
+	if report := watcher.Poll(); len(report.Reloaded) != 0 {
+		t.Fatalf("nothing changed, but reloaded %v", report.Reloaded)
+	}
+
+	os.WriteFile(hints, []byte("two, edited"), 0644)
+	report := watcher.Poll()
+	if len(report.Reloaded) != 1 || report.Reloaded[0] != "challenges" || reloads["releases"] != 0 {
+		t.Fatalf("after editing hints: reloaded %v", report.Reloaded)
+	}
+
+	// Same size and content, new modification time
+	os.Chtimes(hints, time.Now(), time.Now().Add(time.Hour))
+	if report := watcher.Poll(); len(report.Reloaded) != 1 {
+		t.Fatalf("after touching hints: reloaded %v", report.Reloaded)
+	}
+
+	releaseErr = errors.New("bad release.json")
+	report = watcher.ReloadAll()
+	if len(report.Reloaded) != 2 || report.Errors["releases"] != "bad release.json" {
+		t.Fatalf("forced reload = %+v", report)
+	}
+	if last := watcher.LastReport(); last.Errors["releases"] == "" {
+		t.Errorf("last report lost the error: %+v", last)
+	}
+}
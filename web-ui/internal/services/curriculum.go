@@ -0,0 +1,290 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"web-ui/internal/config"
+	"web-ui/internal/models"
+)
+
+// curriculumNamePattern is what curriculum file names, which appear in URLs,
+// may look like
+var curriculumNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+// CurriculumService loads the curricula in the repository's curricula/
+// directory and works out each user's progress through them.
+//
+// A curriculum can mix the three tracks, so progress is counted the way
+// achievements count solved challenges. Entries are resolved against the
+// content on every request, so a curriculum keeps up with content reloads.
+type CurriculumService struct {
+	cfg              *config.Config
+	challengeService *ChallengeService
+	packageService   *PackageService
+	releaseService   *ReleaseService
+	achievements     *AchievementService
+	attempts         AttemptStore
+
+	mutex     sync.RWMutex
+	curricula []models.Curriculum // by title; replaced wholesale by Load
+}
+
+func NewCurriculumService(
+	cfg *config.Config,
+	challengeService *ChallengeService,
+	packageService *PackageService,
+	releaseService *ReleaseService,
+	achievements *AchievementService,
+	attempts AttemptStore,
+) *CurriculumService {
+	return &CurriculumService{
+		cfg:              cfg,
+		challengeService: challengeService,
+		packageService:   packageService,
+		releaseService:   releaseService,
+		achievements:     achievements,
+		attempts:         attempts,
+		curricula:        []models.Curriculum{},
+	}
+}
+
+// Load reads every curriculum and swaps them in at once. Broken files are
+// skipped, and entries naming challenges that do not exist are kept but
+// reported; all problems are logged and returned together.
+func (cs *CurriculumService) Load() error {
+	dir := cs.cfg.CurriculaDir()
+	files, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	var errs []error
+	curricula := []models.Curriculum{}
+	for _, f := range files {
+		name, ok := strings.CutSuffix(f.Name(), ".json")
+		if f.IsDir() || !ok {
+			continue
+		}
+		curriculum, err := cs.loadCurriculum(filepath.Join(dir, f.Name()), name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("curricula/%s: %v", f.Name(), err))
+			continue
+		}
+		for _, missing := range cs.missingEntries(curriculum) {
+			errs = append(errs, fmt.Errorf("curricula/%s: no %s challenge %q", f.Name(), missing.Track, missing.ID))
+		}
+		curricula = append(curricula, curriculum)
+	}
+	sort.Slice(curricula, func(i, j int) bool { return curricula[i].Title < curricula[j].Title })
+
+	cs.mutex.Lock()
+	cs.curricula = curricula
+	cs.mutex.Unlock()
+	for _, err := range errs {
+		log.Printf("curricula: %v", err)
+	}
+	log.Printf("Loaded %d curriculum(s)", len(curricula))
+	return errors.Join(errs...)
+}
+
+// loadCurriculum reads and checks one curriculum file
+func (cs *CurriculumService) loadCurriculum(path, name string) (models.Curriculum, error) {
+	var curriculum models.Curriculum
+	if !curriculumNamePattern.MatchString(name) {
+		return curriculum, fmt.Errorf("name must be lower-case letters, digits and dashes")
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return curriculum, err
+	}
+	if err := json.Unmarshal(raw, &curriculum); err != nil {
+		return curriculum, err
+	}
+
+	curriculum.Name = name
+	if curriculum.Title == "" {
+		curriculum.Title = name
+	}
+	if len(curriculum.Modules) == 0 {
+		return curriculum, fmt.Errorf("no modules")
+	}
+	for i := range curriculum.Modules {
+		module := &curriculum.Modules[i]
+		if module.Title == "" {
+			module.Title = fmt.Sprintf("Module %d", i+1)
+		}
+		for j := range module.Entries {
+			entry := &module.Entries[j]
+			entry.ID = strings.Trim(entry.ID, "/")
+			switch entry.Track {
+			case models.TrackClassic:
+				if _, err := strconv.Atoi(entry.ID); err != nil {
+					return curriculum, fmt.Errorf("%s: classic IDs are numbers, not %q", module.Title, entry.ID)
+				}
+			case models.TrackPackage:
+				if strings.Count(entry.ID, "/") != 1 {
+					return curriculum, fmt.Errorf("%s: package IDs look like \"gin/challenge-1-basic-routing\", not %q", module.Title, entry.ID)
+				}
+			case models.TrackRelease:
+				if strings.Count(entry.ID, "/") != 2 {
+					return curriculum, fmt.Errorf("%s: release IDs look like \"1.26/new-expr/challenge-1\", not %q", module.Title, entry.ID)
+				}
+				entry.ID = strings.TrimPrefix(entry.ID, "go")
+			default:
+				return curriculum, fmt.Errorf("%s: unknown track %q (want classic, package or release)", module.Title, entry.Track)
+			}
+		}
+	}
+	return curriculum, nil
+}
+
+// missingEntries lists the entries naming challenges that do not exist
+func (cs *CurriculumService) missingEntries(curriculum models.Curriculum) []models.CurriculumEntry {
+	var missing []models.CurriculumEntry
+	for _, module := range curriculum.Modules {
+		for _, entry := range module.Entries {
+			if resolved := cs.resolve(entry); resolved.Missing {
+				missing = append(missing, entry)
+			}
+		}
+	}
+	return missing
+}
+
+// Curricula returns every curriculum, by title
+func (cs *CurriculumService) Curricula() []models.Curriculum {
+	cs.mutex.RLock()
+	defer cs.mutex.RUnlock()
+	return cs.curricula
+}
+
+// Curriculum looks up a curriculum by name
+func (cs *CurriculumService) Curriculum(name string) (models.Curriculum, bool) {
+	for _, c := range cs.Curricula() {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return models.Curriculum{}, false
+}
+
+// Progress resolves the curriculum's entries and marks what username has
+// solved and attempted. Anonymous users get the curriculum with no progress.
+func (cs *CurriculumService) Progress(curriculum models.Curriculum, username string) *models.CurriculumProgress {
+	solved, attempted := cs.history(username)
+	return cs.progress(curriculum, solved, attempted)
+}
+
+// AllProgress is Progress for every curriculum
+func (cs *CurriculumService) AllProgress(username string) []*models.CurriculumProgress {
+	solved, attempted := cs.history(username)
+	all := []*models.CurriculumProgress{}
+	for _, curriculum := range cs.Curricula() {
+		all = append(all, cs.progress(curriculum, solved, attempted))
+	}
+	return all
+}
+
+// history returns the challenges username has solved and attempted, keyed by
+// track + ":" + ID
+func (cs *CurriculumService) history(username string) (map[string]solvedChallenge, map[string]bool) {
+	solved := make(map[string]solvedChallenge)
+	attempted := make(map[string]bool)
+	if username != "" {
+		solved = cs.achievements.facts(username).solved
+		for _, a := range cs.attempts.UserAttempts(username) {
+			attempted[a.Track+":"+a.ChallengeID] = true
+		}
+	}
+	return solved, attempted
+}
+
+// progress lays the user's history over the curriculum. Entries whose
+// challenge is missing are shown but do not count.
+func (cs *CurriculumService) progress(curriculum models.Curriculum, solved map[string]solvedChallenge, attempted map[string]bool) *models.CurriculumProgress {
+	progress := &models.CurriculumProgress{
+		Name:        curriculum.Name,
+		Title:       curriculum.Title,
+		Description: curriculum.Description,
+		Modules:     []models.ModuleProgress{},
+	}
+	for _, module := range curriculum.Modules {
+		mp := models.ModuleProgress{Title: module.Title, Description: module.Description, Entries: []models.EntryProgress{}}
+		for _, entry := range module.Entries {
+			ep := cs.resolve(entry)
+			_, ep.Solved = solved[entry.Track+":"+entry.ID]
+			ep.Attempted = ep.Solved || attempted[entry.Track+":"+entry.ID]
+
+			switch {
+			case ep.Missing:
+			case entry.Optional:
+				progress.Optional++
+				if ep.Solved {
+					progress.OptionalSolved++
+				}
+			default:
+				mp.Required++
+				if ep.Solved {
+					mp.RequiredSolved++
+				} else if progress.Next == nil {
+					next := ep
+					progress.Next = &next
+				}
+			}
+			mp.Entries = append(mp.Entries, ep)
+		}
+		mp.Complete = mp.RequiredSolved == mp.Required
+		progress.Required += mp.Required
+		progress.RequiredSolved += mp.RequiredSolved
+		progress.Modules = append(progress.Modules, mp)
+	}
+
+	progress.Complete = progress.RequiredSolved == progress.Required
+	if progress.Required > 0 {
+		progress.Percent = progress.RequiredSolved * 100 / progress.Required
+	} else {
+		progress.Percent = 100
+	}
+	return progress
+}
+
+// resolve looks up an entry's challenge
+func (cs *CurriculumService) resolve(entry models.CurriculumEntry) models.EntryProgress {
+	ep := models.EntryProgress{CurriculumEntry: entry, Title: entry.ID, URL: challengeURL(entry.Track, entry.ID)}
+	switch entry.Track {
+	case models.TrackClassic:
+		if id, err := strconv.Atoi(entry.ID); err == nil {
+			if c, ok := cs.challengeService.GetChallenge(id); ok {
+				ep.Title, ep.Difficulty = c.Title, c.Difficulty
+				return ep
+			}
+		}
+	case models.TrackPackage:
+		if name, slug, ok := strings.Cut(entry.ID, "/"); ok {
+			if pkg, err := cs.packageService.GetPackage(name); err == nil && pkg.ChallengeDetails[slug] != nil {
+				info := pkg.ChallengeDetails[slug]
+				ep.Title, ep.Difficulty = info.Title, info.Difficulty
+				return ep
+			}
+		}
+	case models.TrackRelease:
+		if parts := strings.SplitN(entry.ID, "/", 3); len(parts) == 3 {
+			if c := cs.releaseService.GetChallenge(parts[0], parts[1], parts[2]); c != nil {
+				ep.Title, ep.Difficulty = c.Title, c.Difficulty
+				return ep
+			}
+		}
+	}
+	ep.Missing = true
+	return ep
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"web-ui/internal/config"
+	"web-ui/internal/models"
+)
+
+func TestCurriculumProgress(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"challenge-1", "challenge-2"} {
+		os.MkdirAll(filepath.Join(root, dir), 0755)
+		os.WriteFile(filepath.Join(root, dir, "README.md"), []byte("# Challenge\n"), 0644)
+		os.WriteFile(filepath.Join(root, dir, "solution-template.go"), []byte("package main\n"), 0644)
+	}
+	os.MkdirAll(filepath.Join(root, "curricula"), 0755)
+	os.WriteFile(filepath.Join(root, "curricula", "onboarding.json"), []byte(`{"title": "Onboarding", "modules": [
+		{"title": "Basics", "entries": [
+			{"track": "classic", "id": "1"},
+			{"track": "classic", "id": "2", "optional": true, "notes": "If you have time"},
+			{"track": "classic", "id": "99"}
+		]}
+	]}`), 0644)
+	os.WriteFile(filepath.Join(root, "curricula", "broken.json"), []byte(`{"modules": [{"entries": [{"track": "cobol", "id": "1"}]}]}`), 0644)
+
+	cfg := config.Default()
+	cfg.Content.Root = root
+	cfg.Server.DataDir = t.TempDir()
+	challenges := NewChallengeService(cfg)
+	if err := challenges.LoadChallenges(); err != nil {
+		t.Fatal(err)
+	}
+	packages := NewPackageService(cfg)
+	releases := NewReleaseService(cfg, nil)
+	leaderboards := NewLeaderboardService(cfg, challenges, packages, nil)
+	store, _ := NewFileSubmissionStore(cfg.Server.DataDir)
+	attempts, _ := NewFileAttemptStore(cfg.Server.DataDir)
+	activity, _ := NewActivityService(cfg, attempts)
+	achievements, _ := NewAchievementService(cfg, challenges, packages, releases, leaderboards, activity, store, attempts)
+	curricula := NewCurriculumService(cfg, challenges, packages, releases, achievements, attempts)
+
+	// The broken file and the missing challenge are reported; the rest loads
+	if err := curricula.Load(); err == nil {
+		t.Error("Load reported no problems")
+	}
+	curriculum, ok := curricula.Curriculum("onboarding")
+	if !ok || len(curricula.Curricula()) != 1 {
+		t.Fatalf("curricula = %+v", curricula.Curricula())
+	}
+
+	attempts.AddAttempt(NewAttempt("bob", models.TrackClassic, "1", "run", "package main", false, "", 10))
+	bob := curricula.Progress(curriculum, "bob")
+	if bob.Required != 1 || bob.Optional != 1 || bob.Complete || bob.Next == nil || bob.Next.ID != "1" ||
+		!bob.Modules[0].Entries[0].Attempted || !bob.Modules[0].Entries[2].Missing {
+		t.Errorf("bob's progress = %+v", bob)
+	}
+
+	attempts.AddAttempt(NewAttempt("alice", models.TrackClassic, "1", "submit", "package main", true, "", 10))
+	alice := curricula.AllProgress("alice")
+	if len(alice) != 1 || !alice[0].Complete || alice[0].Percent != 100 || alice[0].OptionalSolved != 0 || alice[0].Next != nil {
+		t.Errorf("alice's progress = %+v", alice)
+	}
+}
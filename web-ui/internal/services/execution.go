@@ -1,23 +1,40 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
+	"web-ui/internal/config"
 	"web-ui/internal/models"
 )
 
 // ExecutionService handles code execution and testing
-type ExecutionService struct{}
+type ExecutionService struct {
+	cfg   *config.Config
+	slots chan struct{} // one token per run in flight; runs beyond the limit queue
+}
 
 // NewExecutionService creates a new execution service
-func NewExecutionService() *ExecutionService {
-	return &ExecutionService{}
+func NewExecutionService(cfg *config.Config) *ExecutionService {
+	return &ExecutionService{
+		cfg:   cfg,
+		slots: make(chan struct{}, cfg.Runner.MaxConcurrent),
+	}
+}
+
+// acquireRun waits until fewer than runner.max_concurrent runs are in flight.
+// Call the returned function when the run is done.
+func (es *ExecutionService) acquireRun() (release func()) {
+	es.slots <- struct{}{}
+	return func() { <-es.slots }
 }
 
 // ExecutionResult represents the result of code execution
@@ -29,8 +46,13 @@ type ExecutionResult struct {
 
 // RunCode executes the provided code against a challenge's tests
 func (es *ExecutionService) RunCode(code string, challenge *models.Challenge) ExecutionResult {
+	defer es.acquireRun()()
 	start := time.Now()
 
+	// The time limit covers fetching dependencies and compiling, not just the tests
+	ctx, cancel := context.WithTimeout(context.Background(), es.cfg.Runner.Timeout)
+	defer cancel()
+
 	// Create temporary directory for execution
 	tempDir, err := ioutil.TempDir("", "challenge-exec")
 	if err != nil {
@@ -62,7 +84,7 @@ func (es *ExecutionService) RunCode(code string, challenge *models.Challenge) Ex
 	}
 
 	// Initialize Go module
-	err = es.initGoModule(tempDir, challenge.ID)
+	err = es.initGoModule(ctx, tempDir, challenge.ID)
 	if err != nil {
 		return ExecutionResult{
 			Passed: false,
@@ -71,7 +93,7 @@ func (es *ExecutionService) RunCode(code string, challenge *models.Challenge) Ex
 	}
 
 	// Automatically detect and install dependencies based on imports
-	err = es.installDependencies(tempDir, code, challenge.ID)
+	err = es.installDependencies(ctx, tempDir, code, challenge.ID)
 	if err != nil {
 		return ExecutionResult{
 			Passed: false,
@@ -80,7 +102,7 @@ func (es *ExecutionService) RunCode(code string, challenge *models.Challenge) Ex
 	}
 
 	// Run tests
-	cmd := exec.Command("go", "test", "-v")
+	cmd := exec.CommandContext(ctx, "go", "test", "-v")
 	cmd.Dir = tempDir
 
 	output, err := cmd.CombinedOutput()
@@ -92,6 +114,11 @@ func (es *ExecutionService) RunCode(code string, challenge *models.Challenge) Ex
 		ExecutionMs: executionTime,
 	}
 
+	if ctx.Err() == context.DeadlineExceeded {
+		result.Output += fmt.Sprintf("\n\nTimed out after %s.", es.cfg.Runner.Timeout)
+		return result
+	}
+
 	if err == nil {
 		result.Passed = true
 	} else {
@@ -109,16 +136,39 @@ func (es *ExecutionService) RunCode(code string, challenge *models.Challenge) Ex
 	return result
 }
 
+// testResultLine matches the per-test verdict lines of `go test -v`, including
+// indented subtests: "--- PASS: TestAdd/negative (0.00s)"
+var testResultLine = regexp.MustCompile(`^\s*--- (PASS|FAIL|SKIP): (\S+) \(([\d.]+)s\)`)
+
+// ParseTestOutput extracts the individual test results from `go test -v` output
+func ParseTestOutput(output string) []models.TestResult {
+	var results []models.TestResult
+	for _, line := range strings.Split(output, "\n") {
+		match := testResultLine.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		seconds, _ := strconv.ParseFloat(match[3], 64)
+		results = append(results, models.TestResult{
+			Name:      match[2],
+			Passed:    match[1] == "PASS",
+			Skipped:   match[1] == "SKIP",
+			ElapsedMs: seconds * 1000,
+		})
+	}
+	return results
+}
+
 // initGoModule initializes a Go module in the temporary directory
-func (es *ExecutionService) initGoModule(tempDir string, challengeID int) error {
+func (es *ExecutionService) initGoModule(ctx context.Context, tempDir string, challengeID int) error {
 	// Initialize go.mod
-	cmd := exec.Command("go", "mod", "init", fmt.Sprintf("challenge-%d", challengeID))
+	cmd := exec.CommandContext(ctx, "go", "mod", "init", fmt.Sprintf("challenge-%d", challengeID))
 	cmd.Dir = tempDir
 	return cmd.Run()
 }
 
 // installDependencies installs dependencies for the given challenge
-func (es *ExecutionService) installDependencies(tempDir string, code string, challengeID int) error {
+func (es *ExecutionService) installDependencies(ctx context.Context, tempDir string, code string, challengeID int) error {
 	// Detect imports from the code
 	requiredPackages := es.detectRequiredPackages(code, challengeID)
 
@@ -129,7 +179,7 @@ func (es *ExecutionService) installDependencies(tempDir string, code string, cha
 	// Install each required package
 	for _, pkg := range requiredPackages {
 		fmt.Printf("Installing dependency: %s\n", pkg)
-		cmd := exec.Command("go", "get", pkg)
+		cmd := exec.CommandContext(ctx, "go", "get", pkg)
 		cmd.Dir = tempDir
 
 		output, err := cmd.CombinedOutput()
@@ -139,7 +189,7 @@ func (es *ExecutionService) installDependencies(tempDir string, code string, cha
 	}
 
 	// Run go mod tidy to clean up dependencies
-	tidyCmd := exec.Command("go", "mod", "tidy")
+	tidyCmd := exec.CommandContext(ctx, "go", "mod", "tidy")
 	tidyCmd.Dir = tempDir
 	tidyCmd.Run() // Ignore errors for tidy
 
@@ -277,7 +327,7 @@ func (es *ExecutionService) isExternalPackage(importPath string) bool {
 
 // SaveSubmissionRequest represents a request to save a submission to filesystem
 type SaveSubmissionRequest struct {
-	Username    string `json:"username"`
+	Username    string `json:"-"` // the signed-in user, never the request body
 	ChallengeID int    `json:"challengeId"`
 	Code        string `json:"code"`
 }
@@ -292,44 +342,18 @@ type SaveSubmissionResponse struct {
 
 // SaveSubmissionToFilesystem saves a user's submission to the filesystem
 func (es *ExecutionService) SaveSubmissionToFilesystem(request SaveSubmissionRequest) SaveSubmissionResponse {
-	// Get working directory for correct relative paths
-	workDir, _ := os.Getwd()
-
-	// Try different path approaches to handle potential path issues
-	var submissionDir string
-	var fileSaved bool
-
-	// Try multiple path options to ensure it works in different environments
-	pathOptions := []string{
-		// Option 1: From web-ui directory (standard case)
-		filepath.Join("..", fmt.Sprintf("challenge-%d", request.ChallengeID), "submissions", request.Username),
-		// Option 2: From root workspace
-		filepath.Join(fmt.Sprintf("challenge-%d", request.ChallengeID), "submissions", request.Username),
-		// Option 3: Absolute path from detected workspace root
-		filepath.Join(workDir, "..", fmt.Sprintf("challenge-%d", request.ChallengeID), "submissions", request.Username),
-	}
-
-	for _, dirPath := range pathOptions {
-		err := os.MkdirAll(dirPath, 0755)
-		if err != nil {
-			continue
-		}
-
-		solutionFile := filepath.Join(dirPath, "solution-template.go")
-		err = ioutil.WriteFile(solutionFile, []byte(request.Code), 0644)
-		if err != nil {
-			continue
+	submissionDir := filepath.Join(es.cfg.ChallengeDir(request.ChallengeID), "submissions", request.Username)
+	solutionFile := filepath.Join(submissionDir, "solution-template.go")
+	if err := os.MkdirAll(submissionDir, 0755); err != nil {
+		return SaveSubmissionResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to create submission directory: %v", err),
 		}
-
-		submissionDir = dirPath
-		fileSaved = true
-		break
 	}
-
-	if !fileSaved {
+	if err := ioutil.WriteFile(solutionFile, []byte(request.Code), 0644); err != nil {
 		return SaveSubmissionResponse{
 			Success: false,
-			Message: "Failed to save solution to any available path",
+			Message: fmt.Sprintf("Failed to save solution: %v", err),
 		}
 	}
 
@@ -337,9 +361,9 @@ func (es *ExecutionService) SaveSubmissionToFilesystem(request SaveSubmissionReq
 	return SaveSubmissionResponse{
 		Success:  true,
 		Message:  "Solution saved to filesystem",
-		FilePath: filepath.Join(submissionDir, "solution-template.go"),
+		FilePath: solutionFile,
 		GitCommands: []string{
-			"cd " + filepath.Join(workDir, ".."),
+			"cd " + es.cfg.Content.Root,
 			fmt.Sprintf("git add %s", filepath.Join(fmt.Sprintf("challenge-%d", request.ChallengeID), "submissions", request.Username, "solution-template.go")),
 			fmt.Sprintf("git commit -m \"Add solution for Challenge %d\"", request.ChallengeID),
 			"git push origin main",
@@ -0,0 +1,242 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"web-ui/internal/config"
+)
+
+// SubmissionCommit is the commit that first brought a user's solution into the repo.
+type SubmissionCommit struct {
+	Commit string    `json:"commit"`
+	Time   time.Time `json:"time"`
+}
+
+// GitHistoryService dates submissions from the repository's git history: the
+// first commit on the main branch that adds a user's files under
+// challenge-N/submissions/<user>/ or packages/<pkg>/<challenge>/submissions/<user>/
+// is when their solution first landed. Failing solutions are merged too, so
+// that is not necessarily when they passed; scoreboard.json records that, and
+// this only dates entries recorded before it did.
+//
+// Only first parents are followed, so a merged pull request is dated by its
+// merge rather than by the commits on its branch, which may be days older.
+// Unlike file modification times, that survives a fresh checkout.
+//
+// Walking the history takes a while on a large repo, so the result is cached in
+// the data directory together with the HEAD it was computed at. On the next
+// start only the commits since then are read.
+type GitHistoryService struct {
+	repoRoot  string
+	cachePath string
+
+	mutex  sync.RWMutex
+	firsts map[string]map[string]SubmissionCommit // challenge key -> username -> first commit
+}
+
+// gitHistoryVersion changes whenever the way submissions are dated does, so
+// caches written the old way are read again from scratch
+const gitHistoryVersion = 2
+
+// gitHistoryCache is the on-disk form of the service's state
+type gitHistoryCache struct {
+	Version int                                    `json:"version"`
+	Head    string                                 `json:"head"`
+	Firsts  map[string]map[string]SubmissionCommit `json:"firsts"`
+}
+
+// submissionPathspecs select every submission file in the repository
+var submissionPathspecs = []string{"challenge-*/submissions/*", "packages/*/*/submissions/*"}
+
+// NewGitHistoryService creates a history service for the repository at the
+// content root, caching what it reads in the data directory
+func NewGitHistoryService(cfg *config.Config) *GitHistoryService {
+	return &GitHistoryService{
+		repoRoot:  cfg.Content.Root,
+		cachePath: filepath.Join(cfg.Server.DataDir, "git-history.json"),
+		firsts:    make(map[string]map[string]SubmissionCommit),
+	}
+}
+
+// ClassicSubmissionKey identifies a classic challenge in the history
+func ClassicSubmissionKey(challengeID int) string {
+	return "challenge-" + strconv.Itoa(challengeID)
+}
+
+// PackageSubmissionKey identifies a package challenge in the history
+func PackageSubmissionKey(packageName, challengeID string) string {
+	return packageName + "/" + challengeID
+}
+
+// FirstSubmission returns when the user's solution to a challenge first landed
+func (g *GitHistoryService) FirstSubmission(key, username string) (SubmissionCommit, bool) {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+	commit, ok := g.firsts[key][username]
+	return commit, ok
+}
+
+// Head returns the commit checked out in the repository
+func (g *GitHistoryService) Head() (string, error) {
+	head, err := g.git("rev-parse", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("not a git checkout: %v", err)
+	}
+	return strings.TrimSpace(head), nil
+}
+
+// Load brings the history up to date with HEAD, reusing the on-disk cache
+func (g *GitHistoryService) Load() error {
+	head, err := g.Head()
+	if err != nil {
+		return err
+	}
+
+	// A shallow clone would date every older submission at its cut-off commit.
+	if shallow, _ := g.git("rev-parse", "--is-shallow-repository"); strings.TrimSpace(shallow) == "true" {
+		return fmt.Errorf("shallow clone: submission dates need the full git history")
+	}
+
+	cache := g.readCache()
+	revisions := []string{head}
+	if cache.Version != gitHistoryVersion {
+		cache = gitHistoryCache{Version: gitHistoryVersion, Firsts: make(map[string]map[string]SubmissionCommit)}
+	} else if cache.Head == head {
+		revisions = nil
+	} else if cache.Head != "" && g.isAncestor(cache.Head, head) {
+		revisions = []string{cache.Head + ".." + head}
+	} else {
+		// No cache, or history was rewritten: start over.
+		cache.Firsts = make(map[string]map[string]SubmissionCommit)
+	}
+
+	if revisions != nil {
+		if err := g.scan(revisions, cache.Firsts); err != nil {
+			return err
+		}
+		cache.Head = head
+		g.writeCache(cache)
+	}
+
+	g.mutex.Lock()
+	g.firsts = cache.Firsts
+	g.mutex.Unlock()
+
+	count := 0
+	for _, users := range cache.Firsts {
+		count += len(users)
+	}
+	log.Printf("Dated %d submission(s) from git history at %.12s", count, head)
+	return nil
+}
+
+// scan walks the first parents of the given revisions and records the
+// earliest commit per submission. -m lists the files a merge brought in
+// against its first parent, and %ct dates it by when it was committed there.
+func (g *GitHistoryService) scan(revisions []string, firsts map[string]map[string]SubmissionCommit) error {
+	args := append([]string{"log", "--reverse", "--first-parent", "-m", "--format=%x00%H %ct", "--name-only"}, revisions...)
+	args = append(args, "--")
+	args = append(args, submissionPathspecs...)
+
+	out, err := g.git(args...)
+	if err != nil {
+		return fmt.Errorf("git log failed: %v", err)
+	}
+
+	var current SubmissionCommit
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "\x00") {
+			fields := strings.Fields(strings.TrimPrefix(line, "\x00"))
+			if len(fields) != 2 {
+				continue
+			}
+			unix, _ := strconv.ParseInt(fields[1], 10, 64)
+			current = SubmissionCommit{Commit: fields[0], Time: time.Unix(unix, 0).UTC()}
+			continue
+		}
+
+		key, username := submissionOwner(line)
+		if key == "" || current.Commit == "" {
+			continue
+		}
+		if firsts[key] == nil {
+			firsts[key] = make(map[string]SubmissionCommit)
+		}
+		if _, seen := firsts[key][username]; !seen {
+			firsts[key][username] = current
+		}
+	}
+	return scanner.Err()
+}
+
+// submissionOwner maps a submission file path to its challenge key and username
+func submissionOwner(path string) (key, username string) {
+	parts := strings.Split(path, "/")
+	switch {
+	case len(parts) >= 4 && strings.HasPrefix(parts[0], "challenge-") && parts[1] == "submissions":
+		return parts[0], parts[2]
+	case len(parts) >= 6 && parts[0] == "packages" && parts[3] == "submissions":
+		return PackageSubmissionKey(parts[1], parts[2]), parts[4]
+	}
+	return "", ""
+}
+
+func (g *GitHistoryService) isAncestor(ancestor, head string) bool {
+	cmd := exec.Command("git", "merge-base", "--is-ancestor", ancestor, head)
+	cmd.Dir = g.repoRoot
+	return cmd.Run() == nil
+}
+
+func (g *GitHistoryService) git(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = g.repoRoot
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
+
+func (g *GitHistoryService) readCache() gitHistoryCache {
+	cache := gitHistoryCache{Firsts: make(map[string]map[string]SubmissionCommit)}
+	raw, err := os.ReadFile(g.cachePath)
+	if err != nil {
+		return cache
+	}
+	if err := json.Unmarshal(raw, &cache); err != nil || cache.Firsts == nil {
+		return gitHistoryCache{Firsts: make(map[string]map[string]SubmissionCommit)}
+	}
+	return cache
+}
+
+func (g *GitHistoryService) writeCache(cache gitHistoryCache) {
+	raw, err := json.Marshal(cache)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(g.cachePath), 0755); err != nil {
+		log.Printf("git history: cannot create cache directory: %v", err)
+		return
+	}
+	tmp := g.cachePath + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0644); err != nil {
+		log.Printf("git history: cannot write cache: %v", err)
+		return
+	}
+	os.Rename(tmp, g.cachePath)
+}
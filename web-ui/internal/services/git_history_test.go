@@ -0,0 +1,73 @@
+package services
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"web-ui/internal/config"
+)
+
+func TestGitHistoryDatesSubmissionsByMerge(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	root := t.TempDir()
+	git := func(date string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = root
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date,
+			"GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@example.com", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	submit := func(username string) {
+		dir := filepath.Join(root, "challenge-1", "submissions", username)
+		os.MkdirAll(dir, 0755)
+		os.WriteFile(filepath.Join(dir, "solution-template.go"), []byte("package main\n"), 0644)
+	}
+
+	git("2026-01-01T00:00:00Z", "init", "-q", "-b", "main")
+	os.WriteFile(filepath.Join(root, "README.md"), []byte("# Challenges\n"), 0644)
+	git("2026-01-01T00:00:00Z", "add", "-A")
+	git("2026-01-01T00:00:00Z", "commit", "-q", "-m", "start")
+
+	// alice's branch was written in January but only merged in March
+	git("2026-01-02T00:00:00Z", "checkout", "-q", "-b", "alice")
+	submit("alice")
+	git("2026-01-02T00:00:00Z", "add", "-A")
+	git("2026-01-02T00:00:00Z", "commit", "-q", "-m", "alice")
+	git("2026-02-01T00:00:00Z", "checkout", "-q", "main")
+	submit("bob")
+	git("2026-02-01T00:00:00Z", "add", "-A")
+	git("2026-02-01T00:00:00Z", "commit", "-q", "-m", "bob")
+	git("2026-03-01T00:00:00Z", "merge", "-q", "--no-ff", "-m", "merge alice", "alice")
+
+	cfg := config.Default()
+	cfg.Content.Root = root
+	cfg.Server.DataDir = t.TempDir()
+	history := NewGitHistoryService(cfg)
+	if err := history.Load(); err != nil {
+		t.Fatal(err)
+	}
+	for username, want := range map[string]string{"alice": "2026-03-01T00:00:00Z", "bob": "2026-02-01T00:00:00Z"} {
+		commit, ok := history.FirstSubmission(ClassicSubmissionKey(1), username)
+		if at, _ := time.Parse(time.RFC3339, want); !ok || !commit.Time.Equal(at) {
+			t.Errorf("%s first submitted at %v (%v), want %s", username, commit.Time, ok, want)
+		}
+	}
+
+	// A cache written before merges were dated this way is read again
+	os.WriteFile(filepath.Join(cfg.Server.DataDir, "git-history.json"), []byte(`{"head": "x", "firsts": {}}`), 0644)
+	history = NewGitHistoryService(cfg)
+	if err := history.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := history.FirstSubmission(ClassicSubmissionKey(1), "alice"); !ok {
+		t.Error("an old cache was trusted")
+	}
+}
@@ -0,0 +1,438 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"web-ui/internal/models"
+)
+
+// Interview limits
+const (
+	MinInterviewMinutes   = 5
+	MaxInterviewMinutes   = 240
+	MaxInterviewQuestions = 10
+
+	// maxInterviewSnapshots bounds a session file; once reached, a new
+	// snapshot replaces the latest one of its challenge
+	maxInterviewSnapshots = 1000
+
+	// interviewHintPenalty is the score lost per AI hint
+	interviewHintPenalty = 5
+)
+
+// ErrInterviewOver is returned when recording into an interview that has
+// ended or run out of time
+var ErrInterviewOver = errors.New("the interview is over")
+
+// InterviewService runs timed mock interviews on the server. The time limit
+// is enforced here: a session ends on its own when time runs out, even if the
+// candidate has closed the page, and nothing more is recorded into it. Each
+// session is a JSON file in interviews/ in the data directory. Besides the
+// candidate, the leads of their teams may watch their interviews.
+type InterviewService struct {
+	dir              string
+	challengeService *ChallengeService
+	teams            *TeamService
+
+	mutex    sync.Mutex
+	sessions map[string]*models.InterviewSession
+	timers   map[string]*time.Timer
+}
+
+func NewInterviewService(dataDir string, challengeService *ChallengeService, teams *TeamService) (*InterviewService, error) {
+	dir := filepath.Join(dataDir, "interviews")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create interviews directory: %v", err)
+	}
+	return &InterviewService{
+		dir:              dir,
+		challengeService: challengeService,
+		teams:            teams,
+		sessions:         make(map[string]*models.InterviewSession),
+		timers:           make(map[string]*time.Timer),
+	}, nil
+}
+
+// Load reads every session, ends those whose time ran out while the server
+// was down and schedules the end of the rest. Unreadable files are skipped.
+func (is *InterviewService) Load() error {
+	files, err := filepath.Glob(filepath.Join(is.dir, "*.json"))
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	is.mutex.Lock()
+	for _, file := range files {
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		var session models.InterviewSession
+		if err := json.Unmarshal(raw, &session); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", filepath.Base(file), err))
+			continue
+		}
+		is.sessions[session.ID] = &session
+		if session.Status == models.InterviewActive {
+			is.schedule(&session)
+		}
+	}
+	is.mutex.Unlock()
+
+	is.Expire(time.Now())
+	return errors.Join(errs...)
+}
+
+// PickChallenges checks the chosen challenges, or picks count at random,
+// optionally of one difficulty. Chosen challenges keep their order, so an
+// interview from a loop runs easiest first; ones picked at random are sorted.
+func (is *InterviewService) PickChallenges(chosen []int, count int, difficulty string) ([]int, error) {
+	var ids []int
+	if len(chosen) > 0 {
+		seen := make(map[int]bool)
+		for _, id := range chosen {
+			if _, ok := is.challengeService.GetChallenge(id); !ok {
+				return nil, fmt.Errorf("there is no challenge %d", id)
+			}
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	} else {
+		if count < 1 {
+			return nil, fmt.Errorf("choose challenges or how many to pick at random")
+		}
+		var pool []int
+		for _, c := range is.challengeService.ListChallenges() {
+			if difficulty == "" || strings.EqualFold(c.Difficulty, difficulty) {
+				pool = append(pool, c.ID)
+			}
+		}
+		if len(pool) < count {
+			return nil, fmt.Errorf("there are only %d challenges to pick from", len(pool))
+		}
+		rand.Shuffle(len(pool), func(i, j int) { pool[i], pool[j] = pool[j], pool[i] })
+		ids = pool[:count]
+		sort.Ints(ids)
+	}
+
+	if len(ids) > MaxInterviewQuestions {
+		return nil, fmt.Errorf("an interview has at most %d challenges", MaxInterviewQuestions)
+	}
+	return ids, nil
+}
+
+// Start begins an interview for username now
+func (is *InterviewService) Start(username string, challengeIDs []int, random bool, minutes int) (models.InterviewSession, error) {
+	now := time.Now()
+	session := &models.InterviewSession{
+		ID:           newAttemptID(),
+		Username:     username,
+		ChallengeIDs: challengeIDs,
+		Random:       random,
+		TimeLimit:    minutes,
+		StartedAt:    now,
+		EndsAt:       now.Add(time.Duration(minutes) * time.Minute),
+		Status:       models.InterviewActive,
+		Snapshots:    []models.InterviewSnapshot{},
+		Runs:         []models.InterviewRun{},
+		AIRequests:   []models.InterviewAIRequest{},
+	}
+
+	is.mutex.Lock()
+	defer is.mutex.Unlock()
+	if err := is.save(session); err != nil {
+		return *session, err
+	}
+	is.sessions[session.ID] = session
+	is.schedule(session)
+	return *session, nil
+}
+
+// schedule ends the session when its time runs out; the caller holds the lock
+func (is *InterviewService) schedule(session *models.InterviewSession) {
+	is.timers[session.ID] = time.AfterFunc(time.Until(session.EndsAt), func() {
+		is.Expire(time.Now())
+	})
+}
+
+// Session looks up a session by ID
+func (is *InterviewService) Session(id string) (models.InterviewSession, bool) {
+	is.mutex.Lock()
+	defer is.mutex.Unlock()
+	session, ok := is.sessions[id]
+	if !ok {
+		return models.InterviewSession{}, false
+	}
+	return cloneSession(session), true
+}
+
+// Active returns username's interview in progress, if any
+func (is *InterviewService) Active(username string) (models.InterviewSession, bool) {
+	is.mutex.Lock()
+	defer is.mutex.Unlock()
+	for _, session := range is.sessions {
+		if session.Status == models.InterviewActive && strings.EqualFold(session.Username, username) {
+			return cloneSession(session), true
+		}
+	}
+	return models.InterviewSession{}, false
+}
+
+// cloneSession copies a session for callers, which read it without the lock;
+// the caller holds the lock
+func cloneSession(session *models.InterviewSession) models.InterviewSession {
+	clone := *session
+	clone.Snapshots = slices.Clone(session.Snapshots)
+	clone.Runs = slices.Clone(session.Runs)
+	clone.AIRequests = slices.Clone(session.AIRequests)
+	clone.Scorecards = slices.Clone(session.Scorecards)
+	return clone
+}
+
+// CanWatch reports whether viewer may see candidate's interviews: the
+// candidate and the leads of their teams may
+func (is *InterviewService) CanWatch(viewer, candidate string) bool {
+	if viewer == "" {
+		return false
+	}
+	if strings.EqualFold(viewer, candidate) {
+		return true
+	}
+	for _, team := range is.teams.Teams() {
+		if containsName(team.Leads, viewer) && containsName(team.Members, candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+// Sessions lists username's interviews, newest first
+func (is *InterviewService) Sessions(username string) []models.InterviewSummary {
+	is.mutex.Lock()
+	defer is.mutex.Unlock()
+	summaries := []models.InterviewSummary{}
+	for _, session := range is.sessions {
+		if !strings.EqualFold(session.Username, username) {
+			continue
+		}
+		summary := models.InterviewSummary{
+			ID:           session.ID,
+			ChallengeIDs: session.ChallengeIDs,
+			TimeLimit:    session.TimeLimit,
+			StartedAt:    session.StartedAt,
+			EndsAt:       session.EndsAt,
+			Status:       session.Status,
+		}
+		if session.Report != nil {
+			score := session.Report.Score
+			summary.Score, summary.Solved = &score, session.Report.Solved
+		}
+		summaries = append(summaries, summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].StartedAt.After(summaries[j].StartedAt) })
+	return summaries
+}
+
+// update applies change to an interview still in progress at the given time
+// and saves it
+func (is *InterviewService) update(id string, at time.Time, change func(*models.InterviewSession)) error {
+	is.mutex.Lock()
+	defer is.mutex.Unlock()
+	session, ok := is.sessions[id]
+	if !ok {
+		return fmt.Errorf("no interview %q", id)
+	}
+	if session.Status != models.InterviewActive || at.After(session.EndsAt) {
+		return ErrInterviewOver
+	}
+	change(session)
+	return is.save(session)
+}
+
+// Snapshot records the candidate's code for a challenge, unless it has not
+// changed since the last snapshot
+func (is *InterviewService) Snapshot(id string, challengeID int, code string, at time.Time) error {
+	return is.update(id, at, func(session *models.InterviewSession) {
+		addSnapshot(session, models.InterviewSnapshot{ChallengeID: challengeID, Code: code, At: at})
+	})
+}
+
+// addSnapshot appends a snapshot, skipping unchanged code and keeping the
+// session under maxInterviewSnapshots
+func addSnapshot(session *models.InterviewSession, snapshot models.InterviewSnapshot) {
+	latest := -1
+	for i := len(session.Snapshots) - 1; i >= 0; i-- {
+		if session.Snapshots[i].ChallengeID == snapshot.ChallengeID {
+			latest = i
+			break
+		}
+	}
+	switch {
+	case latest >= 0 && session.Snapshots[latest].Code == snapshot.Code:
+	case latest >= 0 && len(session.Snapshots) >= maxInterviewSnapshots:
+		session.Snapshots[latest] = snapshot
+	default:
+		session.Snapshots = append(session.Snapshots, snapshot)
+	}
+}
+
+// RecordRun records a test run that started at startedAt, and a snapshot of
+// the code it ran. It returns the run as an attempt for the user's history.
+func (is *InterviewService) RecordRun(id, username string, challengeID int, code string, result ExecutionResult, startedAt time.Time) (*models.Attempt, error) {
+	attempt := NewAttempt(username, models.TrackClassic, strconv.Itoa(challengeID), "run", code, result.Passed, result.Output, result.ExecutionMs)
+	err := is.update(id, startedAt, func(session *models.InterviewSession) {
+		session.Runs = append(session.Runs, models.InterviewRun{
+			ChallengeID: challengeID,
+			Passed:      result.Passed,
+			TestsPassed: attempt.TestsPassed,
+			TestsTotal:  attempt.TestsTotal,
+			ExecutionMs: result.ExecutionMs,
+			Output:      result.Output,
+			At:          startedAt,
+		})
+		addSnapshot(session, models.InterviewSnapshot{ChallengeID: challengeID, Code: code, At: startedAt})
+	})
+	return attempt, err
+}
+
+// RecordAI records a hint, review or set of questions asked for at request.At
+func (is *InterviewService) RecordAI(id string, request models.InterviewAIRequest) error {
+	return is.update(id, request.At, func(session *models.InterviewSession) {
+		session.AIRequests = append(session.AIRequests, request)
+	})
+}
+
+// Finish ends an interview at the candidate's request and scores it. Ending
+// an interview that is already over returns it as it is.
+func (is *InterviewService) Finish(id string, now time.Time) (models.InterviewSession, error) {
+	is.mutex.Lock()
+	defer is.mutex.Unlock()
+	session, ok := is.sessions[id]
+	if !ok {
+		return models.InterviewSession{}, fmt.Errorf("no interview %q", id)
+	}
+	if session.Status != models.InterviewActive {
+		return *session, nil
+	}
+	status := models.InterviewFinished
+	if now.After(session.EndsAt) {
+		status, now = models.InterviewTimedOut, session.EndsAt
+	}
+	err := is.end(session, status, now)
+	return *session, err
+}
+
+// Expire ends every interview whose time has run out by now
+func (is *InterviewService) Expire(now time.Time) {
+	is.mutex.Lock()
+	defer is.mutex.Unlock()
+	for _, session := range is.sessions {
+		if session.Status == models.InterviewActive && !now.Before(session.EndsAt) {
+			if err := is.end(session, models.InterviewTimedOut, session.EndsAt); err != nil {
+				log.Printf("interviews: failed to end %s: %v", session.ID, err)
+			}
+		}
+	}
+}
+
+// end closes and scores a session; the caller holds the lock
+func (is *InterviewService) end(session *models.InterviewSession, status string, at time.Time) error {
+	if timer, ok := is.timers[session.ID]; ok {
+		timer.Stop()
+		delete(is.timers, session.ID)
+	}
+	session.Status = status
+	session.EndedAt = &at
+	session.Report = is.report(session)
+	return is.save(session)
+}
+
+// report scores a session. A challenge is solved by a run that passes every
+// test; the score is the share of challenges solved, less
+// interviewHintPenalty for every hint.
+func (is *InterviewService) report(session *models.InterviewSession) *models.InterviewReport {
+	report := &models.InterviewReport{
+		Total:      len(session.ChallengeIDs),
+		Runs:       len(session.Runs),
+		Challenges: []models.InterviewChallengeReport{},
+	}
+	if session.EndedAt != nil {
+		report.ElapsedSeconds = int(session.EndedAt.Sub(session.StartedAt).Seconds())
+	}
+
+	for _, id := range session.ChallengeIDs {
+		cr := models.InterviewChallengeReport{ChallengeID: id, Title: fmt.Sprintf("Challenge %d", id)}
+		if c, ok := is.challengeService.GetChallenge(id); ok {
+			cr.Title, cr.Difficulty = c.Title, c.Difficulty
+		}
+		for _, run := range session.Runs {
+			if run.ChallengeID != id {
+				continue
+			}
+			cr.Runs++
+			if run.TestsPassed > cr.TestsPassed || cr.Runs == 1 {
+				cr.TestsPassed, cr.TestsTotal = run.TestsPassed, run.TestsTotal
+			}
+			if run.Passed && !cr.Solved {
+				cr.Solved = true
+				cr.SolvedAfterSeconds = int(run.At.Sub(session.StartedAt).Seconds())
+			}
+		}
+		for _, request := range session.AIRequests {
+			if request.ChallengeID == id && request.Kind == models.InterviewHint {
+				cr.Hints++
+			}
+		}
+		for _, snapshot := range session.Snapshots {
+			if snapshot.ChallengeID == id {
+				cr.FinalCode = snapshot.Code
+			}
+		}
+
+		if cr.Solved {
+			report.Solved++
+		}
+		report.TestsPassed += cr.TestsPassed
+		report.TestsTotal += cr.TestsTotal
+		report.Hints += cr.Hints
+		report.Challenges = append(report.Challenges, cr)
+	}
+
+	if report.Total > 0 {
+		report.Score = report.Solved * 100 / report.Total
+	}
+	report.HintPenalty = min(report.Score, report.Hints*interviewHintPenalty)
+	report.Score -= report.HintPenalty
+	applyScorecards(report, session.Scorecards)
+	return report
+}
+
+// save writes a session's file; the caller holds the lock
+func (is *InterviewService) save(session *models.InterviewSession) error {
+	raw, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(is.dir, session.ID+".json")
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	return os.Rename(tmp, path)
+}
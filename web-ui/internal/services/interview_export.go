@@ -0,0 +1,147 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"web-ui/internal/models"
+)
+
+// ErrInterviewNotEnded is returned when exporting an interview still running
+var ErrInterviewNotEnded = errors.New("the interview has not ended")
+
+// InterviewExport is an ended interview as written out in a report: its
+// score, and for each challenge the statement, final code, test results, the
+// AI's review and the interviewer's rubric scoring
+type InterviewExport struct {
+	ID          string                     `json:"id"`
+	Username    string                     `json:"username"`
+	Status      string                     `json:"status"`
+	TimeLimit   int                        `json:"timeLimitMinutes"`
+	StartedAt   time.Time                  `json:"startedAt"`
+	EndedAt     time.Time                  `json:"endedAt"`
+	Report      models.InterviewReport     `json:"report"`
+	Challenges  []InterviewExportChallenge `json:"challenges"`
+	GeneratedAt time.Time                  `json:"generatedAt"`
+}
+
+// InterviewExportChallenge is one challenge of an exported interview
+type InterviewExportChallenge struct {
+	models.InterviewChallengeReport
+	Statement string                     `json:"statement"`           // the challenge's Markdown description
+	Tests     []models.TestResult        `json:"tests"`               // in the best run, as in the report
+	Review    *AICodeReview              `json:"review,omitempty"`    // the last one asked for
+	Questions []string                   `json:"questions,omitempty"` // follow-up questions asked for
+	Scorecard *models.InterviewScorecard `json:"scorecard,omitempty"` // the interviewer's rubric scoring
+	Criteria  []InterviewExportCriterion `json:"criteria,omitempty"`  // the scorecard's rubric, with the scores given
+}
+
+// InterviewExportCriterion is one criterion of a challenge's rubric with the
+// scores it was given
+type InterviewExportCriterion struct {
+	models.RubricCriterion
+	Score      *InterviewExportLevel `json:"score,omitempty"`      // the interviewer's; unset when left unscored
+	Suggestion *InterviewExportLevel `json:"suggestion,omitempty"` // the AI's
+}
+
+// InterviewExportLevel is a score for a criterion, with its level's label
+type InterviewExportLevel struct {
+	Score         int    `json:"score"`
+	Label         string `json:"label"`
+	Justification string `json:"justification,omitempty"`
+}
+
+// Export gathers everything an ended interview's report shows
+func (is *InterviewService) Export(session models.InterviewSession) (InterviewExport, error) {
+	if session.Report == nil || session.EndedAt == nil {
+		return InterviewExport{}, ErrInterviewNotEnded
+	}
+	export := InterviewExport{
+		ID:          session.ID,
+		Username:    session.Username,
+		Status:      session.Status,
+		TimeLimit:   session.TimeLimit,
+		StartedAt:   session.StartedAt,
+		EndedAt:     *session.EndedAt,
+		Report:      *session.Report,
+		Challenges:  []InterviewExportChallenge{},
+		GeneratedAt: time.Now(),
+	}
+
+	for _, cr := range session.Report.Challenges {
+		ec := InterviewExportChallenge{InterviewChallengeReport: cr, Tests: []models.TestResult{}}
+		if c, ok := is.challengeService.GetChallenge(cr.ChallengeID); ok {
+			ec.Statement = c.Description
+		}
+
+		// The report keeps the best run's counts; show that run's tests
+		var best *models.InterviewRun
+		for i, run := range session.Runs {
+			if run.ChallengeID == cr.ChallengeID && (best == nil || run.TestsPassed > best.TestsPassed) {
+				best = &session.Runs[i]
+			}
+		}
+		if best != nil {
+			if tests := ParseTestOutput(best.Output); tests != nil {
+				ec.Tests = tests
+			}
+		}
+
+		for _, request := range session.AIRequests {
+			if request.ChallengeID != cr.ChallengeID {
+				continue
+			}
+			switch request.Kind {
+			case models.InterviewReview:
+				var review AICodeReview
+				if err := json.Unmarshal([]byte(request.Response), &review); err == nil {
+					ec.Review = &review
+				}
+			case models.InterviewQuestions:
+				for _, question := range strings.Split(request.Response, "\n") {
+					if question = strings.TrimSpace(question); question != "" {
+						ec.Questions = append(ec.Questions, question)
+					}
+				}
+			}
+		}
+		for i, scorecard := range session.Scorecards {
+			if scorecard.ChallengeID == cr.ChallengeID {
+				ec.Scorecard = &session.Scorecards[i]
+				ec.Criteria = exportCriteria(scorecard)
+			}
+		}
+		export.Challenges = append(export.Challenges, ec)
+	}
+	return export, nil
+}
+
+// exportCriteria pairs each criterion of a scorecard's rubric with the scores
+// it was given
+func exportCriteria(scorecard models.InterviewScorecard) []InterviewExportCriterion {
+	level := func(criterion models.RubricCriterion, scores []models.RubricScore) *InterviewExportLevel {
+		for _, score := range scores {
+			if score.Criterion != criterion.ID {
+				continue
+			}
+			for _, l := range criterion.Levels {
+				if l.Score == score.Score {
+					return &InterviewExportLevel{Score: score.Score, Label: l.Label, Justification: score.Justification}
+				}
+			}
+		}
+		return nil
+	}
+
+	criteria := make([]InterviewExportCriterion, 0, len(scorecard.Rubric.Criteria))
+	for _, criterion := range scorecard.Rubric.Criteria {
+		criteria = append(criteria, InterviewExportCriterion{
+			RubricCriterion: criterion,
+			Score:           level(criterion, scorecard.Scores),
+			Suggestion:      level(criterion, scorecard.Suggestions),
+		})
+	}
+	return criteria
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"web-ui/internal/config"
+	"web-ui/internal/models"
+)
+
+func TestInterviewExport(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "challenge-1"), 0755)
+	os.WriteFile(filepath.Join(root, "challenge-1", "README.md"), []byte("# Sum\n\nAdd two numbers.\n"), 0644)
+	os.WriteFile(filepath.Join(root, "challenge-1", "solution-template.go"), []byte("package main\n"), 0644)
+	cfg := config.Default()
+	cfg.Content.Root = root
+	cfg.Server.DataDir = t.TempDir()
+	challenges := NewChallengeService(cfg)
+	if err := challenges.LoadChallenges(); err != nil {
+		t.Fatal(err)
+	}
+	interviews, err := NewInterviewService(cfg.Server.DataDir, challenges, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	session, _ := interviews.Start("alice", []int{1}, false, MinInterviewMinutes)
+	if _, err := interviews.Export(session); !errors.Is(err, ErrInterviewNotEnded) {
+		t.Errorf("exporting a running interview: %v", err)
+	}
+
+	start := session.StartedAt
+	interviews.RecordRun(session.ID, "alice", 1, "package main // best", ExecutionResult{
+		Output: "--- PASS: TestA (0.01s)\n--- FAIL: TestB (0.00s)\nFAIL\n",
+	}, start.Add(time.Minute))
+	interviews.RecordRun(session.ID, "alice", 1, "package main // worse", ExecutionResult{
+		Output: "--- FAIL: TestA (0.00s)\n--- FAIL: TestB (0.00s)\nFAIL\n",
+	}, start.Add(2*time.Minute))
+	review, _ := json.Marshal(AICodeReview{OverallScore: 72, Complexity: ComplexityAnalysis{TimeComplexity: "O(1)"}})
+	interviews.RecordAI(session.ID, models.InterviewAIRequest{ChallengeID: 1, Kind: models.InterviewReview, Response: string(review), At: start})
+	interviews.RecordAI(session.ID, models.InterviewAIRequest{ChallengeID: 1, Kind: models.InterviewQuestions, Response: "Why?\n\nHow fast?", At: start})
+	ended, _ := interviews.Finish(session.ID, start.Add(3*time.Minute))
+
+	export, err := interviews.Export(ended)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if export.Report.ElapsedSeconds != 180 || len(export.Challenges) != 1 {
+		t.Fatalf("export = %+v", export)
+	}
+	c := export.Challenges[0]
+	if !strings.Contains(c.Statement, "Add two numbers.") {
+		t.Errorf("statement = %q", c.Statement)
+	}
+	if len(c.Tests) != 2 || !c.Tests[0].Passed || c.Tests[1].Passed || c.Tests[0].ElapsedMs != 10 {
+		t.Errorf("tests of the best run = %+v", c.Tests)
+	}
+	if c.Review == nil || c.Review.OverallScore != 72 || c.Review.Complexity.TimeComplexity != "O(1)" {
+		t.Errorf("review = %+v", c.Review)
+	}
+	if len(c.Questions) != 2 || c.Questions[1] != "How fast?" {
+		t.Errorf("questions = %q", c.Questions)
+	}
+}
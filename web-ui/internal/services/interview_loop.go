@@ -0,0 +1,338 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"web-ui/internal/models"
+)
+
+// defaultEstimatedMinutes stands in for a challenge without a usable estimate
+var defaultEstimatedMinutes = map[string]int{"Beginner": 20, "Intermediate": 45, "Advanced": 90}
+
+// estimateNumber matches the numbers in estimates such as "45-90 min" or
+// "1.5-3 hours"
+var estimateNumber = regexp.MustCompile(`\d+(\.\d+)?`)
+
+// loopsFile is the on-disk form of the interview loops
+type loopsFile struct {
+	Loops []models.InterviewLoop `json:"loops"`
+}
+
+// InterviewLoopService draws interview loops: sets of challenges picked at
+// random under constraints on difficulty, tags, tracks, what the candidate
+// has solved and the total estimated time. Loops draw classic challenges, the
+// ones an interview runs, unless other tracks are asked for.
+//
+// The draw shuffles the matching challenges with the loop's seed, so a seed
+// and constraints draw the same loop as long as the content and the
+// candidate's solved challenges stay the same. Loops are also kept in one
+// JSON file in the data directory, so a loop shared by ID stays as drawn.
+type InterviewLoopService struct {
+	path             string
+	challengeService *ChallengeService
+	packageService   *PackageService
+	releaseService   *ReleaseService
+	achievements     *AchievementService
+	profiles         *ProfileService
+
+	mutex sync.RWMutex
+	loops map[string]models.InterviewLoop
+}
+
+func NewInterviewLoopService(
+	path string,
+	challengeService *ChallengeService,
+	packageService *PackageService,
+	releaseService *ReleaseService,
+	achievements *AchievementService,
+	profiles *ProfileService,
+) *InterviewLoopService {
+	return &InterviewLoopService{
+		path:             path,
+		challengeService: challengeService,
+		packageService:   packageService,
+		releaseService:   releaseService,
+		achievements:     achievements,
+		profiles:         profiles,
+		loops:            make(map[string]models.InterviewLoop),
+	}
+}
+
+// Load reads the loops file; a missing file means no loops yet
+func (ls *InterviewLoopService) Load() error {
+	raw, err := os.ReadFile(ls.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var file loopsFile
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return fmt.Errorf("%s: %v", filepath.Base(ls.path), err)
+	}
+
+	loops := make(map[string]models.InterviewLoop, len(file.Loops))
+	for _, loop := range file.Loops {
+		loops[loop.ID] = loop
+	}
+	ls.mutex.Lock()
+	ls.loops = loops
+	ls.mutex.Unlock()
+	return nil
+}
+
+// save writes the loops file. The caller holds the write lock.
+func (ls *InterviewLoopService) save() error {
+	file := loopsFile{Loops: make([]models.InterviewLoop, 0, len(ls.loops))}
+	for _, loop := range ls.loops {
+		file.Loops = append(file.Loops, loop)
+	}
+	sort.Slice(file.Loops, func(i, j int) bool { return file.Loops[i].ID < file.Loops[j].ID })
+
+	raw, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(ls.path), 0755); err != nil {
+		return fmt.Errorf("failed to create interview loops directory: %v", err)
+	}
+	tmp := ls.path + ".tmp"
+	if err := os.WriteFile(tmp, append(raw, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %v", ls.path, err)
+	}
+	return os.Rename(tmp, ls.path)
+}
+
+// Loop looks up a loop by ID
+func (ls *InterviewLoopService) Loop(id string) (models.InterviewLoop, bool) {
+	ls.mutex.RLock()
+	defer ls.mutex.RUnlock()
+	loop, ok := ls.loops[id]
+	return loop, ok
+}
+
+// Create draws a loop for username and keeps it. A seed of 0 picks one at
+// random. Leaving out what someone else solved would show their progress, so
+// it takes a public profile.
+func (ls *InterviewLoopService) Create(username string, seed int64, constraints models.InterviewLoopConstraints) (models.InterviewLoop, error) {
+	if candidate := constraints.ExcludeSolved; candidate != "" && candidate != username && !ls.profiles.IsPublic(candidate) {
+		return models.InterviewLoop{}, fmt.Errorf("%s's profile is private, so their solved challenges cannot be left out", candidate)
+	}
+	if seed == 0 {
+		// Seeds stay below 2^53 so that JavaScript reads them back exactly
+		seed = rand.Int63n(1<<53-1) + 1
+	}
+	items, err := ls.Draw(seed, constraints)
+	if err != nil {
+		return models.InterviewLoop{}, err
+	}
+	loop := models.InterviewLoop{
+		ID:          newAttemptID(),
+		Seed:        seed,
+		Constraints: constraints,
+		Items:       items,
+		CreatedBy:   username,
+		CreatedAt:   time.Now(),
+	}
+	for _, item := range items {
+		loop.TotalMinutes += item.EstimatedMinutes
+	}
+
+	ls.mutex.Lock()
+	defer ls.mutex.Unlock()
+	ls.loops[loop.ID] = loop
+	if err := ls.save(); err != nil {
+		delete(ls.loops, loop.ID)
+		return models.InterviewLoop{}, err
+	}
+	return loop, nil
+}
+
+// Draw picks a loop's challenges: it shuffles the challenges matching the
+// constraints with the seed, then takes them in that order, skipping any
+// that would go over the time budget, until each difficulty has its count.
+// The loop is ordered easiest first.
+func (ls *InterviewLoopService) Draw(seed int64, constraints models.InterviewLoopConstraints) ([]models.InterviewLoopItem, error) {
+	wanted, total, err := loopCounts(constraints)
+	if err != nil {
+		return nil, err
+	}
+	tracks := map[string]bool{models.TrackClassic: len(constraints.Tracks) == 0}
+	for _, track := range constraints.Tracks {
+		if track != models.TrackClassic && track != models.TrackPackage && track != models.TrackRelease {
+			return nil, fmt.Errorf("unknown track %q", track)
+		}
+		tracks[track] = true
+	}
+	solved := make(map[string]solvedChallenge)
+	if constraints.ExcludeSolved != "" {
+		solved = ls.achievements.facts(constraints.ExcludeSolved).solved
+	}
+
+	var pool []models.InterviewLoopItem
+	for _, item := range ls.catalogue() {
+		if !tracks[item.Track] || !hasAnyTag(item.Tags, constraints.Tags) {
+			continue
+		}
+		if _, ok := solved[item.Track+":"+item.ChallengeID]; ok {
+			continue
+		}
+		pool = append(pool, item)
+	}
+	rand.New(rand.NewSource(seed)).Shuffle(len(pool), func(i, j int) { pool[i], pool[j] = pool[j], pool[i] })
+
+	items := []models.InterviewLoopItem{}
+	budget := constraints.BudgetMinutes
+	for _, item := range pool {
+		key := item.Difficulty
+		if _, ok := wanted[key]; !ok {
+			key = "" // any difficulty
+		}
+		if wanted[key] == 0 || (constraints.BudgetMinutes > 0 && item.EstimatedMinutes > budget) {
+			continue
+		}
+		wanted[key]--
+		budget -= item.EstimatedMinutes
+		items = append(items, item)
+	}
+	if len(items) < total {
+		if constraints.BudgetMinutes > 0 {
+			return nil, fmt.Errorf("only %d of %d challenges matching the constraints fit in %d minutes", len(items), total, constraints.BudgetMinutes)
+		}
+		return nil, fmt.Errorf("only %d of %d challenges match the constraints", len(items), total)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return difficultyRanks[items[i].Difficulty] < difficultyRanks[items[j].Difficulty]
+	})
+	return items, nil
+}
+
+// loopCounts works out how many challenges of each difficulty a loop wants,
+// with "" for any difficulty, and how many in all
+func loopCounts(constraints models.InterviewLoopConstraints) (map[string]int, int, error) {
+	wanted := make(map[string]int)
+	total := 0
+	if len(constraints.DifficultyMix) > 0 {
+		for difficulty, n := range constraints.DifficultyMix {
+			if _, ok := difficultyRanks[difficulty]; !ok {
+				return nil, 0, fmt.Errorf("unknown difficulty %q: use Beginner, Intermediate or Advanced", difficulty)
+			}
+			if n < 0 {
+				return nil, 0, fmt.Errorf("the difficulty mix has a negative count")
+			}
+			wanted[difficulty] = n
+			total += n
+		}
+	} else {
+		wanted[""], total = constraints.Count, constraints.Count
+	}
+	if total < 1 || total > MaxInterviewQuestions {
+		return nil, 0, fmt.Errorf("a loop has 1 to %d challenges", MaxInterviewQuestions)
+	}
+	if constraints.BudgetMinutes < 0 {
+		return nil, 0, fmt.Errorf("the time budget is negative")
+	}
+	return wanted, total, nil
+}
+
+// hasAnyTag reports whether a challenge has one of the wanted tags; tags
+// match by substring, so "http" matches "http-server". No wanted tags match
+// everything.
+func hasAnyTag(tags, wanted []string) bool {
+	if len(wanted) == 0 {
+		return true
+	}
+	for _, w := range wanted {
+		w = strings.ToLower(strings.TrimSpace(w))
+		for _, tag := range tags {
+			if w != "" && strings.Contains(strings.ToLower(tag), w) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// estimatedMinutes reads an estimate such as "45-90 min" or "1.5-3 hours" as
+// the middle of its range, falling back on the difficulty's default
+func estimatedMinutes(estimate, difficulty string) int {
+	numbers := estimateNumber.FindAllString(estimate, -1)
+	if len(numbers) == 0 {
+		if minutes, ok := defaultEstimatedMinutes[difficulty]; ok {
+			return minutes
+		}
+		return defaultEstimatedMinutes["Intermediate"]
+	}
+	sum := 0.0
+	for _, n := range numbers {
+		v, _ := strconv.ParseFloat(n, 64)
+		sum += v
+	}
+	minutes := sum / float64(len(numbers))
+	if strings.Contains(strings.ToLower(estimate), "hour") {
+		minutes *= 60
+	}
+	return int(minutes + 0.5)
+}
+
+// catalogue lists every available challenge on the three tracks in a fixed
+// order, classic first, so that a seed always shuffles it the same way
+func (ls *InterviewLoopService) catalogue() []models.InterviewLoopItem {
+	var catalogue []models.InterviewLoopItem
+	add := func(track, id, title, difficulty, estimate string, tags []string) {
+		if tags == nil {
+			tags = []string{}
+		}
+		catalogue = append(catalogue, models.InterviewLoopItem{
+			Track:            track,
+			ChallengeID:      id,
+			Title:            title,
+			Difficulty:       difficulty,
+			Tags:             tags,
+			EstimatedMinutes: estimatedMinutes(estimate, difficulty),
+			URL:              challengeURL(track, id),
+		})
+	}
+
+	for _, c := range ls.challengeService.ListChallenges() {
+		add(models.TrackClassic, strconv.Itoa(c.ID), c.Title, c.Difficulty, c.EstimatedTime, c.Tags)
+	}
+
+	packages := ls.packageService.GetPackages()
+	names := make([]string, 0, len(packages))
+	for name := range packages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		pkg := packages[name]
+		for _, id := range pkg.LearningPath {
+			info := pkg.ChallengeDetails[id]
+			if info == nil || info.Status != "available" {
+				continue
+			}
+			add(models.TrackPackage, name+"/"+id, info.Title, info.Difficulty, info.EstimatedTime, info.Tags)
+		}
+	}
+
+	for _, release := range ls.releaseService.GetReleases() {
+		for _, feature := range release.Features {
+			for _, c := range feature.Challenges {
+				add(models.TrackRelease, release.Version+"/"+feature.Slug+"/"+c.Slug, c.Title, c.Difficulty, c.EstimatedTime, c.Tags)
+			}
+		}
+	}
+	return catalogue
+}
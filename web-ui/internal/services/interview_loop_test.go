@@ -0,0 +1,132 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"web-ui/internal/config"
+	"web-ui/internal/models"
+)
+
+func TestInterviewLoopDraw(t *testing.T) {
+	root := t.TempDir()
+	metadata := []string{
+		`{"title": "Sum", "difficulty": "Beginner", "tags": ["basics"], "estimated_time": "15-25 min"}`,
+		`{"title": "Workers", "difficulty": "Intermediate", "tags": ["concurrency"], "estimated_time": "30 min"}`,
+		`{"title": "Server", "difficulty": "Intermediate", "tags": ["http-server"], "estimated_time": "45-75 min"}`,
+		`{"title": "Pipeline", "difficulty": "Advanced", "tags": ["concurrency", "generics"], "estimated_time": "1.5-2.5 hours"}`,
+		`{"title": "Cache", "difficulty": "Intermediate", "tags": ["generics"]}`,
+		`{"title": "Parser", "difficulty": "Beginner", "tags": ["strings"], "estimated_time": "20 min"}`,
+	}
+	for i, m := range metadata {
+		dir := filepath.Join(root, fmt.Sprintf("challenge-%d", i+1))
+		os.MkdirAll(dir, 0755)
+		os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Challenge\n"), 0644)
+		os.WriteFile(filepath.Join(dir, "solution-template.go"), []byte("package main\n"), 0644)
+		os.WriteFile(filepath.Join(dir, "metadata.json"), []byte(m), 0644)
+	}
+	cfg := config.Default()
+	cfg.Content.Root = root
+	cfg.Server.DataDir = t.TempDir()
+	challenges := NewChallengeService(cfg)
+	if err := challenges.LoadChallenges(); err != nil {
+		t.Fatal(err)
+	}
+	packages := NewPackageService(cfg)
+	releases := NewReleaseService(cfg, nil)
+	leaderboards := NewLeaderboardService(cfg, challenges, packages, nil)
+	store, _ := NewFileSubmissionStore(cfg.Server.DataDir)
+	attempts, _ := NewFileAttemptStore(cfg.Server.DataDir)
+	activity, _ := NewActivityService(cfg, attempts)
+	achievements, _ := NewAchievementService(cfg, challenges, packages, releases, leaderboards, activity, store, attempts)
+	path := filepath.Join(cfg.Server.DataDir, "interview_loops.json")
+	profiles, err := NewProfileService(cfg, challenges, packages, releases, NewUserService(cfg),
+		leaderboards, NewBadgeService(leaderboards, achievements), achievements, activity, store, attempts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loops := NewInterviewLoopService(path, challenges, packages, releases, achievements, profiles)
+
+	for estimate, want := range map[string]int{"15-25 min": 20, "30 min": 30, "1.5-2.5 hours": 120, "": 45} {
+		if got := estimatedMinutes(estimate, "Intermediate"); got != want {
+			t.Errorf("estimatedMinutes(%q) = %d, want %d", estimate, got, want)
+		}
+	}
+
+	// The same seed draws the same loop, easiest first
+	mix := models.InterviewLoopConstraints{DifficultyMix: map[string]int{"Beginner": 1, "Intermediate": 2}}
+	first, err := loops.Draw(42, mix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, _ := loops.Draw(42, mix)
+	if !reflect.DeepEqual(first, again) || len(first) != 3 || first[0].Difficulty != "Beginner" || first[2].Difficulty != "Intermediate" {
+		t.Errorf("draws = %+v and %+v", first, again)
+	}
+
+	// Tags match by substring, and solved challenges are left out
+	tagged, err := loops.Draw(7, models.InterviewLoopConstraints{Count: 2, Tags: []string{"http", "Generics"}, ExcludeSolved: "alice"})
+	if err != nil || len(tagged) != 2 {
+		t.Fatalf("tagged draw = %+v, %v", tagged, err)
+	}
+	attempts.AddAttempt(NewAttempt("alice", models.TrackClassic, "3", "submit", "package main", true, "", 10))
+	for seed := int64(1); seed <= 20; seed++ {
+		items, err := loops.Draw(seed, models.InterviewLoopConstraints{Count: 2, Tags: []string{"http", "generics"}, ExcludeSolved: "alice"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, item := range items {
+			if item.ChallengeID == "3" {
+				t.Fatal("alice's solved challenge was drawn")
+			}
+		}
+	}
+	if _, err := loops.Draw(1, models.InterviewLoopConstraints{Count: 3, Tags: []string{"http"}, ExcludeSolved: "alice"}); err == nil {
+		t.Error("a draw with too few matching challenges succeeded")
+	}
+
+	// The budget skips what does not fit
+	budgeted, err := loops.Draw(3, models.InterviewLoopConstraints{Count: 3, BudgetMinutes: 75})
+	if err != nil {
+		t.Fatal(err)
+	}
+	total := 0
+	for _, item := range budgeted {
+		total += item.EstimatedMinutes
+	}
+	if total > 75 {
+		t.Errorf("budgeted loop takes %d minutes", total)
+	}
+	if _, err := loops.Draw(3, models.InterviewLoopConstraints{Count: 3, BudgetMinutes: 40}); err == nil {
+		t.Error("a loop over budget was drawn")
+	}
+	if _, err := loops.Draw(3, models.InterviewLoopConstraints{Count: 1, Tracks: []string{"cobol"}}); err == nil {
+		t.Error("an unknown track was accepted")
+	}
+
+	// Loops are kept, and shared by ID
+	loop, err := loops.Create("carol", 42, mix)
+	if err != nil || loop.Seed != 42 || !reflect.DeepEqual(loop.Items, first) {
+		t.Fatalf("create = %+v, %v", loop, err)
+	}
+	reloaded := NewInterviewLoopService(path, challenges, packages, releases, achievements, profiles)
+	if err := reloaded.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if shared, ok := reloaded.Loop(loop.ID); !ok || shared.TotalMinutes != loop.TotalMinutes || shared.CreatedBy != "carol" {
+		t.Errorf("reloaded loop = %+v", shared)
+	}
+
+	// What a private user solved is theirs to leave out
+	profiles.SetPublic("alice", false)
+	private := models.InterviewLoopConstraints{Count: 1, ExcludeSolved: "alice"}
+	if _, err := loops.Create("carol", 1, private); err == nil {
+		t.Error("carol drew a loop leaving out what private alice solved")
+	}
+	if _, err := loops.Create("alice", 1, private); err != nil {
+		t.Errorf("alice could not leave out what alice solved: %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"web-ui/internal/models"
+	"web-ui/internal/utils"
+)
+
+// Replay builds an interview's timeline from its snapshots, runs and AI
+// requests. Each edit carries a diff from the challenge's previous snapshot,
+// or from its template for the first, and runs are marked where the tests
+// start or stop passing.
+func (is *InterviewService) Replay(session models.InterviewSession) models.InterviewReplay {
+	replay := models.InterviewReplay{
+		ID:           session.ID,
+		Username:     session.Username,
+		ChallengeIDs: session.ChallengeIDs,
+		StartedAt:    session.StartedAt,
+		EndsAt:       session.EndsAt,
+		EndedAt:      session.EndedAt,
+		Events:       []models.InterviewReplayEvent{},
+	}
+	event := func(at time.Time, challengeID int, kind string) models.InterviewReplayEvent {
+		return models.InterviewReplayEvent{
+			At:            at,
+			OffsetSeconds: offsetSeconds(session, at),
+			ChallengeID:   challengeID,
+			Kind:          kind,
+		}
+	}
+
+	previous := make(map[int]models.InterviewSnapshot)
+	for _, snapshot := range session.Snapshots {
+		from, fromLabel := is.template(snapshot.ChallengeID), fmt.Sprintf("challenge-%d template", snapshot.ChallengeID)
+		if last, ok := previous[snapshot.ChallengeID]; ok {
+			from, fromLabel = last.Code, snapshotLabel(session, last)
+		}
+		e := event(snapshot.At, snapshot.ChallengeID, models.InterviewEdit)
+		e.Diff = utils.UnifiedDiff(from, snapshot.Code, fromLabel, snapshotLabel(session, snapshot))
+		replay.Events = append(replay.Events, e)
+		previous[snapshot.ChallengeID] = snapshot
+	}
+
+	passing := make(map[int]bool)
+	for _, run := range session.Runs {
+		e := event(run.At, run.ChallengeID, models.InterviewTestRun)
+		e.Passed, e.TestsPassed, e.TestsTotal = run.Passed, run.TestsPassed, run.TestsTotal
+		switch {
+		case run.Passed && !passing[run.ChallengeID]:
+			e.Transition = models.InterviewNowPassing
+		case !run.Passed && passing[run.ChallengeID]:
+			e.Transition = models.InterviewNowFailing
+		}
+		passing[run.ChallengeID] = run.Passed
+		replay.Events = append(replay.Events, e)
+	}
+
+	for _, request := range session.AIRequests {
+		e := event(request.At, request.ChallengeID, request.Kind)
+		e.Level = request.Level
+		replay.Events = append(replay.Events, e)
+	}
+
+	// A run is recorded with a snapshot of its code at the same moment; the
+	// stable sort keeps the edit first
+	sort.SliceStable(replay.Events, func(i, j int) bool { return replay.Events[i].At.Before(replay.Events[j].At) })
+	return replay
+}
+
+// CodeAt reconstructs the code of every challenge of an interview as it was
+// at the given moment
+func (is *InterviewService) CodeAt(session models.InterviewSession, at time.Time) models.InterviewCodeAt {
+	codeAt := models.InterviewCodeAt{At: at, OffsetSeconds: offsetSeconds(session, at), Challenges: []models.InterviewCode{}}
+	for _, id := range session.ChallengeIDs {
+		code := models.InterviewCode{ChallengeID: id, Code: is.template(id)}
+		for _, snapshot := range session.Snapshots {
+			if snapshot.ChallengeID == id && !snapshot.At.After(at) {
+				savedAt := snapshot.At
+				code.Code, code.SavedAt = snapshot.Code, &savedAt
+			}
+		}
+		for _, run := range session.Runs {
+			if run.ChallengeID == id && !run.At.After(at) {
+				code.Passing = run.Passed
+			}
+		}
+		codeAt.Challenges = append(codeAt.Challenges, code)
+	}
+	return codeAt
+}
+
+// template returns a challenge's starting code, or nothing if it is gone
+func (is *InterviewService) template(challengeID int) string {
+	if challenge, ok := is.challengeService.GetChallenge(challengeID); ok {
+		return challenge.Template
+	}
+	return ""
+}
+
+// offsetSeconds is how far into the interview a moment is
+func offsetSeconds(session models.InterviewSession, at time.Time) int {
+	return int(at.Sub(session.StartedAt).Seconds())
+}
+
+// snapshotLabel names a snapshot in a diff by its challenge and offset
+func snapshotLabel(session models.InterviewSession, snapshot models.InterviewSnapshot) string {
+	offset := offsetSeconds(session, snapshot.At)
+	return fmt.Sprintf("challenge-%d@%02d:%02d", snapshot.ChallengeID, offset/60, offset%60)
+}
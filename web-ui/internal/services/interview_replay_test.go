@@ -0,0 +1,75 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"web-ui/internal/config"
+)
+
+func TestInterviewReplay(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "challenge-1"), 0755)
+	os.WriteFile(filepath.Join(root, "challenge-1", "README.md"), []byte("# Challenge\n"), 0644)
+	os.WriteFile(filepath.Join(root, "challenge-1", "solution-template.go"), []byte("package main\n"), 0644)
+	cfg := config.Default()
+	cfg.Content.Root = root
+	cfg.Server.DataDir = t.TempDir()
+	challenges := NewChallengeService(cfg)
+	if err := challenges.LoadChallenges(); err != nil {
+		t.Fatal(err)
+	}
+	teamsPath := filepath.Join(cfg.Server.DataDir, "teams.json")
+	os.WriteFile(teamsPath, []byte(`{"teams": [{"name": "backend", "members": ["alice"], "leads": ["carol"]}]}`), 0644)
+	teams := NewTeamService(teamsPath, nil, challenges, nil, nil, nil)
+	if err := teams.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	interviews, err := NewInterviewService(cfg.Server.DataDir, challenges, teams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !interviews.CanWatch("Carol", "alice") || interviews.CanWatch("bob", "alice") || interviews.CanWatch("alice", "carol") {
+		t.Error("only alice and her team's leads may watch her interviews")
+	}
+
+	session, _ := interviews.Start("alice", []int{1}, false, MinInterviewMinutes)
+	start := session.StartedAt
+	fail := ExecutionResult{Output: "--- FAIL: TestA (0.00s)\nFAIL\n"}
+	pass := ExecutionResult{Passed: true, Output: "--- PASS: TestA (0.00s)\nPASS\n"}
+	interviews.Snapshot(session.ID, 1, "package main\n\nfunc A() {}\n", start.Add(10*time.Second))
+	interviews.RecordRun(session.ID, "alice", 1, "package main\n\nfunc A() {}\n", fail, start.Add(20*time.Second))
+	interviews.RecordRun(session.ID, "alice", 1, "package main\n\nfunc A() int { return 1 }\n", pass, start.Add(40*time.Second))
+	interviews.RecordRun(session.ID, "alice", 1, "package main\n\nfunc A() int { return 1 }\n", pass, start.Add(50*time.Second))
+	session, _ = interviews.Session(session.ID)
+
+	replay := interviews.Replay(session)
+	var kinds []string
+	for _, e := range replay.Events {
+		kinds = append(kinds, e.Kind+e.Transition)
+	}
+	// The first run's code was already saved; the second's is an edit
+	if got := strings.Join(kinds, ","); got != "edit,run,edit,runpassing,run" {
+		t.Fatalf("events = %s", got)
+	}
+	if first := replay.Events[0]; first.OffsetSeconds != 10 || !strings.Contains(first.Diff, "+func A() {}") {
+		t.Errorf("first edit = %+v", first)
+	}
+
+	before := interviews.CodeAt(session, start.Add(5*time.Second)).Challenges[0]
+	between := interviews.CodeAt(session, start.Add(30*time.Second)).Challenges[0]
+	after := interviews.CodeAt(session, start.Add(45*time.Second)).Challenges[0]
+	if before.Code != "package main\n" || before.SavedAt != nil {
+		t.Errorf("code before the first edit = %+v", before)
+	}
+	if !strings.Contains(between.Code, "func A() {}") || between.Passing {
+		t.Errorf("code after the failing run = %+v", between)
+	}
+	if !strings.Contains(after.Code, "return 1") || !after.Passing {
+		t.Errorf("code after the passing run = %+v", after)
+	}
+}
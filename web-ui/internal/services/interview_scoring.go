@@ -0,0 +1,106 @@
+package services
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"web-ui/internal/models"
+)
+
+// CanScore reports whether scorer may score candidate's interviews against
+// a rubric: the leads of the candidate's teams may, but not the candidate
+func (is *InterviewService) CanScore(scorer, candidate string) bool {
+	return !strings.EqualFold(scorer, candidate) && is.CanWatch(scorer, candidate)
+}
+
+// Score records an interviewer's scores for one challenge of an ended
+// interview against a rubric, replacing any earlier scoring of it, and
+// updates the report's rubric scores. The AI's suggestions are kept as long
+// as the rubric is the same.
+func (is *InterviewService) Score(id string, challengeID int, rubric models.Rubric, scores []models.RubricScore, notes, scorer string, at time.Time) (models.InterviewScorecard, error) {
+	weighted, err := WeightedRubricScore(rubric, scores)
+	if err != nil {
+		return models.InterviewScorecard{}, err
+	}
+	return is.scorecard(id, challengeID, rubric, func(scorecard *models.InterviewScorecard) {
+		scorecard.Scores = slices.Clone(scores)
+		scorecard.WeightedScore = &weighted
+		scorecard.Notes = notes
+		scorecard.ScoredBy = scorer
+		scorecard.ScoredAt = &at
+	})
+}
+
+// Suggest records the AI's suggested scores for one challenge of an ended
+// interview, which an interviewer then starts from. Suggestions do not count
+// towards the report until an interviewer scores the challenge.
+func (is *InterviewService) Suggest(id string, challengeID int, rubric models.Rubric, suggestions []models.RubricScore, at time.Time) (models.InterviewScorecard, error) {
+	return is.scorecard(id, challengeID, rubric, func(scorecard *models.InterviewScorecard) {
+		scorecard.Suggestions = slices.Clone(suggestions)
+		scorecard.SuggestedAt = &at
+	})
+}
+
+// scorecard applies change to the scorecard of a challenge of an ended
+// interview, starting one on the rubric if there is none or it was scored on
+// another rubric or an earlier version of this one, and saves the session
+func (is *InterviewService) scorecard(id string, challengeID int, rubric models.Rubric, change func(*models.InterviewScorecard)) (models.InterviewScorecard, error) {
+	is.mutex.Lock()
+	defer is.mutex.Unlock()
+	session, ok := is.sessions[id]
+	if !ok {
+		return models.InterviewScorecard{}, fmt.Errorf("no interview %q", id)
+	}
+	if session.Report == nil {
+		return models.InterviewScorecard{}, ErrInterviewNotEnded
+	}
+	if !slices.Contains(session.ChallengeIDs, challengeID) {
+		return models.InterviewScorecard{}, fmt.Errorf("challenge %d is not part of interview %s", challengeID, id)
+	}
+
+	scorecards := slices.Clone(session.Scorecards)
+	i := slices.IndexFunc(scorecards, func(s models.InterviewScorecard) bool { return s.ChallengeID == challengeID })
+	if i < 0 {
+		scorecards = append(scorecards, models.InterviewScorecard{ChallengeID: challengeID})
+		i = len(scorecards) - 1
+	}
+	scorecard := &scorecards[i]
+	if !reflect.DeepEqual(scorecard.Rubric, rubric) {
+		*scorecard = models.InterviewScorecard{ChallengeID: challengeID}
+	}
+	scorecard.Rubric = rubric
+	change(scorecard)
+
+	// Sessions handed out share the report, so it is replaced, not changed
+	report := *session.Report
+	report.Challenges = slices.Clone(report.Challenges)
+	applyScorecards(&report, scorecards)
+	session.Scorecards, session.Report = scorecards, &report
+	return *scorecard, is.save(session)
+}
+
+// applyScorecards sets the rubric scores of a report from the challenges
+// scored by an interviewer; the overall rubric score is their mean
+func applyScorecards(report *models.InterviewReport, scorecards []models.InterviewScorecard) {
+	sum, scored := 0, 0
+	report.RubricScore = nil
+	for i := range report.Challenges {
+		cr := &report.Challenges[i]
+		cr.RubricScore = nil
+		for _, scorecard := range scorecards {
+			if scorecard.ChallengeID == cr.ChallengeID && scorecard.WeightedScore != nil {
+				score := *scorecard.WeightedScore
+				cr.RubricScore = &score
+				sum += score
+				scored++
+			}
+		}
+	}
+	if scored > 0 {
+		mean := (sum + scored/2) / scored
+		report.RubricScore = &mean
+	}
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"web-ui/internal/config"
+	"web-ui/internal/models"
+)
+
+func TestInterviewSessionRecordingAndTimeout(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"challenge-1", "challenge-2"} {
+		os.MkdirAll(filepath.Join(root, dir), 0755)
+		os.WriteFile(filepath.Join(root, dir, "README.md"), []byte("# Challenge\n"), 0644)
+		os.WriteFile(filepath.Join(root, dir, "solution-template.go"), []byte("package main\n"), 0644)
+	}
+	cfg := config.Default()
+	cfg.Content.Root = root
+	cfg.Server.DataDir = t.TempDir()
+	challenges := NewChallengeService(cfg)
+	if err := challenges.LoadChallenges(); err != nil {
+		t.Fatal(err)
+	}
+
+	interviews, err := NewInterviewService(cfg.Server.DataDir, challenges, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := interviews.PickChallenges([]int{1, 99}, 0, ""); err == nil {
+		t.Error("an interview on a missing challenge was accepted")
+	}
+	if ids, err := interviews.PickChallenges(nil, 2, ""); err != nil || len(ids) != 2 || ids[0] != 1 {
+		t.Errorf("random picks = %v, %v", ids, err)
+	}
+	if ids, err := interviews.PickChallenges([]int{2, 1, 2}, 0, ""); err != nil || !reflect.DeepEqual(ids, []int{2, 1}) {
+		t.Errorf("chosen picks = %v, %v", ids, err)
+	}
+
+	session, err := interviews.Start("alice", []int{1, 2}, false, MinInterviewMinutes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	interviews.Snapshot(session.ID, 1, "package main // v1", now)
+	interviews.Snapshot(session.ID, 1, "package main // v1", now) // unchanged, skipped
+	attempt, err := interviews.RecordRun(session.ID, "alice", 1, "package main // v2",
+		ExecutionResult{Passed: true, Output: "--- PASS: TestA (0.00s)\n--- PASS: TestB (0.00s)\nPASS\n"}, now)
+	if err != nil || attempt.TestsPassed != 2 || attempt.ChallengeID != "1" {
+		t.Fatalf("run attempt = %+v, %v", attempt, err)
+	}
+	interviews.RecordAI(session.ID, models.InterviewAIRequest{ChallengeID: 2, Kind: models.InterviewHint, Level: 1, Response: "think", At: now})
+
+	// Time runs out: the session ends by itself and takes nothing more
+	late := session.EndsAt.Add(time.Second)
+	if err := interviews.Snapshot(session.ID, 2, "package main", late); !errors.Is(err, ErrInterviewOver) {
+		t.Errorf("late snapshot: %v", err)
+	}
+	interviews.Expire(late)
+	ended, _ := interviews.Session(session.ID)
+	if ended.Status != models.InterviewTimedOut || ended.Report == nil || len(ended.Snapshots) != 2 {
+		t.Fatalf("ended session = %+v", ended)
+	}
+	// One of two challenges solved, less one hint
+	report := ended.Report
+	if report.Solved != 1 || report.Hints != 1 || report.Score != 45 || report.Challenges[0].FinalCode != "package main // v2" {
+		t.Errorf("report = %+v", report)
+	}
+
+	// Sessions survive a restart
+	interviews, _ = NewInterviewService(cfg.Server.DataDir, challenges, nil)
+	if err := interviews.Load(); err != nil {
+		t.Fatal(err)
+	}
+	summaries := interviews.Sessions("Alice")
+	if len(summaries) != 1 || summaries[0].Score == nil || *summaries[0].Score != 45 {
+		t.Errorf("reloaded summaries = %+v", summaries)
+	}
+	if _, ok := interviews.Active("alice"); ok {
+		t.Error("an ended interview is still active")
+	}
+}
@@ -0,0 +1,291 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// jsonlLog is an append-only file of JSON records, one per line, mirrored in
+// memory. It is the storage primitive behind the embedded stores: records
+// survive restarts, appends are a single write, and a torn last line (from a
+// crash mid-write) is cut off on load instead of failing start-up.
+type jsonlLog[T any] struct {
+	path    string
+	mu      sync.RWMutex
+	records []T
+}
+
+// openJSONL loads every record in path, creating the parent directory if needed.
+func openJSONL[T any](path string) (*jsonlLog[T], error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %v", err)
+	}
+
+	l := &jsonlLog[T]{path: path}
+	if err := repairTornTail(path); err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %v", path, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024) // submissions carry whole source files
+	for scanner.Scan() {
+		var record T
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			continue
+		}
+		l.records = append(l.records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+
+	return l, nil
+}
+
+// Append writes record to disk and then adds it to the in-memory mirror.
+func (l *jsonlLog[T]) Append(record T) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %v", l.path, err)
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %v", l.path, err)
+	}
+
+	if err := appendLine(file, line, info.Size()); err != nil {
+		return fmt.Errorf("failed to write %s: %v", l.path, err)
+	}
+
+	l.records = append(l.records, record)
+	return nil
+}
+
+// appendLine writes line and its newline to the end of file, which was size
+// bytes long. A partial write is cut off again, so the file keeps ending at a
+// record boundary and the offsets indexed after it stay right.
+func appendLine(file *os.File, line []byte, size int64) error {
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		if terr := file.Truncate(size); terr != nil {
+			return fmt.Errorf("%v (and failed to cut the partial record off: %v)", err, terr)
+		}
+		return err
+	}
+	return nil
+}
+
+// Filter returns the records accepted by keep, newest first.
+func (l *jsonlLog[T]) Filter(keep func(T) bool) []T {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	var out []T
+	for i := len(l.records) - 1; i >= 0; i-- {
+		if keep(l.records[i]) {
+			out = append(out, l.records[i])
+		}
+	}
+	return out
+}
+
+// repairTornTail cuts a torn last line, left by a crash mid-write, off the
+// end of a JSON-lines file. Left in place, the next append would run on from
+// it and be lost with it. A last line that is a whole record but only lacks
+// its newline, as after a hand edit, is kept and given one instead.
+func repairTornTail(path string) error {
+	file, err := os.OpenFile(path, os.O_RDWR, 0)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %v", path, err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	// Walk back from the end in blocks to the last newline
+	end := info.Size()
+	keep := int64(0)
+	buf := make([]byte, 64*1024)
+	for pos := end; pos > 0; {
+		n := int64(len(buf))
+		if pos < n {
+			n = pos
+		}
+		pos -= n
+		if _, err := file.ReadAt(buf[:n], pos); err != nil {
+			return fmt.Errorf("failed to read %s: %v", path, err)
+		}
+		if i := bytes.LastIndexByte(buf[:n], '\n'); i >= 0 {
+			keep = pos + int64(i) + 1
+			break
+		}
+	}
+	if keep == end {
+		return nil
+	}
+
+	tail := make([]byte, end-keep)
+	if _, err := file.ReadAt(tail, keep); err != nil {
+		return fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	if len(bytes.TrimSpace(tail)) > 0 && json.Valid(tail) {
+		if _, err := file.WriteAt([]byte("\n"), end); err != nil {
+			return fmt.Errorf("failed to write %s: %v", path, err)
+		}
+		return nil
+	}
+	return file.Truncate(keep)
+}
+
+// jsonlIndex is an append-only file of JSON records like jsonlLog, for
+// records too big to mirror in memory, such as submissions carrying whole
+// source files and test output. Only a small key per record and where the
+// record sits in the file are kept in memory; the records a query selects are
+// read back from disk.
+type jsonlIndex[T any, K any] struct {
+	path    string
+	key     func(T) K
+	mu      sync.RWMutex
+	entries []jsonlEntry[K]
+	size    int64 // of the file, where the next record goes
+}
+
+// jsonlEntry locates one record of a jsonlIndex
+type jsonlEntry[K any] struct {
+	key    K
+	offset int64
+	length int // without the newline
+}
+
+// openJSONLIndex indexes every record in path, creating the parent directory
+// if needed
+func openJSONLIndex[T any, K any](path string, key func(T) K) (*jsonlIndex[T, K], error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %v", err)
+	}
+	if err := repairTornTail(path); err != nil {
+		return nil, err
+	}
+
+	ix := &jsonlIndex[T, K]{path: path, key: key}
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return ix, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %v", path, err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReaderSize(file, 64*1024)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			var record T
+			if json.Unmarshal(line, &record) == nil {
+				ix.entries = append(ix.entries, jsonlEntry[K]{key: key(record), offset: ix.size, length: len(bytes.TrimSuffix(line, []byte("\n")))})
+			}
+			ix.size += int64(len(line))
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", path, err)
+		}
+	}
+	return ix, nil
+}
+
+// Append writes record to disk and then indexes it
+func (ix *jsonlIndex[T, K]) Append(record T) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	file, err := os.OpenFile(ix.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %v", ix.path, err)
+	}
+	defer file.Close()
+
+	if err := appendLine(file, line, ix.size); err != nil {
+		return fmt.Errorf("failed to write %s: %v", ix.path, err)
+	}
+
+	ix.entries = append(ix.entries, jsonlEntry[K]{key: ix.key(record), offset: ix.size, length: len(line)})
+	ix.size += int64(len(line)) + 1
+	return nil
+}
+
+// Filter returns the entries whose keys keep accepts, newest appended first
+func (ix *jsonlIndex[T, K]) Filter(keep func(K) bool) []jsonlEntry[K] {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	var out []jsonlEntry[K]
+	for i := len(ix.entries) - 1; i >= 0; i-- {
+		if keep(ix.entries[i].key) {
+			out = append(out, ix.entries[i])
+		}
+	}
+	return out
+}
+
+// Read reads the records of entries back from disk, in the same order
+func (ix *jsonlIndex[T, K]) Read(entries []jsonlEntry[K]) ([]T, error) {
+	records := make([]T, 0, len(entries))
+	if len(entries) == 0 {
+		return records, nil
+	}
+	file, err := os.Open(ix.path)
+	if err != nil {
+		return records, fmt.Errorf("failed to open %s: %v", ix.path, err)
+	}
+	defer file.Close()
+
+	for _, entry := range entries {
+		line := make([]byte, entry.length)
+		if _, err := file.ReadAt(line, entry.offset); err != nil {
+			return records, fmt.Errorf("failed to read %s: %v", ix.path, err)
+		}
+		var record T
+		if err := json.Unmarshal(line, &record); err != nil {
+			return records, fmt.Errorf("failed to decode %s at %d: %v", ix.path, entry.offset, err)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
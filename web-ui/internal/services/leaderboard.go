@@ -0,0 +1,239 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"web-ui/internal/config"
+	"web-ui/internal/models"
+	"web-ui/internal/scoreboard"
+)
+
+// LeaderboardService aggregates the per-challenge scoreboards into the main and
+// package leaderboards. The web UI, the API and the README generator all go
+// through it, so they rank users the same way.
+type LeaderboardService struct {
+	cfg               *config.Config
+	challengeService  *ChallengeService
+	packageService    *PackageService
+	scoreboardService *ScoreboardService
+}
+
+// NewLeaderboardService creates a new leaderboard service
+func NewLeaderboardService(cfg *config.Config, challengeService *ChallengeService, packageService *PackageService, scoreboardService *ScoreboardService) *LeaderboardService {
+	return &LeaderboardService{
+		cfg:               cfg,
+		challengeService:  challengeService,
+		packageService:    packageService,
+		scoreboardService: scoreboardService,
+	}
+}
+
+// achievementTier is a named level reached at a number of completed challenges
+type achievementTier struct {
+	min  int
+	icon string
+	name string
+}
+
+// classicAchievements are the main leaderboard levels, highest first
+var classicAchievements = []achievementTier{
+	{20, "🔥", "Master"},
+	{15, "⭐", "Expert"},
+	{10, "💪", "Advanced"},
+	{5, "🚀", "Intermediate"},
+	{0, "🌱", "Beginner"},
+}
+
+// packageAchievements are the package leaderboard levels, highest first
+var packageAchievements = []achievementTier{
+	{15, "🔥", "Package Master"},
+	{10, "⭐", "Package Expert"},
+	{5, "💪", "Package Advanced"},
+	{3, "🚀", "Package Intermediate"},
+	{0, "🌱", "Package Beginner"},
+}
+
+// achievementFor returns the highest tier reached with count completions
+func achievementFor(tiers []achievementTier, count int) achievementTier {
+	for _, tier := range tiers {
+		if count >= tier.min {
+			return tier
+		}
+	}
+	return tiers[len(tiers)-1]
+}
+
+// MainCompletions reads every classic challenge scoreboard and returns, per
+// user, the set of challenges where they passed ALL tests
+func (ls *LeaderboardService) MainCompletions() map[string]map[int]bool {
+	userCompletions := make(map[string]map[int]bool)
+
+	for challengeID := range ls.challengeService.GetChallenges() {
+		board, err := scoreboard.Load(ls.cfg.ChallengeDir(challengeID))
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range board.Entries {
+			if !entry.Completed() {
+				continue
+			}
+			if userCompletions[entry.Username] == nil {
+				userCompletions[entry.Username] = make(map[int]bool)
+			}
+			userCompletions[entry.Username][challengeID] = true
+		}
+	}
+
+	return userCompletions
+}
+
+// MainRank returns the user's rank by completed challenges, or 0 if unranked
+func (ls *LeaderboardService) MainRank(username string) int {
+	return mainRank(ls.MainCompletions(), username)
+}
+
+// mainRank ranks username among completions, as returned by MainCompletions
+func mainRank(completions map[string]map[int]bool, username string) int {
+	userCompletions := make(map[string]int)
+	for user, completed := range completions {
+		userCompletions[user] = len(completed)
+	}
+
+	targetCompletions := userCompletions[username]
+	if targetCompletions == 0 {
+		return 0 // User is unranked
+	}
+
+	// Users sharing a completion count share a rank
+	rank := 1
+	for user, completions := range userCompletions {
+		if user != username && completions > targetCompletions {
+			rank++
+		}
+	}
+
+	return rank
+}
+
+// MainLeaderboard ranks every user with a completed classic challenge, by
+// completion count (descending) and then username
+func (ls *LeaderboardService) MainLeaderboard() []models.LeaderboardUser {
+	totalChallenges := len(ls.challengeService.GetChallenges())
+	sponsors := LoadSponsors()
+
+	var leaderboard []models.LeaderboardUser
+	for username, completions := range ls.MainCompletions() {
+		completedCount := len(completions)
+		tier := achievementFor(classicAchievements, completedCount)
+
+		leaderboard = append(leaderboard, models.LeaderboardUser{
+			Username:            username,
+			CompletedCount:      completedCount,
+			CompletionRate:      float64(completedCount) / float64(totalChallenges) * 100,
+			CompletedChallenges: completions,
+			Achievement:         tier.icon + " " + tier.name,
+			IsSponsor:           sponsors[username],
+		})
+	}
+
+	sort.Slice(leaderboard, func(i, j int) bool {
+		if leaderboard[i].CompletedCount != leaderboard[j].CompletedCount {
+			return leaderboard[i].CompletedCount > leaderboard[j].CompletedCount
+		}
+		return leaderboard[i].Username < leaderboard[j].Username
+	})
+
+	for i := range leaderboard {
+		leaderboard[i].Rank = i + 1
+	}
+
+	return leaderboard
+}
+
+// PackageChallenges returns a package's challenges in learning path order
+func (ls *LeaderboardService) PackageChallenges(packageName string) ([]*models.PackageChallenge, error) {
+	pkg, err := ls.packageService.GetPackage(packageName)
+	if err != nil {
+		return nil, err
+	}
+
+	challengesMap, err := ls.packageService.GetPackageChallenges(packageName)
+	if err != nil {
+		challengesMap = make(map[string]*models.PackageChallenge)
+	}
+
+	var challenges []*models.PackageChallenge
+	for _, id := range pkg.LearningPath {
+		if ch, ok := challengesMap[id]; ok {
+			challenges = append(challenges, ch)
+		}
+	}
+	return challenges, nil
+}
+
+// PackageCompletions returns, per user, when they first passed ALL tests of each
+// of the given package challenges
+func (ls *LeaderboardService) PackageCompletions(packageName string, challenges []*models.PackageChallenge) map[string]map[string]time.Time {
+	userCompletions := make(map[string]map[string]time.Time)
+
+	for _, challenge := range challenges {
+		board, err := scoreboard.Load(ls.cfg.PackageChallengeDir(packageName, challenge.ID))
+		if err != nil {
+			continue
+		}
+
+		key := PackageSubmissionKey(packageName, challenge.ID)
+		for _, entry := range board.Entries {
+			if !entry.Completed() {
+				continue
+			}
+			if userCompletions[entry.Username] == nil {
+				userCompletions[entry.Username] = make(map[string]time.Time)
+			}
+			userCompletions[entry.Username][challenge.ID] = ls.scoreboardService.SubmittedAt(key, entry)
+		}
+	}
+
+	return userCompletions
+}
+
+// PackageLeaderboard ranks users by completed challenges in a package. Ties go to
+// whoever finished their last challenge first.
+func (ls *LeaderboardService) PackageLeaderboard(packageName string, challenges []*models.PackageChallenge) []models.PackageScoreboardEntry {
+	sponsors := LoadSponsors()
+
+	var leaderboard []models.PackageScoreboardEntry
+	for username, completed := range ls.PackageCompletions(packageName, challenges) {
+		var lastSubmission time.Time
+		for _, at := range completed {
+			if at.After(lastSubmission) {
+				lastSubmission = at
+			}
+		}
+
+		leaderboard = append(leaderboard, models.PackageScoreboardEntry{
+			Username:    username,
+			PackageName: packageName,
+			ChallengeID: "", // Not specific to one challenge
+			SubmittedAt: lastSubmission,
+			TestsPassed: len(completed),
+			TestsTotal:  len(challenges),
+			IsSponsor:   sponsors[username],
+		})
+	}
+
+	sort.Slice(leaderboard, func(i, j int) bool {
+		if leaderboard[i].TestsPassed != leaderboard[j].TestsPassed {
+			return leaderboard[i].TestsPassed > leaderboard[j].TestsPassed
+		}
+		if a, b := leaderboard[i].SubmittedAt, leaderboard[j].SubmittedAt; !a.Equal(b) {
+			// An unknown date does not win a tie
+			return b.IsZero() || (!a.IsZero() && a.Before(b))
+		}
+		return leaderboard[i].Username < leaderboard[j].Username
+	})
+
+	return leaderboard
+}
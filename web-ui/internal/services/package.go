@@ -2,30 +2,37 @@ package services
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"web-ui/internal/config"
 	"web-ui/internal/models"
 )
 
 type PackageService struct {
+	cfg          *config.Config
 	httpClient   *http.Client
 	packagesPath string
-	// In-memory cache to avoid repeated GitHub API calls (no TTL; load once per process)
+	// In-memory cache to avoid repeated GitHub API calls. Reload swaps it
+	// wholesale and only fetches stars for packages it has not seen before.
+	mutex          sync.RWMutex
 	cachedPackages map[string]*models.Package
 }
 
-func NewPackageService() *PackageService {
+func NewPackageService(cfg *config.Config) *PackageService {
 	return &PackageService{
+		cfg: cfg,
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
-		packagesPath:   "../packages", // Relative to web-ui directory
+		packagesPath:   cfg.PackagesDir(),
 		cachedPackages: nil,
 	}
 }
@@ -48,45 +55,77 @@ type PackageMetadata struct {
 }
 
 func (s *PackageService) LoadPackages() error {
-	// This method is called to ensure packages are loaded
-	// Load packages and count them for logging
-	packages := s.GetPackages()
-	fmt.Printf("Loaded %d packages with real-time GitHub stars\n", len(packages))
-	return nil
+	err := s.Reload()
+	fmt.Printf("Loaded %d packages with real-time GitHub stars\n", len(s.GetPackages()))
+	return err
 }
 
+// GetPackages returns all packages, loading them on first use. The map must
+// not be modified.
 func (s *PackageService) GetPackages() map[string]*models.Package {
 	// Serve from cache if already populated
-	if s.cachedPackages != nil {
-		return s.cachedPackages
+	s.mutex.RLock()
+	packages := s.cachedPackages
+	s.mutex.RUnlock()
+	if packages != nil {
+		return packages
 	}
 
+	s.Reload()
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.cachedPackages
+}
+
+// Reload re-reads every package from disk and swaps them in at once. A package
+// that fails to load keeps its previously loaded version, and the failures are
+// returned together.
+func (s *PackageService) Reload() error {
+	s.mutex.RLock()
+	previous := s.cachedPackages
+	s.mutex.RUnlock()
+
 	packages := make(map[string]*models.Package)
 
 	// Read packages directory
 	entries, err := os.ReadDir(s.packagesPath)
 	if err != nil {
 		fmt.Printf("Error reading packages directory: %v\n", err)
-		// Populate cache (empty) to prevent repeated attempts this run
-		s.cachedPackages = packages
-		return s.cachedPackages
+		if previous == nil {
+			// Populate cache (empty) to prevent repeated attempts
+			s.mutex.Lock()
+			s.cachedPackages = packages
+			s.mutex.Unlock()
+		}
+		return fmt.Errorf("reading packages directory: %v", err)
 	}
 
+	var errs []error
 	for _, entry := range entries {
-		if entry.IsDir() {
-			packagePath := filepath.Join(s.packagesPath, entry.Name())
-			if pkg := s.loadPackage(packagePath, entry.Name()); pkg != nil {
-				packages[pkg.Name] = pkg
+		if !entry.IsDir() {
+			continue
+		}
+		packagePath := filepath.Join(s.packagesPath, entry.Name())
+		pkg, err := s.loadPackage(packagePath, entry.Name(), previous[entry.Name()])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("package %s: %v", entry.Name(), err))
+			if old, ok := previous[entry.Name()]; ok {
+				packages[entry.Name()] = old
 			}
+			continue
 		}
+		packages[pkg.Name] = pkg
 	}
 
-	// Populate cache once per process lifetime
+	s.mutex.Lock()
 	s.cachedPackages = packages
-	return s.cachedPackages
+	s.mutex.Unlock()
+	return errors.Join(errs...)
 }
 
-func (s *PackageService) loadPackage(packagePath, packageName string) *models.Package {
+// loadPackage reads a package from disk. Stars are carried over from the
+// previous load when there is one, to keep reloads off the GitHub API.
+func (s *PackageService) loadPackage(packagePath, packageName string, previous *models.Package) (*models.Package, error) {
 	// Ensure httpClient is initialized
 	if s.httpClient == nil {
 		s.httpClient = &http.Client{
@@ -99,18 +138,19 @@ func (s *PackageService) loadPackage(packagePath, packageName string) *models.Pa
 	metadataBytes, err := os.ReadFile(metadataPath)
 	if err != nil {
 		fmt.Printf("Error reading package.json for %s: %v\n", packageName, err)
-		return nil
+		return nil, err
 	}
 
 	var metadata PackageMetadata
 	if err := json.Unmarshal(metadataBytes, &metadata); err != nil {
 		fmt.Printf("Error parsing package.json for %s: %v\n", packageName, err)
-		return nil
+		return nil, fmt.Errorf("invalid package.json: %v", err)
 	}
 
 	// Fetch real-time GitHub stars
-	stars := s.fetchGitHubStars(metadata.GitHubURL)
-	if stars > 0 {
+	if previous != nil && previous.Stars > 0 {
+		metadata.Stars = previous.Stars
+	} else if stars := s.fetchGitHubStars(metadata.GitHubURL); stars > 0 {
 		metadata.Stars = stars
 	}
 
@@ -133,7 +173,7 @@ func (s *PackageService) loadPackage(packagePath, packageName string) *models.Pa
 		EstimatedTime:    metadata.EstimatedTime,
 		RealWorldUsage:   metadata.RealWorldUsage,
 		ChallengeDetails: challengeDetails,
-	}
+	}, nil
 }
 
 // loadChallengeDetails dynamically loads metadata for each challenge in the learning path
@@ -521,3 +561,41 @@ func (s *PackageService) GetPackageChallenge(packageID, challengeID string) (*mo
 
 	return challenge, nil
 }
+
+// SubmissionsDir is where solutions to a package challenge are saved, one
+// directory per user
+func (s *PackageService) SubmissionsDir(packageID, challengeID string) string {
+	return filepath.Join(s.packagesPath, packageID, challengeID, "submissions")
+}
+
+// SaveSubmission writes a user's solution to a package challenge into the
+// content tree, ready to be committed
+func (s *PackageService) SaveSubmission(username, packageID, challengeID, code string) SaveSubmissionResponse {
+	submissionDir := filepath.Join(s.SubmissionsDir(packageID, challengeID), username)
+	solutionFile := filepath.Join(submissionDir, "solution.go")
+	if err := os.MkdirAll(submissionDir, 0755); err != nil {
+		return SaveSubmissionResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to create submission directory: %v", err),
+		}
+	}
+	if err := os.WriteFile(solutionFile, []byte(code), 0644); err != nil {
+		return SaveSubmissionResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to save solution: %v", err),
+		}
+	}
+
+	relativePath := filepath.Join("packages", packageID, challengeID, "submissions", username, "solution.go")
+	return SaveSubmissionResponse{
+		Success:  true,
+		Message:  "Solution saved to filesystem",
+		FilePath: solutionFile,
+		GitCommands: []string{
+			"cd " + s.cfg.Content.Root,
+			fmt.Sprintf("git add %s", relativePath),
+			fmt.Sprintf("git commit -m \"Add solution for %s %s by %s\"", packageID, challengeID, username),
+			"git push origin main",
+		},
+	}
+}
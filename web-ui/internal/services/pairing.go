@@ -0,0 +1,442 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf16"
+
+	"web-ui/internal/models"
+)
+
+// Pairing limits
+const (
+	MaxPairParticipants = 10
+	maxPairChat         = 200    // lines kept per room
+	maxPairChatLength   = 2000   // characters per line
+	maxPairNotes        = 100000 // characters
+	maxPairText         = 200000 // UTF-16 code units of shared code
+	pairClientBuffer    = 256    // messages queued per connection before it is dropped
+	pairRoomIdle        = 24 * time.Hour
+)
+
+// PairingService runs live pairing rooms. Each room holds one shared text
+// that participants edit concurrently: they send operations against the
+// revision they last saw and the room transforms them past everything applied
+// since, so every editor converges. Rooms live in memory and are dropped a
+// day after the last participant leaves.
+type PairingService struct {
+	challengeService *ChallengeService
+	executionService *ExecutionService
+
+	mutex sync.Mutex
+	rooms map[string]*pairRoom
+}
+
+// pairRoom is a room's state, guarded by its own mutex
+type pairRoom struct {
+	mutex        sync.Mutex
+	room         models.PairRoom
+	text         []uint16
+	history      []TextOperation // history[i] took the text from revision i to i+1
+	interviewers map[string]bool // lowercase usernames
+	clients      map[string]*PairClient
+	chat         []models.PairChatMessage
+	notes        string
+	run          *models.PairRun
+	running      bool
+	lastActive   time.Time
+}
+
+// PairClient is one connection to a room. The handler writes whatever
+// arrives on Send to the WebSocket; Send is closed when the client leaves or
+// falls too far behind.
+type PairClient struct {
+	Send        chan []byte
+	participant models.PairParticipant
+	room        *pairRoom
+}
+
+func NewPairingService(challengeService *ChallengeService, executionService *ExecutionService) *PairingService {
+	return &PairingService{
+		challengeService: challengeService,
+		executionService: executionService,
+		rooms:            make(map[string]*pairRoom),
+	}
+}
+
+// randomToken returns n random bytes in hex
+func randomToken(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("crypto/rand: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
+
+// Create opens a room on a challenge, with username as its first interviewer
+func (ps *PairingService) Create(username string, challengeID int) (models.PairRoom, error) {
+	challenge, ok := ps.challengeService.GetChallenge(challengeID)
+	if !ok {
+		return models.PairRoom{}, fmt.Errorf("there is no challenge %d", challengeID)
+	}
+	now := time.Now()
+	r := &pairRoom{
+		room: models.PairRoom{
+			ID:             randomToken(8),
+			ChallengeID:    challengeID,
+			CreatedBy:      username,
+			CreatedAt:      now,
+			InterviewerKey: randomToken(16),
+		},
+		text:         utf16.Encode([]rune(challenge.Template)),
+		interviewers: map[string]bool{strings.ToLower(username): true},
+		clients:      make(map[string]*PairClient),
+		lastActive:   now,
+	}
+
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+	ps.sweep(now)
+	ps.rooms[r.room.ID] = r
+	return r.room, nil
+}
+
+// sweep drops rooms nobody has been in for pairRoomIdle; the caller holds the
+// service lock
+func (ps *PairingService) sweep(now time.Time) {
+	for id, r := range ps.rooms {
+		r.mutex.Lock()
+		idle := len(r.clients) == 0 && now.Sub(r.lastActive) > pairRoomIdle
+		r.mutex.Unlock()
+		if idle {
+			delete(ps.rooms, id)
+		}
+	}
+}
+
+// Room looks up a room, without its interviewer key
+func (ps *PairingService) Room(id string) (models.PairRoom, bool) {
+	ps.mutex.Lock()
+	r, ok := ps.rooms[id]
+	ps.mutex.Unlock()
+	if !ok {
+		return models.PairRoom{}, false
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	room := r.room
+	room.InterviewerKey = ""
+	return room, true
+}
+
+// IsInterviewer reports whether username joins a room as an interviewer: its
+// creator does, as does anyone with the interviewer key or who has joined
+// with it before
+func (ps *PairingService) IsInterviewer(id, username, key string) bool {
+	ps.mutex.Lock()
+	r, ok := ps.rooms[id]
+	ps.mutex.Unlock()
+	if !ok {
+		return false
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.isInterviewer(username, key)
+}
+
+// isInterviewer is IsInterviewer for the caller holding the room lock
+func (r *pairRoom) isInterviewer(username, key string) bool {
+	return r.interviewers[strings.ToLower(username)] ||
+		(key != "" && subtle.ConstantTimeCompare([]byte(key), []byte(r.room.InterviewerKey)) == 1)
+}
+
+// Join connects username to a room. The welcome message is already queued on
+// the client's Send.
+func (ps *PairingService) Join(id, username, key string) (*PairClient, error) {
+	ps.mutex.Lock()
+	r, ok := ps.rooms[id]
+	ps.mutex.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("there is no room %q", id)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if len(r.clients) >= MaxPairParticipants {
+		return nil, fmt.Errorf("the room is full")
+	}
+	role := models.PairCandidate
+	if r.isInterviewer(username, key) {
+		role = models.PairInterviewer
+		r.interviewers[strings.ToLower(username)] = true
+	}
+	client := &PairClient{
+		Send:        make(chan []byte, pairClientBuffer),
+		participant: models.PairParticipant{ID: randomToken(4), Username: username, Role: role},
+		room:        r,
+	}
+	r.clients[client.participant.ID] = client
+	r.lastActive = time.Now()
+
+	code := string(utf16.Decode(r.text))
+	room := r.room
+	welcome := models.PairMessage{
+		Type:         "welcome",
+		You:          &client.participant,
+		Room:         &room,
+		Revision:     len(r.history),
+		Code:         &code,
+		Participants: r.participants(),
+		Chat:         r.chat,
+		Run:          r.run,
+	}
+	if role == models.PairInterviewer {
+		notes := r.notes
+		welcome.Notes = &notes
+	} else {
+		welcome.Room.InterviewerKey = ""
+	}
+	r.deliver(client, welcome)
+	r.broadcast(models.PairMessage{Type: "presence", Participants: r.participants()}, client)
+	return client, nil
+}
+
+// Leave disconnects a client; leaving twice is harmless
+func (ps *PairingService) Leave(client *PairClient) {
+	r := client.room
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.drop(client)
+}
+
+// drop removes a client and tells the others; the caller holds the room lock
+func (r *pairRoom) drop(client *PairClient) {
+	if _, ok := r.clients[client.participant.ID]; !ok {
+		return
+	}
+	delete(r.clients, client.participant.ID)
+	close(client.Send)
+	r.lastActive = time.Now()
+	r.broadcast(models.PairMessage{Type: "presence", Participants: r.participants()}, nil)
+}
+
+// participants lists who is connected; the caller holds the room lock
+func (r *pairRoom) participants() []models.PairParticipant {
+	participants := make([]models.PairParticipant, 0, len(r.clients))
+	for _, c := range r.clients {
+		participants = append(participants, c.participant)
+	}
+	return participants
+}
+
+// deliver queues a message for one client, dropping the client if it has
+// fallen behind; the caller holds the room lock
+func (r *pairRoom) deliver(client *PairClient, message models.PairMessage) {
+	raw, err := json.Marshal(message)
+	if err != nil {
+		log.Printf("pairing: %v", err)
+		return
+	}
+	select {
+	case client.Send <- raw:
+	default:
+		log.Printf("pairing: dropping %s from room %s: too far behind", client.participant.Username, r.room.ID)
+		r.drop(client)
+	}
+}
+
+// broadcast delivers a message to every client but one; the caller holds the
+// room lock
+func (r *pairRoom) broadcast(message models.PairMessage, except *PairClient) {
+	for _, c := range r.clients {
+		if c != except {
+			r.deliver(c, message)
+		}
+	}
+}
+
+// Receive handles a message from a client
+func (ps *PairingService) Receive(client *PairClient, raw []byte) {
+	r := client.room
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if _, ok := r.clients[client.participant.ID]; !ok {
+		return
+	}
+	r.lastActive = time.Now()
+
+	var message models.PairMessage
+	if err := json.Unmarshal(raw, &message); err != nil {
+		r.deliver(client, models.PairMessage{Type: "error", Error: "invalid message: " + err.Error()})
+		return
+	}
+	interviewer := client.participant.Role == models.PairInterviewer
+	var err error
+	switch message.Type {
+	case "op":
+		err = r.receiveOp(client, message)
+	case "cursor":
+		if message.Cursor == nil {
+			return
+		}
+		cursor := PairCursorWithin(*message.Cursor, len(r.text))
+		client.participant.Cursor = &cursor
+		r.broadcast(models.PairMessage{Type: "cursor", From: client.participant.ID, Cursor: &cursor}, client)
+	case "chat":
+		text := strings.TrimSpace(message.Text)
+		if text == "" || len(text) > maxPairChatLength {
+			err = fmt.Errorf("a chat line has 1 to %d characters", maxPairChatLength)
+			break
+		}
+		line := models.PairChatMessage{From: client.participant.Username, Text: text, At: time.Now()}
+		r.chat = append(r.chat, line)
+		if len(r.chat) > maxPairChat {
+			r.chat = r.chat[len(r.chat)-maxPairChat:]
+		}
+		r.broadcast(models.PairMessage{Type: "chat", Chat: []models.PairChatMessage{line}}, nil)
+	case "run":
+		err = ps.startRun(client)
+	case "notes":
+		if !interviewer || message.Notes == nil {
+			err = fmt.Errorf("only interviewers have notes")
+			break
+		}
+		if len(*message.Notes) > maxPairNotes {
+			err = fmt.Errorf("notes are limited to %d characters", maxPairNotes)
+			break
+		}
+		r.notes = *message.Notes
+		for _, c := range r.clients {
+			if c != client && c.participant.Role == models.PairInterviewer {
+				r.deliver(c, models.PairMessage{Type: "notes", From: client.participant.ID, Notes: message.Notes})
+			}
+		}
+	case "challenge":
+		if !interviewer {
+			err = fmt.Errorf("only interviewers can switch challenge")
+			break
+		}
+		err = ps.switchChallenge(r, message.ChallengeID)
+	default:
+		err = fmt.Errorf("unknown message type %q", message.Type)
+	}
+	if err != nil {
+		r.deliver(client, models.PairMessage{Type: "error", Error: err.Error()})
+	}
+}
+
+// PairCursorWithin clamps a cursor to a text of the given length
+func PairCursorWithin(cursor models.PairCursor, length int) models.PairCursor {
+	clamp := func(i int) int { return max(0, min(i, length)) }
+	return models.PairCursor{Index: clamp(cursor.Index), SelectionEnd: clamp(cursor.SelectionEnd)}
+}
+
+// receiveOp transforms a client's operation past everything applied since
+// the revision it was made on, applies it, acknowledges it to its author and
+// forwards it to the others; the caller holds the room lock
+func (r *pairRoom) receiveOp(client *PairClient, message models.PairMessage) error {
+	var op TextOperation
+	if err := json.Unmarshal(message.Op, &op); err != nil {
+		return fmt.Errorf("invalid operation: %v", err)
+	}
+	if message.Revision < 0 || message.Revision > len(r.history) {
+		return fmt.Errorf("unknown revision %d", message.Revision)
+	}
+	for _, applied := range r.history[message.Revision:] {
+		var err error
+		if op, _, err = TransformOperations(op, applied); err != nil {
+			return fmt.Errorf("operation does not fit revision %d: %v", message.Revision, err)
+		}
+	}
+	if err := r.apply(op, client.participant.ID); err != nil {
+		return err
+	}
+	r.deliver(client, models.PairMessage{Type: "ack", Revision: len(r.history)})
+	return nil
+}
+
+// apply applies an operation to the text, moves everyone's cursor past it and
+// sends it to every client but its author, if any; the caller holds the room
+// lock
+func (r *pairRoom) apply(op TextOperation, author string) error {
+	text, err := op.Apply(r.text)
+	if err != nil {
+		return err
+	}
+	if len(text) > maxPairText {
+		return fmt.Errorf("the shared code is limited to %d characters", maxPairText)
+	}
+	r.text = text
+	r.history = append(r.history, op)
+	for _, c := range r.clients {
+		if cursor := c.participant.Cursor; cursor != nil {
+			cursor.Index, cursor.SelectionEnd = op.TransformIndex(cursor.Index), op.TransformIndex(cursor.SelectionEnd)
+		}
+	}
+
+	raw, _ := json.Marshal(op)
+	message := models.PairMessage{Type: "op", Revision: len(r.history), Op: raw, From: author}
+	for id, c := range r.clients {
+		if id != author {
+			r.deliver(c, message)
+		}
+	}
+	return nil
+}
+
+// switchChallenge replaces the shared text with another challenge's template,
+// as an operation so that edits in flight still apply; the caller holds the
+// room lock
+func (ps *PairingService) switchChallenge(r *pairRoom, challengeID int) error {
+	challenge, ok := ps.challengeService.GetChallenge(challengeID)
+	if !ok {
+		return fmt.Errorf("there is no challenge %d", challengeID)
+	}
+	op := TextOperation{}.delete(len(r.text)).insert(challenge.Template)
+	if err := r.apply(op, ""); err != nil {
+		return err
+	}
+	r.room.ChallengeID = challengeID
+	r.run = nil
+	r.broadcast(models.PairMessage{Type: "challenge", ChallengeID: challengeID}, nil)
+	return nil
+}
+
+// startRun runs the shared code in the background and sends everyone the
+// result; the caller holds the room lock
+func (ps *PairingService) startRun(client *PairClient) error {
+	r := client.room
+	if r.running {
+		return fmt.Errorf("the tests are already running")
+	}
+	challenge, ok := ps.challengeService.GetChallenge(r.room.ChallengeID)
+	if !ok {
+		return fmt.Errorf("challenge %d is gone", r.room.ChallengeID)
+	}
+	r.running = true
+	r.broadcast(models.PairMessage{Type: "running", From: client.participant.ID}, nil)
+
+	code, username := string(utf16.Decode(r.text)), client.participant.Username
+	go func() {
+		result := ps.executionService.RunCode(code, challenge)
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		r.running = false
+		r.run = &models.PairRun{
+			From:        username,
+			Passed:      result.Passed,
+			Output:      result.Output,
+			ExecutionMs: result.ExecutionMs,
+			At:          time.Now(),
+		}
+		r.broadcast(models.PairMessage{Type: "run", Run: r.run}, nil)
+	}()
+	return nil
+}
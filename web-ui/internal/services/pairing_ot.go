@@ -0,0 +1,222 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"unicode/utf16"
+)
+
+// TextOperation is an edit to a whole text, as a sequence of components that
+// each retain, insert or delete. Lengths count UTF-16 code units, as browsers
+// do. In JSON it is an array in which a positive number retains, a negative
+// number deletes and a string inserts, as in ot.js.
+type TextOperation []OpComponent
+
+// OpComponent is one step of a TextOperation; exactly one field is set
+type OpComponent struct {
+	Retain int
+	Insert string
+	Delete int
+}
+
+// utf16Len is the length of s in UTF-16 code units
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		if r >= 0x10000 {
+			n += 2
+		} else {
+			n++
+		}
+	}
+	return n
+}
+
+func (op TextOperation) retain(n int) TextOperation {
+	if n <= 0 {
+		return op
+	}
+	if last := len(op) - 1; last >= 0 && op[last].Retain > 0 {
+		op[last].Retain += n
+		return op
+	}
+	return append(op, OpComponent{Retain: n})
+}
+
+func (op TextOperation) insert(s string) TextOperation {
+	if s == "" {
+		return op
+	}
+	if last := len(op) - 1; last >= 0 && op[last].Insert != "" {
+		op[last].Insert += s
+		return op
+	}
+	return append(op, OpComponent{Insert: s})
+}
+
+func (op TextOperation) delete(n int) TextOperation {
+	if n <= 0 {
+		return op
+	}
+	if last := len(op) - 1; last >= 0 && op[last].Delete > 0 {
+		op[last].Delete += n
+		return op
+	}
+	return append(op, OpComponent{Delete: n})
+}
+
+// BaseLength is the length of the text the operation applies to
+func (op TextOperation) BaseLength() int {
+	n := 0
+	for _, c := range op {
+		n += c.Retain + c.Delete
+	}
+	return n
+}
+
+// Apply applies the operation to a text
+func (op TextOperation) Apply(text []uint16) ([]uint16, error) {
+	if op.BaseLength() != len(text) {
+		return nil, fmt.Errorf("operation covers %d characters, the text has %d", op.BaseLength(), len(text))
+	}
+	result := make([]uint16, 0, len(text))
+	i := 0
+	for _, c := range op {
+		switch {
+		case c.Retain > 0:
+			result = append(result, text[i:i+c.Retain]...)
+			i += c.Retain
+		case c.Insert != "":
+			result = append(result, utf16.Encode([]rune(c.Insert))...)
+		case c.Delete > 0:
+			i += c.Delete
+		}
+	}
+	return result, nil
+}
+
+// TransformIndex moves a position in the text to where it is after the
+// operation
+func (op TextOperation) TransformIndex(index int) int {
+	at, moved := 0, index
+	for _, c := range op {
+		if at > index {
+			break
+		}
+		switch {
+		case c.Retain > 0:
+			at += c.Retain
+		case c.Insert != "":
+			moved += utf16Len(c.Insert)
+		case c.Delete > 0:
+			moved -= min(c.Delete, index-at)
+			at += c.Delete
+		}
+	}
+	return moved
+}
+
+// TransformOperations transforms two concurrent operations on the same text
+// into a′ and b′ such that applying a then b′ gives the same text as applying b
+// then a′. Where both insert at the same place, a's text comes first.
+func TransformOperations(a, b TextOperation) (TextOperation, TextOperation, error) {
+	if a.BaseLength() != b.BaseLength() {
+		return nil, nil, errors.New("the operations are not on the same text")
+	}
+
+	var a2, b2 TextOperation
+	i, j := 0, 0
+	next := func(op TextOperation, k *int) (OpComponent, bool) {
+		if *k < len(op) {
+			*k++
+			return op[*k-1], true
+		}
+		return OpComponent{}, false
+	}
+	c1, ok1 := next(a, &i)
+	c2, ok2 := next(b, &j)
+	for ok1 || ok2 {
+		if ok1 && c1.Insert != "" {
+			a2, b2 = a2.insert(c1.Insert), b2.retain(utf16Len(c1.Insert))
+			c1, ok1 = next(a, &i)
+			continue
+		}
+		if ok2 && c2.Insert != "" {
+			a2, b2 = a2.retain(utf16Len(c2.Insert)), b2.insert(c2.Insert)
+			c2, ok2 = next(b, &j)
+			continue
+		}
+
+		switch {
+		case c1.Retain > 0 && c2.Retain > 0:
+			n := min(c1.Retain, c2.Retain)
+			a2, b2 = a2.retain(n), b2.retain(n)
+			c1.Retain, c2.Retain = c1.Retain-n, c2.Retain-n
+		case c1.Delete > 0 && c2.Delete > 0:
+			n := min(c1.Delete, c2.Delete)
+			c1.Delete, c2.Delete = c1.Delete-n, c2.Delete-n
+		case c1.Delete > 0 && c2.Retain > 0:
+			n := min(c1.Delete, c2.Retain)
+			a2 = a2.delete(n)
+			c1.Delete, c2.Retain = c1.Delete-n, c2.Retain-n
+		case c1.Retain > 0 && c2.Delete > 0:
+			n := min(c1.Retain, c2.Delete)
+			b2 = b2.delete(n)
+			c1.Retain, c2.Delete = c1.Retain-n, c2.Delete-n
+		}
+		if c1.Retain == 0 && c1.Delete == 0 {
+			c1, ok1 = next(a, &i)
+		}
+		if c2.Retain == 0 && c2.Delete == 0 {
+			c2, ok2 = next(b, &j)
+		}
+	}
+	return a2, b2, nil
+}
+
+// MarshalJSON writes the ot.js form
+func (op TextOperation) MarshalJSON() ([]byte, error) {
+	parts := make([]interface{}, 0, len(op))
+	for _, c := range op {
+		switch {
+		case c.Retain > 0:
+			parts = append(parts, c.Retain)
+		case c.Insert != "":
+			parts = append(parts, c.Insert)
+		case c.Delete > 0:
+			parts = append(parts, -c.Delete)
+		}
+	}
+	return json.Marshal(parts)
+}
+
+// UnmarshalJSON reads the ot.js form
+func (op *TextOperation) UnmarshalJSON(data []byte) error {
+	var parts []json.RawMessage
+	if err := json.Unmarshal(data, &parts); err != nil {
+		return err
+	}
+	var parsed TextOperation
+	for _, part := range parts {
+		var n int
+		if err := json.Unmarshal(part, &n); err == nil {
+			if n == 0 {
+				return errors.New("operation has an empty component")
+			}
+			if n > 0 {
+				parsed = parsed.retain(n)
+			} else {
+				parsed = parsed.delete(-n)
+			}
+			continue
+		}
+		var s string
+		if err := json.Unmarshal(part, &s); err != nil || s == "" {
+			return fmt.Errorf("operation component %s is neither a count nor text", part)
+		}
+		parsed = parsed.insert(s)
+	}
+	*op = parsed
+	return nil
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"unicode/utf16"
+
+	"web-ui/internal/config"
+	"web-ui/internal/models"
+)
+
+func TestTransformOperationsConverge(t *testing.T) {
+	text := utf16.Encode([]rune("héllo 🌍 world"))
+	ops := []TextOperation{
+		TextOperation{}.retain(5).insert(",").retain(len(text) - 5),
+		TextOperation{}.retain(6).delete(2).insert("🌏").retain(len(text) - 8),
+		TextOperation{}.delete(len(text)).insert("gone"),
+		TextOperation{}.retain(5).insert("!").retain(len(text) - 5),
+		TextOperation{}.retain(len(text)),
+	}
+	for i, a := range ops {
+		for j, b := range ops {
+			a2, b2, err := TransformOperations(a, b)
+			if err != nil {
+				t.Fatalf("transform %d, %d: %v", i, j, err)
+			}
+			ab, _ := a.Apply(text)
+			ab, err1 := b2.Apply(ab)
+			ba, _ := b.Apply(text)
+			ba, err2 := a2.Apply(ba)
+			if err1 != nil || err2 != nil || string(utf16.Decode(ab)) != string(utf16.Decode(ba)) {
+				t.Errorf("ops %d and %d diverge: %q vs %q (%v, %v)", i, j, string(utf16.Decode(ab)), string(utf16.Decode(ba)), err1, err2)
+			}
+		}
+	}
+
+	var op TextOperation
+	if err := json.Unmarshal([]byte(`[3,"ab",-2,1]`), &op); err != nil || op.BaseLength() != 6 {
+		t.Fatalf("unmarshal = %v, %v", op, err)
+	}
+	if raw, _ := json.Marshal(op); string(raw) != `[3,"ab",-2,1]` {
+		t.Errorf("marshal = %s", raw)
+	}
+	if op.TransformIndex(4) != 5 || op.TransformIndex(6) != 6 {
+		t.Errorf("transformed indexes = %d, %d", op.TransformIndex(4), op.TransformIndex(6))
+	}
+}
+
+func TestPairingRoomRolesAndNotes(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "challenge-1"), 0755)
+	os.WriteFile(filepath.Join(root, "challenge-1", "README.md"), []byte("# Challenge\n"), 0644)
+	os.WriteFile(filepath.Join(root, "challenge-1", "solution-template.go"), []byte("package main\n"), 0644)
+	cfg := config.Default()
+	cfg.Content.Root = root
+	challenges := NewChallengeService(cfg)
+	if err := challenges.LoadChallenges(); err != nil {
+		t.Fatal(err)
+	}
+
+	pairing := NewPairingService(challenges, nil)
+	if _, err := pairing.Create("alice", 99); err == nil {
+		t.Error("a room on a missing challenge was opened")
+	}
+	room, err := pairing.Create("alice", 1)
+	if err != nil || room.InterviewerKey == "" {
+		t.Fatalf("create = %+v, %v", room, err)
+	}
+
+	read := func(c *PairClient) models.PairMessage {
+		t.Helper()
+		select {
+		case raw := <-c.Send:
+			var message models.PairMessage
+			json.Unmarshal(raw, &message)
+			return message
+		default:
+			t.Fatal("no message waiting")
+			return models.PairMessage{}
+		}
+	}
+	send := func(c *PairClient, message models.PairMessage) {
+		raw, _ := json.Marshal(message)
+		pairing.Receive(c, raw)
+	}
+
+	alice, _ := pairing.Join(room.ID, "alice", "")
+	welcome := read(alice)
+	if welcome.You.Role != models.PairInterviewer || welcome.Notes == nil || *welcome.Code != "package main\n" {
+		t.Fatalf("alice's welcome = %+v", welcome)
+	}
+	bob, _ := pairing.Join(room.ID, "bob", "")
+	welcome = read(bob)
+	if welcome.You.Role != models.PairCandidate || welcome.Notes != nil || welcome.Room.InterviewerKey != "" {
+		t.Fatalf("bob's welcome = %+v", welcome)
+	}
+	carol, _ := pairing.Join(room.ID, "carol", room.InterviewerKey)
+	if read(carol).You.Role != models.PairInterviewer {
+		t.Error("the interviewer key did not make carol an interviewer")
+	}
+	read(alice) // presence
+	read(alice)
+	read(bob)
+
+	// Concurrent edits on revision 0 both land
+	op1, _ := json.Marshal(TextOperation{}.insert("// alice\n").retain(13))
+	op2, _ := json.Marshal(TextOperation{}.retain(13).insert("// bob\n"))
+	send(alice, models.PairMessage{Type: "op", Revision: 0, Op: op1})
+	send(bob, models.PairMessage{Type: "op", Revision: 0, Op: op2})
+	if ack := read(alice); ack.Type != "ack" || ack.Revision != 1 {
+		t.Errorf("alice's ack = %+v", ack)
+	}
+	if forwarded := read(bob); forwarded.Type != "op" || forwarded.Revision != 1 {
+		t.Errorf("bob got %+v", forwarded)
+	}
+	if ack := read(bob); ack.Type != "ack" || ack.Revision != 2 {
+		t.Errorf("bob's ack = %+v", ack)
+	}
+	if forwarded := read(alice); forwarded.Type != "op" || forwarded.Revision != 2 {
+		t.Errorf("alice got %+v", forwarded)
+	}
+	if text := string(utf16.Decode(pairing.rooms[room.ID].text)); text != "// alice\npackage main\n// bob\n" {
+		t.Errorf("shared text = %q", text)
+	}
+
+	// Notes reach interviewers only, and only interviewers write them
+	read(carol)
+	read(carol)
+	notes := "strong on tests"
+	send(alice, models.PairMessage{Type: "notes", Notes: &notes})
+	if got := read(carol); got.Type != "notes" || *got.Notes != notes {
+		t.Errorf("carol got %+v", got)
+	}
+	if len(bob.Send) != 0 {
+		t.Error("the candidate was sent the notes")
+	}
+	send(bob, models.PairMessage{Type: "notes", Notes: &notes})
+	if got := read(bob); got.Type != "error" {
+		t.Errorf("candidate's notes answered %+v", got)
+	}
+
+	pairing.Leave(bob)
+	pairing.Leave(bob)
+	if got := read(alice); got.Type != "presence" || len(got.Participants) != 2 {
+		t.Errorf("after bob left alice got %+v", got)
+	}
+}
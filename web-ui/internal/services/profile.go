@@ -0,0 +1,314 @@
+package services
+
+import (
+	"path/filepath"
+	"sort"
+	"time"
+
+	"web-ui/internal/config"
+	"web-ui/internal/models"
+)
+
+// recentActivityLimit is how many runs and submits a profile lists
+const recentActivityLimit = 10
+
+// ProfileService assembles user profiles from the scoreboards, the submission
+// store and the attempt history, and keeps each user's profile settings.
+//
+// Private profiles show other visitors only what the public leaderboards
+// already show: rank, achievement, sponsor status, completion counts and
+// badges. Their owner always sees everything.
+type ProfileService struct {
+	cfg              *config.Config
+	challengeService *ChallengeService
+	packageService   *PackageService
+	releaseService   *ReleaseService
+	userService      *UserService
+	leaderboards     *LeaderboardService
+	badges           *BadgeService
+	achievements     *AchievementService
+	activity         *ActivityService
+	store            SubmissionStore
+	attempts         AttemptStore
+	settings         *jsonlLog[models.ProfileSettings]
+}
+
+// NewProfileService opens the profile settings log in the data directory
+func NewProfileService(
+	cfg *config.Config,
+	challengeService *ChallengeService,
+	packageService *PackageService,
+	releaseService *ReleaseService,
+	userService *UserService,
+	leaderboards *LeaderboardService,
+	badges *BadgeService,
+	achievements *AchievementService,
+	activity *ActivityService,
+	store SubmissionStore,
+	attempts AttemptStore,
+) (*ProfileService, error) {
+	settings, err := openJSONL[models.ProfileSettings](filepath.Join(cfg.Server.DataDir, "profile_settings.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	return &ProfileService{
+		cfg:              cfg,
+		challengeService: challengeService,
+		packageService:   packageService,
+		releaseService:   releaseService,
+		userService:      userService,
+		leaderboards:     leaderboards,
+		badges:           badges,
+		achievements:     achievements,
+		activity:         activity,
+		store:            store,
+		attempts:         attempts,
+		settings:         settings,
+	}, nil
+}
+
+// IsPublic reports whether the user's profile is public. Profiles are public
+// until their owner makes them private.
+func (ps *ProfileService) IsPublic(username string) bool {
+	latest := ps.settings.Filter(func(s models.ProfileSettings) bool { return s.Username == username })
+	return len(latest) == 0 || latest[0].Public
+}
+
+// VisibleTo returns a SubmissionQuery.Visible filter that lists viewer's own
+// submissions and those of users with public profiles. It remembers what it
+// looked up, so use a new one per query.
+func (ps *ProfileService) VisibleTo(viewer string) func(username string) bool {
+	public := make(map[string]bool)
+	return func(username string) bool {
+		if username == viewer {
+			return true
+		}
+		visible, ok := public[username]
+		if !ok {
+			visible = ps.IsPublic(username)
+			public[username] = visible
+		}
+		return visible
+	}
+}
+
+// SetPublic makes the user's profile public or private
+func (ps *ProfileService) SetPublic(username string, public bool) error {
+	return ps.settings.Append(models.ProfileSettings{Username: username, Public: public, UpdatedAt: time.Now()})
+}
+
+// Activity returns username's streaks and calendar, or nothing of them if the
+// profile is private to viewer
+func (ps *ProfileService) Activity(username, viewer string) *models.UserActivity {
+	if viewer != username && !ps.IsPublic(username) {
+		return privateActivity(username)
+	}
+	return ps.activity.Activity(username, true)
+}
+
+// SetTimezone sets the time zone the user's days and streaks are counted in.
+// Check it with ValidTimezone first.
+func (ps *ProfileService) SetTimezone(username, timezone string) error {
+	return ps.activity.SetTimezone(username, timezone)
+}
+
+// Profile returns username's profile as viewer sees it. It reports false for
+// users the site has no record of that viewer may see, unless they are looking
+// at their own.
+func (ps *ProfileService) Profile(username, viewer string) (*models.UserProfile, bool) {
+	// Every scoreboard is read once per profile
+	completions := ps.leaderboards.MainCompletions()
+	attempts := ps.attempts.UserAttempts(username)
+	earned := ps.achievements.Earned(username)
+
+	profile := &models.UserProfile{
+		Username: username,
+		Public:   ps.IsPublic(username),
+		Rank:     mainRank(completions, username),
+		Sponsor:  LoadSponsors()[username],
+	}
+	profile.Limited = !profile.Public && viewer != username
+
+	ps.addClassic(profile, completions[username])
+	ps.addPackages(profile, attempts, earned)
+	ps.addReleases(profile)
+	ps.addActivity(profile, attempts)
+	profile.Activity = ps.activity.Activity(username, true)
+
+	tier := achievementFor(classicAchievements, profile.Classic.Solved)
+	profile.Achievement, profile.AchievementIcon = tier.name, tier.icon
+	profile.Achievements = earned
+
+	if _, ok := ps.badges.Stats(username); ok && ps.cfg.Features.Badges {
+		profile.Badges = &models.ProfileBadges{
+			Card:    "/badges/" + username + ".svg",
+			Compact: "/badges/" + username + "/compact.svg",
+			Shields: "/badges/" + username + ".json",
+		}
+	}
+
+	// Only what viewer may see counts, so a private user without public
+	// results looks the same as one the site has never seen
+	if profile.Limited {
+		ps.limit(profile)
+	}
+	known := viewer == username || profile.Rank > 0 || len(profile.Achievements) > 0 || len(profile.Classic.Challenges) > 0 ||
+		len(profile.Packages) > 0 || len(profile.Releases) > 0 || len(profile.RecentActivity) > 0
+	return profile, known
+}
+
+// limit drops everything the public leaderboards do not already show
+func (ps *ProfileService) limit(profile *models.UserProfile) {
+	profile.Classic.Challenges = []models.ClassicChallengeProgress{}
+	for i := range profile.Packages {
+		profile.Packages[i].Completed = []string{}
+		profile.Packages[i].Progress = nil
+	}
+	profile.Releases = []models.ReleaseProgress{}
+	profile.RecentActivity = []models.ProfileActivity{}
+	profile.Activity = privateActivity(profile.Username)
+	profile.Achievements = []models.EarnedAchievement{}
+}
+
+// privateActivity stands in for the activity of a private profile. Streaks
+// and active days are not on the leaderboards, so none of it is shown.
+func privateActivity(username string) *models.UserActivity {
+	return &models.UserActivity{Username: username, Private: true}
+}
+
+// addClassic combines the user's scoreboard completions with their saved
+// solutions
+func (ps *ProfileService) addClassic(profile *models.UserProfile, completed map[int]bool) {
+	challenges := ps.challengeService.GetChallenges()
+	attempted := ps.userService.GetUserAttempts(profile.Username, challenges)
+
+	profile.Classic = models.ClassicProgress{
+		Solved:     len(completed),
+		Total:      len(challenges),
+		Challenges: []models.ClassicChallengeProgress{},
+	}
+	for _, c := range ps.challengeService.ListChallenges() {
+		if !completed[c.ID] && !attempted.AttemptedIDs[c.ID] {
+			continue
+		}
+		progress := models.ClassicChallengeProgress{
+			ID:         c.ID,
+			Title:      c.Title,
+			Difficulty: c.Difficulty,
+			Completed:  completed[c.ID],
+			Score:      attempted.Scores[c.ID],
+		}
+		if progress.Completed {
+			progress.Score = 100
+		}
+		profile.Classic.Challenges = append(profile.Classic.Challenges, progress)
+	}
+}
+
+// addPackages lists the packages the user has completed challenges in
+func (ps *ProfileService) addPackages(profile *models.UserProfile, attempts []models.Attempt, earned []models.EarnedAchievement) {
+	profile.Packages = []models.PackagePathProgress{}
+	for name, pkg := range ps.packageService.GetPackages() {
+		challenges, err := ps.leaderboards.PackageChallenges(name)
+		if err != nil {
+			continue
+		}
+		completed := ps.leaderboards.PackageCompletions(name, challenges)[profile.Username]
+		if len(completed) == 0 {
+			continue
+		}
+
+		progress := models.PackagePathProgress{
+			Name:        name,
+			DisplayName: pkg.DisplayName,
+			Solved:      len(completed),
+			Total:       len(challenges),
+			Completed:   []string{},
+			Progress:    packageProgress(profile.Username, name, challenges, completed, attempts, earned),
+		}
+		for _, c := range challenges {
+			if _, ok := completed[c.ID]; ok {
+				progress.Completed = append(progress.Completed, c.ID)
+			}
+		}
+		profile.Packages = append(profile.Packages, progress)
+	}
+	sort.Slice(profile.Packages, func(i, j int) bool {
+		if profile.Packages[i].Solved != profile.Packages[j].Solved {
+			return profile.Packages[i].Solved > profile.Packages[j].Solved
+		}
+		return profile.Packages[i].Name < profile.Packages[j].Name
+	})
+}
+
+// addReleases summarises the user's release challenge runs, most recent first
+func (ps *ProfileService) addReleases(profile *models.UserProfile) {
+	byChallenge := make(map[string]*models.ReleaseProgress)
+	profile.Releases = []models.ReleaseProgress{}
+
+	query := SubmissionQuery{Username: profile.Username, Limit: MaxSubmissionPageSize}
+	for {
+		runs, total := ps.store.ReleaseSubmissions(query)
+		for _, run := range runs {
+			key := run.Release + "/" + run.Feature + "/" + run.Challenge
+			progress, ok := byChallenge[key]
+			if !ok {
+				progress = &models.ReleaseProgress{
+					Release:   run.Release,
+					Feature:   run.Feature,
+					Challenge: run.Challenge,
+					Title:     run.Challenge,
+					URL:       "/releases/" + key,
+					LastRunAt: run.SubmittedAt, // runs come newest first
+				}
+				if c := ps.releaseService.GetChallenge(run.Release, run.Feature, run.Challenge); c != nil {
+					progress.Title = c.Title
+				}
+				byChallenge[key] = progress
+			}
+			progress.Runs++
+			progress.Passed = progress.Passed || run.Passed
+		}
+		query.Offset += len(runs)
+		if len(runs) == 0 || query.Offset >= total {
+			break
+		}
+	}
+
+	for _, progress := range byChallenge {
+		profile.Releases = append(profile.Releases, *progress)
+	}
+	sort.Slice(profile.Releases, func(i, j int) bool {
+		return profile.Releases[i].LastRunAt.After(profile.Releases[j].LastRunAt)
+	})
+}
+
+// addActivity lists the user's latest runs and submits on every track
+func (ps *ProfileService) addActivity(profile *models.UserProfile, attempts []models.Attempt) {
+	profile.RecentActivity = []models.ProfileActivity{}
+	for i := len(attempts) - 1; i >= 0 && len(profile.RecentActivity) < recentActivityLimit; i-- {
+		a := attempts[i]
+		profile.RecentActivity = append(profile.RecentActivity, models.ProfileActivity{
+			Track:       a.Track,
+			ChallengeID: a.ChallengeID,
+			URL:         challengeURL(a.Track, a.ChallengeID),
+			Action:      a.Action,
+			Passed:      a.Passed,
+			TestsPassed: a.TestsPassed,
+			TestsTotal:  a.TestsTotal,
+			At:          a.CreatedAt,
+		})
+	}
+}
+
+// challengeURL is the page of a challenge identified as in models.Attempt
+func challengeURL(track, challengeID string) string {
+	switch track {
+	case models.TrackPackage:
+		return "/packages/" + challengeID
+	case models.TrackRelease:
+		return "/releases/" + challengeID
+	default:
+		return "/challenge/" + challengeID
+	}
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"web-ui/internal/config"
+	"web-ui/internal/models"
+	"web-ui/internal/scoreboard"
+)
+
+func TestProfileAggregatesTracksAndHonoursPrivacy(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"challenge-1", "challenge-2", "challenge-2/submissions/alice"} {
+		os.MkdirAll(filepath.Join(root, dir), 0755)
+	}
+	for _, file := range []string{"challenge-1/README.md", "challenge-1/solution-template.go",
+		"challenge-2/README.md", "challenge-2/solution-template.go", "challenge-2/submissions/alice/solution-template.go"} {
+		os.WriteFile(filepath.Join(root, file), []byte("# Challenge\n"), 0644)
+	}
+	scoreboard.Save(filepath.Join(root, "challenge-1"), &scoreboard.Board{Entries: []scoreboard.Entry{{Username: "alice", Passed: 3, Total: 3}}})
+
+	cfg := config.Default()
+	cfg.Content.Root = root
+	cfg.Server.DataDir = t.TempDir()
+	challenges := NewChallengeService(cfg)
+	if err := challenges.LoadChallenges(); err != nil {
+		t.Fatal(err)
+	}
+	packages := NewPackageService(cfg)
+	leaderboards := NewLeaderboardService(cfg, challenges, packages, nil)
+	store, _ := NewFileSubmissionStore(cfg.Server.DataDir)
+	attempts, _ := NewFileAttemptStore(cfg.Server.DataDir)
+	releases := NewReleaseService(cfg, nil)
+	activity, _ := NewActivityService(cfg, attempts)
+	achievements, _ := NewAchievementService(cfg, challenges, packages, releases, leaderboards, activity, store, attempts)
+	profiles, err := NewProfileService(cfg, challenges, packages, releases, NewUserService(cfg),
+		leaderboards, NewBadgeService(leaderboards, achievements), achievements, activity, store, attempts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Keep the test off the network
+	seedSponsors(map[string]bool{"alice": true})
+	defer ResetSponsors()
+
+	for i := 0; i < 2; i++ {
+		store.AddReleaseSubmission(models.ReleaseSubmission{Username: "alice", Release: "1.26", Feature: "new-expr", Challenge: "challenge-1", Passed: i == 1, SubmittedAt: time.Now()})
+	}
+	attempts.AddAttempt(NewAttempt("alice", models.TrackClassic, "2", "run", "package main", false, "", 10))
+	achievements.Evaluate("alice")
+
+	profile, ok := profiles.Profile("alice", "bob")
+	if !ok {
+		t.Fatal("alice has no profile")
+	}
+	if profile.Rank != 1 || !profile.Sponsor || profile.Classic.Solved != 1 || profile.Classic.Total != 2 {
+		t.Errorf("profile = rank %d, sponsor %v, solved %d/%d", profile.Rank, profile.Sponsor, profile.Classic.Solved, profile.Classic.Total)
+	}
+	if len(profile.Classic.Challenges) != 2 || !profile.Classic.Challenges[0].Completed || profile.Classic.Challenges[1].Completed {
+		t.Errorf("classic challenges = %+v", profile.Classic.Challenges)
+	}
+	if len(profile.Releases) != 1 || profile.Releases[0].Runs != 2 || !profile.Releases[0].Passed {
+		t.Errorf("releases = %+v", profile.Releases)
+	}
+	if len(profile.RecentActivity) != 1 || profile.RecentActivity[0].URL != "/challenge/2" {
+		t.Errorf("activity = %+v", profile.RecentActivity)
+	}
+	if profile.Badges == nil || profile.Badges.Card != "/badges/alice.svg" {
+		t.Errorf("badges = %+v", profile.Badges)
+	}
+
+	// Private: others see leaderboard facts only, the owner sees everything
+	if err := profiles.SetPublic("alice", false); err != nil {
+		t.Fatal(err)
+	}
+	hidden, _ := profiles.Profile("alice", "bob")
+	if !hidden.Limited || len(hidden.Classic.Challenges) != 0 || len(hidden.Releases) != 0 || len(hidden.RecentActivity) != 0 || len(hidden.Achievements) != 0 {
+		t.Errorf("private profile leaks: %+v", hidden)
+	}
+	if !hidden.Activity.Private || hidden.Activity.ActiveDays != 0 || hidden.Activity.LongestStreak != 0 {
+		t.Errorf("private profile leaks its activity: %+v", hidden.Activity)
+	}
+	if activity := profiles.Activity("alice", "bob"); !activity.Private || activity.CurrentStreak != 0 || activity.ActiveDays != 0 {
+		t.Errorf("private activity leaks: %+v", activity)
+	}
+	if activity := profiles.Activity("alice", "alice"); activity.Private || activity.ActiveDays != 1 {
+		t.Errorf("owner's activity = %+v", activity)
+	}
+	if hidden.Rank != 1 || hidden.Classic.Solved != 1 {
+		t.Errorf("private profile lost its leaderboard facts: %+v", hidden)
+	}
+	if visible := profiles.VisibleTo("bob"); visible("alice") || !visible("bob") || !visible("carol") {
+		t.Error("submissions of a private profile are listed for others")
+	}
+	if !profiles.VisibleTo("alice")("alice") {
+		t.Error("the owner cannot list their own submissions")
+	}
+	if own, _ := profiles.Profile("alice", "alice"); own.Limited || len(own.RecentActivity) != 1 || len(own.Achievements) == 0 {
+		t.Errorf("owner's view is limited: %+v", own)
+	}
+
+	if _, ok := profiles.Profile("nobody", ""); ok {
+		t.Error("a user the site has never seen has a profile")
+	}
+
+	// A private user with nothing public looks like one the site has never seen
+	attempts.AddAttempt(NewAttempt("dave", models.TrackClassic, "2", "run", "package main", false, "", 10))
+	profiles.SetPublic("dave", false)
+	if _, ok := profiles.Profile("dave", "bob"); ok {
+		t.Error("a private profile with only recent activity is found by others")
+	}
+	if _, ok := profiles.Profile("dave", "dave"); !ok {
+		t.Error("the owner of a private profile cannot see it")
+	}
+}
+
+// seedSponsors fills the sponsor cache so LoadSponsors stays off the network
+func seedSponsors(sponsors map[string]bool) {
+	sponsorCache.mutex.Lock()
+	defer sponsorCache.mutex.Unlock()
+	sponsorCache.sponsors, sponsorCache.lastUpdated = sponsors, time.Now()
+}
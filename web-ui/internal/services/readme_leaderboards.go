@@ -0,0 +1,337 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// README markers delimiting the generated leaderboard sections. Each section
+// runs from its heading up to and including its end comment.
+const (
+	ClassicLeaderboardHeading = "## 🏆 Top 10 Leaderboard"
+	ClassicLeaderboardEnd     = "<!-- END_CLASSIC_LEADERBOARD -->"
+	PackageLeaderboardHeading = "## 🚀 Package Challenges Leaderboard"
+	PackageLeaderboardEnd     = "<!-- END_PACKAGE_LEADERBOARD -->"
+)
+
+// readmeAvatar renders a contributor's avatar and profile link for a README table
+func readmeAvatar(username string, sponsor bool) string {
+	badge := ""
+	if sponsor {
+		badge = " ❤️"
+	}
+	return fmt.Sprintf(`<img src="https://github.com/%s.png" width="24" height="24" style="border-radius: 50%%;"><br/>**[%s](https://github.com/%s)**%s`, username, username, username, badge)
+}
+
+// readmeRank renders a 1-based rank, with medals for the podium
+func readmeRank(rank int) string {
+	switch rank {
+	case 1:
+		return "🥇"
+	case 2:
+		return "🥈"
+	case 3:
+		return "🥉"
+	}
+	return fmt.Sprintf("%d", rank)
+}
+
+// ClassicReadmeSection renders the README's top 10 leaderboard for classic challenges
+func (ls *LeaderboardService) ClassicReadmeSection() string {
+	var ids []int
+	for id := range ls.challengeService.GetChallenges() {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	total := len(ids)
+
+	leaderboard := ls.MainLeaderboard()
+	sponsors := LoadSponsors()
+
+	lines := []string{
+		ClassicLeaderboardHeading,
+		"",
+		"Our most accomplished Go developers, ranked by number of challenges completed:",
+		"",
+		"> **Note**: The data below is automatically updated by GitHub Actions when challenge scoreboards change.",
+		"",
+	}
+
+	if len(leaderboard) == 0 {
+		lines = append(lines, "No completed challenges yet. Be the first to solve a challenge!", "")
+	} else {
+		lines = append(lines,
+			"| 🏅 | Developer | Solved | Rate | Achievement | Progress |",
+			"|:---:|:---:|:---:|:---:|:---:|:---|",
+		)
+
+		// Progress shows every challenge, split over two rows
+		split := (total + 1) / 2
+		for i, user := range leaderboard {
+			if i == 10 {
+				break
+			}
+			var rows [2]strings.Builder
+			for j, id := range ids {
+				mark := "⬜"
+				if user.CompletedChallenges[id] {
+					mark = "✅"
+				}
+				rows[j/max(split, 1)].WriteString(mark)
+			}
+
+			lines = append(lines, fmt.Sprintf("| %s | %s | **%d**/%d | **%.1f%%** | %s | %s<br/>%s |",
+				readmeRank(i+1),
+				readmeAvatar(user.Username, sponsors[user.Username]),
+				user.CompletedCount, total,
+				user.CompletionRate,
+				achievementFor(classicAchievements, user.CompletedCount).name,
+				rows[0].String(), rows[1].String(),
+			))
+		}
+
+		lines = append(lines,
+			"",
+			`<div align="center">`,
+			"",
+			"✅ Completed • ⬜ Not Completed",
+			"",
+			fmt.Sprintf("*All %d challenges shown in two rows*", total),
+			"",
+			"</div>",
+		)
+	}
+
+	mostSolved := "0 by N/A"
+	if len(leaderboard) > 0 {
+		mostSolved = fmt.Sprintf("%d by %s", leaderboard[0].CompletedCount, leaderboard[0].Username)
+	}
+	lines = append(lines,
+		"",
+		fmt.Sprintf("*Updated automatically based on %d available challenges*", total),
+		"",
+		"### Challenge Progress Overview",
+		"",
+		fmt.Sprintf("- **Total Challenges Available**: %d", total),
+		fmt.Sprintf("- **Active Developers**: %d", len(leaderboard)),
+		fmt.Sprintf("- **Most Challenges Solved**: %s", mostSolved),
+		"",
+		ClassicLeaderboardEnd,
+		"",
+	)
+	return strings.Join(lines, "\n")
+}
+
+// packageLeader is one user's completions across all packages
+type packageLeader struct {
+	username  string
+	total     int
+	byPackage map[string]int
+}
+
+// PackageReadmeSection renders the README's package challenges leaderboard
+func (ls *LeaderboardService) PackageReadmeSection() string {
+	packages := ls.packageService.GetPackages()
+	var names []string
+	for name := range packages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	sponsors := LoadSponsors()
+	leaders := make(map[string]*packageLeader)
+	packageTotals := make(map[string]int)
+	perPackage := make(map[string][]*packageLeader)
+	totalChallenges := 0
+
+	for _, name := range names {
+		challenges, err := ls.PackageChallenges(name)
+		if err != nil {
+			continue
+		}
+		packageTotals[name] = len(challenges)
+		totalChallenges += len(challenges)
+
+		for username, completed := range ls.PackageCompletions(name, challenges) {
+			leader := leaders[username]
+			if leader == nil {
+				leader = &packageLeader{username: username, byPackage: make(map[string]int)}
+				leaders[username] = leader
+			}
+			leader.total += len(completed)
+			leader.byPackage[name] = len(completed)
+			perPackage[name] = append(perPackage[name], &packageLeader{username: username, total: len(completed)})
+		}
+	}
+
+	ranked := make([]*packageLeader, 0, len(leaders))
+	for _, leader := range leaders {
+		ranked = append(ranked, leader)
+	}
+	sortPackageLeaders(ranked)
+
+	lines := []string{
+		PackageLeaderboardHeading,
+		"",
+		"Master Go packages through hands-on challenges! Each package offers a structured learning path with real-world scenarios.",
+		"",
+		"> **Note**: The data below is automatically updated by GitHub Actions when package challenge scoreboards change.",
+		"",
+	}
+
+	if len(ranked) == 0 {
+		lines = append(lines, "No completed package challenges yet. Be the first to solve a package challenge!", "")
+	} else {
+		lines = append(lines,
+			"| 🏅 | Developer | Total Solved | Packages | Achievement | Challenge Distribution |",
+			"|:---:|:---:|:---:|:---:|:---:|:---|",
+		)
+		for i, leader := range ranked {
+			if i == 10 {
+				break
+			}
+			var breakdown []string
+			for _, name := range names {
+				if count, ok := leader.byPackage[name]; ok {
+					breakdown = append(breakdown, fmt.Sprintf("**%s**: %d", name, count))
+				}
+			}
+			plural := "s"
+			if len(leader.byPackage) == 1 {
+				plural = ""
+			}
+			tier := achievementFor(packageAchievements, leader.total)
+
+			lines = append(lines, fmt.Sprintf("| %s | %s | **%d** | **%d** pkg%s | %s %s | %s |",
+				readmeRank(i+1),
+				readmeAvatar(leader.username, sponsors[leader.username]),
+				leader.total,
+				len(leader.byPackage), plural,
+				tier.icon, tier.name,
+				strings.Join(breakdown, " • "),
+			))
+		}
+		lines = append(lines,
+			"",
+			`<div align="center">`,
+			"",
+			"🚀 **Package Challenges** - Learn Go packages through practical, real-world scenarios",
+			"",
+			"</div>",
+		)
+	}
+
+	lines = append(lines, "", "### 📦 Per-Package Progress", "")
+	for _, name := range names {
+		users := perPackage[name]
+		if len(users) == 0 {
+			continue
+		}
+		sortPackageLeaders(users)
+
+		lines = append(lines,
+			fmt.Sprintf("#### %s Package", strings.ToUpper(name[:1])+name[1:]),
+			"",
+			"| Rank | Developer | Completed | Progress |",
+			"|:---:|:---:|:---:|:---|",
+		)
+		for i, user := range users {
+			if i == 5 {
+				break
+			}
+			lines = append(lines, fmt.Sprintf("| %s | **[%s](https://github.com/%s)** | %d/%d | %s |",
+				readmeRank(i+1), user.username, user.username, user.total, packageTotals[name],
+				progressBar(user.total, packageTotals[name], 10)))
+		}
+		lines = append(lines, "")
+	}
+
+	lines = append(lines,
+		"### 📊 Package Challenge Statistics",
+		"",
+		fmt.Sprintf("- **Total Package Challenges Available**: %d", totalChallenges),
+		fmt.Sprintf("- **Active Package Learners**: %d", len(ranked)),
+		fmt.Sprintf("- **Available Packages**: %d (%s)", len(names), strings.Join(names, ", ")),
+		"",
+	)
+	if len(ranked) > 0 {
+		lines = append(lines, fmt.Sprintf("- **Most Package Challenges Solved**: %d by %s", ranked[0].total, ranked[0].username), "")
+	}
+	lines = append(lines, PackageLeaderboardEnd, "")
+	return strings.Join(lines, "\n")
+}
+
+// sortPackageLeaders orders by completions (descending), then username
+func sortPackageLeaders(leaders []*packageLeader) {
+	sort.Slice(leaders, func(i, j int) bool {
+		if leaders[i].total != leaders[j].total {
+			return leaders[i].total > leaders[j].total
+		}
+		return leaders[i].username < leaders[j].username
+	})
+}
+
+// progressBar renders completed/total as a bar of length squares and a percentage
+func progressBar(completed, total, length int) string {
+	if total == 0 {
+		return strings.Repeat("⬜", length)
+	}
+	progress := float64(completed) / float64(total)
+	filled := int(progress * float64(length))
+	return fmt.Sprintf("%s%s %.0f%%", strings.Repeat("🟩", filled), strings.Repeat("⬜", length-filled), progress*100)
+}
+
+// ReplaceReadmeSection swaps the section starting at heading and ending with the
+// end marker line for section. A missing section is inserted before the first
+// of insertBefore found in the file.
+func ReplaceReadmeSection(content, heading, end, section string, insertBefore ...string) (string, error) {
+	start := strings.Index(content, heading)
+	if start == -1 {
+		for _, anchor := range insertBefore {
+			if pos := strings.Index(content, anchor); pos != -1 {
+				return content[:pos] + section + "\n" + content[pos:], nil
+			}
+		}
+		return "", fmt.Errorf("no place to insert %q", heading)
+	}
+
+	stop := strings.Index(content[start:], end)
+	if stop == -1 {
+		return "", fmt.Errorf("%q has no %s marker", heading, end)
+	}
+	stop += start + len(end)
+	if nl := strings.IndexByte(content[stop:], '\n'); nl != -1 {
+		stop += nl + 1
+	} else {
+		stop = len(content)
+	}
+	return content[:start] + section + content[stop:], nil
+}
+
+// UpdateReadmeLeaderboards rewrites both generated leaderboard sections of the
+// README at path
+func (ls *LeaderboardService) UpdateReadmeLeaderboards(path string) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	content := string(raw)
+
+	content, err = ReplaceReadmeSection(content, ClassicLeaderboardHeading, ClassicLeaderboardEnd,
+		ls.ClassicReadmeSection(), PackageLeaderboardHeading, "## Key Features")
+	if err != nil {
+		return err
+	}
+	content, err = ReplaceReadmeSection(content, PackageLeaderboardHeading, PackageLeaderboardEnd,
+		ls.PackageReadmeSection(), "## Key Features")
+	if err != nil {
+		return err
+	}
+
+	if content == string(raw) {
+		return nil
+	}
+	return os.WriteFile(path, []byte(content), 0644)
+}
@@ -0,0 +1,416 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"web-ui/internal/models"
+)
+
+// recommendationCount is how many challenges a user is recommended
+const recommendationCount = 3
+
+// recentFailureWindow is how long failed attempts keep shaping recommendations
+const recentFailureWindow = 14 * 24 * time.Hour
+
+// difficultyRanks orders the difficulty levels every track uses
+var difficultyRanks = map[string]int{"Beginner": 0, "Intermediate": 1, "Advanced": 2}
+
+// conceptStopWords are words in prerequisites and titles that name no concept
+var conceptStopWords = map[string]bool{
+	"and": true, "the": true, "with": true, "for": true, "from": true, "into": true, "your": true,
+	"basic": true, "basics": true, "understanding": true, "knowledge": true, "familiarity": true,
+	"experience": true, "concept": true, "fundamental": true, "fundamentals": true, "golang": true,
+	"package": true, "using": true, "working": true, "writing": true, "simple": true, "challenge": true,
+}
+
+// conceptAliases maps words to the tag their concept is practised under
+var conceptAliases = map[string]string{
+	"goroutine": "concurrency",
+	"channel":   "concurrency",
+	"mutex":     "concurrency",
+	"waitgroup": "concurrency",
+}
+
+// RecommendationService suggests the challenges a user should try next.
+//
+// Every unsolved challenge on the three tracks is scored on a few signals:
+// nearly passing it recently, being an easier challenge on tags the user is
+// stuck on, prerequisites the user's solved challenges cover (and ones they
+// do not), tags the user has practised, and how its difficulty compares to
+// what the user has solved. The strongest signal gives the reason shown.
+type RecommendationService struct {
+	challengeService *ChallengeService
+	packageService   *PackageService
+	releaseService   *ReleaseService
+	achievements     *AchievementService
+	attempts         AttemptStore
+}
+
+// NewRecommendationService creates a recommender. Solved challenges are counted
+// as achievements count them.
+func NewRecommendationService(
+	challengeService *ChallengeService,
+	packageService *PackageService,
+	releaseService *ReleaseService,
+	achievements *AchievementService,
+	attempts AttemptStore,
+) *RecommendationService {
+	return &RecommendationService{
+		challengeService: challengeService,
+		packageService:   packageService,
+		releaseService:   releaseService,
+		achievements:     achievements,
+		attempts:         attempts,
+	}
+}
+
+// catalogueEntry is a challenge the recommender can suggest
+type catalogueEntry struct {
+	models.Recommendation
+	label         string // how a reason names it, such as "gin challenge-1-basic-routing"
+	rank          int    // difficulty
+	order         int    // in the catalogue
+	prerequisites []string
+	concepts      map[string]bool
+}
+
+// struggle is a challenge the user has recently failed and not yet solved
+type struggle struct {
+	entry       *catalogueEntry
+	failures    int // failed attempts since the last pass
+	failedTests int // in the latest attempt
+}
+
+// candidate is a scored recommendation
+type candidate struct {
+	entry  *catalogueEntry
+	score  int
+	best   int // points of the signal behind the reason
+	kind   string
+	reason string
+}
+
+// add scores a signal, keeping the reason of the strongest
+func (c *candidate) add(points int, kind, reason string) {
+	c.score += points
+	if reason != "" && points > c.best {
+		c.best, c.kind, c.reason = points, kind, reason
+	}
+}
+
+// Recommend returns up to three challenges for username to try next, best first
+func (rs *RecommendationService) Recommend(username string) []models.Recommendation {
+	catalogue := rs.catalogue()
+	byKey := make(map[string]*catalogueEntry, len(catalogue))
+	for _, e := range catalogue {
+		byKey[e.Track+":"+e.ChallengeID] = e
+	}
+
+	solved := make(map[*catalogueEntry]bool)
+	for key := range rs.achievements.facts(username).solved {
+		if e, ok := byKey[key]; ok {
+			solved[e] = true
+		}
+	}
+	struggles := rs.struggles(username, byKey, solved)
+	var stuck []*struggle // in catalogue order, so reasons do not change between requests
+	for _, s := range struggles {
+		if s.failures >= stuckAfterFailures {
+			stuck = append(stuck, s)
+		}
+	}
+	sort.Slice(stuck, func(i, j int) bool { return stuck[i].entry.order < stuck[j].entry.order })
+
+	// The user is ready for the hardest difficulty they have solved two of
+	level, solvedAt := 0, make(map[int]int)
+	for e := range solved {
+		if solvedAt[e.rank]++; solvedAt[e.rank] >= 2 {
+			level = max(level, e.rank)
+		}
+	}
+	practised := make(map[string]int) // tag → solved challenges
+	for e := range solved {
+		for _, tag := range e.Tags {
+			practised[strings.ToLower(tag)]++
+		}
+	}
+	known := make(map[string]bool) // concepts some challenge covers
+	for _, e := range catalogue {
+		for concept := range e.concepts {
+			known[concept] = true
+		}
+	}
+
+	var candidates []*candidate
+	for _, e := range catalogue {
+		if solved[e] {
+			continue
+		}
+		c := &candidate{entry: e}
+
+		if s := struggles[e]; s != nil {
+			if s.failures < stuckAfterFailures {
+				c.add(50, models.RecommendResume, resumeReason(s))
+			} else {
+				c.add(-30, "", "") // an easier challenge comes first
+			}
+		}
+		for _, s := range stuck {
+			if s.entry == e || !easier(e, s.entry) {
+				continue
+			}
+			if shared := sharedTags(e, s.entry); len(shared) > 0 {
+				c.add(40+len(shared), models.RecommendStepping, steppingReason(s, shared[0], e))
+			}
+		}
+
+		met, unmet, via := 0, 0, (*catalogueEntry)(nil)
+		for _, p := range e.prerequisites {
+			switch covering := solvedCovering(p, catalogue, solved, known); {
+			case covering != nil:
+				met++
+				via = covering
+			case coversKnown(p, known):
+				unmet++
+			}
+		}
+		if met > 0 {
+			c.add(30*met/(met+unmet), models.RecommendPrerequisite,
+				fmt.Sprintf("You passed %s; try %s next", titled(via), e.label))
+		}
+		c.add(-10*unmet, "", "")
+
+		tag, count := "", 0
+		for _, t := range e.Tags {
+			if n := practised[strings.ToLower(t)]; n > count {
+				tag, count = t, n
+			}
+		}
+		if count > 0 {
+			c.add(min(3*count, 15), models.RecommendTags,
+				fmt.Sprintf("It practises %s, which you have solved %d %s on", tag, count, plural(count, "challenge")))
+		}
+
+		switch {
+		case len(solved) == 0 && e.rank == 0:
+			points := 10
+			if e.Track == models.TrackClassic {
+				points = 12
+			}
+			c.add(points, models.RecommendStart, "A good first challenge to start with")
+		case len(solved) == 0:
+			c.add(-25, "", "")
+		case e.rank == level:
+			c.add(10, models.RecommendLevel, fmt.Sprintf("%s, the level you have been solving at", e.Difficulty))
+		case e.rank == level+1:
+			c.add(5, models.RecommendLevel, fmt.Sprintf("A step up to %s", strings.ToLower(e.Difficulty)))
+		case e.rank > level+1:
+			c.add(-25, "", "")
+		}
+
+		if c.reason != "" && c.score > 0 {
+			candidates = append(candidates, c)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score > candidates[j].score
+		}
+		return candidates[i].entry.order < candidates[j].entry.order
+	})
+	recommendations := []models.Recommendation{}
+	for _, c := range candidates[:min(recommendationCount, len(candidates))] {
+		r := c.entry.Recommendation
+		r.Kind, r.Reason = c.kind, c.reason
+		recommendations = append(recommendations, r)
+	}
+	return recommendations
+}
+
+// catalogue lists every challenge on the three tracks, classic first
+func (rs *RecommendationService) catalogue() []*catalogueEntry {
+	var catalogue []*catalogueEntry
+	add := func(e *catalogueEntry, title string) {
+		e.URL = challengeURL(e.Track, e.ChallengeID)
+		e.rank = difficultyRanks[e.Difficulty]
+		e.order = len(catalogue)
+		if e.Tags == nil {
+			e.Tags = []string{}
+		}
+		e.concepts = make(map[string]bool)
+		for _, tag := range e.Tags {
+			for _, word := range conceptWords(tag) {
+				e.concepts[word] = true
+			}
+		}
+		for _, word := range conceptWords(title) {
+			e.concepts[word] = true
+		}
+		catalogue = append(catalogue, e)
+	}
+
+	for _, c := range rs.challengeService.ListChallenges() {
+		id := strconv.Itoa(c.ID)
+		add(&catalogueEntry{
+			Recommendation: models.Recommendation{Track: models.TrackClassic, ChallengeID: id, Title: c.Title, Difficulty: c.Difficulty, Tags: c.Tags},
+			label:          "challenge " + id,
+			prerequisites:  c.Prerequisites,
+		}, c.Title)
+	}
+
+	packages := rs.packageService.GetPackages()
+	names := make([]string, 0, len(packages))
+	for name := range packages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		pkg := packages[name]
+		for _, id := range pkg.LearningPath {
+			info := pkg.ChallengeDetails[id]
+			if info == nil || info.Status != "available" {
+				continue
+			}
+			add(&catalogueEntry{
+				Recommendation: models.Recommendation{Track: models.TrackPackage, ChallengeID: name + "/" + id, Title: info.Title, Difficulty: info.Difficulty, Tags: info.Tags},
+				label:          name + " " + id,
+				prerequisites:  info.Prerequisites,
+			}, info.Title)
+		}
+	}
+
+	for _, release := range rs.releaseService.GetReleases() {
+		for _, feature := range release.Features {
+			for _, c := range feature.Challenges {
+				add(&catalogueEntry{
+					Recommendation: models.Recommendation{Track: models.TrackRelease, ChallengeID: release.Version + "/" + feature.Slug + "/" + c.Slug, Title: c.Title, Difficulty: c.Difficulty, Tags: c.Tags},
+					label:          "go" + release.Version + " " + feature.Slug,
+					prerequisites:  c.Prerequisites,
+				}, c.Title)
+			}
+		}
+	}
+	return catalogue
+}
+
+// struggles finds the unsolved challenges the user has failed recently
+func (rs *RecommendationService) struggles(username string, byKey map[string]*catalogueEntry, solved map[*catalogueEntry]bool) map[*catalogueEntry]*struggle {
+	struggles := make(map[*catalogueEntry]*struggle)
+	since := time.Now().Add(-recentFailureWindow)
+	for _, a := range rs.attempts.UserAttempts(username) {
+		e, ok := byKey[a.Track+":"+a.ChallengeID]
+		if !ok || solved[e] {
+			continue
+		}
+		if a.Passed {
+			delete(struggles, e)
+			continue
+		}
+		if a.CreatedAt.Before(since) {
+			continue
+		}
+		s, ok := struggles[e]
+		if !ok {
+			s = &struggle{entry: e}
+			struggles[e] = s
+		}
+		s.failures++
+		s.failedTests = a.TestsTotal - a.TestsPassed
+	}
+	return struggles
+}
+
+// solvedCovering returns a solved challenge covering a concept the
+// prerequisite names, or nil
+func solvedCovering(prerequisite string, catalogue []*catalogueEntry, solved map[*catalogueEntry]bool, known map[string]bool) *catalogueEntry {
+	for _, word := range conceptWords(prerequisite) {
+		if !known[word] {
+			continue
+		}
+		for _, e := range catalogue {
+			if solved[e] && e.concepts[word] {
+				return e
+			}
+		}
+	}
+	return nil
+}
+
+// coversKnown reports whether the prerequisite names a concept some challenge
+// covers. Prerequisites that name none, such as "Basic Go syntax", say
+// nothing about which challenges come first.
+func coversKnown(prerequisite string, known map[string]bool) bool {
+	for _, word := range conceptWords(prerequisite) {
+		if known[word] {
+			return true
+		}
+	}
+	return false
+}
+
+// conceptWords breaks text into the lower-case, singular words it names
+// concepts with
+func conceptWords(text string) []string {
+	var words []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !unicode.IsLetter(r) }) {
+		if len(word) < 3 || conceptStopWords[word] {
+			continue
+		}
+		if len(word) > 4 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") {
+			word = strings.TrimSuffix(word, "s")
+		}
+		if alias, ok := conceptAliases[word]; ok {
+			word = alias
+		}
+		words = append(words, word)
+	}
+	return words
+}
+
+// easier reports whether a should come before b
+func easier(a, b *catalogueEntry) bool {
+	return a.rank < b.rank || (a.rank == b.rank && a.Track == b.Track && a.order < b.order)
+}
+
+// sharedTags lists the tags of a that b has too
+func sharedTags(a, b *catalogueEntry) []string {
+	var shared []string
+	for _, tag := range a.Tags {
+		if hasTag(b.Tags, tag) {
+			shared = append(shared, tag)
+		}
+	}
+	return shared
+}
+
+func resumeReason(s *struggle) string {
+	if s.failedTests > 0 {
+		return fmt.Sprintf("You were %d %s short of passing %s; pick it back up", s.failedTests, plural(s.failedTests, "test"), s.entry.label)
+	}
+	return fmt.Sprintf("Your last run of %s did not pass; pick it back up", s.entry.label)
+}
+
+func steppingReason(s *struggle, tag string, e *catalogueEntry) string {
+	if s.failedTests > 0 {
+		return fmt.Sprintf("You failed %d %s on %s; try %s before %s", s.failedTests, plural(s.failedTests, "test"), tag, e.label, s.entry.label)
+	}
+	return fmt.Sprintf("You have not passed %s after %d tries; try %s on %s first", s.entry.label, s.failures, e.label, tag)
+}
+
+// titled names a challenge with its title, as in "challenge 27 (Generics)"
+func titled(e *catalogueEntry) string {
+	return fmt.Sprintf("%s (%s)", e.label, e.Title)
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return noun
+	}
+	return noun + "s"
+}
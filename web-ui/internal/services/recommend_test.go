@@ -0,0 +1,81 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"web-ui/internal/config"
+	"web-ui/internal/models"
+)
+
+func TestRecommendationsFollowProgress(t *testing.T) {
+	root := t.TempDir()
+	for dir, metadata := range map[string]string{
+		"challenge-1": `{"title": "Generic Stack", "difficulty": "Beginner", "tags": ["generics"]}`,
+		"challenge-2": `{"title": "Fan Out", "difficulty": "Beginner", "tags": ["concurrency"]}`,
+		"challenge-3": `{"title": "Rate Limiter", "difficulty": "Advanced", "tags": ["concurrency", "http"], "prerequisites": ["Goroutines and channels"]}`,
+		"challenge-4": `{"title": "Generic Cache", "difficulty": "Intermediate", "tags": ["caching"], "prerequisites": ["Go Generics (Go 1.18+)", "Basic Go syntax"]}`,
+	} {
+		os.MkdirAll(filepath.Join(root, dir), 0755)
+		os.WriteFile(filepath.Join(root, dir, "README.md"), []byte("# Challenge\n"), 0644)
+		os.WriteFile(filepath.Join(root, dir, "solution-template.go"), []byte("package main\n"), 0644)
+		os.WriteFile(filepath.Join(root, dir, "metadata.json"), []byte(metadata), 0644)
+	}
+
+	cfg := config.Default()
+	cfg.Content.Root = root
+	cfg.Server.DataDir = t.TempDir()
+	challenges := NewChallengeService(cfg)
+	if err := challenges.LoadChallenges(); err != nil {
+		t.Fatal(err)
+	}
+	packages := NewPackageService(cfg)
+	releases := NewReleaseService(cfg, nil)
+	leaderboards := NewLeaderboardService(cfg, challenges, packages, nil)
+	store, _ := NewFileSubmissionStore(cfg.Server.DataDir)
+	attempts, _ := NewFileAttemptStore(cfg.Server.DataDir)
+	activity, _ := NewActivityService(cfg, attempts)
+	achievements, _ := NewAchievementService(cfg, challenges, packages, releases, leaderboards, activity, store, attempts)
+	recommender := NewRecommendationService(challenges, packages, releases, achievements, attempts)
+
+	// A newcomer starts with the beginner challenges
+	got := recommender.Recommend("newcomer")
+	if len(got) != 2 || got[0].ChallengeID != "1" || got[1].ChallengeID != "2" || got[0].Kind != models.RecommendStart {
+		t.Errorf("newcomer got %+v", got)
+	}
+
+	// alice solved the generics challenge, so the one that builds on it is next
+	attempts.AddAttempt(NewAttempt("alice", models.TrackClassic, "1", "submit", "package main", true, "", 10))
+	got = recommender.Recommend("alice")
+	if len(got) == 0 || got[0].ChallengeID != "4" || got[0].Kind != models.RecommendPrerequisite ||
+		got[0].Reason != "You passed challenge 1 (Generic Stack); try challenge 4 next" {
+		t.Errorf("alice got %+v", got)
+	}
+
+	// bob keeps failing the rate limiter, so the easier concurrency challenge comes first
+	for i := 0; i < 3; i++ {
+		attempt := NewAttempt("bob", models.TrackClassic, "3", "run", "package main", false, "", 10)
+		attempt.TestsPassed, attempt.TestsTotal = 1, 3
+		attempts.AddAttempt(attempt)
+	}
+	got = recommender.Recommend("bob")
+	if len(got) == 0 || got[0].ChallengeID != "2" || got[0].Kind != models.RecommendStepping ||
+		!strings.Contains(got[0].Reason, "You failed 2 tests on concurrency; try challenge 2 before challenge 3") {
+		t.Errorf("bob got %+v", got)
+	}
+	for _, r := range got {
+		if r.ChallengeID == "3" {
+			t.Errorf("bob was sent back to the challenge they are stuck on: %+v", got)
+		}
+	}
+
+	// One failure is worth picking back up
+	attempt := NewAttempt("carol", models.TrackClassic, "2", "run", "package main", false, "", 10)
+	attempt.TestsPassed, attempt.TestsTotal = 4, 5
+	attempts.AddAttempt(attempt)
+	if got = recommender.Recommend("carol"); len(got) == 0 || got[0].ChallengeID != "2" || got[0].Kind != models.RecommendResume {
+		t.Errorf("carol got %+v", got)
+	}
+}
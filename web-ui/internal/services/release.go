@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
 	"log"
@@ -13,8 +14,10 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"web-ui/internal/config"
 	"web-ui/internal/models"
 )
 
@@ -23,18 +26,25 @@ import (
 // Everything is directory-driven: dropping a new releases/<version>/<feature>/
 // directory in publishes it. There is no registry to update.
 type ReleaseService struct {
+	cfg          *config.Config
+	runner       *ExecutionService // shares its limit on concurrent runs
 	releasesPath string
-	cached       []*models.Release
+
+	mutex  sync.RWMutex
+	cached []*models.Release // replaced wholesale by Load, never modified in place
 }
 
-func NewReleaseService() *ReleaseService {
-	return &ReleaseService{releasesPath: "../releases"} // relative to web-ui/
+func NewReleaseService(cfg *config.Config, runner *ExecutionService) *ReleaseService {
+	return &ReleaseService{cfg: cfg, runner: runner, releasesPath: cfg.ReleasesDir()}
 }
 
-// Load reads every release from disk. Called once at start-up.
+// Load reads every release from disk and swaps them in at once. Broken files
+// are skipped, logged and returned together; everything else still loads.
 func (s *ReleaseService) Load() error {
-	s.cached = nil
-	releases := s.GetReleases()
+	releases, errs := s.readReleases()
+	s.mutex.Lock()
+	s.cached = releases
+	s.mutex.Unlock()
 
 	features, challenges := 0, 0
 	for _, r := range releases {
@@ -43,28 +53,39 @@ func (s *ReleaseService) Load() error {
 	}
 	log.Printf("Loaded %d release(s), %d documented feature(s), %d challenge(s)",
 		len(releases), features, challenges)
-	return nil
+	return errors.Join(errs...)
 }
 
 // GetReleases returns all releases, newest version first.
 func (s *ReleaseService) GetReleases() []*models.Release {
-	if s.cached != nil {
-		return s.cached
+	s.mutex.RLock()
+	cached := s.cached
+	s.mutex.RUnlock()
+	if cached != nil {
+		return cached
 	}
 
+	s.Load()
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.cached
+}
+
+// readReleases loads the releases directory, newest version first
+func (s *ReleaseService) readReleases() ([]*models.Release, loadErrors) {
+	var errs loadErrors
 	entries, err := os.ReadDir(s.releasesPath)
 	if err != nil {
-		log.Printf("releases: cannot read %s: %v", s.releasesPath, err)
-		s.cached = []*models.Release{}
-		return s.cached
+		errs.add("cannot read %s: %v", s.releasesPath, err)
+		return []*models.Release{}, errs
 	}
 
-	var out []*models.Release
+	out := []*models.Release{}
 	for _, e := range entries {
 		if !e.IsDir() {
 			continue
 		}
-		if r := s.loadRelease(filepath.Join(s.releasesPath, e.Name())); r != nil {
+		if r := s.loadRelease(filepath.Join(s.releasesPath, e.Name()), &errs); r != nil {
 			out = append(out, r)
 		}
 	}
@@ -72,9 +93,7 @@ func (s *ReleaseService) GetReleases() []*models.Release {
 	sort.Slice(out, func(i, j int) bool {
 		return versionLess(out[j].Version, out[i].Version) // descending
 	})
-
-	s.cached = out
-	return s.cached
+	return out, errs
 }
 
 func (s *ReleaseService) GetRelease(version string) *models.Release {
@@ -114,7 +133,16 @@ func (s *ReleaseService) GetChallenge(version, feature, challenge string) *model
 
 // ── loading ──────────────────────────────────────────────────────────────────
 
-func (s *ReleaseService) loadRelease(dir string) *models.Release {
+// loadErrors collects the problems found while loading, so a reload can report them
+type loadErrors []error
+
+func (e *loadErrors) add(format string, args ...interface{}) {
+	err := fmt.Errorf(format, args...)
+	log.Printf("releases: %v", err)
+	*e = append(*e, err)
+}
+
+func (s *ReleaseService) loadRelease(dir string, errs *loadErrors) *models.Release {
 	raw, err := os.ReadFile(filepath.Join(dir, "release.json"))
 	if err != nil {
 		return nil // not a release directory (e.g. the track README)
@@ -122,13 +150,13 @@ func (s *ReleaseService) loadRelease(dir string) *models.Release {
 
 	var rel models.Release
 	if err := json.Unmarshal(raw, &rel); err != nil {
-		log.Printf("releases: bad release.json in %s: %v", dir, err)
+		errs.add("bad release.json in %s: %v", dir, err)
 		return nil
 	}
 
 	for i, slug := range rel.FeatureSlugs {
 		fdir := filepath.Join(dir, slug)
-		f := s.loadFeature(fdir, slug, &rel)
+		f := s.loadFeature(fdir, slug, &rel, errs)
 		if f == nil {
 			// Listed in release.json but not written yet.
 			f = &models.ReleaseFeature{
@@ -153,7 +181,7 @@ func (s *ReleaseService) loadRelease(dir string) *models.Release {
 	return &rel
 }
 
-func (s *ReleaseService) loadFeature(dir, slug string, rel *models.Release) *models.ReleaseFeature {
+func (s *ReleaseService) loadFeature(dir, slug string, rel *models.Release, errs *loadErrors) *models.ReleaseFeature {
 	raw, err := os.ReadFile(filepath.Join(dir, "feature.json"))
 	if err != nil {
 		return nil
@@ -161,7 +189,7 @@ func (s *ReleaseService) loadFeature(dir, slug string, rel *models.Release) *mod
 
 	var f models.ReleaseFeature
 	if err := json.Unmarshal(raw, &f); err != nil {
-		log.Printf("releases: bad feature.json in %s: %v", dir, err)
+		errs.add("bad feature.json in %s: %v", dir, err)
 		return nil
 	}
 
@@ -203,7 +231,7 @@ func (s *ReleaseService) loadFeature(dir, slug string, rel *models.Release) *mod
 	}
 
 	for i, cslug := range f.ChallengeSlugs {
-		c := s.loadChallenge(filepath.Join(dir, cslug), cslug, &f, rel)
+		c := s.loadChallenge(filepath.Join(dir, cslug), cslug, &f, rel, errs)
 		if c == nil {
 			continue
 		}
@@ -217,7 +245,7 @@ func (s *ReleaseService) loadFeature(dir, slug string, rel *models.Release) *mod
 	return &f
 }
 
-func (s *ReleaseService) loadChallenge(dir, slug string, f *models.ReleaseFeature, rel *models.Release) *models.ReleaseChallenge {
+func (s *ReleaseService) loadChallenge(dir, slug string, f *models.ReleaseFeature, rel *models.Release, errs *loadErrors) *models.ReleaseChallenge {
 	raw, err := os.ReadFile(filepath.Join(dir, "metadata.json"))
 	if err != nil {
 		return nil
@@ -225,7 +253,7 @@ func (s *ReleaseService) loadChallenge(dir, slug string, f *models.ReleaseFeatur
 
 	var c models.ReleaseChallenge
 	if err := json.Unmarshal(raw, &c); err != nil {
-		log.Printf("releases: bad metadata.json in %s: %v", dir, err)
+		errs.add("bad metadata.json in %s: %v", dir, err)
 		return nil
 	}
 
@@ -264,10 +292,11 @@ func (s *ReleaseService) loadChallenge(dir, slug string, f *models.ReleaseFeatur
 //
 // Like the local ExecutionService this compiles and runs submitted code in a
 // temporary directory on the host. That is fine for local development. Anywhere
-// untrusted users can reach it, set RELEASES_RUNNER=off and serve the content
-// read-only, or route this through the sandboxed execution engine.
+// untrusted users can reach it, set features.releases_runner = false
+// (RELEASES_RUNNER=off) and serve the content read-only, or route this through
+// the sandboxed execution engine.
 func (s *ReleaseService) RunnerEnabled() bool {
-	return !strings.EqualFold(os.Getenv("RELEASES_RUNNER"), "off")
+	return s.cfg.Features.ReleasesRunner
 }
 
 // RunChallenge compiles the submitted code together with the challenge's test
@@ -307,7 +336,8 @@ func (s *ReleaseService) RunChallenge(code string, c *models.ReleaseChallenge) m
 		}
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+	defer s.runner.acquireRun()()
+	ctx, cancel := context.WithTimeout(context.Background(), s.cfg.Runner.Timeout)
 	defer cancel()
 
 	cmd := exec.CommandContext(ctx, "go", "test", "-v", "./...")
@@ -324,7 +354,7 @@ func (s *ReleaseService) RunChallenge(code string, c *models.ReleaseChallenge) m
 	}
 
 	if ctx.Err() == context.DeadlineExceeded {
-		res.Output += "\n\nTimed out after 3 minutes."
+		res.Output += fmt.Sprintf("\n\nTimed out after %s.", s.cfg.Runner.Timeout)
 		return res
 	}
 	if runErr == nil {
@@ -0,0 +1,228 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"web-ui/internal/config"
+	"web-ui/internal/models"
+)
+
+// DefaultRubric is the name of the rubric that scores every challenge no
+// other rubric lists
+const DefaultRubric = "default"
+
+// rubricNamePattern is what rubric file names and criterion IDs, which are
+// kept in scorecards, may look like
+var rubricNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+// RubricService loads the interview scoring rubrics in the repository's
+// rubrics/ directory and works out which one scores each challenge.
+type RubricService struct {
+	cfg              *config.Config
+	challengeService *ChallengeService
+
+	mutex       sync.RWMutex
+	rubrics     []models.Rubric // by title; replaced wholesale by Load
+	byChallenge map[int]models.Rubric
+}
+
+func NewRubricService(cfg *config.Config, challengeService *ChallengeService) *RubricService {
+	return &RubricService{
+		cfg:              cfg,
+		challengeService: challengeService,
+		rubrics:          []models.Rubric{},
+		byChallenge:      make(map[int]models.Rubric),
+	}
+}
+
+// Load reads every rubric and swaps them in at once. Broken files are
+// skipped, as is a second rubric for a challenge that already has one;
+// challenges that do not exist are reported. All problems are logged and
+// returned together.
+func (rs *RubricService) Load() error {
+	dir := rs.cfg.RubricsDir()
+	files, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	var errs []error
+	rubrics := []models.Rubric{}
+	byChallenge := make(map[int]models.Rubric)
+	for _, f := range files {
+		name, ok := strings.CutSuffix(f.Name(), ".json")
+		if f.IsDir() || !ok {
+			continue
+		}
+		rubric, err := loadRubric(filepath.Join(dir, f.Name()), name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("rubrics/%s: %v", f.Name(), err))
+			continue
+		}
+		for _, id := range rubric.Challenges {
+			if other, ok := byChallenge[id]; ok {
+				errs = append(errs, fmt.Errorf("rubrics/%s: challenge %d is already scored by %s", f.Name(), id, other.Name))
+				continue
+			}
+			if _, ok := rs.challengeService.GetChallenge(id); !ok {
+				errs = append(errs, fmt.Errorf("rubrics/%s: no classic challenge %d", f.Name(), id))
+			}
+			byChallenge[id] = rubric
+		}
+		rubrics = append(rubrics, rubric)
+	}
+	sort.Slice(rubrics, func(i, j int) bool { return rubrics[i].Title < rubrics[j].Title })
+
+	rs.mutex.Lock()
+	rs.rubrics = rubrics
+	rs.byChallenge = byChallenge
+	rs.mutex.Unlock()
+	for _, err := range errs {
+		log.Printf("rubrics: %v", err)
+	}
+	log.Printf("Loaded %d rubric(s)", len(rubrics))
+	return errors.Join(errs...)
+}
+
+// loadRubric reads and checks one rubric file, putting each criterion's
+// levels in order
+func loadRubric(path, name string) (models.Rubric, error) {
+	var rubric models.Rubric
+	if !rubricNamePattern.MatchString(name) {
+		return rubric, fmt.Errorf("name must be lower-case letters, digits and dashes")
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return rubric, err
+	}
+	if err := json.Unmarshal(raw, &rubric); err != nil {
+		return rubric, err
+	}
+
+	rubric.Name = name
+	if rubric.Title == "" {
+		rubric.Title = name
+	}
+	if name == DefaultRubric && len(rubric.Challenges) > 0 {
+		return rubric, fmt.Errorf("the default rubric scores every challenge, so it lists none")
+	}
+	if name != DefaultRubric && len(rubric.Challenges) == 0 {
+		return rubric, fmt.Errorf("no challenges: a rubric other than the default lists the challenges it scores")
+	}
+	if len(rubric.Criteria) == 0 {
+		return rubric, fmt.Errorf("no criteria")
+	}
+	seen := make(map[string]bool)
+	for i := range rubric.Criteria {
+		criterion := &rubric.Criteria[i]
+		if !rubricNamePattern.MatchString(criterion.ID) {
+			return rubric, fmt.Errorf("criterion %d: IDs are lower-case letters, digits and dashes, not %q", i+1, criterion.ID)
+		}
+		if seen[criterion.ID] {
+			return rubric, fmt.Errorf("criterion %q appears twice", criterion.ID)
+		}
+		seen[criterion.ID] = true
+		if criterion.Title == "" {
+			criterion.Title = criterion.ID
+		}
+		if criterion.Weight < 1 {
+			return rubric, fmt.Errorf("%s: weights are whole numbers from 1", criterion.ID)
+		}
+		if len(criterion.Levels) < 2 {
+			return rubric, fmt.Errorf("%s: at least two levels", criterion.ID)
+		}
+		sort.Slice(criterion.Levels, func(a, b int) bool { return criterion.Levels[a].Score < criterion.Levels[b].Score })
+		for j, level := range criterion.Levels {
+			if j > 0 && level.Score == criterion.Levels[j-1].Score {
+				return rubric, fmt.Errorf("%s: two levels score %d", criterion.ID, level.Score)
+			}
+			if level.Label == "" {
+				return rubric, fmt.Errorf("%s: the level scoring %d has no label", criterion.ID, level.Score)
+			}
+		}
+	}
+	return rubric, nil
+}
+
+// Rubrics lists every rubric by title
+func (rs *RubricService) Rubrics() []models.Rubric {
+	rs.mutex.RLock()
+	defer rs.mutex.RUnlock()
+	return rs.rubrics
+}
+
+// RubricFor returns the rubric that scores a classic challenge: the one
+// listing it, or else the default. There is none when no rubric lists it and
+// there is no default.
+func (rs *RubricService) RubricFor(challengeID int) (models.Rubric, bool) {
+	rs.mutex.RLock()
+	defer rs.mutex.RUnlock()
+	if rubric, ok := rs.byChallenge[challengeID]; ok {
+		return rubric, true
+	}
+	for _, rubric := range rs.rubrics {
+		if rubric.Name == DefaultRubric {
+			return rubric, true
+		}
+	}
+	return models.Rubric{}, false
+}
+
+// WeightedRubricScore checks scores against a rubric and weights them into a
+// percentage. Each criterion's score counts from its lowest level, 0%, to its
+// highest, 100%. Criteria left unscored, such as communication in an
+// interview nobody watched, are left out of the weighting.
+func WeightedRubricScore(rubric models.Rubric, scores []models.RubricScore) (int, error) {
+	if len(scores) == 0 {
+		return 0, fmt.Errorf("no criteria scored")
+	}
+	seen := make(map[string]bool)
+	var weighted float64
+	var weights int
+	for _, score := range scores {
+		criterion, ok := rubricCriterion(rubric, score.Criterion)
+		if !ok {
+			return 0, fmt.Errorf("rubric %s has no criterion %q", rubric.Name, score.Criterion)
+		}
+		if seen[score.Criterion] {
+			return 0, fmt.Errorf("criterion %q is scored twice", score.Criterion)
+		}
+		seen[score.Criterion] = true
+		if !hasRubricLevel(criterion, score.Score) {
+			return 0, fmt.Errorf("%s has no level scoring %d", criterion.ID, score.Score)
+		}
+		lowest, highest := criterion.Levels[0].Score, criterion.Levels[len(criterion.Levels)-1].Score
+		weighted += float64(criterion.Weight) * float64(score.Score-lowest) / float64(highest-lowest)
+		weights += criterion.Weight
+	}
+	return int(weighted*100/float64(weights) + 0.5), nil
+}
+
+// rubricCriterion looks up a criterion of a rubric by ID
+func rubricCriterion(rubric models.Rubric, id string) (models.RubricCriterion, bool) {
+	for _, criterion := range rubric.Criteria {
+		if criterion.ID == id {
+			return criterion, true
+		}
+	}
+	return models.RubricCriterion{}, false
+}
+
+// hasRubricLevel reports whether a criterion has a level with the score
+func hasRubricLevel(criterion models.RubricCriterion, score int) bool {
+	for _, level := range criterion.Levels {
+		if level.Score == score {
+			return true
+		}
+	}
+	return false
+}
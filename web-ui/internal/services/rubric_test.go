@@ -0,0 +1,148 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"web-ui/internal/config"
+	"web-ui/internal/models"
+)
+
+func TestRubricScoring(t *testing.T) {
+	root := t.TempDir()
+	for _, id := range []string{"1", "2"} {
+		dir := filepath.Join(root, "challenge-"+id)
+		os.MkdirAll(dir, 0755)
+		os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Challenge\n"), 0644)
+		os.WriteFile(filepath.Join(dir, "solution-template.go"), []byte("package main\n"), 0644)
+	}
+	rubrics := map[string]string{
+		"default.json": `{"title": "Default", "criteria": [
+			{"id": "correctness", "weight": 3, "levels": [{"score": 4, "label": "Thorough"}, {"score": 1, "label": "Incorrect"}, {"score": 2, "label": "Partial"}, {"score": 3, "label": "Correct"}]},
+			{"id": "idioms", "weight": 1, "levels": [{"score": 0, "label": "Unidiomatic"}, {"score": 1, "label": "Idiomatic"}]}
+		]}`,
+		"concurrency.json": `{"title": "Concurrency", "challenges": [2, 9], "criteria": [
+			{"id": "races", "weight": 1, "levels": [{"score": 1, "label": "Racy"}, {"score": 2, "label": "Safe"}]}
+		]}`,
+		"again.json":      `{"challenges": [2], "criteria": [{"id": "races", "weight": 1, "levels": [{"score": 1, "label": "Racy"}, {"score": 2, "label": "Safe"}]}]}`,
+		"unweighted.json": `{"challenges": [1], "criteria": [{"id": "x", "weight": 0, "levels": [{"score": 1, "label": "A"}, {"score": 2, "label": "B"}]}]}`,
+	}
+	os.MkdirAll(filepath.Join(root, "rubrics"), 0755)
+	for name, body := range rubrics {
+		os.WriteFile(filepath.Join(root, "rubrics", name), []byte(body), 0644)
+	}
+	cfg := config.Default()
+	cfg.Content.Root = root
+	cfg.Server.DataDir = t.TempDir()
+	challenges := NewChallengeService(cfg)
+	if err := challenges.LoadChallenges(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The unweighted rubric, the second rubric for challenge 2 (files load in
+	// name order) and the missing challenge 9 are reported; the rest load
+	service := NewRubricService(cfg, challenges)
+	if err := service.Load(); err == nil {
+		t.Error("broken rubrics loaded without errors")
+	}
+	if got := len(service.Rubrics()); got != 3 {
+		t.Errorf("loaded %d rubrics, want 3", got)
+	}
+	if rubric, ok := service.RubricFor(2); !ok || rubric.Name != "again" {
+		t.Errorf("rubric for challenge 2 = %+v", rubric)
+	}
+	rubric, ok := service.RubricFor(1)
+	if !ok || rubric.Name != DefaultRubric || rubric.Criteria[0].Levels[0].Score != 1 || rubric.Criteria[0].Title != "correctness" {
+		t.Fatalf("rubric for challenge 1 = %+v", rubric)
+	}
+
+	// Scores count from the lowest level to the highest, weighted
+	for _, test := range []struct {
+		scores []models.RubricScore
+		want   int
+	}{
+		{[]models.RubricScore{{Criterion: "correctness", Score: 4}, {Criterion: "idioms", Score: 1}}, 100},
+		{[]models.RubricScore{{Criterion: "correctness", Score: 1}, {Criterion: "idioms", Score: 0}}, 0},
+		{[]models.RubricScore{{Criterion: "correctness", Score: 3}, {Criterion: "idioms", Score: 0}}, 50},
+		{[]models.RubricScore{{Criterion: "correctness", Score: 2}}, 33},
+	} {
+		if got, err := WeightedRubricScore(rubric, test.scores); err != nil || got != test.want {
+			t.Errorf("WeightedRubricScore(%+v) = %d, %v, want %d", test.scores, got, err, test.want)
+		}
+	}
+	for _, scores := range [][]models.RubricScore{
+		nil,
+		{{Criterion: "speed", Score: 1}},
+		{{Criterion: "correctness", Score: 5}},
+		{{Criterion: "idioms", Score: 1}, {Criterion: "idioms", Score: 0}},
+	} {
+		if _, err := WeightedRubricScore(rubric, scores); err == nil {
+			t.Errorf("WeightedRubricScore(%+v) succeeded", scores)
+		}
+	}
+
+	// Suggestions are kept off the report until an interviewer scores
+	interviews, err := NewInterviewService(cfg.Server.DataDir, challenges, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	session, _ := interviews.Start("alice", []int{1, 2}, false, MinInterviewMinutes)
+	now := time.Now()
+	good := []models.RubricScore{{Criterion: "correctness", Score: 3}, {Criterion: "idioms", Score: 0}}
+	if _, err := interviews.Score(session.ID, 1, rubric, good, "", "lead", now); !errors.Is(err, ErrInterviewNotEnded) {
+		t.Errorf("scoring a running interview: %v", err)
+	}
+	interviews.Finish(session.ID, now)
+	scorecard, err := interviews.Suggest(session.ID, 1, rubric, []models.RubricScore{{Criterion: "correctness", Score: 4, Justification: "Handles empty input"}}, now)
+	if err != nil || scorecard.WeightedScore != nil || len(scorecard.Suggestions) != 1 {
+		t.Fatalf("suggest = %+v, %v", scorecard, err)
+	}
+	if ended, _ := interviews.Session(session.ID); ended.Report.RubricScore != nil {
+		t.Errorf("suggestions scored the report: %d", *ended.Report.RubricScore)
+	}
+
+	scorecard, err = interviews.Score(session.ID, 1, rubric, good, "Talked it through", "lead", now)
+	if err != nil || *scorecard.WeightedScore != 50 || len(scorecard.Suggestions) != 1 || scorecard.ScoredBy != "lead" {
+		t.Fatalf("score = %+v, %v", scorecard, err)
+	}
+	races, _ := service.RubricFor(2)
+	interviews.Score(session.ID, 2, races, []models.RubricScore{{Criterion: "races", Score: 2}}, "", "lead", now)
+	ended, _ := interviews.Session(session.ID)
+	if r := ended.Report; r.RubricScore == nil || *r.RubricScore != 75 || *r.Challenges[0].RubricScore != 50 || *r.Challenges[1].RubricScore != 100 {
+		t.Errorf("report rubric scores = %+v", r)
+	}
+
+	// A changed rubric starts the scorecard over
+	changed := rubric
+	changed.Title = "Changed"
+	scorecard, _ = interviews.Score(session.ID, 1, changed, good, "", "lead", now)
+	if len(scorecard.Suggestions) != 0 || scorecard.Rubric.Title != "Changed" {
+		t.Errorf("scorecard on a changed rubric = %+v", scorecard)
+	}
+
+	// The export shows each criterion with its scores
+	ended, _ = interviews.Session(session.ID)
+	export, err := interviews.Export(ended)
+	if err != nil {
+		t.Fatal(err)
+	}
+	criteria := export.Challenges[0].Criteria
+	if len(criteria) != 2 || criteria[0].Score == nil || criteria[0].Score.Label != "Correct" || criteria[1].Score.Label != "Unidiomatic" {
+		t.Errorf("exported criteria = %+v", criteria)
+	}
+
+	// Suggestions from the AI are kept only where they fit the rubric
+	ai := &AIService{}
+	parsed := ai.parseRubricScores("```json\n"+`{"scores": [
+		{"criterion": "correctness", "score": 3, "justification": "ok"},
+		{"criterion": "correctness", "score": 1},
+		{"criterion": "idioms", "score": 7},
+		{"criterion": "testing", "score": 1}
+	]}`+"\n```", rubric)
+	if len(parsed) != 1 || parsed[0].Justification != "ok" {
+		t.Errorf("parsed suggestions = %+v", parsed)
+	}
+}
@@ -1,136 +1,108 @@
 package services
 
 import (
-	"io/ioutil"
-	"path/filepath"
-	"strconv"
-	"strings"
+	"errors"
+	"os"
+	"sync"
 	"time"
 
+	"web-ui/internal/config"
 	"web-ui/internal/models"
+	"web-ui/internal/scoreboard"
 )
 
 // ScoreboardService handles scoreboard-related operations
 type ScoreboardService struct {
+	cfg         *config.Config
 	scoreboards models.ScoreboardMap
+	history     *GitHistoryService
+	mutex       sync.RWMutex
 }
 
-// NewScoreboardService creates a new scoreboard service
-func NewScoreboardService() *ScoreboardService {
+// NewScoreboardService creates a new scoreboard service. history may be nil
+// when the server does not run from a git checkout.
+func NewScoreboardService(cfg *config.Config, history *GitHistoryService) *ScoreboardService {
 	return &ScoreboardService{
+		cfg:         cfg,
 		scoreboards: make(models.ScoreboardMap),
+		history:     history,
 	}
 }
 
-// LoadScoreboards loads all scoreboards from the filesystem
-func (ss *ScoreboardService) LoadScoreboards(challenges models.ChallengeMap) error {
-	for id := range challenges {
-		challengeDir := filepath.Join("..", "challenge-"+strconv.Itoa(id))
-		ss.loadScoreboardForChallenge(id, challengeDir)
+// SubmittedAt dates a scoreboard entry of the challenge identified by key (see
+// ClassicSubmissionKey and PackageSubmissionKey) by the timestamp recorded in
+// scoreboard.json, which for a passing entry is when the user passed. Entries
+// recorded before runs were dated fall back to when the user's solution first
+// landed in git, and stay zero when the history does not know either.
+func (ss *ScoreboardService) SubmittedAt(key string, entry scoreboard.Entry) time.Time {
+	if !entry.Timestamp.IsZero() || ss.history == nil {
+		return entry.Timestamp
 	}
-	return nil
-}
-
-// loadScoreboardForChallenge loads the scoreboard for a specific challenge
-func (ss *ScoreboardService) loadScoreboardForChallenge(id int, dir string) {
-	scoreboardPath := filepath.Join(dir, "SCOREBOARD.md")
-	scoreboardContent, err := ioutil.ReadFile(scoreboardPath)
-	if err != nil {
-		return
+	if commit, ok := ss.history.FirstSubmission(key, entry.Username); ok {
+		return commit.Time
 	}
-
-	// Parse scoreboard markdown table
-	entries := ss.parseScoreboardMarkdown(string(scoreboardContent), id)
-	ss.scoreboards[id] = entries
+	return time.Time{}
 }
 
-// parseScoreboardMarkdown parses the scoreboard markdown table
-func (ss *ScoreboardService) parseScoreboardMarkdown(content string, challengeID int) []models.ScoreboardEntry {
-	lines := strings.Split(content, "\n")
-	entries := []models.ScoreboardEntry{}
-
-	// Determine format by looking at header line
-	var format int = 1 // Default to format 1
-	headerLine := ""
-	for i, line := range lines {
-		if i > 0 && strings.Contains(line, "|") {
-			headerLine = line
-			break
-		}
-	}
-
-	if strings.Contains(headerLine, "Rank") && strings.Contains(headerLine, "Username") {
-		format = 2
-	}
-
-	for i, line := range lines {
-		// Skip header and separator lines
-		if i < 3 {
-			continue
-		}
-
-		// Skip empty lines
-		if strings.TrimSpace(line) == "" {
-			continue
-		}
-
-		// Skip separator lines
-		if strings.Contains(line, "---") {
-			continue
-		}
-
-		parts := strings.Split(line, "|")
-		if len(parts) < 3 {
-			continue
-		}
-
-		var username string
-
-		if format == 1 {
-			// Format is: | Username | Passed Tests | Total Tests |
-			username = strings.TrimSpace(parts[1])
-		} else {
-			// Format is: | Rank | Username | Solution | Date Submitted |
-			username = strings.TrimSpace(parts[2])
-		}
-
-		// Skip empty usernames or placeholders
-		if username == "" || username == "------" || ss.isNumeric(username) {
+// LoadScoreboards loads all scoreboards from the filesystem and swaps them in
+// at once. Entries added with AddSubmission since the last load are replaced by
+// what the files say.
+func (ss *ScoreboardService) LoadScoreboards(challenges models.ChallengeMap) error {
+	scoreboards := make(models.ScoreboardMap, len(challenges))
+	var errs []error
+	for id := range challenges {
+		entries, err := ss.loadScoreboardForChallenge(id, ss.cfg.ChallengeDir(id))
+		if err != nil {
+			if !os.IsNotExist(err) {
+				errs = append(errs, err)
+			}
 			continue
 		}
-
-		// Use current time for existing entries
-		entry := models.ScoreboardEntry{
-			Username:    username,
-			ChallengeID: challengeID,
-			SubmittedAt: time.Now(),
-		}
-
-		entries = append(entries, entry)
+		scoreboards[id] = entries
 	}
 
-	return entries
+	ss.mutex.Lock()
+	ss.scoreboards = scoreboards
+	ss.mutex.Unlock()
+	return errors.Join(errs...)
 }
 
-// isNumeric checks if a string contains only digits
-func (ss *ScoreboardService) isNumeric(s string) bool {
-	for _, r := range s {
-		if r < '0' || r > '9' {
-			return false
-		}
+// loadScoreboardForChallenge loads the scoreboard for a specific challenge
+func (ss *ScoreboardService) loadScoreboardForChallenge(id int, dir string) ([]models.ScoreboardEntry, error) {
+	board, err := scoreboard.Load(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]models.ScoreboardEntry, 0, len(board.Entries))
+	for _, e := range board.Entries {
+		// Entries without a date stay zero, which pages show as unknown
+		entries = append(entries, models.ScoreboardEntry{
+			Username:    e.Username,
+			ChallengeID: id,
+			SubmittedAt: ss.SubmittedAt(ClassicSubmissionKey(id), e),
+		})
 	}
-	return true
+	return entries, nil
 }
 
 // GetScoreboard returns the scoreboard for a specific challenge
 func (ss *ScoreboardService) GetScoreboard(challengeID int) ([]models.ScoreboardEntry, bool) {
+	ss.mutex.RLock()
+	defer ss.mutex.RUnlock()
 	scoreboard, exists := ss.scoreboards[challengeID]
 	return scoreboard, exists
 }
 
-// GetAllScoreboards returns all scoreboards
+// GetAllScoreboards returns a snapshot of all scoreboards
 func (ss *ScoreboardService) GetAllScoreboards() models.ScoreboardMap {
-	return ss.scoreboards
+	ss.mutex.RLock()
+	defer ss.mutex.RUnlock()
+	snapshot := make(models.ScoreboardMap, len(ss.scoreboards))
+	for id, entries := range ss.scoreboards {
+		snapshot[id] = entries
+	}
+	return snapshot
 }
 
 // AddSubmission adds a submission to the scoreboard
@@ -142,6 +114,8 @@ func (ss *ScoreboardService) AddSubmission(submission models.Submission) {
 	}
 
 	// Add to the scoreboard for this challenge
+	ss.mutex.Lock()
+	defer ss.mutex.Unlock()
 	if ss.scoreboards[submission.ChallengeID] == nil {
 		ss.scoreboards[submission.ChallengeID] = []models.ScoreboardEntry{}
 	}
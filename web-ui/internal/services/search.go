@@ -0,0 +1,498 @@
+package services
+
+import (
+	"html"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"web-ui/internal/models"
+)
+
+// SearchFacets are the filters a search accepts, in the order they are reported
+var SearchFacets = []string{"track", "difficulty", "tag", "package", "go", "kind"}
+
+// SearchQuery is a free-text query narrowed by facet filters. Values of one
+// facet are alternatives; different facets must all match.
+type SearchQuery struct {
+	Text    string
+	Filters map[string][]string // facet → accepted values
+	Limit   int
+	Offset  int
+}
+
+// searchField is a part of a challenge that is indexed, with its weight
+type searchField int
+
+const (
+	fieldTitle searchField = iota
+	fieldTags
+	fieldSummary // short description, objectives, feature summary
+	fieldBody    // README
+	fieldLearning
+	numSearchFields
+)
+
+var searchFieldBoost = [numSearchFields]float64{8, 5, 3, 1, 0.5}
+
+// snippetFields are searched for a snippet in this order
+var snippetFields = []searchField{fieldBody, fieldLearning, fieldSummary, fieldTitle}
+
+// searchDocument is one indexed challenge
+type searchDocument struct {
+	result models.SearchResult
+	facets map[string][]string
+	text   [numSearchFields]string // plain text, for snippets
+	order  int                     // listing order when there is no query
+}
+
+// posting records how strongly a term occurs in a document
+type posting struct {
+	doc    int
+	weight float64
+}
+
+// searchIndex is an immutable inverted index over every track
+type searchIndex struct {
+	docs     []*searchDocument
+	postings map[string][]posting
+	terms    []string // sorted, for prefix matching
+}
+
+// SearchService searches challenges across the classic, package and release
+// tracks. The index is rebuilt from the other services whenever content is
+// reloaded and swapped in whole, so searches never see a half-built index.
+type SearchService struct {
+	challengeService *ChallengeService
+	packageService   *PackageService
+	releaseService   *ReleaseService
+
+	mutex sync.RWMutex
+	index *searchIndex
+}
+
+// NewSearchService creates a search service and builds its index
+func NewSearchService(challengeService *ChallengeService, packageService *PackageService, releaseService *ReleaseService) *SearchService {
+	s := &SearchService{
+		challengeService: challengeService,
+		packageService:   packageService,
+		releaseService:   releaseService,
+	}
+	s.Rebuild()
+	return s
+}
+
+// Rebuild re-indexes the content the services currently hold
+func (s *SearchService) Rebuild() error {
+	var docs []*searchDocument
+	docs = append(docs, s.classicDocuments()...)
+	docs = append(docs, s.packageDocuments()...)
+	docs = append(docs, s.releaseDocuments()...)
+	for i, doc := range docs {
+		doc.order = i
+	}
+
+	index := buildSearchIndex(docs)
+	s.mutex.Lock()
+	s.index = index
+	s.mutex.Unlock()
+	return nil
+}
+
+func (s *SearchService) classicDocuments() []*searchDocument {
+	var docs []*searchDocument
+	for _, c := range s.challengeService.ListChallenges() {
+		doc := &searchDocument{
+			result: models.SearchResult{
+				Track:      "classic",
+				ID:         strconv.Itoa(c.ID),
+				Title:      c.Title,
+				URL:        "/challenge/" + strconv.Itoa(c.ID),
+				Summary:    c.ShortDescription,
+				Difficulty: c.Difficulty,
+				Tags:       c.Tags,
+			},
+		}
+		doc.text[fieldTitle] = c.Title
+		doc.text[fieldTags] = strings.Join(c.Tags, " ")
+		doc.text[fieldSummary] = joinText(c.ShortDescription, strings.Join(c.LearningObjectives, ". "))
+		doc.text[fieldBody] = plainText(strings.Replace(c.Description, scoreboardLink, "", 1))
+		doc.text[fieldLearning] = plainText(c.LearningMaterials)
+		docs = append(docs, doc)
+	}
+	return docs
+}
+
+// scoreboardLink opens every classic README; it is not worth a snippet
+const scoreboardLink = "[View the Scoreboard](SCOREBOARD.md)"
+
+func (s *SearchService) packageDocuments() []*searchDocument {
+	packages := s.packageService.GetPackages()
+	names := make([]string, 0, len(packages))
+	for name := range packages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var docs []*searchDocument
+	for _, name := range names {
+		pkg := packages[name]
+		for _, id := range pkg.LearningPath {
+			info := pkg.ChallengeDetails[id]
+			if info == nil || info.Status != "available" {
+				continue
+			}
+			challenge, err := s.packageService.GetPackageChallenge(name, id)
+			if err != nil {
+				continue
+			}
+			doc := &searchDocument{
+				result: models.SearchResult{
+					Track:      "package",
+					ID:         name + "/" + id,
+					Title:      info.Title,
+					URL:        "/packages/" + name + "/" + id,
+					Summary:    info.Description,
+					Difficulty: info.Difficulty,
+					Tags:       info.Tags,
+					Package:    name,
+				},
+			}
+			doc.text[fieldTitle] = joinText(info.Title, pkg.DisplayName)
+			doc.text[fieldTags] = strings.Join(info.Tags, " ")
+			doc.text[fieldSummary] = joinText(info.Description, strings.Join(info.LearningObjectives, ". "))
+			doc.text[fieldBody] = plainText(challenge.Description)
+			doc.text[fieldLearning] = plainText(challenge.LearningMaterials)
+			docs = append(docs, doc)
+		}
+	}
+	return docs
+}
+
+func (s *SearchService) releaseDocuments() []*searchDocument {
+	var docs []*searchDocument
+	for _, release := range s.releaseService.GetReleases() {
+		for _, feature := range release.Features {
+			for _, c := range feature.Challenges {
+				goVersion := c.GoVersion
+				if goVersion == "" {
+					goVersion = release.Version
+				}
+				doc := &searchDocument{
+					result: models.SearchResult{
+						Track:      "release",
+						ID:         release.Version + "/" + feature.Slug + "/" + c.Slug,
+						Title:      c.Title,
+						URL:        "/releases/" + release.Version + "/" + feature.Slug + "/" + c.Slug,
+						Summary:    c.ShortDescription,
+						Difficulty: c.Difficulty,
+						Tags:       c.Tags,
+						GoVersion:  goVersion,
+						Kind:       feature.Kind,
+					},
+				}
+				doc.text[fieldTitle] = joinText(c.Title, feature.Title)
+				doc.text[fieldTags] = strings.Join(append(append([]string{}, c.Tags...), feature.Tags...), " ")
+				doc.text[fieldSummary] = joinText(c.ShortDescription, strings.Join(c.LearningObjectives, ". "), feature.ShortDescription)
+				doc.text[fieldBody] = plainText(string(c.ReadmeHTML))
+				doc.text[fieldLearning] = plainText(string(c.LearningHTML))
+				docs = append(docs, doc)
+			}
+		}
+	}
+	return docs
+}
+
+func buildSearchIndex(docs []*searchDocument) *searchIndex {
+	index := &searchIndex{docs: docs, postings: make(map[string][]posting)}
+	for i, doc := range docs {
+		r := doc.result
+		doc.facets = map[string][]string{
+			"track":      {r.Track},
+			"difficulty": nonEmpty(r.Difficulty),
+			"tag":        r.Tags,
+			"package":    nonEmpty(r.Package),
+			"go":         nonEmpty(r.GoVersion),
+			"kind":       nonEmpty(r.Kind),
+		}
+
+		weights := make(map[string]float64)
+		for field, text := range doc.text {
+			counts := make(map[string]int)
+			for _, term := range tokenize(text) {
+				counts[term]++
+			}
+			// Dampened term frequency, so a README repeating a word does not
+			// outrank a title containing it
+			for term, n := range counts {
+				weights[term] += searchFieldBoost[field] * (1 + math.Log(float64(n)))
+			}
+		}
+		for term, weight := range weights {
+			index.postings[term] = append(index.postings[term], posting{doc: i, weight: weight})
+		}
+	}
+
+	for term := range index.postings {
+		index.terms = append(index.terms, term)
+	}
+	sort.Strings(index.terms)
+	return index
+}
+
+// Search runs a query. An empty query lists everything the filters allow.
+func (s *SearchService) Search(query SearchQuery) models.SearchResponse {
+	s.mutex.RLock()
+	index := s.index
+	s.mutex.RUnlock()
+
+	terms := tokenize(query.Text)
+	scores := index.score(terms)
+
+	response := models.SearchResponse{
+		Query:   query.Text,
+		Results: []models.SearchResult{},
+		Facets:  make(map[string]map[string]int),
+	}
+	for _, facet := range SearchFacets {
+		response.Facets[facet] = make(map[string]int)
+	}
+
+	var matches []int
+	for i, doc := range index.docs {
+		if _, ok := scores[i]; !ok && len(terms) > 0 {
+			continue
+		}
+		if doc.matches(query.Filters, "") {
+			matches = append(matches, i)
+		}
+		for _, facet := range SearchFacets {
+			if doc.matches(query.Filters, facet) {
+				for _, value := range doc.facets[facet] {
+					response.Facets[facet][value]++
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(matches, func(a, b int) bool {
+		if scores[matches[a]] != scores[matches[b]] {
+			return scores[matches[a]] > scores[matches[b]]
+		}
+		return index.docs[matches[a]].order < index.docs[matches[b]].order
+	})
+	response.Total = len(matches)
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+	if query.Offset < len(matches) {
+		matches = matches[max(query.Offset, 0):]
+	} else {
+		matches = nil
+	}
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	highlight := highlighter(terms)
+	for _, i := range matches {
+		doc := index.docs[i]
+		result := doc.result
+		result.Score = math.Round(scores[i]*100) / 100
+		if highlight != nil {
+			result.Snippet = doc.snippet(highlight)
+		}
+		response.Results = append(response.Results, result)
+	}
+	return response
+}
+
+// score ranks the documents containing every query term. Terms of three or
+// more letters also match as word prefixes, which covers plurals and words
+// still being typed.
+func (index *searchIndex) score(terms []string) map[int]float64 {
+	scores := make(map[int]float64)
+	for n, term := range terms {
+		expanded := []string{term}
+		if len(term) >= 3 {
+			expanded = index.withPrefix(term)
+		}
+
+		termScores := make(map[int]float64)
+		for _, t := range expanded {
+			postings := index.postings[t]
+			idf := math.Log(1 + float64(len(index.docs))/float64(len(postings)))
+			if t != term {
+				idf /= 2 // completions count for less than the word itself
+			}
+			for _, p := range postings {
+				termScores[p.doc] = math.Max(termScores[p.doc], p.weight*idf)
+			}
+		}
+
+		if n == 0 {
+			scores = termScores
+			continue
+		}
+		for doc := range scores {
+			if s, ok := termScores[doc]; ok {
+				scores[doc] += s
+			} else {
+				delete(scores, doc)
+			}
+		}
+	}
+	return scores
+}
+
+// withPrefix returns the indexed terms starting with prefix
+func (index *searchIndex) withPrefix(prefix string) []string {
+	start := sort.SearchStrings(index.terms, prefix)
+	end := start
+	for end < len(index.terms) && strings.HasPrefix(index.terms[end], prefix) {
+		end++
+	}
+	return index.terms[start:end]
+}
+
+// matches reports whether the document passes every filter except the one
+// on facet skip
+func (doc *searchDocument) matches(filters map[string][]string, skip string) bool {
+	for facet, accepted := range filters {
+		if facet == skip || len(accepted) == 0 {
+			continue
+		}
+		found := false
+		for _, value := range doc.facets[facet] {
+			for _, want := range accepted {
+				if strings.EqualFold(value, want) {
+					found = true
+				}
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// snippet returns about 160 characters around the first match, HTML-escaped
+// with every match marked
+func (doc *searchDocument) snippet(highlight *regexp.Regexp) string {
+	const before, length = 60, 160
+	for _, field := range snippetFields {
+		text := doc.text[field]
+		loc := highlight.FindStringIndex(text)
+		if loc == nil {
+			continue
+		}
+
+		start := max(loc[0]-before, 0)
+		end := min(start+length, len(text))
+		if start > 0 {
+			start = wordStart(text, start)
+		}
+		end = wordEnd(text, max(end, loc[1]))
+		window := text[start:end]
+
+		var out strings.Builder
+		if start > 0 {
+			out.WriteString("…")
+		}
+		last := 0
+		for _, m := range highlight.FindAllStringIndex(window, -1) {
+			out.WriteString(html.EscapeString(window[last:m[0]]))
+			out.WriteString("<mark>" + html.EscapeString(window[m[0]:m[1]]) + "</mark>")
+			last = m[1]
+		}
+		out.WriteString(html.EscapeString(window[last:]))
+		if end < len(text) {
+			out.WriteString("…")
+		}
+		return out.String()
+	}
+	return ""
+}
+
+// highlighter matches the query terms at the start of words, case-insensitively
+func highlighter(terms []string) *regexp.Regexp {
+	if len(terms) == 0 {
+		return nil
+	}
+	quoted := make([]string, len(terms))
+	for i, term := range terms {
+		quoted[i] = regexp.QuoteMeta(term)
+	}
+	return regexp.MustCompile(`(?i)\b(?:` + strings.Join(quoted, "|") + `)\w*`)
+}
+
+// searchStopWords are too common in challenge text to be worth indexing
+var searchStopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "be": true, "by": true,
+	"for": true, "from": true, "in": true, "is": true, "it": true, "of": true, "on": true,
+	"or": true, "that": true, "the": true, "this": true, "to": true, "with": true, "you": true,
+}
+
+// tokenize splits text into lowercase words, dropping stop words
+func tokenize(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	terms := words[:0]
+	for _, word := range words {
+		if len(word) > 1 && !searchStopWords[word] {
+			terms = append(terms, word)
+		}
+	}
+	return terms
+}
+
+var (
+	htmlTag         = regexp.MustCompile(`<[^>]*>`)
+	markdownLink    = regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`)
+	markdownSyntax  = regexp.MustCompile("(?m)^\\s*(#+|[-*+>]|\\d+\\.)\\s+|[*_`|]+")
+	whitespaceSpans = regexp.MustCompile(`\s+`)
+)
+
+// plainText reduces markdown or HTML to the words a reader sees
+func plainText(text string) string {
+	text = htmlTag.ReplaceAllString(text, " ")
+	text = html.UnescapeString(text)
+	text = markdownLink.ReplaceAllString(text, "$1")
+	text = markdownSyntax.ReplaceAllString(text, " ")
+	return strings.TrimSpace(whitespaceSpans.ReplaceAllString(text, " "))
+}
+
+func joinText(parts ...string) string {
+	return strings.Join(parts, ". ")
+}
+
+func nonEmpty(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return []string{value}
+}
+
+// wordStart moves i forward to the start of the next word
+func wordStart(text string, i int) int {
+	for i < len(text) && text[i] != ' ' {
+		i++
+	}
+	return min(i+1, len(text))
+}
+
+// wordEnd moves i forward to the end of the current word
+func wordEnd(text string, i int) int {
+	for i < len(text) && text[i] != ' ' {
+		i++
+	}
+	return i
+}
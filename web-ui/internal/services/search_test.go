@@ -0,0 +1,56 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"web-ui/internal/models"
+)
+
+func TestSearchRanksFiltersAndHighlights(t *testing.T) {
+	doc := func(track, id, title, difficulty, body string, tags ...string) *searchDocument {
+		d := &searchDocument{result: models.SearchResult{Track: track, ID: id, Title: title, Difficulty: difficulty, Tags: tags}}
+		d.text[fieldTitle] = title
+		d.text[fieldTags] = strings.Join(tags, " ")
+		d.text[fieldBody] = body
+		return d
+	}
+	docs := []*searchDocument{
+		doc("classic", "8", "Chat Server with Channels", "Advanced", "Broadcast messages to clients over channels.", "concurrency"),
+		doc("classic", "4", "Concurrent BFS", "Intermediate", "Use goroutines and a channel per <worker>.", "concurrency", "graphs"),
+		doc("package", "gin/challenge-1", "Basic Routing", "Beginner", "Route requests with gin."),
+	}
+	search := &SearchService{index: buildSearchIndex(docs)}
+
+	// "channel" matches "channels" as a prefix; the title match ranks first
+	response := search.Search(SearchQuery{Text: "channel"})
+	if response.Total != 2 || response.Results[0].ID != "8" {
+		t.Fatalf("channel: %+v", response.Results)
+	}
+	if got := response.Results[1].Snippet; !strings.Contains(got, "<mark>channel</mark>") || !strings.Contains(got, "&lt;worker&gt;") {
+		t.Errorf("snippet not highlighted and escaped: %s", got)
+	}
+
+	// Every term must match
+	if response := search.Search(SearchQuery{Text: "channels broadcast"}); response.Total != 1 {
+		t.Errorf("channels broadcast: %d results", response.Total)
+	}
+
+	// Facets count what the other filters allow
+	response = search.Search(SearchQuery{Filters: map[string][]string{"track": {"classic"}, "difficulty": {"advanced"}}})
+	if response.Total != 1 || response.Results[0].ID != "8" {
+		t.Fatalf("classic advanced: %+v", response.Results)
+	}
+	if response.Facets["track"]["package"] != 0 || response.Facets["track"]["classic"] != 1 {
+		t.Errorf("track facet = %v", response.Facets["track"])
+	}
+	if response.Facets["difficulty"]["Intermediate"] != 1 {
+		t.Errorf("difficulty facet = %v; it should ignore its own filter", response.Facets["difficulty"])
+	}
+
+	// Paging
+	response = search.Search(SearchQuery{Limit: 2, Offset: 2})
+	if response.Total != 3 || len(response.Results) != 1 || response.Results[0].Track != "package" {
+		t.Errorf("second page: total %d, %+v", response.Total, response.Results)
+	}
+}
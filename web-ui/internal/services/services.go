@@ -0,0 +1,219 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"web-ui/internal/config"
+)
+
+// Services holds every service the server uses. The server, the commands and
+// tests all build them through NewServices, so they are wired up the same way.
+type Services struct {
+	Challenges      *ChallengeService
+	GitHistory      *GitHistoryService
+	Scoreboards     *ScoreboardService
+	Users           *UserService
+	Execution       *ExecutionService
+	Packages        *PackageService
+	AI              *AIService
+	Submissions     SubmissionStore
+	Attempts        AttemptStore
+	Leaderboards    *LeaderboardService
+	Releases        *ReleaseService
+	Activity        *ActivityService
+	Achievements    *AchievementService
+	Webhooks        *WebhookService
+	Search          *SearchService
+	Recommendations *RecommendationService
+	Curricula       *CurriculumService
+	Badges          *BadgeService
+	Profiles        *ProfileService
+	Teams           *TeamService
+	Assignments     *AssignmentService
+	Interviews      *InterviewService
+	InterviewLoops  *InterviewLoopService
+	Rubrics         *RubricService
+	Pairing         *PairingService
+
+	// ContentWatcher reloads the services above when their content changes. It
+	// is set up but not started.
+	ContentWatcher *ContentWatcher
+}
+
+// NewServices builds every service and loads what they read. State in the data
+// directory that cannot be opened is an error; content that fails to load is
+// logged and left out, as it would be on a reload.
+func NewServices(cfg *config.Config) (*Services, error) {
+	s := &Services{
+		Challenges: NewChallengeService(cfg),
+		GitHistory: NewGitHistoryService(cfg),
+		Users:      NewUserService(cfg),
+		Execution:  NewExecutionService(cfg),
+		Packages:   NewPackageService(cfg),
+		AI:         NewAIService(cfg),
+	}
+	s.Scoreboards = NewScoreboardService(cfg, s.GitHistory)
+
+	var err error
+	if s.Submissions, err = NewFileSubmissionStore(cfg.Server.DataDir); err != nil {
+		return nil, fmt.Errorf("submission store: %v", err)
+	}
+	if s.Attempts, err = NewFileAttemptStore(cfg.Server.DataDir); err != nil {
+		return nil, fmt.Errorf("attempt store: %v", err)
+	}
+
+	log.Println("Loading challenges...")
+	if err := s.Challenges.LoadChallenges(); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+
+	log.Println("Reading submission dates from git history...")
+	if err := s.GitHistory.Load(); err != nil {
+		// Not fatal: deployments without .git fall back to scoreboard and file times.
+		log.Printf("Warning: %v", err)
+	}
+
+	log.Println("Loading scoreboards...")
+	if err := s.Scoreboards.LoadScoreboards(s.Challenges.GetChallenges()); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+
+	log.Println("Loading packages...")
+	if err := s.Packages.LoadPackages(); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+
+	// Reload content when it changes on disk. Merged submissions also move HEAD,
+	// so the git history is brought up to date along with the scoreboards.
+	s.ContentWatcher = NewContentWatcher(cfg.Content.Root)
+	reloadHistory := func() {
+		if err := s.GitHistory.Load(); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}
+	s.ContentWatcher.Watch("challenges", []string{"challenge-*"}, func() error {
+		err := s.Challenges.LoadChallenges()
+		reloadHistory()
+		return errors.Join(err, s.Scoreboards.LoadScoreboards(s.Challenges.GetChallenges()))
+	})
+	s.ContentWatcher.Watch("packages", []string{"packages"}, func() error {
+		reloadHistory()
+		return s.Packages.Reload()
+	})
+
+	s.Leaderboards = NewLeaderboardService(cfg, s.Challenges, s.Packages, s.Scoreboards)
+
+	// "New in Go" release track, read from the releases/ directory and reloaded
+	// by the content watcher
+	s.Releases = NewReleaseService(cfg, s.Execution)
+	if err := s.Releases.Load(); err != nil {
+		log.Printf("releases: %v", err)
+	}
+	s.ContentWatcher.Watch("releases", []string{"releases"}, s.Releases.Load)
+
+	// Daily activity and streaks are computed from the attempt history in each
+	// user's time zone
+	if s.Activity, err = NewActivityService(cfg, s.Attempts); err != nil {
+		return nil, fmt.Errorf("activity: %v", err)
+	}
+
+	// Achievements are evaluated after every submit on any track. Their rules
+	// may be overridden by achievements.json in the repository.
+	s.Achievements, err = NewAchievementService(cfg, s.Challenges, s.Packages,
+		s.Releases, s.Leaderboards, s.Activity, s.Submissions, s.Attempts)
+	if err != nil {
+		return nil, fmt.Errorf("achievements: %v", err)
+	}
+	if err := s.Achievements.LoadRules(); err != nil {
+		log.Printf("achievements: %v", err)
+	}
+	s.ContentWatcher.Watch("achievements", []string{"achievements.json"}, s.Achievements.LoadRules)
+
+	// Outgoing webhooks announce runs, submissions, achievements and completed
+	// packages. Like the teams file, the webhooks file is managed over the
+	// admin API.
+	if s.Webhooks, err = NewWebhookService(cfg.Server.WebhooksFile, cfg.Server.DataDir); err != nil {
+		return nil, fmt.Errorf("webhooks: %v", err)
+	}
+	if err := s.Webhooks.Load(); err != nil {
+		log.Printf("webhooks: %v", err)
+	}
+
+	// Profile badges render from the leaderboards on request
+	s.Badges = NewBadgeService(s.Leaderboards, s.Achievements)
+
+	// Profiles gather every track, so they are built once everything else is.
+	// Their privacy settings also decide whose submissions the API lists.
+	s.Profiles, err = NewProfileService(cfg, s.Challenges, s.Packages, s.Releases,
+		s.Users, s.Leaderboards, s.Badges, s.Achievements, s.Activity, s.Submissions, s.Attempts)
+	if err != nil {
+		return nil, fmt.Errorf("profiles: %v", err)
+	}
+
+	// Search indexes all three tracks, so it is built once releases are loaded
+	// and re-indexes after any of them reloads (sources reload in order).
+	s.Search = NewSearchService(s.Challenges, s.Packages, s.Releases)
+	s.ContentWatcher.Watch("search", []string{"challenge-*", "packages", "releases"}, s.Search.Rebuild)
+
+	// Recommendations read the content and the user's history on request
+	s.Recommendations = NewRecommendationService(s.Challenges, s.Packages,
+		s.Releases, s.Achievements, s.Attempts)
+
+	// Curricula sequence challenges from every track, so they load after the
+	// tracks and re-check their entries when any track reloads
+	s.Curricula = NewCurriculumService(cfg, s.Challenges, s.Packages,
+		s.Releases, s.Achievements, s.Attempts)
+	if err := s.Curricula.Load(); err != nil {
+		log.Printf("curricula: %v", err)
+	}
+	s.ContentWatcher.Watch("curricula", []string{"curricula", "challenge-*", "packages", "releases"}, s.Curricula.Load)
+
+	// Team dashboards. The teams file is watched like content when it lives in
+	// the repository (the default data directory does); the admin API edits it
+	// wherever it is.
+	s.Teams = NewTeamService(cfg.Server.TeamsFile, s.Profiles, s.Challenges,
+		s.Releases, s.Leaderboards, s.Attempts)
+	if err := s.Teams.Load(); err != nil {
+		log.Printf("teams: %v", err)
+	}
+	if rel, err := filepath.Rel(cfg.Content.Root, cfg.Server.TeamsFile); err == nil && !strings.HasPrefix(rel, "..") {
+		s.ContentWatcher.Watch("teams", []string{filepath.ToSlash(rel)}, s.Teams.Load)
+	}
+
+	// Assignments go to teams and their members, so they load after teams
+	s.Assignments = NewAssignmentService(filepath.Join(cfg.Server.DataDir, "assignments.json"),
+		s.Teams, s.Curricula, s.Attempts)
+	if err := s.Assignments.Load(); err != nil {
+		log.Printf("assignments: %v", err)
+	}
+
+	// Mock interviews are timed on the server; sessions still running when it
+	// restarts resume, or end if their time ran out meanwhile
+	if s.Interviews, err = NewInterviewService(cfg.Server.DataDir, s.Challenges, s.Teams); err != nil {
+		return nil, fmt.Errorf("interviews: %v", err)
+	}
+	if err := s.Interviews.Load(); err != nil {
+		log.Printf("interviews: %v", err)
+	}
+	// Interview loops are drawn from any track and kept so they can be shared
+	s.InterviewLoops = NewInterviewLoopService(filepath.Join(cfg.Server.DataDir, "interview_loops.json"),
+		s.Challenges, s.Packages, s.Releases, s.Achievements, s.Profiles)
+	if err := s.InterviewLoops.Load(); err != nil {
+		log.Printf("interview loops: %v", err)
+	}
+	// Scoring rubrics are content, and re-check their challenges when those reload
+	s.Rubrics = NewRubricService(cfg, s.Challenges)
+	if err := s.Rubrics.Load(); err != nil {
+		log.Printf("rubrics: %v", err)
+	}
+	s.ContentWatcher.Watch("rubrics", []string{"rubrics", "challenge-*"}, s.Rubrics.Load)
+
+	// Pairing rooms are live only and kept in memory
+	s.Pairing = NewPairingService(s.Challenges, s.Execution)
+
+	return s, nil
+}
@@ -0,0 +1,31 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"web-ui/internal/config"
+)
+
+func TestNewServices(t *testing.T) {
+	cfg := config.Default()
+	cfg.Content.Root = t.TempDir()
+	cfg.Server.DataDir = t.TempDir()
+	cfg.Server.TeamsFile = filepath.Join(cfg.Server.DataDir, "teams.json")
+	cfg.Server.WebhooksFile = filepath.Join(cfg.Server.DataDir, "webhooks.json")
+	svc, err := NewServices(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if svc.Profiles == nil || svc.Pairing == nil || svc.ContentWatcher == nil {
+		t.Errorf("services left unset: %+v", svc)
+	}
+
+	// A data directory that cannot be used is reported, not fatal
+	cfg.Server.DataDir = filepath.Join(t.TempDir(), "data")
+	os.WriteFile(cfg.Server.DataDir, nil, 0644)
+	if _, err := NewServices(cfg); err == nil {
+		t.Error("services were built on a data directory that is a file")
+	}
+}
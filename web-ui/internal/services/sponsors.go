@@ -0,0 +1,137 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sponsorCache caches sponsor data to avoid hitting GitHub on every leaderboard render
+var sponsorCache = struct {
+	sponsors    map[string]bool
+	lastUpdated time.Time // when sponsors was fetched; moved back after a failed fetch to retry sooner
+	refreshing  bool
+	mutex       sync.Mutex
+}{
+	sponsors: make(map[string]bool),
+}
+
+const (
+	// sponsorCacheTTL is how long fetched sponsors are used, even if there are none
+	sponsorCacheTTL = time.Hour
+	// sponsorRetryAfter is how long a failed fetch waits to be retried
+	sponsorRetryAfter = 5 * time.Minute
+)
+
+// sponsorsURL is the public sponsors page the sponsors are scraped from
+var sponsorsURL = "https://github.com/sponsors/RezaSi"
+
+// LoadSponsors returns the maintainer's GitHub sponsors, scraped from the public
+// sponsors page and cached for an hour. Only one caller refreshes the cache;
+// the others get what is cached meanwhile. A failed refresh keeps the sponsors
+// last fetched and is retried after a few minutes.
+func LoadSponsors() map[string]bool {
+	sponsorCache.mutex.Lock()
+	if sponsorCache.refreshing || time.Since(sponsorCache.lastUpdated) < sponsorCacheTTL {
+		defer sponsorCache.mutex.Unlock()
+		return sponsorCache.sponsors
+	}
+	sponsorCache.refreshing = true
+	sponsorCache.mutex.Unlock()
+
+	sponsors, err := scrapeSponsorsFromGitHub()
+
+	sponsorCache.mutex.Lock()
+	defer sponsorCache.mutex.Unlock()
+	sponsorCache.refreshing = false
+	if err != nil {
+		log.Printf("sponsors: %v; keeping the %d last fetched", err, len(sponsorCache.sponsors))
+		sponsorCache.lastUpdated = time.Now().Add(sponsorRetryAfter - sponsorCacheTTL)
+		return sponsorCache.sponsors
+	}
+	sponsorCache.sponsors = sponsors
+	sponsorCache.lastUpdated = time.Now()
+	return sponsors
+}
+
+// ResetSponsors clears the sponsor cache so the next LoadSponsors refetches
+func ResetSponsors() {
+	sponsorCache.mutex.Lock()
+	sponsorCache.sponsors = make(map[string]bool)
+	sponsorCache.lastUpdated = time.Time{} // Reset to zero time to force refresh
+	sponsorCache.mutex.Unlock()
+}
+
+// scrapeSponsorsFromGitHub scrapes the public GitHub sponsors page
+func scrapeSponsorsFromGitHub() (map[string]bool, error) {
+	sponsorMap := make(map[string]bool)
+
+	// Create HTTP client with timeout
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	req, err := http.NewRequest("GET", sponsorsURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %v", err)
+	}
+
+	// Set user agent to avoid being blocked
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; GoSponsorScraper/1.0)")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching sponsors page: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("GitHub sponsors page returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading sponsors page: %v", err)
+	}
+
+	html := string(body)
+
+	// Extract usernames from the HTML using regex - look for avatar images with alt="@username"
+	avatarRegex := regexp.MustCompile(`alt="@([a-zA-Z0-9][a-zA-Z0-9\-]*)"`)
+	matches := avatarRegex.FindAllStringSubmatch(html, -1)
+
+	for _, match := range matches {
+		if len(match) >= 2 {
+			username := match[1]
+			// Filter out the repository owner from sponsors list
+			if username != "RezaSi" {
+				sponsorMap[username] = true
+			}
+		}
+	}
+
+	// Fallback: if no sponsors found with avatar method, try href patterns
+	if len(sponsorMap) == 0 {
+		// Look for href="/username" patterns that aren't common GitHub paths
+		linkRegex := regexp.MustCompile(`href="/([a-zA-Z0-9][a-zA-Z0-9\-]+)"`)
+		linkMatches := linkRegex.FindAllStringSubmatch(html, -1)
+
+		for _, match := range linkMatches {
+			if len(match) >= 2 {
+				username := match[1]
+				// Filter out common GitHub paths that aren't usernames
+				if username != "sponsors" && username != "github" && username != "RezaSi" &&
+					!strings.HasPrefix(username, "orgs/") &&
+					!strings.Contains(username, "/") &&
+					len(username) > 2 { // reasonable username length
+					sponsorMap[username] = true
+				}
+			}
+		}
+	}
+
+	return sponsorMap, nil
+}
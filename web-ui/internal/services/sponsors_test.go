@@ -0,0 +1,61 @@
+package services
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLoadSponsorsCachesEmptyAndKeepsLastGood(t *testing.T) {
+	var fetches atomic.Int32
+	var page atomic.Value
+	page.Store(`<img alt="@alice"><img alt="@RezaSi">`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		body := page.Load().(string)
+		if body == "" {
+			http.Error(w, "down", http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+	defer func(url string) { sponsorsURL = url }(sponsorsURL)
+	sponsorsURL = server.URL
+	ResetSponsors()
+	defer ResetSponsors()
+
+	if sponsors := LoadSponsors(); !sponsors["alice"] || sponsors["RezaSi"] || len(sponsors) != 1 {
+		t.Fatalf("sponsors = %v", sponsors)
+	}
+
+	// GitHub failing keeps the sponsors last fetched, and is not asked again
+	// on every render
+	expire := func(age time.Duration) {
+		sponsorCache.mutex.Lock()
+		sponsorCache.lastUpdated = time.Now().Add(-age)
+		sponsorCache.mutex.Unlock()
+	}
+	page.Store("")
+	expire(sponsorCacheTTL)
+	if sponsors := LoadSponsors(); !sponsors["alice"] {
+		t.Errorf("a failed fetch dropped the sponsors: %v", sponsors)
+	}
+	LoadSponsors()
+	if n := fetches.Load(); n != 2 {
+		t.Errorf("fetched %d times, want 2", n)
+	}
+
+	// Nobody sponsoring is cached like anything else
+	page.Store("<p>No sponsors yet</p>")
+	expire(sponsorCacheTTL)
+	if sponsors := LoadSponsors(); len(sponsors) != 0 {
+		t.Errorf("sponsors = %v, want none", sponsors)
+	}
+	LoadSponsors()
+	if n := fetches.Load(); n != 3 {
+		t.Errorf("fetched %d times, want 3", n)
+	}
+}
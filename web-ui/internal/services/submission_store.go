@@ -0,0 +1,178 @@
+package services
+
+import (
+	"log"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"web-ui/internal/models"
+)
+
+// SubmissionQuery selects a page of stored submissions. Zero values match
+// everything, so an empty query lists the most recent submissions.
+type SubmissionQuery struct {
+	Username  string
+	Challenge string // classic ID ("12"), package challenge ID or release challenge slug
+	Since     time.Time
+	Until     time.Time
+	Limit     int
+	Offset    int
+	Visible   func(username string) bool // whose submissions may be listed; nil lists everyone's
+}
+
+// Submission page sizes: a query without a limit gets the default, and no
+// page is bigger than the maximum
+const (
+	DefaultSubmissionPageSize = 50
+	MaxSubmissionPageSize     = 200
+)
+
+// SubmissionStore persists every submission the server sees, across all three
+// tracks. Implementations must be safe for concurrent handlers. Listings are
+// returned newest first together with the total number of matches, so callers
+// can paginate.
+type SubmissionStore interface {
+	AddSubmission(submission models.Submission) error
+	AddPackageSubmission(submission models.PackageSubmission) error
+	AddReleaseSubmission(submission models.ReleaseSubmission) error
+
+	Submissions(q SubmissionQuery) ([]models.Submission, int)
+	PackageSubmissions(q SubmissionQuery) ([]models.PackageSubmission, int)
+	ReleaseSubmissions(q SubmissionQuery) ([]models.ReleaseSubmission, int)
+}
+
+// FileSubmissionStore is the embedded SubmissionStore: one JSON-lines file per
+// track under the data directory, so the server needs no database to run.
+// Only who submitted what and when is kept in memory; the submissions
+// themselves, with their code and test output, are read from disk a page at
+// a time.
+type FileSubmissionStore struct {
+	classic  *jsonlIndex[models.Submission, submissionKey]
+	packages *jsonlIndex[models.PackageSubmission, submissionKey]
+	releases *jsonlIndex[models.ReleaseSubmission, submissionKey]
+}
+
+// submissionKey is what queries select submissions by
+type submissionKey struct {
+	username    string
+	challenge   string
+	submittedAt time.Time
+}
+
+// NewFileSubmissionStore opens (or creates) the submission logs in dataDir.
+func NewFileSubmissionStore(dataDir string) (*FileSubmissionStore, error) {
+	classic, err := openJSONLIndex(filepath.Join(dataDir, "submissions.jsonl"), func(sub models.Submission) submissionKey {
+		return submissionKey{sub.Username, strconv.Itoa(sub.ChallengeID), sub.SubmittedAt}
+	})
+	if err != nil {
+		return nil, err
+	}
+	packages, err := openJSONLIndex(filepath.Join(dataDir, "package_submissions.jsonl"), func(sub models.PackageSubmission) submissionKey {
+		return submissionKey{sub.Username, sub.ChallengeID, sub.SubmittedAt}
+	})
+	if err != nil {
+		return nil, err
+	}
+	releases, err := openJSONLIndex(filepath.Join(dataDir, "release_submissions.jsonl"), func(sub models.ReleaseSubmission) submissionKey {
+		return submissionKey{sub.Username, sub.Challenge, sub.SubmittedAt}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileSubmissionStore{classic: classic, packages: packages, releases: releases}, nil
+}
+
+// AddSubmission stores a classic challenge submission
+func (s *FileSubmissionStore) AddSubmission(submission models.Submission) error {
+	return s.classic.Append(submission)
+}
+
+// AddPackageSubmission stores a package challenge submission
+func (s *FileSubmissionStore) AddPackageSubmission(submission models.PackageSubmission) error {
+	return s.packages.Append(submission)
+}
+
+// AddReleaseSubmission stores the result of a release challenge run
+func (s *FileSubmissionStore) AddReleaseSubmission(submission models.ReleaseSubmission) error {
+	return s.releases.Append(submission)
+}
+
+// Submissions lists classic challenge submissions matching q
+func (s *FileSubmissionStore) Submissions(q SubmissionQuery) ([]models.Submission, int) {
+	return querySubmissions(s.classic, q)
+}
+
+// PackageSubmissions lists package challenge submissions matching q
+func (s *FileSubmissionStore) PackageSubmissions(q SubmissionQuery) ([]models.PackageSubmission, int) {
+	return querySubmissions(s.packages, q)
+}
+
+// ReleaseSubmissions lists release challenge runs matching q
+func (s *FileSubmissionStore) ReleaseSubmissions(q SubmissionQuery) ([]models.ReleaseSubmission, int) {
+	return querySubmissions(s.releases, q)
+}
+
+// querySubmissions selects the submissions matching q, newest submitted
+// first, and reads the requested page of them from disk. Submissions are
+// appended as they finish, which is not always the order they were
+// submitted in, so the order comes from the submission times.
+func querySubmissions[T any](ix *jsonlIndex[T, submissionKey], q SubmissionQuery) ([]T, int) {
+	matches := ix.Filter(func(key submissionKey) bool {
+		return q.matches(key.username, key.challenge, key.submittedAt)
+	})
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].key.submittedAt.After(matches[j].key.submittedAt)
+	})
+
+	page, total := paginate(matches, q)
+	records, err := ix.Read(page)
+	if err != nil {
+		log.Printf("submissions: %v", err)
+	}
+	return records, total
+}
+
+// matches reports whether a submission with the given fields is selected by q
+func (q SubmissionQuery) matches(username, challenge string, at time.Time) bool {
+	if q.Username != "" && q.Username != username {
+		return false
+	}
+	if q.Visible != nil && !q.Visible(username) {
+		return false
+	}
+	if q.Challenge != "" && q.Challenge != challenge {
+		return false
+	}
+	if !q.Since.IsZero() && at.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && !at.Before(q.Until) {
+		return false
+	}
+	return true
+}
+
+// paginate cuts the requested page out of matches and returns it with the total
+func paginate[T any](matches []T, q SubmissionQuery) ([]T, int) {
+	total := len(matches)
+
+	limit := q.Limit
+	if limit <= 0 {
+		limit = DefaultSubmissionPageSize
+	}
+	if limit > MaxSubmissionPageSize {
+		limit = MaxSubmissionPageSize
+	}
+
+	if q.Offset >= total {
+		return []T{}, total
+	}
+	end := q.Offset + limit
+	if end > total {
+		end = total
+	}
+	return matches[q.Offset:end], total
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"web-ui/internal/models"
+)
+
+func TestFileSubmissionStorePersistsAndPaginates(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileSubmissionStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			user := "alice"
+			if i%2 == 1 {
+				user = "bob"
+			}
+			if err := store.AddSubmission(models.Submission{
+				Username:    user,
+				ChallengeID: 1 + i%3,
+				SubmittedAt: base.Add(time.Duration(i) * time.Hour),
+			}); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// Reopen to make sure everything made it to disk.
+	store, err = NewFileSubmissionStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	all, total := store.Submissions(SubmissionQuery{Limit: 20})
+	if total != 20 || len(all) != 20 {
+		t.Fatalf("got %d/%d submissions after reopen, want 20", len(all), total)
+	}
+	for i, s := range all {
+		if want := base.Add(time.Duration(19-i) * time.Hour); !s.SubmittedAt.Equal(want) {
+			t.Fatalf("submission %d was submitted at %v, want %v: not newest first", i, s.SubmittedAt, want)
+		}
+	}
+
+	page, total := store.Submissions(SubmissionQuery{Username: "alice", Limit: 4, Offset: 8})
+	if total != 10 || len(page) != 2 {
+		t.Fatalf("alice page: got %d of %d, want 2 of 10", len(page), total)
+	}
+
+	window, _ := store.Submissions(SubmissionQuery{Since: base.Add(5 * time.Hour), Until: base.Add(10 * time.Hour)})
+	if len(window) != 5 {
+		t.Fatalf("time window: got %d, want 5", len(window))
+	}
+	for i := 1; i < len(window); i++ {
+		if window[i].SubmittedAt.After(window[i-1].SubmittedAt) {
+			t.Fatalf("submissions not newest first: %v", window)
+		}
+	}
+
+	// Hidden users are left out before paging, so the total stays right
+	public, total := store.Submissions(SubmissionQuery{Limit: 4, Visible: func(username string) bool { return username != "bob" }})
+	if total != 10 || len(public) != 4 || public[0].Username != "alice" {
+		t.Fatalf("visible page: got %d of %d, want 4 of alice's 10", len(public), total)
+	}
+
+	byChallenge, _ := store.Submissions(SubmissionQuery{Challenge: "2"})
+	for _, s := range byChallenge {
+		if s.ChallengeID != 2 {
+			t.Fatalf("challenge filter leaked challenge %d", s.ChallengeID)
+		}
+	}
+}
+
+func TestFileSubmissionStoreCutsTornLine(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "submissions.jsonl")
+	os.WriteFile(path, []byte(`{"username":"alice","challengeId":1,"submittedAt":"2026-01-01T00:00:00Z"}`+"\n"+`{"username":"bob","chall`), 0644)
+
+	store, err := NewFileSubmissionStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.AddSubmission(models.Submission{Username: "carol", ChallengeID: 2, SubmittedAt: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The append after the torn line survives a restart
+	store, err = NewFileSubmissionStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	all, total := store.Submissions(SubmissionQuery{})
+	if total != 2 || all[0].Username != "carol" || all[1].Username != "alice" {
+		t.Fatalf("after a torn line: %+v", all)
+	}
+}
+
+func TestFileSubmissionStoreKeepsLastLineWithoutNewline(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "submissions.jsonl")
+	os.WriteFile(path, []byte(`{"username":"alice","challengeId":1,"submittedAt":"2026-01-01T00:00:00Z"}`), 0644)
+
+	store, err := NewFileSubmissionStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.AddSubmission(models.Submission{Username: "carol", ChallengeID: 2, SubmittedAt: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)}); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err = NewFileSubmissionStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	all, total := store.Submissions(SubmissionQuery{})
+	if total != 2 || all[0].Username != "carol" || all[1].Username != "alice" {
+		t.Fatalf("after a last line without a newline: %+v", all)
+	}
+}
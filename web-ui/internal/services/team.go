@@ -0,0 +1,466 @@
+package services
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"web-ui/internal/models"
+)
+
+// stuckAfterFailures is how many failed attempts in a row, without a pass,
+// flag a member as stuck on a challenge
+const stuckAfterFailures = 3
+
+// teamNamePattern is what team names, which appear in URLs, may look like
+var teamNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+// teamsFile is the layout of the teams file
+type teamsFile struct {
+	Teams []models.Team `json:"teams"`
+}
+
+// TeamService keeps the team definitions and builds team dashboards from the
+// members' profiles and attempt histories.
+//
+// Teams live in one JSON file, which may be edited by hand (the content
+// watcher reloads it) or through the admin API (which rewrites it).
+type TeamService struct {
+	path             string
+	profiles         *ProfileService
+	challengeService *ChallengeService
+	releaseService   *ReleaseService
+	leaderboards     *LeaderboardService
+	attempts         AttemptStore
+
+	mutex sync.RWMutex
+	teams map[string]models.Team
+}
+
+// NewTeamService creates a team service for the teams file at path. Call
+// Load before use.
+func NewTeamService(
+	path string,
+	profiles *ProfileService,
+	challengeService *ChallengeService,
+	releaseService *ReleaseService,
+	leaderboards *LeaderboardService,
+	attempts AttemptStore,
+) *TeamService {
+	return &TeamService{
+		path:             path,
+		profiles:         profiles,
+		challengeService: challengeService,
+		releaseService:   releaseService,
+		leaderboards:     leaderboards,
+		attempts:         attempts,
+		teams:            make(map[string]models.Team),
+	}
+}
+
+// Load reads the teams file. A missing file means there are no teams. The
+// current teams are kept if the file cannot be read.
+func (ts *TeamService) Load() error {
+	raw, err := os.ReadFile(ts.path)
+	if os.IsNotExist(err) {
+		raw, err = []byte(`{"teams": []}`), nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var file teamsFile
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return fmt.Errorf("%s: %v", ts.path, err)
+	}
+	teams := make(map[string]models.Team)
+	for _, team := range file.Teams {
+		team, err := normalizeTeam(team)
+		if err != nil {
+			return fmt.Errorf("%s: %v", ts.path, err)
+		}
+		if _, dup := teams[team.Name]; dup {
+			return fmt.Errorf("%s: team %q is defined twice", ts.path, team.Name)
+		}
+		teams[team.Name] = team
+	}
+
+	ts.mutex.Lock()
+	ts.teams = teams
+	ts.mutex.Unlock()
+	return nil
+}
+
+// ValidTeamName reports whether name may name a team
+func ValidTeamName(name string) bool {
+	return teamNamePattern.MatchString(name)
+}
+
+// normalizeTeam checks a team's name and tidies its member lists
+func normalizeTeam(team models.Team) (models.Team, error) {
+	team.Name = strings.ToLower(strings.TrimSpace(team.Name))
+	if !ValidTeamName(team.Name) {
+		return team, fmt.Errorf("team name %q must be lowercase letters, digits and dashes", team.Name)
+	}
+	team.DisplayName = strings.TrimSpace(team.DisplayName)
+	team.Members = uniqueNames(team.Members)
+	team.Leads = uniqueNames(team.Leads)
+	return team, nil
+}
+
+// uniqueNames trims names and drops empty and repeated ones, keeping order
+func uniqueNames(names []string) []string {
+	seen := make(map[string]bool)
+	unique := []string{}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name != "" && !seen[strings.ToLower(name)] {
+			seen[strings.ToLower(name)] = true
+			unique = append(unique, name)
+		}
+	}
+	return unique
+}
+
+// Teams returns every team, by name
+func (ts *TeamService) Teams() []models.Team {
+	ts.mutex.RLock()
+	defer ts.mutex.RUnlock()
+
+	teams := make([]models.Team, 0, len(ts.teams))
+	for _, team := range ts.teams {
+		teams = append(teams, team)
+	}
+	sort.Slice(teams, func(i, j int) bool { return teams[i].Name < teams[j].Name })
+	return teams
+}
+
+// Team looks up a team by name
+func (ts *TeamService) Team(name string) (models.Team, bool) {
+	ts.mutex.RLock()
+	defer ts.mutex.RUnlock()
+	team, ok := ts.teams[name]
+	return team, ok
+}
+
+// CanView reports whether username may see the team's dashboard: its members
+// and leads may
+func (ts *TeamService) CanView(team models.Team, username string) bool {
+	if username == "" {
+		return false
+	}
+	for _, name := range append(append([]string{}, team.Members...), team.Leads...) {
+		if strings.EqualFold(name, username) {
+			return true
+		}
+	}
+	return false
+}
+
+// TeamsFor returns the teams whose dashboards username may see
+func (ts *TeamService) TeamsFor(username string) []models.Team {
+	teams := []models.Team{}
+	for _, team := range ts.Teams() {
+		if ts.CanView(team, username) {
+			teams = append(teams, team)
+		}
+	}
+	return teams
+}
+
+// Put creates or replaces a team and saves the teams file
+func (ts *TeamService) Put(team models.Team) (models.Team, error) {
+	team, err := normalizeTeam(team)
+	if err != nil {
+		return team, err
+	}
+
+	ts.mutex.Lock()
+	defer ts.mutex.Unlock()
+	previous, existed := ts.teams[team.Name]
+	ts.teams[team.Name] = team
+	if err := ts.save(); err != nil {
+		if existed {
+			ts.teams[team.Name] = previous
+		} else {
+			delete(ts.teams, team.Name)
+		}
+		return team, err
+	}
+	return team, nil
+}
+
+// Delete removes a team and saves the teams file. It reports false if there
+// was no such team.
+func (ts *TeamService) Delete(name string) (bool, error) {
+	ts.mutex.Lock()
+	defer ts.mutex.Unlock()
+	team, ok := ts.teams[name]
+	if !ok {
+		return false, nil
+	}
+	delete(ts.teams, name)
+	if err := ts.save(); err != nil {
+		ts.teams[name] = team
+		return true, err
+	}
+	return true, nil
+}
+
+// save writes the teams file; the caller holds the write lock
+func (ts *TeamService) save() error {
+	file := teamsFile{Teams: make([]models.Team, 0, len(ts.teams))}
+	for _, team := range ts.teams {
+		file.Teams = append(file.Teams, team)
+	}
+	sort.Slice(file.Teams, func(i, j int) bool { return file.Teams[i].Name < file.Teams[j].Name })
+
+	raw, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(ts.path), 0755); err != nil {
+		return fmt.Errorf("failed to create teams directory: %v", err)
+	}
+	tmp := ts.path + ".tmp"
+	if err := os.WriteFile(tmp, append(raw, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %v", ts.path, err)
+	}
+	return os.Rename(tmp, ts.path)
+}
+
+// Dashboard builds the team's dashboard as viewer sees it. Leads see every
+// member's whole profile; other members see private profiles only as the
+// public leaderboards show them.
+func (ts *TeamService) Dashboard(team models.Team, viewer string) *models.TeamDashboard {
+	lead := containsName(team.Leads, viewer)
+	dashboard := &models.TeamDashboard{
+		Team:        team,
+		Members:     []models.TeamMemberProgress{},
+		Heatmap:     []models.TeamHeatmapRow{},
+		Stuck:       []models.StuckMember{},
+		GeneratedAt: time.Now(),
+	}
+
+	// Classic challenges are always listed; package and release challenges
+	// only once a member has worked on them.
+	rows := make(map[string]*models.TeamHeatmapRow)
+	var order []string
+	row := func(track, id string) *models.TeamHeatmapRow {
+		key := track + ":" + id
+		if r, ok := rows[key]; ok {
+			return r
+		}
+		r := &models.TeamHeatmapRow{
+			Track:       track,
+			ChallengeID: id,
+			Title:       ts.challengeTitle(track, id),
+			URL:         challengeURL(track, id),
+			Cells:       make([]models.TeamHeatmapCell, len(team.Members)),
+		}
+		rows[key] = r
+		order = append(order, key)
+		return r
+	}
+	for _, c := range ts.challengeService.ListChallenges() {
+		row(models.TrackClassic, strconv.Itoa(c.ID))
+	}
+	classic := len(order)
+
+	for i, username := range team.Members {
+		// Members agreed to be followed by their leads by joining, but not by
+		// each other
+		as := viewer
+		if lead {
+			as = username
+		}
+		profile, _ := ts.profiles.Profile(username, as)
+		member := models.TeamMemberProgress{
+			Username:      username,
+			Limited:       profile.Limited,
+			Rank:          profile.Rank,
+			ClassicSolved: profile.Classic.Solved,
+			ClassicTotal:  profile.Classic.Total,
+		}
+
+		for _, c := range profile.Classic.Challenges {
+			cell := &row(models.TrackClassic, strconv.Itoa(c.ID)).Cells[i]
+			cell.Status = models.CellAttempted
+			if c.Completed {
+				cell.Status = models.CellSolved
+			}
+		}
+		for _, pkg := range profile.Packages {
+			member.PackagesSolved += pkg.Solved
+			for _, id := range pkg.Completed {
+				row(models.TrackPackage, pkg.Name+"/"+id).Cells[i].Status = models.CellSolved
+			}
+		}
+		for _, release := range profile.Releases {
+			member.ReleasesAttempted++
+			cell := &row(models.TrackRelease, release.Release+"/"+release.Feature+"/"+release.Challenge).Cells[i]
+			cell.Status = models.CellAttempted
+			if release.Passed {
+				member.ReleasesPassed++
+				cell.Status = models.CellSolved
+			}
+		}
+
+		if profile.Limited {
+			dashboard.Members = append(dashboard.Members, member)
+			continue
+		}
+
+		// Failures since the last pass, per challenge
+		attempts := ts.attempts.UserAttempts(username)
+		member.Attempts = len(attempts)
+		lastFailure := make(map[*models.TeamHeatmapRow]time.Time)
+		for _, a := range attempts {
+			r := row(a.Track, a.ChallengeID)
+			cell := &r.Cells[i]
+			if cell.Status == "" {
+				cell.Status = models.CellAttempted
+			}
+			if a.Passed {
+				cell.Failures = 0
+			} else {
+				cell.Failures++
+				lastFailure[r] = a.CreatedAt
+			}
+			member.LastActiveAt = a.CreatedAt
+		}
+		for r, at := range lastFailure {
+			cell := &r.Cells[i]
+			cell.Stuck = cell.Status != models.CellSolved && cell.Failures >= stuckAfterFailures
+			if cell.Stuck {
+				dashboard.Stuck = append(dashboard.Stuck, models.StuckMember{
+					Username:      username,
+					Track:         r.Track,
+					ChallengeID:   r.ChallengeID,
+					Title:         r.Title,
+					URL:           r.URL,
+					Failures:      cell.Failures,
+					LastAttemptAt: at,
+				})
+			}
+		}
+
+		dashboard.Members = append(dashboard.Members, member)
+	}
+
+	// Classic rows come first in challenge order, then the rest by track and ID
+	sort.Slice(order[classic:], func(i, j int) bool {
+		a, b := rows[order[classic+i]], rows[order[classic+j]]
+		if a.Track != b.Track {
+			return a.Track < b.Track
+		}
+		return a.ChallengeID < b.ChallengeID
+	})
+	for _, key := range order {
+		r := rows[key]
+		for _, cell := range r.Cells {
+			if cell.Status == models.CellSolved {
+				r.Solved++
+			}
+		}
+		dashboard.Heatmap = append(dashboard.Heatmap, *r)
+	}
+
+	sort.Slice(dashboard.Stuck, func(i, j int) bool {
+		a, b := dashboard.Stuck[i], dashboard.Stuck[j]
+		if a.Failures != b.Failures {
+			return a.Failures > b.Failures
+		}
+		return a.LastAttemptAt.After(b.LastAttemptAt)
+	})
+	return dashboard
+}
+
+// challengeTitle names a challenge identified as in models.Attempt, falling
+// back to its ID
+func (ts *TeamService) challengeTitle(track, id string) string {
+	switch track {
+	case models.TrackClassic:
+		if n, err := strconv.Atoi(id); err == nil {
+			if c, ok := ts.challengeService.GetChallenge(n); ok {
+				return c.Title
+			}
+		}
+	case models.TrackPackage:
+		if pkg, slug, ok := strings.Cut(id, "/"); ok {
+			challenges, _ := ts.leaderboards.PackageChallenges(pkg)
+			for _, c := range challenges {
+				if c.ID == slug {
+					return c.Title
+				}
+			}
+		}
+	case models.TrackRelease:
+		if parts := strings.SplitN(id, "/", 3); len(parts) == 3 {
+			if c := ts.releaseService.GetChallenge(parts[0], parts[1], parts[2]); c != nil {
+				return c.Title
+			}
+		}
+	}
+	return id
+}
+
+// WriteMembersCSV writes one row per member with their progress on each track.
+// Like every export, text cells are kept from running as formulas.
+func WriteMembersCSV(w io.Writer, dashboard *models.TeamDashboard) error {
+	out := csv.NewWriter(w)
+	out.Write([]string{"username", "rank", "classic_solved", "classic_total", "package_solved",
+		"release_passed", "release_attempted", "attempts", "last_active", "stuck_on"})
+
+	stuck := make(map[string]int)
+	for _, s := range dashboard.Stuck {
+		stuck[s.Username]++
+	}
+	for _, m := range dashboard.Members {
+		lastActive := ""
+		if !m.LastActiveAt.IsZero() {
+			lastActive = m.LastActiveAt.UTC().Format(time.RFC3339)
+		}
+		out.Write([]string{
+			spreadsheetText(m.Username),
+			strconv.Itoa(m.Rank),
+			strconv.Itoa(m.ClassicSolved),
+			strconv.Itoa(m.ClassicTotal),
+			strconv.Itoa(m.PackagesSolved),
+			strconv.Itoa(m.ReleasesPassed),
+			strconv.Itoa(m.ReleasesAttempted),
+			strconv.Itoa(m.Attempts),
+			lastActive,
+			strconv.Itoa(stuck[m.Username]),
+		})
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// WriteChallengesCSV writes the heatmap in long form: one row per member and
+// challenge they have worked on
+func WriteChallengesCSV(w io.Writer, dashboard *models.TeamDashboard) error {
+	out := csv.NewWriter(w)
+	out.Write([]string{"username", "track", "challenge", "title", "status", "failures_since_pass"})
+	for _, r := range dashboard.Heatmap {
+		for i, cell := range r.Cells {
+			if cell.Status == "" {
+				continue
+			}
+			out.Write([]string{spreadsheetText(dashboard.Members[i].Username), spreadsheetText(r.Track),
+				spreadsheetText(r.ChallengeID), spreadsheetText(r.Title), spreadsheetText(cell.Status), strconv.Itoa(cell.Failures)})
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"web-ui/internal/config"
+	"web-ui/internal/models"
+	"web-ui/internal/scoreboard"
+)
+
+func TestTeamDashboardAndTeamsFile(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"challenge-1", "challenge-2"} {
+		os.MkdirAll(filepath.Join(root, dir), 0755)
+		os.WriteFile(filepath.Join(root, dir, "README.md"), []byte("# Challenge\n"), 0644)
+		os.WriteFile(filepath.Join(root, dir, "solution-template.go"), []byte("package main\n"), 0644)
+	}
+	scoreboard.Save(filepath.Join(root, "challenge-1"), &scoreboard.Board{Entries: []scoreboard.Entry{{Username: "alice", Passed: 3, Total: 3}}})
+
+	cfg := config.Default()
+	cfg.Content.Root = root
+	cfg.Server.DataDir = t.TempDir()
+	challenges := NewChallengeService(cfg)
+	if err := challenges.LoadChallenges(); err != nil {
+		t.Fatal(err)
+	}
+	packages := NewPackageService(cfg)
+	releases := NewReleaseService(cfg, nil)
+	leaderboards := NewLeaderboardService(cfg, challenges, packages, nil)
+	store, _ := NewFileSubmissionStore(cfg.Server.DataDir)
+	attempts, _ := NewFileAttemptStore(cfg.Server.DataDir)
+	activity, _ := NewActivityService(cfg, attempts)
+	achievements, _ := NewAchievementService(cfg, challenges, packages, releases, leaderboards, activity, store, attempts)
+	profiles, err := NewProfileService(cfg, challenges, packages, releases, NewUserService(cfg),
+		leaderboards, NewBadgeService(leaderboards, achievements), achievements, activity, store, attempts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Keep the test off the network
+	seedSponsors(map[string]bool{"nobody": true})
+	defer ResetSponsors()
+
+	path := filepath.Join(cfg.Server.DataDir, "teams.json")
+	os.WriteFile(path, []byte(`{"teams": [{"name": "Onboarding", "members": ["alice", " bob", "alice"], "leads": ["carol"]}]}`), 0644)
+	teams := NewTeamService(path, profiles, challenges, releases, leaderboards, attempts)
+	if err := teams.Load(); err != nil {
+		t.Fatal(err)
+	}
+	team, ok := teams.Team("onboarding")
+	if !ok || strings.Join(team.Members, ",") != "alice,bob" {
+		t.Fatalf("team = %+v, %v", team, ok)
+	}
+	if !teams.CanView(team, "carol") || teams.CanView(team, "dave") || len(teams.TeamsFor("bob")) != 1 {
+		t.Error("members and leads, and only they, should see the dashboard")
+	}
+
+	// bob keeps failing challenge 2; one pass would clear it
+	for i := 0; i < 3; i++ {
+		attempts.AddAttempt(NewAttempt("bob", models.TrackClassic, "2", "run", "package main", false, "", 10))
+	}
+	attempts.AddAttempt(NewAttempt("alice", models.TrackClassic, "2", "run", "package main", false, "", 10))
+	attempts.AddAttempt(NewAttempt("alice", models.TrackClassic, "2", "run", "package main", true, "", 10))
+
+	dashboard := teams.Dashboard(team, "carol")
+	if len(dashboard.Members) != 2 || dashboard.Members[0].ClassicSolved != 1 || dashboard.Members[1].Attempts != 3 {
+		t.Errorf("members = %+v", dashboard.Members)
+	}
+	if len(dashboard.Heatmap) != 2 || dashboard.Heatmap[0].Solved != 1 || dashboard.Heatmap[0].Cells[0].Status != models.CellSolved {
+		t.Errorf("heatmap = %+v", dashboard.Heatmap)
+	}
+	if cell := dashboard.Heatmap[1].Cells; cell[0].Stuck || cell[0].Status != models.CellAttempted || !cell[1].Stuck || cell[1].Failures != 3 {
+		t.Errorf("challenge 2 cells = %+v", cell)
+	}
+	if len(dashboard.Stuck) != 1 || dashboard.Stuck[0].Username != "bob" || dashboard.Stuck[0].URL != "/challenge/2" {
+		t.Errorf("stuck = %+v", dashboard.Stuck)
+	}
+
+	// A private profile is private to the rest of the team, but not its leads
+	profiles.SetPublic("bob", false)
+	if d := teams.Dashboard(team, "carol"); d.Members[1].Limited || d.Members[1].Attempts != 3 {
+		t.Errorf("lead sees bob as %+v", d.Members[1])
+	}
+	if d := teams.Dashboard(team, "bob"); d.Members[1].Limited || d.Members[1].Attempts != 3 {
+		t.Errorf("bob sees their own row as %+v", d.Members[1])
+	}
+	d := teams.Dashboard(team, "alice")
+	if bob := d.Members[1]; !bob.Limited || bob.Attempts != 0 || !bob.LastActiveAt.IsZero() || len(d.Stuck) != 0 {
+		t.Errorf("alice sees bob as %+v, stuck %+v", bob, d.Stuck)
+	}
+	if cell := d.Heatmap[1].Cells[1]; cell.Status != "" || cell.Failures != 0 {
+		t.Errorf("alice sees bob's challenge 2 as %+v", cell)
+	}
+	profiles.SetPublic("bob", true)
+
+	var out bytes.Buffer
+	WriteChallengesCSV(&out, dashboard)
+	if want := "bob,classic,2,Challenge,attempted,3\n"; !strings.Contains(out.String(), want) {
+		t.Errorf("challenges CSV lacks %q:\n%s", want, out.String())
+	}
+	out.Reset()
+	WriteChallengesCSV(&out, &models.TeamDashboard{
+		Members: []models.TeamMemberProgress{{Username: "bob"}},
+		Heatmap: []models.TeamHeatmapRow{{Track: models.TrackClassic, ChallengeID: "2", Title: "@SUM(A1)",
+			Cells: []models.TeamHeatmapCell{{Status: "attempted"}}}},
+	})
+	if want := "bob,classic,2,'@SUM(A1),attempted,0\n"; !strings.Contains(out.String(), want) {
+		t.Errorf("challenges CSV lets a title run as a formula:\n%s", out.String())
+	}
+
+	// The admin API's changes survive a reload
+	if _, err := teams.Put(models.Team{Name: "platform", Members: []string{"dave"}}); err != nil {
+		t.Fatal(err)
+	}
+	if found, err := teams.Delete("onboarding"); !found || err != nil {
+		t.Fatalf("delete = %v, %v", found, err)
+	}
+	reloaded := NewTeamService(path, profiles, challenges, releases, leaderboards, attempts)
+	if err := reloaded.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if all := reloaded.Teams(); len(all) != 1 || all[0].Name != "platform" {
+		t.Errorf("teams after reload = %+v", all)
+	}
+}
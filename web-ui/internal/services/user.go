@@ -1,26 +1,27 @@
 package services
 
 import (
-	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
-	"strconv"
-	"strings"
 	"sync"
 
+	"web-ui/internal/config"
 	"web-ui/internal/models"
+	"web-ui/internal/scoreboard"
 )
 
 // UserService handles user-related operations
 type UserService struct {
+	cfg          *config.Config
 	userAttempts models.UserAttemptsMap
 	mutex        sync.RWMutex
 }
 
 // NewUserService creates a new user service
-func NewUserService() *UserService {
+func NewUserService(cfg *config.Config) *UserService {
 	return &UserService{
+		cfg:          cfg,
 		userAttempts: make(models.UserAttemptsMap),
 	}
 }
@@ -61,24 +62,9 @@ func (us *UserService) LoadUserAttempts(username string, challenges models.Chall
 
 // hasUserSubmission checks if a user has a submission for a challenge
 func (us *UserService) hasUserSubmission(username string, challengeID int) bool {
-	// Try different path formats to handle potential path issues
-	// Absolute path
-	submissionDir := filepath.Join("..", fmt.Sprintf("challenge-%d", challengeID), "submissions", username)
-	submissionFile := filepath.Join(submissionDir, "solution-template.go")
-
-	// Check if the file exists
-	if _, err := os.Stat(submissionFile); err == nil {
-		return true
-	}
-
-	// Alternative path (direct from workspace root)
-	altSubmissionFile := filepath.Join(fmt.Sprintf("challenge-%d", challengeID), "submissions", username, "solution-template.go")
-
-	if _, err := os.Stat(altSubmissionFile); err == nil {
-		return true
-	}
-
-	return false
+	submissionFile := filepath.Join(us.cfg.ChallengeDir(challengeID), "submissions", username, "solution-template.go")
+	_, err := os.Stat(submissionFile)
+	return err == nil
 }
 
 // GetExistingSolution returns the content of an existing solution file if it exists
@@ -87,22 +73,12 @@ func (us *UserService) GetExistingSolution(username string, challengeID int) str
 		return ""
 	}
 
-	// Try different path formats
-	// First try the relative path from web-ui
-	submissionFile := filepath.Join("..", fmt.Sprintf("challenge-%d", challengeID), "submissions", username, "solution-template.go")
+	submissionFile := filepath.Join(us.cfg.ChallengeDir(challengeID), "submissions", username, "solution-template.go")
 	content, err := ioutil.ReadFile(submissionFile)
-	if err == nil {
-		return string(content)
-	}
-
-	// Try alternative path from root directory
-	altSubmissionFile := filepath.Join(fmt.Sprintf("challenge-%d", challengeID), "submissions", username, "solution-template.go")
-	content, err = ioutil.ReadFile(altSubmissionFile)
-	if err == nil {
-		return string(content)
+	if err != nil {
+		return ""
 	}
-
-	return ""
+	return string(content)
 }
 
 // RefreshUserAttempts clears the cache for a user and reloads their attempts
@@ -128,44 +104,17 @@ func (us *UserService) GetUserAttempts(username string, challenges models.Challe
 
 // calculateScore calculates the score for a user's submission for a challenge
 func (us *UserService) calculateScore(username string, challengeID int) int {
-	// Read the scoreboard file for this challenge
-	scoreboardPath := filepath.Join("..", fmt.Sprintf("challenge-%d", challengeID), "SCOREBOARD.md")
-	content, err := ioutil.ReadFile(scoreboardPath)
+	// Read the scoreboard for this challenge
+	board, err := scoreboard.Load(us.cfg.ChallengeDir(challengeID))
 	if err != nil {
-		// Try alternative path
-		scoreboardPath = filepath.Join(fmt.Sprintf("challenge-%d", challengeID), "SCOREBOARD.md")
-		content, err = ioutil.ReadFile(scoreboardPath)
-		if err != nil {
-			// No scoreboard file, return default score
-			return 50
-		}
+		// No scoreboard file, return default score
+		return 50
 	}
 
-	scoreboardContent := string(content)
-
-	// Parse the scoreboard to find this user's results
-	lines := strings.Split(scoreboardContent, "\n")
-	for _, line := range lines {
-		// Look for lines that contain the username
-		if strings.Contains(line, username) && strings.Contains(line, "|") {
-			// Parse the table row: | Username | Passed Tests | Total Tests |
-			parts := strings.Split(line, "|")
-			if len(parts) >= 4 {
-				passedStr := strings.TrimSpace(parts[2])
-				totalStr := strings.TrimSpace(parts[3])
-
-				passed, err1 := strconv.Atoi(passedStr)
-				total, err2 := strconv.Atoi(totalStr)
-
-				if err1 == nil && err2 == nil && total > 0 {
-					// Calculate percentage score
-					score := (passed * 100) / total
-					return score
-				}
-			}
-		}
+	entry, found := board.Find(username)
+	if !found {
+		// User not found in scoreboard, return 0
+		return 0
 	}
-
-	// User not found in scoreboard, return 0
-	return 0
+	return entry.Score()
 }
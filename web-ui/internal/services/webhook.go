@@ -0,0 +1,461 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"web-ui/internal/models"
+)
+
+// Webhook request headers. The signature is "sha256=" and the hex HMAC-SHA256
+// of the body, keyed with the webhook's secret, as GitHub signs its webhooks.
+const (
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// webhookBackoff is how long to wait before each retry of a failed delivery;
+// a delivery is given up after the last
+var webhookBackoff = []time.Duration{5 * time.Second, 30 * time.Second, 2 * time.Minute, 10 * time.Minute, 30 * time.Minute}
+
+// webhookTimeout bounds a single try, including reading the response
+const webhookTimeout = 10 * time.Second
+
+// webhooksFile is the on-disk layout of the webhooks file
+type webhooksFile struct {
+	Webhooks []models.Webhook `json:"webhooks"`
+}
+
+// WebhookService sends events to the outgoing webhooks defined in the
+// webhooks file.
+//
+// Events are delivered in the background so that a slow receiver never holds
+// up a test run. A delivery that fails with a network error, a 5xx, 408 or 429
+// is retried with backoff; any other answer is final. Every delivery, with
+// each of its tries, is appended to webhook_deliveries.jsonl in the data
+// directory. Deliveries waiting for a retry are recorded there as pending,
+// with the body to send, so that those cut short by a restart are resumed by
+// Load. Every run sends an event, so the log is only indexed in memory and
+// the deliveries asked for are read back from disk.
+type WebhookService struct {
+	path       string
+	client     *http.Client
+	deliveries *jsonlIndex[models.WebhookDelivery, deliveryKey]
+	backoff    []time.Duration
+
+	mutex   sync.RWMutex
+	hooks   map[string]models.Webhook
+	active  map[string]bool // IDs of the deliveries in progress
+	pending sync.WaitGroup
+}
+
+// deliveryKey is what delivery lookups select records by
+type deliveryKey struct {
+	id      string
+	webhook string
+	pending bool
+}
+
+func NewWebhookService(path, dataDir string) (*WebhookService, error) {
+	deliveries, err := openJSONLIndex(filepath.Join(dataDir, "webhook_deliveries.jsonl"), func(d models.WebhookDelivery) deliveryKey {
+		return deliveryKey{d.ID, d.Webhook, d.Pending}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &WebhookService{
+		path:       path,
+		client:     &http.Client{Timeout: webhookTimeout},
+		deliveries: deliveries,
+		backoff:    webhookBackoff,
+		hooks:      make(map[string]models.Webhook),
+		active:     make(map[string]bool),
+	}, nil
+}
+
+// Load reads the webhooks file. A missing file means there are no webhooks. The current webhooks are kept if
+// the file cannot be read.
+func (ws *WebhookService) Load() error {
+	raw, err := os.ReadFile(ws.path)
+	if os.IsNotExist(err) {
+		raw, err = []byte(`{"webhooks": []}`), nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var file webhooksFile
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return fmt.Errorf("%s: %v", ws.path, err)
+	}
+	hooks := make(map[string]models.Webhook)
+	for _, hook := range file.Webhooks {
+		hook, err := normalizeWebhook(hook)
+		if err != nil {
+			return fmt.Errorf("%s: %v", ws.path, err)
+		}
+		if _, dup := hooks[hook.Name]; dup {
+			return fmt.Errorf("%s: webhook %q is defined twice", ws.path, hook.Name)
+		}
+		hooks[hook.Name] = hook
+	}
+
+	ws.mutex.Lock()
+	ws.hooks = hooks
+	ws.mutex.Unlock()
+	return nil
+}
+
+// Resume restarts the deliveries that were still being tried when the server
+// stopped, where their backoff left off. Deliveries to a webhook that has
+// since been removed are given up. Only the server calls it: the command-line
+// tools load the same data and would send the deliveries a second time.
+func (ws *WebhookService) Resume() {
+	pending := ws.latestDeliveries(func(key deliveryKey) bool { return key.pending }, 0)
+	for _, delivery := range pending {
+		hook, ok := ws.Webhook(delivery.Webhook)
+		if !ok {
+			delivery.Pending, delivery.Payload, delivery.FinishedAt = false, nil, time.Now()
+			log.Printf("webhooks: %s to %s given up: the webhook was removed", delivery.Event, delivery.Webhook)
+			if err := ws.deliveries.Append(delivery); err != nil {
+				log.Printf("webhooks: failed to record delivery: %v", err)
+			}
+			continue
+		}
+		ws.start(hook, delivery, delivery.Payload)
+	}
+}
+
+// ValidateWebhook reports what is wrong with a webhook, if anything
+func ValidateWebhook(hook models.Webhook) error {
+	_, err := normalizeWebhook(hook)
+	return err
+}
+
+// normalizeWebhook checks a webhook and tidies its event list
+func normalizeWebhook(hook models.Webhook) (models.Webhook, error) {
+	hook.Name = strings.ToLower(strings.TrimSpace(hook.Name))
+	if !ValidTeamName(hook.Name) {
+		return hook, fmt.Errorf("webhook name %q must be lowercase letters, digits and dashes", hook.Name)
+	}
+	hook.URL = strings.TrimSpace(hook.URL)
+	if u, err := url.Parse(hook.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return hook, fmt.Errorf("webhook %q: %q is not an http or https URL", hook.Name, hook.URL)
+	}
+
+	events := uniqueNames(hook.Events)
+	for _, event := range events {
+		if event != "*" && !hasTag(models.WebhookEvents, event) {
+			return hook, fmt.Errorf("webhook %q: unknown event %q (want %s or *)", hook.Name, event, strings.Join(models.WebhookEvents, ", "))
+		}
+	}
+	if len(events) == 0 {
+		return hook, fmt.Errorf("webhook %q: no events", hook.Name)
+	}
+	hook.Events = events
+	return hook, nil
+}
+
+// Webhooks returns every webhook, by name
+func (ws *WebhookService) Webhooks() []models.Webhook {
+	ws.mutex.RLock()
+	defer ws.mutex.RUnlock()
+	hooks := make([]models.Webhook, 0, len(ws.hooks))
+	for _, hook := range ws.hooks {
+		hooks = append(hooks, hook)
+	}
+	sort.Slice(hooks, func(i, j int) bool { return hooks[i].Name < hooks[j].Name })
+	return hooks
+}
+
+// Webhook looks up a webhook by name
+func (ws *WebhookService) Webhook(name string) (models.Webhook, bool) {
+	ws.mutex.RLock()
+	defer ws.mutex.RUnlock()
+	hook, ok := ws.hooks[name]
+	return hook, ok
+}
+
+// Put creates or replaces a webhook and saves the webhooks file
+func (ws *WebhookService) Put(hook models.Webhook) (models.Webhook, error) {
+	hook, err := normalizeWebhook(hook)
+	if err != nil {
+		return hook, err
+	}
+
+	ws.mutex.Lock()
+	defer ws.mutex.Unlock()
+	previous, existed := ws.hooks[hook.Name]
+	ws.hooks[hook.Name] = hook
+	if err := ws.save(); err != nil {
+		if existed {
+			ws.hooks[hook.Name] = previous
+		} else {
+			delete(ws.hooks, hook.Name)
+		}
+		return hook, err
+	}
+	return hook, nil
+}
+
+// Delete removes a webhook and saves the webhooks file. It reports false if
+// there was no such webhook.
+func (ws *WebhookService) Delete(name string) (bool, error) {
+	ws.mutex.Lock()
+	defer ws.mutex.Unlock()
+	hook, ok := ws.hooks[name]
+	if !ok {
+		return false, nil
+	}
+	delete(ws.hooks, name)
+	if err := ws.save(); err != nil {
+		ws.hooks[name] = hook
+		return true, err
+	}
+	return true, nil
+}
+
+// save writes the webhooks file; the caller holds the write lock. The file
+// holds secrets, so only its owner may read it.
+func (ws *WebhookService) save() error {
+	file := webhooksFile{Webhooks: make([]models.Webhook, 0, len(ws.hooks))}
+	for _, hook := range ws.hooks {
+		file.Webhooks = append(file.Webhooks, hook)
+	}
+	sort.Slice(file.Webhooks, func(i, j int) bool { return file.Webhooks[i].Name < file.Webhooks[j].Name })
+
+	raw, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(ws.path), 0755); err != nil {
+		return fmt.Errorf("failed to create webhooks directory: %v", err)
+	}
+	tmp := ws.path + ".tmp"
+	if err := os.WriteFile(tmp, append(raw, '\n'), 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %v", ws.path, err)
+	}
+	return os.Rename(tmp, ws.path)
+}
+
+// Emit sends an event to every webhook subscribed to it, in the background.
+// A nil service emits nothing, so callers need not check for one.
+func (ws *WebhookService) Emit(event string, data interface{}) {
+	if ws == nil {
+		return
+	}
+	var subscribed []models.Webhook
+	for _, hook := range ws.Webhooks() {
+		if hasTag(hook.Events, "*") || hasTag(hook.Events, event) {
+			subscribed = append(subscribed, hook)
+		}
+	}
+	if len(subscribed) == 0 {
+		return
+	}
+
+	ev, body, err := newWebhookEvent(event, data)
+	if err != nil {
+		log.Printf("webhooks: failed to encode %s: %v", event, err)
+		return
+	}
+	for _, hook := range subscribed {
+		ws.start(hook, newWebhookDelivery(hook, ev), body)
+	}
+}
+
+// start delivers in the background, unless the delivery is already under way
+func (ws *WebhookService) start(hook models.Webhook, delivery models.WebhookDelivery, body []byte) {
+	ws.mutex.Lock()
+	defer ws.mutex.Unlock()
+	if ws.active[delivery.ID] {
+		return
+	}
+	ws.active[delivery.ID] = true
+	ws.pending.Add(1)
+	go func() {
+		defer ws.pending.Done()
+		ws.deliver(hook, delivery, body, ws.backoff)
+		ws.mutex.Lock()
+		delete(ws.active, delivery.ID)
+		ws.mutex.Unlock()
+	}()
+}
+
+// Ping sends a ping event to a webhook once, without retries, and returns
+// the delivery
+func (ws *WebhookService) Ping(hook models.Webhook) models.WebhookDelivery {
+	ev, body, _ := newWebhookEvent(models.EventPing, map[string]string{"webhook": hook.Name})
+	return ws.deliver(hook, newWebhookDelivery(hook, ev), body, nil)
+}
+
+// Wait blocks until every delivery in progress has finished or given up
+func (ws *WebhookService) Wait() {
+	ws.pending.Wait()
+}
+
+// Deliveries returns the recorded deliveries to a webhook, or to all of them
+// if webhook is empty, newest first
+func (ws *WebhookService) Deliveries(webhook string, limit int) []models.WebhookDelivery {
+	deliveries := ws.latestDeliveries(func(key deliveryKey) bool {
+		return webhook == "" || key.webhook == webhook
+	}, limit)
+	for i := range deliveries {
+		deliveries[i].Payload = nil
+	}
+	return deliveries
+}
+
+// latestDeliveries reads the latest record of each delivery that keep
+// accepts from disk, newest first, up to limit of them if limit is positive
+func (ws *WebhookService) latestDeliveries(keep func(deliveryKey) bool, limit int) []models.WebhookDelivery {
+	seen := make(map[string]bool)
+	entries := ws.deliveries.Filter(func(key deliveryKey) bool {
+		if seen[key.id] {
+			return false
+		}
+		seen[key.id] = true
+		return keep(key)
+	})
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	deliveries, err := ws.deliveries.Read(entries)
+	if err != nil {
+		log.Printf("webhooks: %v", err)
+	}
+	return deliveries
+}
+
+// newWebhookEvent wraps data in an event and encodes it once for every
+// webhook, so each receives the same bytes
+func newWebhookEvent(event string, data interface{}) (models.WebhookEvent, []byte, error) {
+	ev := models.WebhookEvent{ID: newAttemptID(), Event: event, CreatedAt: time.Now().UTC(), Data: data}
+	body, err := json.Marshal(ev)
+	return ev, body, err
+}
+
+// newWebhookDelivery starts the record of delivering an event to a webhook
+func newWebhookDelivery(hook models.Webhook, ev models.WebhookEvent) models.WebhookDelivery {
+	return models.WebhookDelivery{
+		ID:        newAttemptID(),
+		Webhook:   hook.Name,
+		URL:       hook.URL,
+		EventID:   ev.ID,
+		Event:     ev.Event,
+		Tries:     []models.WebhookDeliveryAttempt{},
+		CreatedAt: time.Now(),
+	}
+}
+
+// deliver tries to deliver an event, waiting out backoff between tries, and
+// records the outcome. Until then a delivery that may be retried is recorded
+// as pending, before its first try and after every failed one. A resumed
+// delivery carries on from the tries it already made.
+func (ws *WebhookService) deliver(hook models.Webhook, delivery models.WebhookDelivery, body []byte, backoff []time.Duration) models.WebhookDelivery {
+	delivery.URL = hook.URL
+	if len(delivery.Tries) == 0 && len(backoff) > 0 {
+		ws.recordPending(delivery, body)
+	}
+	for {
+		if n := len(delivery.Tries); n > 0 && n <= len(backoff) {
+			time.Sleep(time.Until(delivery.Tries[n-1].At.Add(backoff[n-1])))
+		}
+		attempt, retry := ws.send(hook, delivery, body)
+		delivery.Tries = append(delivery.Tries, attempt)
+		if !retry {
+			delivery.Delivered = attempt.Error == "" && attempt.StatusCode < 300
+			break
+		}
+		if len(delivery.Tries) > len(backoff) {
+			break
+		}
+		ws.recordPending(delivery, body)
+	}
+	delivery.Pending, delivery.Payload = false, nil
+	delivery.FinishedAt = time.Now()
+
+	if !delivery.Delivered {
+		reason := delivery.Tries[len(delivery.Tries)-1].Error
+		if reason == "" {
+			reason = fmt.Sprintf("status %d", delivery.Tries[len(delivery.Tries)-1].StatusCode)
+		}
+		log.Printf("webhooks: %s to %s failed after %d tries: %s", delivery.Event, hook.Name, len(delivery.Tries), reason)
+	}
+	if err := ws.deliveries.Append(delivery); err != nil {
+		log.Printf("webhooks: failed to record delivery: %v", err)
+	}
+	return delivery
+}
+
+// recordPending records a delivery that is still to be tried
+func (ws *WebhookService) recordPending(delivery models.WebhookDelivery, body []byte) {
+	delivery.Pending, delivery.Payload = true, body
+	if err := ws.deliveries.Append(delivery); err != nil {
+		log.Printf("webhooks: failed to record pending delivery: %v", err)
+	}
+}
+
+// send makes one try at a delivery, reporting whether it is worth retrying
+func (ws *WebhookService) send(hook models.Webhook, delivery models.WebhookDelivery, body []byte) (attempt models.WebhookDeliveryAttempt, retry bool) {
+	attempt.At = time.Now()
+	defer func() { attempt.DurationMs = time.Since(attempt.At).Milliseconds() }()
+
+	req, err := http.NewRequest("POST", hook.URL, bytes.NewReader(body))
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt, false
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "go-interview-practice-web-ui/1.0")
+	req.Header.Set(WebhookEventHeader, delivery.Event)
+	req.Header.Set(WebhookDeliveryHeader, delivery.EventID)
+	if hook.Secret != "" {
+		req.Header.Set(WebhookSignatureHeader, SignWebhook(hook.Secret, body))
+	}
+
+	resp, err := ws.client.Do(req)
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt, true
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	resp.Body.Close()
+
+	attempt.StatusCode = resp.StatusCode
+	switch {
+	case resp.StatusCode < 300:
+		return attempt, false
+	case resp.StatusCode >= 500, resp.StatusCode == http.StatusRequestTimeout, resp.StatusCode == http.StatusTooManyRequests:
+		return attempt, true
+	default:
+		return attempt, false
+	}
+}
+
+// SignWebhook returns the signature header value for body
+func SignWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhook reports whether signature is body's signature with secret
+func VerifyWebhook(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(signature), []byte(SignWebhook(secret, body)))
+}
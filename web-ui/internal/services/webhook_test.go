@@ -0,0 +1,147 @@
+package services
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"web-ui/internal/models"
+)
+
+func TestWebhookDeliveryRetriesAndSignatures(t *testing.T) {
+	var mutex sync.Mutex
+	var received []models.WebhookEvent
+	tries := 0
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !VerifyWebhook("s3cret", body, r.Header.Get(WebhookSignatureHeader)) {
+			http.Error(w, "bad signature", http.StatusUnauthorized)
+			return
+		}
+		mutex.Lock()
+		defer mutex.Unlock()
+		tries++
+		if tries == 1 {
+			http.Error(w, "try again", http.StatusServiceUnavailable)
+			return
+		}
+		var event models.WebhookEvent
+		json.Unmarshal(body, &event)
+		received = append(received, event)
+	}))
+	defer receiver.Close()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "webhooks.json")
+	webhooks, err := NewWebhookService(path, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	webhooks.backoff = []time.Duration{time.Millisecond}
+	if _, err := webhooks.Put(models.Webhook{Name: "chat", URL: receiver.URL, Events: []string{"gossip"}}); err == nil {
+		t.Error("a webhook for an unknown event was accepted")
+	}
+	webhooks.Put(models.Webhook{Name: "chat", URL: receiver.URL, Secret: "s3cret", Events: []string{models.EventSubmissionPassed}})
+	webhooks.Put(models.Webhook{Name: "forged", URL: receiver.URL, Secret: "wrong", Events: []string{"*"}})
+
+	// The webhooks file survives a restart
+	webhooks, _ = NewWebhookService(path, dir)
+	webhooks.backoff = []time.Duration{time.Millisecond}
+	if err := webhooks.Load(); err != nil || len(webhooks.Webhooks()) != 2 {
+		t.Fatalf("reloaded webhooks = %+v, %v", webhooks.Webhooks(), err)
+	}
+
+	webhooks.Emit(models.EventRunCompleted, models.AttemptEvent{Username: "alice"})
+	webhooks.Emit(models.EventSubmissionPassed, models.AttemptEvent{Username: "alice", Track: models.TrackClassic, ChallengeID: "1", Passed: true})
+	webhooks.Wait()
+
+	// chat got its one event on the second try; forged was refused and not retried
+	if len(received) != 1 || received[0].Event != models.EventSubmissionPassed || tries != 2 {
+		t.Fatalf("received %+v in %d tries", received, tries)
+	}
+	chat := webhooks.Deliveries("chat", 0)
+	if len(chat) != 1 || !chat[0].Delivered || len(chat[0].Tries) != 2 || chat[0].Tries[0].StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("chat deliveries = %+v", chat)
+	}
+	forged := webhooks.Deliveries("forged", 0)
+	if len(forged) != 2 || forged[0].Delivered || len(forged[0].Tries) != 1 || forged[0].Tries[0].StatusCode != http.StatusUnauthorized {
+		t.Errorf("forged deliveries = %+v", forged)
+	}
+
+	if ping := webhooks.Ping(models.Webhook{Name: "chat", URL: receiver.URL, Secret: "s3cret"}); !ping.Delivered || len(received) != 2 {
+		t.Errorf("ping = %+v", ping)
+	}
+}
+
+func TestWebhookPendingDeliveriesResume(t *testing.T) {
+	var mutex sync.Mutex
+	var received []string
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		received = append(received, r.Header.Get(WebhookDeliveryHeader))
+	}))
+	defer receiver.Close()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "webhooks.json")
+	webhooks, _ := NewWebhookService(path, dir)
+	chat, _ := webhooks.Put(models.Webhook{Name: "chat", URL: receiver.URL, Events: []string{"*"}})
+	gone, _ := webhooks.Put(models.Webhook{Name: "gone", URL: receiver.URL, Events: []string{"*"}})
+
+	// The server stopped while one delivery waited for its retry and another
+	// had not been tried yet
+	ev, body, _ := newWebhookEvent(models.EventSubmissionPassed, models.AttemptEvent{Username: "alice"})
+	retrying := newWebhookDelivery(chat, ev)
+	retrying.Tries = append(retrying.Tries, models.WebhookDeliveryAttempt{At: time.Now(), StatusCode: http.StatusBadGateway})
+	webhooks.recordPending(retrying, body)
+	webhooks.recordPending(newWebhookDelivery(gone, ev), body)
+	webhooks.Delete("gone")
+
+	webhooks, _ = NewWebhookService(path, dir)
+	webhooks.backoff = []time.Duration{time.Millisecond}
+	if err := webhooks.Load(); err != nil {
+		t.Fatal(err)
+	}
+	webhooks.Wait()
+	if len(received) != 0 || len(webhooks.Deliveries("", 0)) != 2 || !webhooks.Deliveries("", 0)[0].Pending {
+		t.Fatalf("loading alone delivered %v", received)
+	}
+	webhooks.Resume()
+	webhooks.Wait()
+
+	if len(received) != 1 || received[0] != ev.ID {
+		t.Fatalf("received %v, want the one event %s", received, ev.ID)
+	}
+	deliveries := webhooks.Deliveries("", 0)
+	if len(deliveries) != 2 {
+		t.Fatalf("deliveries = %+v", deliveries)
+	}
+	for _, d := range deliveries {
+		if d.Pending || d.Payload != nil {
+			t.Errorf("%s is still pending: %+v", d.Webhook, d)
+		}
+		switch d.Webhook {
+		case "chat":
+			if !d.Delivered || len(d.Tries) != 2 {
+				t.Errorf("resumed delivery = %+v", d)
+			}
+		case "gone":
+			if d.Delivered || len(d.Tries) != 0 {
+				t.Errorf("delivery to a removed webhook = %+v", d)
+			}
+		}
+	}
+
+	// Finished deliveries are not resumed again
+	webhooks.Resume()
+	webhooks.Wait()
+	if len(received) != 1 {
+		t.Errorf("received %v after resuming again", received)
+	}
+}
@@ -0,0 +1,28 @@
+package utils
+
+import "testing"
+
+func TestUnifiedDiff(t *testing.T) {
+	from := "package main\n\nfunc Sum(a, b int) int {\n\treturn 0\n}\n"
+	to := "package main\n\nfunc Sum(a, b int) int {\n\treturn a + b\n}\n"
+
+	want := "--- attempt-1\n+++ attempt-2\n" +
+		"@@ -1,5 +1,5 @@\n" +
+		" package main\n" +
+		" \n" +
+		" func Sum(a, b int) int {\n" +
+		"-\treturn 0\n" +
+		"+\treturn a + b\n" +
+		" }\n"
+	if got := UnifiedDiff(from, to, "attempt-1", "attempt-2"); got != want {
+		t.Errorf("UnifiedDiff mismatch\n got:\n%s\nwant:\n%s", got, want)
+	}
+
+	if got := UnifiedDiff(from, from, "a", "b"); got != "" {
+		t.Errorf("identical inputs should produce no diff, got %q", got)
+	}
+
+	if got := UnifiedDiff("", "x\n", "a", "b"); got != "--- a\n+++ b\n@@ -0,0 +1,1 @@\n+x\n" {
+		t.Errorf("diff from empty file: %q", got)
+	}
+}
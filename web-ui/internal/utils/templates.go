@@ -98,6 +98,7 @@ func markdownToHTML(markdown string) string {
 func GetTemplateFuncs() template.FuncMap {
 	return template.FuncMap{
 		"lower": strings.ToLower,
+		"join":  strings.Join,
 		"truncateDescription": func(s string) string {
 			// Extract first paragraph that is not a heading or link
 			lines := strings.Split(s, "\n")
@@ -429,5 +430,11 @@ func GetTemplateFuncs() template.FuncMap {
 			}
 			return s[:length-3] + "..."
 		},
+		"duration": func(seconds int) string {
+			if seconds >= 3600 {
+				return fmt.Sprintf("%dh %02dm", seconds/3600, seconds%3600/60)
+			}
+			return fmt.Sprintf("%dm %02ds", seconds/60, seconds%60)
+		},
 	}
 }
@@ -0,0 +1,276 @@
+package utils
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WebSocket opcodes (RFC 6455 section 5.2)
+const (
+	wsContinuation = 0x0
+	wsText         = 0x1
+	wsBinary       = 0x2
+	wsClose        = 0x8
+	wsPing         = 0x9
+	wsPong         = 0xA
+)
+
+// MaxWebSocketMessage bounds a message read from a client
+const MaxWebSocketMessage = 1 << 20
+
+// maxControlPayload bounds the payload of a close, ping or pong frame, which
+// may not be fragmented either (RFC 6455 section 5.5)
+const maxControlPayload = 125
+
+// wsProtocolError is the close status for a peer that broke the protocol
+const wsProtocolError = 1002
+
+// wsGUID is appended to the client's key to accept a handshake
+const wsGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC11B85"
+
+// ErrWebSocketClosed is returned by ReadMessage once the peer has closed the
+// connection
+var ErrWebSocketClosed = errors.New("websocket closed")
+
+// WebSocket is the server side of a WebSocket connection. It sends and
+// receives whole text messages, answers pings and honours close frames; it
+// does not negotiate extensions. Writes may come from several goroutines,
+// reads from one.
+type WebSocket struct {
+	conn   net.Conn
+	reader *bufio.Reader
+
+	writeMutex sync.Mutex
+	closed     bool
+}
+
+// UpgradeWebSocket completes the WebSocket handshake for a request. Requests
+// from other origins are refused: browsers send cookies with WebSocket
+// handshakes, so another site could otherwise connect as the signed-in user.
+func UpgradeWebSocket(w http.ResponseWriter, r *http.Request) (*WebSocket, error) {
+	if r.Method != "GET" ||
+		!headerContains(r.Header, "Connection", "upgrade") ||
+		!headerContains(r.Header, "Upgrade", "websocket") {
+		http.Error(w, "Expected a WebSocket handshake", http.StatusBadRequest)
+		return nil, fmt.Errorf("not a websocket handshake")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "Unsupported WebSocket version", http.StatusUpgradeRequired)
+		return nil, fmt.Errorf("unsupported websocket version %q", r.Header.Get("Sec-WebSocket-Version"))
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		http.Error(w, "Missing Sec-WebSocket-Key", http.StatusBadRequest)
+		return nil, fmt.Errorf("missing websocket key")
+	}
+	if origin := r.Header.Get("Origin"); origin != "" {
+		if u, err := url.Parse(origin); err != nil || !strings.EqualFold(u.Host, r.Host) {
+			http.Error(w, "Cross-origin WebSocket refused", http.StatusForbidden)
+			return nil, fmt.Errorf("cross-origin websocket from %q", origin)
+		}
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "WebSockets are not supported here", http.StatusInternalServerError)
+		return nil, fmt.Errorf("response writer cannot be hijacked")
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, err
+	}
+
+	accept := sha1.Sum([]byte(key + wsGUID))
+	response := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(accept[:]) + "\r\n\r\n"
+	if _, err := rw.WriteString(response); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &WebSocket{conn: conn, reader: rw.Reader}, nil
+}
+
+// headerContains reports whether a comma-separated header has a token
+func headerContains(header http.Header, name, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ReadMessage returns the next text or binary message, reassembling
+// fragments. It answers pings, and on a close frame replies in kind and
+// returns ErrWebSocketClosed. Frames out of order fail the connection.
+func (ws *WebSocket) ReadMessage() ([]byte, error) {
+	var message []byte
+	fragmented := false
+	for {
+		fin, opcode, payload, err := ws.readFrame()
+		if err != nil {
+			return nil, err
+		}
+		switch opcode {
+		case wsPing:
+			if err := ws.writeFrame(wsPong, payload); err != nil {
+				return nil, err
+			}
+			continue
+		case wsPong:
+			continue
+		case wsClose:
+			ws.writeFrame(wsClose, payload)
+			ws.Close()
+			return nil, ErrWebSocketClosed
+		case wsText, wsBinary:
+			if fragmented {
+				return nil, ws.failProtocol("new message before the last one was finished")
+			}
+			message, fragmented = payload, true
+		case wsContinuation:
+			if !fragmented {
+				return nil, ws.failProtocol("continuation without a message")
+			}
+			if len(message)+len(payload) > MaxWebSocketMessage {
+				return nil, fmt.Errorf("websocket: message over %d bytes", MaxWebSocketMessage)
+			}
+			message = append(message, payload...)
+		default:
+			return nil, ws.failProtocol(fmt.Sprintf("unknown opcode %d", opcode))
+		}
+		if fin {
+			return message, nil
+		}
+	}
+}
+
+// readFrame reads one frame, unmasking the payload. Clients must mask, and
+// with no extensions negotiated must leave the RSV bits clear.
+func (ws *WebSocket) readFrame() (fin bool, opcode byte, payload []byte, err error) {
+	var header [2]byte
+	if _, err = io.ReadFull(ws.reader, header[:]); err != nil {
+		return
+	}
+	fin, opcode = header[0]&0x80 != 0, header[0]&0x0F
+	if header[0]&0x70 != 0 {
+		err = ws.failProtocol("reserved bits set")
+		return
+	}
+	if header[1]&0x80 == 0 {
+		err = fmt.Errorf("websocket: unmasked client frame")
+		return
+	}
+
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		var extended [2]byte
+		if _, err = io.ReadFull(ws.reader, extended[:]); err != nil {
+			return
+		}
+		length = uint64(binary.BigEndian.Uint16(extended[:]))
+	case 127:
+		var extended [8]byte
+		if _, err = io.ReadFull(ws.reader, extended[:]); err != nil {
+			return
+		}
+		length = binary.BigEndian.Uint64(extended[:])
+	}
+	if length > MaxWebSocketMessage {
+		err = fmt.Errorf("websocket: frame over %d bytes", MaxWebSocketMessage)
+		return
+	}
+	if opcode&0x8 != 0 && (!fin || length > maxControlPayload) {
+		err = ws.failProtocol(fmt.Sprintf("control frame %d fragmented or over %d bytes", opcode, maxControlPayload))
+		return
+	}
+
+	var mask [4]byte
+	if _, err = io.ReadFull(ws.reader, mask[:]); err != nil {
+		return
+	}
+	payload = make([]byte, length)
+	if _, err = io.ReadFull(ws.reader, payload); err != nil {
+		return
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return
+}
+
+// failProtocol closes the connection with a protocol error status and
+// returns the error for reason
+func (ws *WebSocket) failProtocol(reason string) error {
+	ws.writeFrame(wsClose, binary.BigEndian.AppendUint16(nil, wsProtocolError))
+	ws.Close()
+	return fmt.Errorf("websocket: %s", reason)
+}
+
+// WriteMessage sends a text message
+func (ws *WebSocket) WriteMessage(data []byte) error {
+	return ws.writeFrame(wsText, data)
+}
+
+// writeFrame sends one unfragmented, unmasked frame
+func (ws *WebSocket) writeFrame(opcode byte, payload []byte) error {
+	ws.writeMutex.Lock()
+	defer ws.writeMutex.Unlock()
+	if ws.closed {
+		return ErrWebSocketClosed
+	}
+
+	frame := []byte{0x80 | opcode}
+	switch length := len(payload); {
+	case length < 126:
+		frame = append(frame, byte(length))
+	case length <= 0xFFFF:
+		frame = append(frame, 126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(length))
+	default:
+		frame = append(frame, 127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(length))
+	}
+	frame = append(frame, payload...)
+
+	ws.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	_, err := ws.conn.Write(frame)
+	return err
+}
+
+// Ping sends a ping, to keep idle connections open through proxies
+func (ws *WebSocket) Ping() error {
+	return ws.writeFrame(wsPing, nil)
+}
+
+// Close closes the connection without a close handshake
+func (ws *WebSocket) Close() error {
+	ws.writeMutex.Lock()
+	defer ws.writeMutex.Unlock()
+	if ws.closed {
+		return nil
+	}
+	ws.closed = true
+	return ws.conn.Close()
+}
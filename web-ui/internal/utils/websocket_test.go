@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+)
+
+// clientFrame builds a masked frame as a browser sends it
+func clientFrame(fin bool, opcode byte, payload []byte) []byte {
+	first := opcode
+	if fin {
+		first |= 0x80
+	}
+	frame := []byte{first}
+	if len(payload) < 126 {
+		frame = append(frame, 0x80|byte(len(payload)))
+	} else {
+		frame = append(frame, 0x80|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(len(payload)))
+	}
+	mask := []byte{1, 2, 3, 4}
+	frame = append(frame, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	return frame
+}
+
+func TestWebSocketRejectsBadFrames(t *testing.T) {
+	rsv := clientFrame(true, wsText, []byte("hi"))
+	rsv[0] |= 0x40
+	for name, frame := range map[string][]byte{
+		"oversized ping":        clientFrame(true, wsPing, make([]byte, 126)),
+		"oversized close":       clientFrame(true, wsClose, make([]byte, 200)),
+		"fragmented ping":       clientFrame(false, wsPing, []byte("hi")),
+		"fragmented close":      clientFrame(false, wsClose, nil),
+		"reserved bits":         rsv,
+		"unknown opcode":        clientFrame(true, 0x3, nil),
+		"lone continuation":     clientFrame(true, wsContinuation, []byte("hi")),
+		"interrupted fragments": append(clientFrame(false, wsText, []byte("he")), clientFrame(true, wsText, []byte("llo"))...),
+	} {
+		server, client := net.Pipe()
+		ws := &WebSocket{conn: server, reader: bufio.NewReader(server)}
+		go client.Write(frame)
+
+		reply := make(chan []byte, 1)
+		go func() {
+			got, _ := io.ReadAll(client)
+			reply <- got
+		}()
+
+		if _, err := ws.ReadMessage(); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+		got := <-reply
+		if len(got) != 4 || got[0] != 0x80|wsClose || binary.BigEndian.Uint16(got[2:]) != wsProtocolError {
+			t.Errorf("%s: answered %x, want a close with status %d", name, got, wsProtocolError)
+		}
+	}
+
+	// A ping within the limits is answered with its payload
+	server, client := net.Pipe()
+	defer client.Close()
+	ws := &WebSocket{conn: server, reader: bufio.NewReader(server)}
+	go func() {
+		client.Write(clientFrame(true, wsPing, []byte("hi")))
+		pong := make([]byte, 4)
+		io.ReadFull(client, pong)
+		if string(pong[2:]) != "hi" {
+			t.Errorf("pong = %x", pong)
+		}
+		client.Write(clientFrame(true, wsText, []byte("hello")))
+	}()
+	if message, err := ws.ReadMessage(); err != nil || string(message) != "hello" {
+		t.Errorf("ReadMessage = %q, %v", message, err)
+	}
+}
+
+func TestWebSocketReassemblesFragments(t *testing.T) {
+	server, client := net.Pipe()
+	defer client.Close()
+	ws := &WebSocket{conn: server, reader: bufio.NewReader(server)}
+
+	// A first fragment may be empty, and pings may come between fragments
+	go func() {
+		client.Write(clientFrame(false, wsText, nil))
+		client.Write(clientFrame(false, wsContinuation, []byte("hel")))
+		client.Write(clientFrame(true, wsPing, nil))
+		io.ReadFull(client, make([]byte, 2))
+		client.Write(clientFrame(true, wsContinuation, []byte("lo")))
+	}()
+	if message, err := ws.ReadMessage(); err != nil || string(message) != "hello" {
+		t.Errorf("ReadMessage = %q, %v", message, err)
+	}
+}
@@ -3,13 +3,16 @@ package main
 import (
 	"bufio"
 	"embed"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
-	"strconv"
 	"strings"
+	_ "time/tzdata" // time zones for activity streaks, even where the system has none
 
+	"web-ui/internal/config"
 	"web-ui/internal/server"
 	"web-ui/internal/services"
 )
@@ -21,55 +24,89 @@ func main() {
 	// Load environment variables from .env file
 	loadEnvFile()
 
-	// Initialize services
-	challengeService := services.NewChallengeService()
-	scoreboardService := services.NewScoreboardService()
-	userService := services.NewUserService()
-	executionService := services.NewExecutionService()
-	packageService := services.NewPackageService()
-	aiService := services.NewAIService()
-
-	// Load data
-	log.Println("Loading challenges...")
-	if err := challengeService.LoadChallenges(); err != nil {
-		log.Fatalf("Failed to load challenges: %v", err)
+	// `web-ui scoreboard ...` maintains the checked-in scoreboards instead of serving
+	if len(os.Args) > 1 && os.Args[1] == "scoreboard" {
+		if err := runScoreboardCommand(loadConfig(nil, false), os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
 	}
 
-	log.Println("Loading scoreboards...")
-	if err := scoreboardService.LoadScoreboards(challengeService.GetChallenges()); err != nil {
-		log.Fatalf("Failed to load scoreboards: %v", err)
+	// `web-ui badges export` writes the profile badges out as static files
+	if len(os.Args) > 1 && os.Args[1] == "badges" {
+		if err := runBadgesCommand(loadConfig(nil, false), os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
 	}
 
-	log.Println("Loading packages...")
-	if err := packageService.LoadPackages(); err != nil {
-		log.Fatalf("Failed to load packages: %v", err)
+	// `web-ui webhooks receive` runs a local receiver for trying out webhooks
+	if len(os.Args) > 1 && os.Args[1] == "webhooks" {
+		if err := runWebhooksCommand(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	cfg := loadConfig(os.Args[1:], true)
+	log.Printf("Serving content from %s", cfg.Content.Root)
+
+	// Initialize services
+	svc, err := services.NewServices(cfg)
+	if err != nil {
+		log.Fatal(err)
 	}
 
 	// Initialize server
-	srv := server.NewServer(
-		content,
-		challengeService,
-		scoreboardService,
-		userService,
-		executionService,
-		packageService,
-		aiService,
-	)
+	srv := server.NewServer(content, cfg, svc)
 
 	// Setup routes
-	mux := srv.SetupRoutes()
+	handler, err := srv.SetupRoutes()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Retry the webhook deliveries the last run left pending
+	svc.Webhooks.Resume()
+
+	// A zero poll interval disables polling (POST /api/admin/reload still works)
+	if cfg.Content.PollInterval > 0 {
+		svc.ContentWatcher.Start(cfg.Content.PollInterval)
+	}
 
 	// Start server
-	// Port is configurable so several instances (or another service already on
-	// 8080) can coexist locally. Defaults to 8080 as before.
-	port := 8080
-	if p := os.Getenv("PORT"); p != "" {
-		if n, err := strconv.Atoi(p); err == nil && n > 0 {
-			port = n
+	log.Printf("Server starting on http://localhost:%d", cfg.Server.Port)
+	log.Fatal(http.ListenAndServe(fmt.Sprintf(":%d", cfg.Server.Port), handler))
+}
+
+// loadConfig loads and validates the configuration, exiting on errors. With
+// --print-config it prints the configuration and exits instead. Sign-in is
+// only checked when serving, since the commands sign no one in.
+func loadConfig(args []string, serving bool) *config.Config {
+	cfg, err := config.Load(args)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		log.Fatalf("Configuration: %v", err)
+	}
+
+	validate := cfg.Validate
+	if serving {
+		validate = func() error { return errors.Join(cfg.Validate(), cfg.ValidateSignIn()) }
+	}
+	if cfg.PrintConfig {
+		cfg.Print(os.Stdout)
+		if err := validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "\nInvalid configuration:\n%v\n", err)
+			os.Exit(1)
 		}
+		os.Exit(0)
+	}
+	if err := validate(); err != nil {
+		log.Fatalf("Invalid configuration:\n%v", err)
 	}
-	log.Printf("Server starting on http://localhost:%d", port)
-	log.Fatal(http.ListenAndServe(fmt.Sprintf(":%d", port), mux))
+	return cfg
 }
 
 // loadEnvFile loads environment variables from a .env file
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"testing"
 
+	"web-ui/internal/config"
 	"web-ui/internal/services"
 	"web-ui/internal/utils"
 )
@@ -12,7 +13,11 @@ import (
 // Local smoke test for the releases track: everything on disk loads coherently and
 // every release template parses against base.html with the shared func map.
 func TestReleasesLoadAndTemplatesParse(t *testing.T) {
-	svc := services.NewReleaseService()
+	cfg, err := config.Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := services.NewReleaseService(cfg, services.NewExecutionService(cfg))
 	releases := svc.GetReleases()
 	if len(releases) == 0 {
 		t.Fatal("no releases loaded from ../releases")
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"web-ui/internal/config"
+	"web-ui/internal/scoreboard"
+	"web-ui/internal/services"
+)
+
+const scoreboardUsage = `usage: web-ui scoreboard <command> [arguments]
+
+Commands:
+  generate [-readme PATH]  rewrite every challenge's scoreboard.json and SCOREBOARD.md
+                           and the leaderboard sections of the repository README
+  record DIR               record the results of a judge run, read from stdin as
+                           "username passed total" lines, in DIR/scoreboard.json
+                           and regenerate DIR/SCOREBOARD.md
+
+The repository is found from the working directory unless content.root
+(CONTENT_ROOT) is configured.`
+
+// runScoreboardCommand implements `web-ui scoreboard ...`, which keeps the
+// checked-in scoreboards and README leaderboards in sync with the same services
+// the server uses
+func runScoreboardCommand(cfg *config.Config, args []string) error {
+	if len(args) == 0 || (args[0] != "generate" && args[0] != "record") {
+		return fmt.Errorf("%s", scoreboardUsage)
+	}
+
+	switch args[0] {
+	case "generate":
+		flags := flag.NewFlagSet("generate", flag.ContinueOnError)
+		readme := flags.String("readme", filepath.Join(cfg.Content.Root, "README.md"), "README to update")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+		svc, err := services.NewServices(cfg)
+		if err != nil {
+			return err
+		}
+		return generateScoreboards(cfg, svc, *readme)
+
+	case "record":
+		if len(args) != 2 {
+			return fmt.Errorf("record needs one challenge directory")
+		}
+		dir := args[1]
+		head, err := services.NewGitHistoryService(cfg).Head()
+		if err != nil {
+			log.Printf("Warning: %v", err)
+		}
+		results, err := scoreboard.ParseResults(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("%s: %v", dir, err)
+		}
+		board, err := scoreboard.Merge(dir, boardNameForDir(dir), results, time.Now().UTC(), head)
+		if err != nil {
+			return fmt.Errorf("%s: %v", dir, err)
+		}
+		if err := scoreboard.Save(dir, board); err != nil {
+			return fmt.Errorf("%s: %v", dir, err)
+		}
+		log.Printf("Recorded %d result(s) in %s", len(board.Entries), filepath.Join(dir, scoreboard.JSONFile))
+	}
+	return nil
+}
+
+// generateScoreboards rewrites every per-challenge scoreboard and the README leaderboards
+func generateScoreboards(cfg *config.Config, svc *services.Services, readme string) error {
+	history, leaderboards := svc.GitHistory, svc.Leaderboards
+
+	count := 0
+	for id := range svc.Challenges.GetChallenges() {
+		if rewriteScoreboard(cfg.ChallengeDir(id), services.ClassicSubmissionKey(id), history) {
+			count++
+		}
+	}
+	for name := range svc.Packages.GetPackages() {
+		challenges, err := leaderboards.PackageChallenges(name)
+		if err != nil {
+			continue
+		}
+		for _, challenge := range challenges {
+			dir := cfg.PackageChallengeDir(name, challenge.ID)
+			if rewriteScoreboard(dir, services.PackageSubmissionKey(name, challenge.ID), history) {
+				count++
+			}
+		}
+	}
+	log.Printf("Rewrote %d challenge scoreboard(s)", count)
+
+	if err := leaderboards.UpdateReadmeLeaderboards(readme); err != nil {
+		return fmt.Errorf("updating %s: %v", readme, err)
+	}
+	log.Printf("Updated leaderboards in %s", readme)
+	return nil
+}
+
+// rewriteScoreboard saves a challenge's scoreboard in canonical form. It reports
+// false when the challenge has no scoreboard yet.
+func rewriteScoreboard(dir, key string, history *services.GitHistoryService) bool {
+	board, err := scoreboard.Load(dir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("Warning: %v", err)
+		}
+		return false
+	}
+	stampEntries(board, history, key)
+	if err := scoreboard.Save(dir, board); err != nil {
+		log.Printf("Warning: cannot save scoreboard in %s: %v", dir, err)
+		return false
+	}
+	return true
+}
+
+// stampEntries dates entries recorded before judge runs were dated by when
+// the user's solution first landed, which is the best the git history knows
+func stampEntries(board *scoreboard.Board, history *services.GitHistoryService, key string) {
+	for i, e := range board.Entries {
+		if !e.Timestamp.IsZero() {
+			continue
+		}
+		if commit, ok := history.FirstSubmission(key, e.Username); ok {
+			board.Entries[i].Timestamp = commit.Time
+			board.Entries[i].Commit = commit.Commit
+		}
+	}
+}
+
+// boardNameForDir names a challenge directory in its SCOREBOARD.md heading:
+// challenge-N, or "<pkg> <challenge>" for package challenges
+func boardNameForDir(dir string) string {
+	return strings.Replace(submissionKeyForDir(dir), "/", " ", 1)
+}
+
+// submissionKeyForDir maps a challenge directory to its git history key:
+// .../challenge-N or .../packages/<pkg>/<challenge>
+func submissionKeyForDir(dir string) string {
+	parts := strings.Split(filepath.ToSlash(filepath.Clean(dir)), "/")
+	n := len(parts)
+	if n >= 3 && parts[n-3] == "packages" {
+		return services.PackageSubmissionKey(parts[n-2], parts[n-1])
+	}
+	return parts[n-1]
+}
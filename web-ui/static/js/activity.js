@@ -0,0 +1,120 @@
+// Daily activity: streaks and a contributions-style calendar, rendered into
+// any element with data-activity-user="username". When the element is marked
+// data-activity-owner="true" and the user has not chosen a time zone yet, the
+// browser's is saved so days begin and end at the user's midnight.
+
+const ACTIVITY_LEVEL_COLORS = ['#ebedf0', '#9be9a8', '#40c463', '#30a14e', '#216e39'];
+const ACTIVITY_CELL = 11;
+const ACTIVITY_GAP = 3;
+
+function fetchActivity(username) {
+    return fetch(`/api/users/${encodeURIComponent(username)}/activity`)
+        .then(response => {
+            if (!response.ok) throw new Error(`activity: ${response.status}`);
+            return response.json();
+        });
+}
+
+function saveBrowserTimezone(username) {
+    const timezone = Intl.DateTimeFormat().resolvedOptions().timeZone;
+    if (!timezone) return Promise.resolve(false);
+    return fetch(`/api/users/${encodeURIComponent(username)}`, {
+        method: 'PATCH',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ timezone: timezone })
+    }).then(response => response.ok);
+}
+
+function describeActivityDay(day) {
+    if (!day.total) return `No activity on ${day.date}`;
+    const parts = [];
+    if (day.runs) parts.push(`${day.runs} run${day.runs === 1 ? '' : 's'}`);
+    if (day.submits) parts.push(`${day.submits} submit${day.submits === 1 ? '' : 's'}`);
+    return `${parts.join(', ')} (${day.passes} passed) on ${day.date}`;
+}
+
+function renderActivityCalendar(calendar) {
+    const step = ACTIVITY_CELL + ACTIVITY_GAP;
+    const top = 14;
+    const width = calendar.weeks.length * step;
+    const height = top + 7 * step;
+    const svgNS = 'http://www.w3.org/2000/svg';
+    const svg = document.createElementNS(svgNS, 'svg');
+    svg.setAttribute('viewBox', `0 0 ${width} ${height}`);
+    svg.setAttribute('width', '100%');
+    svg.setAttribute('role', 'img');
+    svg.setAttribute('aria-label', `Activity from ${calendar.start} to ${calendar.end}`);
+
+    let lastMonth = '';
+    calendar.weeks.forEach((week, w) => {
+        const month = week[0].date.slice(5, 7);
+        if (month !== lastMonth && w < calendar.weeks.length - 2) {
+            const label = document.createElementNS(svgNS, 'text');
+            label.setAttribute('x', w * step);
+            label.setAttribute('y', 10);
+            label.setAttribute('font-size', '9');
+            label.setAttribute('fill', '#6c757d');
+            label.textContent = new Date(week[0].date + 'T00:00:00Z')
+                .toLocaleDateString('en-US', { month: 'short', timeZone: 'UTC' });
+            svg.appendChild(label);
+            lastMonth = month;
+        }
+        week.forEach((day, d) => {
+            const cell = document.createElementNS(svgNS, 'rect');
+            cell.setAttribute('x', w * step);
+            cell.setAttribute('y', top + d * step);
+            cell.setAttribute('width', ACTIVITY_CELL);
+            cell.setAttribute('height', ACTIVITY_CELL);
+            cell.setAttribute('rx', 2);
+            cell.setAttribute('fill', ACTIVITY_LEVEL_COLORS[day.level] || ACTIVITY_LEVEL_COLORS[0]);
+            const title = document.createElementNS(svgNS, 'title');
+            title.textContent = describeActivityDay(day);
+            cell.appendChild(title);
+            svg.appendChild(cell);
+        });
+    });
+    return svg;
+}
+
+function renderActivity(container, activity) {
+    if (activity.private) {
+        container.innerHTML = '<div class="text-muted small"><i class="bi bi-lock me-1"></i>This profile is private, so its activity is hidden.</div>';
+        return;
+    }
+    const streakDays = n => `${n} day${n === 1 ? '' : 's'}`;
+    container.innerHTML = `
+        <div class="d-flex flex-wrap gap-4 mb-2">
+            <div><div class="text-muted small">Current streak</div><div class="fs-5 fw-bold">🔥 ${streakDays(activity.currentStreak)}</div></div>
+            <div><div class="text-muted small">Longest streak</div><div class="fs-5 fw-bold">${streakDays(activity.longestStreak)}</div></div>
+            <div><div class="text-muted small">Active days</div><div class="fs-5 fw-bold">${activity.activeDays}</div></div>
+        </div>
+        <div class="activity-calendar"></div>
+        <div class="text-muted small mt-1"></div>`;
+
+    const notes = [`Days run midnight to midnight in ${activity.timezone}.`];
+    if (activity.streakFreezes > 0) {
+        notes.push(`A streak survives ${activity.streakFreezes} missed day${activity.streakFreezes === 1 ? '' : 's'} a week.`);
+    }
+    container.lastElementChild.textContent = notes.join(' ');
+    if (activity.calendar) {
+        container.querySelector('.activity-calendar').appendChild(renderActivityCalendar(activity.calendar));
+    }
+}
+
+function loadActivity(container) {
+    const username = container.dataset.activityUser;
+    fetchActivity(username)
+        .then(activity => {
+            if (container.dataset.activityOwner !== 'true' || activity.timezoneSaved) return activity;
+            return saveBrowserTimezone(username).then(saved => saved ? fetchActivity(username) : activity);
+        })
+        .then(activity => renderActivity(container, activity))
+        .catch(error => {
+            console.error(error);
+            container.innerHTML = '<div class="text-muted small">Activity is unavailable right now.</div>';
+        });
+}
+
+document.addEventListener('DOMContentLoaded', function() {
+    document.querySelectorAll('[data-activity-user]').forEach(loadActivity);
+});
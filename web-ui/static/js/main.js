@@ -553,21 +553,6 @@ document.addEventListener('DOMContentLoaded', function() {
             });
         }
     });
-    
-    // Handle username persistence
-    const usernameInput = document.getElementById('username');
-    if (usernameInput) {
-        // Load saved username
-        const savedUsername = localStorage.getItem('githubUsername');
-        if (savedUsername) {
-            usernameInput.value = savedUsername;
-        }
-        
-        // Save username when changed
-        usernameInput.addEventListener('change', function() {
-            localStorage.setItem('githubUsername', this.value);
-        });
-    }
 });
 
 // Initialize learning materials with highlighting
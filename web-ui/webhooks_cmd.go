@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+
+	"web-ui/internal/services"
+)
+
+const webhooksUsage = `usage: web-ui webhooks receive [-addr ADDR] [-secret SECRET] [-fail N]
+
+Runs a local webhook receiver for trying out outgoing webhooks. It accepts
+POSTs on any path at ADDR (default localhost:9090), checks each signature
+against SECRET (default WEBHOOK_SECRET) and prints the event. Point a webhook
+at it with:
+
+  curl -X PUT -H "Authorization: Bearer $ADMIN_TOKEN" \
+    -d '{"url": "http://localhost:9090/", "secret": "SECRET", "events": ["*"]}' \
+    http://localhost:8080/api/admin/webhooks/local
+
+Deliveries with a bad signature are answered 401. With -fail N, the first N
+deliveries of each event are answered 503, to watch the server retry.`
+
+// runWebhooksCommand implements `web-ui webhooks receive`
+func runWebhooksCommand(args []string) error {
+	if len(args) == 0 || args[0] != "receive" {
+		return fmt.Errorf("%s", webhooksUsage)
+	}
+
+	flags := flag.NewFlagSet("receive", flag.ContinueOnError)
+	addr := flags.String("addr", "localhost:9090", "address to listen on")
+	secret := flags.String("secret", os.Getenv("WEBHOOK_SECRET"), "the webhook's secret; signatures are not checked without one")
+	fail := flags.Int("fail", 0, "answer the first N tries of each event with 503")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	var mutex sync.Mutex
+	tries := make(map[string]int) // event ID → tries seen
+
+	log.Printf("Receiving webhooks on http://%s/", *addr)
+	return http.ListenAndServe(*addr, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+		if err != nil {
+			http.Error(w, "Failed to read body", http.StatusBadRequest)
+			return
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		event := r.Header.Get(services.WebhookEventHeader)
+		delivery := r.Header.Get(services.WebhookDeliveryHeader)
+		signature := r.Header.Get(services.WebhookSignatureHeader)
+
+		if *secret != "" && !services.VerifyWebhook(*secret, body, signature) {
+			log.Printf("%s %s: bad signature %q", event, delivery, signature)
+			http.Error(w, "Bad signature", http.StatusUnauthorized)
+			return
+		}
+		tries[delivery]++
+		if tries[delivery] <= *fail {
+			log.Printf("%s %s: failing try %d on purpose", event, delivery, tries[delivery])
+			http.Error(w, "Failing on purpose", http.StatusServiceUnavailable)
+			return
+		}
+
+		var pretty bytes.Buffer
+		if json.Indent(&pretty, body, "", "  ") != nil {
+			pretty.Write(body)
+		}
+		verified := "unsigned"
+		if *secret != "" {
+			verified = "signature ok"
+		}
+		log.Printf("%s %s (%s, try %d):\n%s", event, delivery, verified, tries[delivery], pretty.String())
+		w.WriteHeader(http.StatusNoContent)
+	}))
+}